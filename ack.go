@@ -0,0 +1,115 @@
+package zerolog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// AckWriter is implemented by writers able to acknowledge the persistence of
+// an event. It is used by the events created with Logger.Critical, for which
+// Msg only returns once the event has been acknowledged.
+type AckWriter interface {
+	// WriteAck writes p and returns nil once the underlying transport
+	// acknowledged its persistence. It must give up and return an error when
+	// ctx is done, and must not retain p.
+	WriteAck(ctx context.Context, level Level, p []byte) error
+}
+
+// AckPolicy defines how critical events are acknowledged.
+type AckPolicy struct {
+	// Timeout is the maximum duration of a single write attempt. Zero means no
+	// timeout.
+	Timeout time.Duration
+
+	// Retries is the number of additional attempts made after a failed one.
+	Retries int
+
+	// Backoff is the delay between two attempts.
+	Backoff time.Duration
+}
+
+// DefaultAckPolicy is the policy used by loggers with no AckPolicy set.
+var DefaultAckPolicy = AckPolicy{
+	Timeout: 5 * time.Second,
+	Retries: 2,
+	Backoff: 100 * time.Millisecond,
+}
+
+// writeAck writes p to w until it is acknowledged or the attempts of policy
+// are exhausted. Writers not implementing AckWriter are considered to have
+// persisted the event once WriteLevel returns without error.
+func writeAck(w LevelWriter, level Level, p []byte, policy AckPolicy) (err error) {
+	aw, ok := w.(AckWriter)
+	if !ok {
+		if a, isAdapter := w.(LevelWriterAdapter); isAdapter {
+			aw, ok = a.Writer.(AckWriter)
+		}
+	}
+	for attempt := 0; attempt <= policy.Retries; attempt++ {
+		if attempt > 0 && policy.Backoff > 0 {
+			time.Sleep(policy.Backoff)
+		}
+		if !ok {
+			if _, err = w.WriteLevel(level, p); err == nil {
+				return nil
+			}
+			continue
+		}
+		ctx, cancel := context.Background(), context.CancelFunc(func() {})
+		if policy.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, policy.Timeout)
+		}
+		err = aw.WriteAck(ctx, level, p)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("event not acknowledged after %d attempt(s): %v", policy.Retries+1, err)
+}
+
+// syncer is implemented by writers able to flush their content to stable
+// storage, like *os.File.
+type syncer interface {
+	Sync() error
+}
+
+// SyncAckWriter is an AckWriter acknowledging events once they are written
+// and, if Writer implements a Sync() error method like *os.File does, synced
+// to stable storage.
+type SyncAckWriter struct {
+	Writer io.Writer
+
+	mu sync.Mutex
+}
+
+// Write implements io.Writer.
+func (w *SyncAckWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Writer.Write(p)
+}
+
+// WriteLevel implements LevelWriter.
+func (w *SyncAckWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	return w.Write(p)
+}
+
+// WriteAck implements AckWriter.
+func (w *SyncAckWriter) WriteAck(ctx context.Context, level Level, p []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := w.Writer.Write(p); err != nil {
+		return err
+	}
+	if s, ok := w.Writer.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type flakyAckWriter struct {
+	bytes.Buffer
+	fails    int
+	attempts int
+}
+
+func (w *flakyAckWriter) WriteAck(ctx context.Context, level Level, p []byte) error {
+	w.attempts++
+	if w.attempts <= w.fails {
+		return errors.New("not persisted")
+	}
+	_, err := w.Write(p)
+	return err
+}
+
+type blockingAckWriter struct {
+	bytes.Buffer
+}
+
+func (w *blockingAckWriter) WriteAck(ctx context.Context, level Level, p []byte) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCritical(t *testing.T) {
+	t.Run("retry", func(t *testing.T) {
+		w := &flakyAckWriter{fails: 2}
+		log := New(w).Level(ErrorLevel).Sample(&BasicSampler{N: 0}).AckPolicy(AckPolicy{Retries: 2})
+		log.Critical().Str("order", "42").Msg("placed")
+		if got, want := decodeIfBinaryToString(w.Bytes()), `{"level":"info","order":"42","message":"placed"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+		if w.attempts != 3 {
+			t.Errorf("attempts = %d, want 3", w.attempts)
+		}
+	})
+	t.Run("exhausted", func(t *testing.T) {
+		var reported error
+		ErrorHandler = func(err error) { reported = err }
+		defer func() { ErrorHandler = nil }()
+		w := &flakyAckWriter{fails: 5}
+		log := New(w).AckPolicy(AckPolicy{Retries: 1})
+		log.Critical().Msg("placed")
+		if w.attempts != 2 {
+			t.Errorf("attempts = %d, want 2", w.attempts)
+		}
+		if reported == nil || !strings.Contains(reported.Error(), "not persisted") {
+			t.Errorf("unexpected reported error: %v", reported)
+		}
+	})
+	t.Run("timeout", func(t *testing.T) {
+		var reported error
+		ErrorHandler = func(err error) { reported = err }
+		defer func() { ErrorHandler = nil }()
+		log := New(&blockingAckWriter{}).AckPolicy(AckPolicy{Timeout: 10 * time.Millisecond})
+		log.Critical().Msg("placed")
+		if reported == nil || !strings.Contains(reported.Error(), context.DeadlineExceeded.Error()) {
+			t.Errorf("unexpected reported error: %v", reported)
+		}
+	})
+	t.Run("plain writer", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.Critical().Msg("placed")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"placed"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+	t.Run("disabled", func(t *testing.T) {
+		w := &flakyAckWriter{}
+		log := New(w).Level(Disabled)
+		log.Critical().Msg("placed")
+		if w.attempts != 0 {
+			t.Errorf("attempts = %d, want 0", w.attempts)
+		}
+	})
+}
+
+func TestSyncAckWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(&SyncAckWriter{Writer: out})
+	log.Critical().Msg("placed")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"placed"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
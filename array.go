@@ -2,6 +2,7 @@ package zerolog
 
 import (
 	"net"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -17,7 +18,8 @@ var arrayPool = &sync.Pool{
 // Array is used to prepopulate an array of items
 // which can be re-used to add to log messages.
 type Array struct {
-	buf []byte
+	buf      []byte
+	floatFmt *FloatFormat // float formatting of the logger, for marshalers
 }
 
 func putArray(a *Array) {
@@ -38,6 +40,7 @@ func putArray(a *Array) {
 func Arr() *Array {
 	a := arrayPool.Get().(*Array)
 	a.buf = a.buf[:0]
+	a.floatFmt = nil
 	return a
 }
 
@@ -60,6 +63,7 @@ func (a *Array) write(dst []byte) []byte {
 // interface and appends it to the array.
 func (a *Array) Object(obj LogObjectMarshaler) *Array {
 	e := Dict()
+	e.floatFmt = a.floatFmt
 	obj.MarshalZerologObject(e)
 	e.buf = enc.AppendEndMarker(e.buf)
 	a.buf = append(enc.AppendArrayDelim(a.buf), e.buf...)
@@ -93,7 +97,7 @@ func (a *Array) RawJSON(val []byte) *Array {
 
 // Err serializes and appends the err to the array.
 func (a *Array) Err(err error) *Array {
-	switch m := ErrorMarshalFunc(err).(type) {
+	switch m := marshalError(err, nil).(type) {
 	case LogObjectMarshaler:
 		e := newEvent(nil, 0)
 		e.buf = e.buf[:0]
@@ -123,7 +127,7 @@ func (a *Array) Bool(b bool) *Array {
 
 // Int appends i as a int to the array.
 func (a *Array) Int(i int) *Array {
-	a.buf = enc.AppendInt(enc.AppendArrayDelim(a.buf), i)
+	a.buf = appendInt64(enc.AppendArrayDelim(a.buf), int64(i))
 	return a
 }
 
@@ -147,13 +151,13 @@ func (a *Array) Int32(i int32) *Array {
 
 // Int64 appends i as a int64 to the array.
 func (a *Array) Int64(i int64) *Array {
-	a.buf = enc.AppendInt64(enc.AppendArrayDelim(a.buf), i)
+	a.buf = appendInt64(enc.AppendArrayDelim(a.buf), i)
 	return a
 }
 
 // Uint appends i as a uint to the array.
 func (a *Array) Uint(i uint) *Array {
-	a.buf = enc.AppendUint(enc.AppendArrayDelim(a.buf), i)
+	a.buf = appendUint64(enc.AppendArrayDelim(a.buf), uint64(i))
 	return a
 }
 
@@ -177,19 +181,33 @@ func (a *Array) Uint32(i uint32) *Array {
 
 // Uint64 appends i as a uint64 to the array.
 func (a *Array) Uint64(i uint64) *Array {
-	a.buf = enc.AppendUint64(enc.AppendArrayDelim(a.buf), i)
+	a.buf = appendUint64(enc.AppendArrayDelim(a.buf), i)
+	return a
+}
+
+// Int64Str appends i as a string to the array, regardless of
+// LargeIntAsString.
+func (a *Array) Int64Str(i int64) *Array {
+	a.buf = enc.AppendString(enc.AppendArrayDelim(a.buf), strconv.FormatInt(i, 10))
+	return a
+}
+
+// Uint64Str appends i as a string to the array, regardless of
+// LargeIntAsString.
+func (a *Array) Uint64Str(i uint64) *Array {
+	a.buf = enc.AppendString(enc.AppendArrayDelim(a.buf), strconv.FormatUint(i, 10))
 	return a
 }
 
 // Float32 appends f as a float32 to the array.
 func (a *Array) Float32(f float32) *Array {
-	a.buf = enc.AppendFloat32(enc.AppendArrayDelim(a.buf), f, FloatingPointPrecision)
+	a.buf = appendFloat32(enc.AppendArrayDelim(a.buf), f, a.floatFmt)
 	return a
 }
 
 // Float64 appends f as a float64 to the array.
 func (a *Array) Float64(f float64) *Array {
-	a.buf = enc.AppendFloat64(enc.AppendArrayDelim(a.buf), f, FloatingPointPrecision)
+	a.buf = appendFloat64(enc.AppendArrayDelim(a.buf), f, a.floatFmt)
 	return a
 }
 
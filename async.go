@@ -0,0 +1,210 @@
+package zerolog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAsyncQueueFull is the error reported to AsyncOptions.Sink for the
+// events dropped by an AsyncWriter.
+var ErrAsyncQueueFull = errors.New("async writer queue full, event dropped")
+
+// errAsyncClosed is returned by the AsyncWriter methods once closed.
+var errAsyncClosed = errors.New("zerolog: async writer closed")
+
+// AsyncOptions configures an AsyncWriter.
+type AsyncOptions struct {
+	// QueueSize is the number of events queued while waiting to be written.
+	// Defaults to 1024.
+	QueueSize int
+
+	// Block, if true, makes Write wait for room in the queue instead of
+	// dropping the event when the queue is full.
+	Block bool
+
+	// Sink, if not nil, receives the write failures of the wrapped writer
+	// and a Drop error for each dropped event. Write failures are otherwise
+	// reported to ErrorHandler, and drops only counted.
+	Sink ErrorSink
+}
+
+type asyncEvent struct {
+	level Level
+	p     []byte
+}
+
+// AsyncWriter is a LevelWriter queueing events on a bounded queue and
+// writing them to the wrapped writer from a background go-routine, so slow
+// outputs do not slow down the logging go-routines. See NewAsyncWriter.
+type AsyncWriter struct {
+	w     LevelWriter
+	opts  AsyncOptions
+	pool  sync.Pool
+	queue chan asyncEvent
+	flush chan chan error
+	done  chan struct{}
+
+	mu       sync.RWMutex
+	closed   bool
+	dropped  uint64
+	closeErr error // set by the background go-routine before done is closed
+}
+
+// NewAsyncWriter returns an AsyncWriter writing to w from a background
+// go-routine, until Close is called:
+//
+//	w := zerolog.NewAsyncWriter(os.Stdout, zerolog.AsyncOptions{QueueSize: 4096})
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Events written when the queue is full are dropped, unless opts.Block is
+// set, and counted by Dropped. The wrapped writer is only used from the
+// background go-routine and does not need to be thread safe.
+func NewAsyncWriter(w io.Writer, opts AsyncOptions) *AsyncWriter {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = 1024
+	}
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	aw := &AsyncWriter{
+		w:     lw,
+		opts:  opts,
+		queue: make(chan asyncEvent, opts.QueueSize),
+		flush: make(chan chan error),
+		done:  make(chan struct{}),
+	}
+	aw.pool.New = func() interface{} {
+		return make([]byte, 0, 512)
+	}
+	go aw.run()
+	return aw
+}
+
+// Write implements the io.Writer interface.
+func (w *AsyncWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The event is copied and
+// queued.
+func (w *AsyncWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errAsyncClosed
+	}
+	evt := asyncEvent{level, append(w.pool.Get().([]byte)[:0], p...)}
+	if w.opts.Block {
+		w.queue <- evt
+		return len(p), nil
+	}
+	select {
+	case w.queue <- evt:
+	default:
+		w.putBuf(evt.p)
+		atomic.AddUint64(&w.dropped, 1)
+		if w.opts.Sink != nil {
+			w.opts.Sink.HandleError(&InternalError{Kind: Drop, Level: level, Err: ErrAsyncQueueFull})
+		}
+	}
+	return len(p), nil
+}
+
+// Dropped returns the number of events dropped because the queue was full.
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Flush writes the queued events and flushes the wrapped writer.
+func (w *AsyncWriter) Flush() error {
+	return w.FlushContext(context.Background())
+}
+
+// FlushContext is like Flush but returns ctx.Err() if ctx is done before the
+// queued events are written. The events are still written afterwards.
+func (w *AsyncWriter) FlushContext(ctx context.Context) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errAsyncClosed
+	}
+	c := make(chan error, 1)
+	select {
+	case w.flush <- c:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-c:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes the queued events, stops the background go-routine and closes
+// the wrapped writer if it implements io.Closer.
+func (w *AsyncWriter) Close() error {
+	return w.CloseContext(context.Background())
+}
+
+// CloseContext is like Close but returns ctx.Err() if ctx is done before the
+// queued events are written. The remaining events are then written by the
+// background go-routine, and the wrapped writer closed, without waiting.
+func (w *AsyncWriter) CloseContext(ctx context.Context) error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errAsyncClosed
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	select {
+	case <-w.done:
+		return w.closeErr
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case evt, ok := <-w.queue:
+			if !ok {
+				flush(w.w)
+				if closer, ok := w.w.(io.Closer); ok {
+					w.closeErr = closer.Close()
+				}
+				return
+			}
+			w.write(evt)
+		case c := <-w.flush:
+			for n := len(w.queue); n > 0; n-- {
+				w.write(<-w.queue)
+			}
+			c <- flush(w.w)
+		}
+	}
+}
+
+func (w *AsyncWriter) write(evt asyncEvent) {
+	if _, err := w.w.WriteLevel(evt.level, evt.p); err != nil {
+		reportError(w.opts.Sink, nil, WriteFailure, evt.level, err)
+	}
+	w.putBuf(evt.p)
+}
+
+func (w *AsyncWriter) putBuf(p []byte) {
+	// Keep the pooled buffers of similar sizes, see golang.org/issue/23199.
+	if cap(p) <= 1<<16 {
+		w.pool.Put(p[:0])
+	}
+}
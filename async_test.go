@@ -0,0 +1,106 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gatedWriter records the writes, each waiting for gate to be readable.
+type gatedWriter struct {
+	gate    chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	levels  []Level
+	flushed int
+	closed  bool
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+func (w *gatedWriter) WriteLevel(level Level, p []byte) (int, error) {
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.levels = append(w.levels, level)
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flushed++
+	return nil
+}
+
+func (w *gatedWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func TestAsyncWriter(t *testing.T) {
+	gw := &gatedWriter{gate: make(chan struct{})}
+	close(gw.gate)
+	w := NewAsyncWriter(gw, AsyncOptions{})
+	log := New(w)
+	log.Info().Msg("one")
+	log.Warn().Msg("two")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	got := decodeIfBinaryToString(gw.buf.Bytes())
+	want := `{"level":"info","message":"one"}` + "\n" + `{"level":"warn","message":"two"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if gw.flushed != 1 || len(gw.levels) != 2 || gw.levels[1] != WarnLevel {
+		t.Errorf("flushed = %d, levels = %v", gw.flushed, gw.levels)
+	}
+	if err := w.Close(); err != nil || !gw.closed {
+		t.Errorf("Close() = %v, closed = %v", err, gw.closed)
+	}
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Error("Write after Close: expected an error")
+	}
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	gw := &gatedWriter{gate: make(chan struct{})}
+	var drops int
+	w := NewAsyncWriter(gw, AsyncOptions{
+		QueueSize: 2,
+		Sink: ErrorSinkFunc(func(err *InternalError) {
+			if err.Kind == Drop && errors.Is(err, ErrAsyncQueueFull) {
+				drops++
+			}
+		}),
+	})
+	log := New(w)
+	for i := 0; i < 10; i++ {
+		log.Info().Int("i", i).Msg("")
+	}
+	// At most one event is being written and two queued.
+	if d := w.Dropped(); d < 7 || int(d) != drops {
+		t.Errorf("Dropped() = %d, drops reported = %d", d, drops)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := w.FlushContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("FlushContext() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	close(gw.gate)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(gw.levels); uint64(n)+w.Dropped() != 10 {
+		t.Errorf("written %d events, dropped %d, want 10 in total", n, w.Dropped())
+	}
+}
@@ -0,0 +1,152 @@
+package zerolog
+
+import (
+	"sync"
+	"time"
+)
+
+// AutoDebug is a Hook watching the rate of error level events and raising
+// the level of a registered logger for a bounded period when it exceeds a
+// threshold, giving debug logs around incidents without running with debug
+// logs enabled all the time.
+//
+//	db := zerolog.New(w).Level(zerolog.InfoLevel)
+//	db = db.Hook(&zerolog.AutoDebug{
+//	    Name:      "db",
+//	    Threshold: 10,
+//	    Window:    time.Minute,
+//	    Duration:  5 * time.Minute,
+//	})
+//	zerolog.Register("db", &db)
+//
+// The hook must be installed before the logger is registered, as hooks are
+// copied into the logger.
+type AutoDebug struct {
+	// Name is the name under which the logger to adapt is registered. See
+	// Register.
+	Name string
+
+	// Threshold is the number of error level (or higher) events within
+	// Window triggering the activation. Defaults to 1.
+	Threshold int
+
+	// Window is the period over which errors are counted. Defaults to 1
+	// minute.
+	Window time.Duration
+
+	// Level is the level set while active. The zero value is DebugLevel.
+	Level Level
+
+	// Duration is how long the level stays raised before being restored.
+	// Defaults to 1 minute.
+	Duration time.Duration
+
+	// Cooldown is the minimum time between the end of an activation and the
+	// next one. Defaults to Duration.
+	Cooldown time.Duration
+
+	// OnChange, if not nil, is called when the level is raised (active is
+	// true) and restored (active is false).
+	OnChange func(name string, active bool)
+
+	mu       sync.Mutex
+	errors   []time.Time
+	active   bool
+	previous Level
+	until    time.Time
+	timer    *time.Timer
+}
+
+// Run implements the Hook interface.
+func (a *AutoDebug) Run(e *Event, level Level, msg string) {
+	if !level.AtLeast(ErrorLevel) || level.AtLeast(NoLevel) {
+		return
+	}
+	now := time.Now()
+	a.mu.Lock()
+	if a.active || now.Before(a.until) || !a.record(now) {
+		a.mu.Unlock()
+		return
+	}
+	l := Registered(a.Name)
+	if l == nil {
+		a.mu.Unlock()
+		return
+	}
+	raised := a.Level
+	a.previous = l.GetLevel()
+	if raised.AtLeast(a.previous) {
+		// Already verbose enough.
+		a.mu.Unlock()
+		return
+	}
+	if SetRegisteredLevel(a.Name, raised) != nil {
+		a.mu.Unlock()
+		return
+	}
+	a.active = true
+	a.errors = a.errors[:0]
+	a.timer = time.AfterFunc(a.duration(), a.Restore)
+	onChange := a.OnChange
+	a.mu.Unlock()
+	if onChange != nil {
+		onChange(a.Name, true)
+	}
+}
+
+// record adds an error at time now and reports if the threshold is reached.
+// a.mu must be held.
+func (a *AutoDebug) record(now time.Time) bool {
+	threshold := a.Threshold
+	if threshold <= 0 {
+		threshold = 1
+	}
+	window := a.Window
+	if window <= 0 {
+		window = time.Minute
+	}
+	cutoff := now.Add(-window)
+	i := 0
+	for i < len(a.errors) && !a.errors[i].After(cutoff) {
+		i++
+	}
+	a.errors = append(a.errors[i:], now)
+	return len(a.errors) >= threshold
+}
+
+func (a *AutoDebug) duration() time.Duration {
+	if a.Duration <= 0 {
+		return time.Minute
+	}
+	return a.Duration
+}
+
+// Active reports whether the level is currently raised.
+func (a *AutoDebug) Active() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.active
+}
+
+// Restore restores the level the logger had before the activation, if
+// active. It is called automatically after Duration.
+func (a *AutoDebug) Restore() {
+	a.mu.Lock()
+	if !a.active {
+		a.mu.Unlock()
+		return
+	}
+	a.timer.Stop()
+	a.active = false
+	cooldown := a.Cooldown
+	if cooldown <= 0 {
+		cooldown = a.duration()
+	}
+	a.until = time.Now().Add(cooldown)
+	_ = SetRegisteredLevel(a.Name, a.previous)
+	onChange := a.OnChange
+	a.mu.Unlock()
+	if onChange != nil {
+		onChange(a.Name, false)
+	}
+}
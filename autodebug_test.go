@@ -0,0 +1,73 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAutoDebug(t *testing.T) {
+	var changes []bool
+	ad := &AutoDebug{
+		Name:      "autodebug",
+		Threshold: 2,
+		Window:    time.Minute,
+		Duration:  time.Hour,
+		Cooldown:  time.Hour,
+		OnChange: func(name string, active bool) {
+			changes = append(changes, active)
+		},
+	}
+	out := &bytes.Buffer{}
+	l := New(out).Level(InfoLevel).Hook(ad)
+	Register("autodebug", &l)
+	defer Unregister("autodebug")
+
+	l.Error().Err(errors.New("first")).Msg("")
+	if ad.Active() || l.GetLevel() != InfoLevel {
+		t.Fatal("activated below threshold")
+	}
+	l.Error().Err(errors.New("second")).Msg("")
+	if !ad.Active() || l.GetLevel() != DebugLevel {
+		t.Fatalf("not activated, level = %v", l.GetLevel())
+	}
+	out.Reset()
+	l.Debug().Msg("verbose")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"debug","message":"verbose"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	ad.Restore()
+	if ad.Active() || l.GetLevel() != InfoLevel {
+		t.Fatalf("not restored, level = %v", l.GetLevel())
+	}
+	// Cooldown prevents re-activation.
+	l.Error().Msg("")
+	l.Error().Msg("")
+	if ad.Active() {
+		t.Error("activated during cooldown")
+	}
+	if want := []bool{true, false}; len(changes) != 2 || changes[0] != want[0] || changes[1] != want[1] {
+		t.Errorf("OnChange calls = %v, want %v", changes, want)
+	}
+}
+
+func TestAutoDebugExpires(t *testing.T) {
+	ad := &AutoDebug{Name: "autodebug", Duration: 10 * time.Millisecond}
+	l := New(nil).Level(WarnLevel).Hook(ad)
+	Register("autodebug", &l)
+	defer Unregister("autodebug")
+
+	l.Error().Msg("")
+	if !ad.Active() {
+		t.Fatal("not activated")
+	}
+	deadline := time.Now().Add(time.Second)
+	for ad.Active() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if ad.Active() || l.GetLevel() != WarnLevel {
+		t.Errorf("not restored after Duration, level = %v", l.GetLevel())
+	}
+}
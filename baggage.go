@@ -0,0 +1,43 @@
+package zerolog
+
+import "context"
+
+// BaggageHook is a Hook copying baggage members, like the ones propagated by
+// OpenTelemetry, from the event's context into event fields. The context is
+// the one attached with Event.Ctx or Context.Ctx.
+//
+// To keep zerolog free of tracing dependencies, members are looked up using
+// the Member function. With OpenTelemetry:
+//
+//	hook := zerolog.BaggageHook{
+//	    Keys: []string{"tenant", "plan"},
+//	    Member: func(ctx context.Context, key string) (string, bool) {
+//	        m := baggage.FromContext(ctx).Member(key)
+//	        return m.Value(), m.Key() != ""
+//	    },
+//	}
+//	logger := zerolog.New(os.Stdout).Hook(hook)
+type BaggageHook struct {
+	// Keys is the allowlist of baggage members copied into fields. Baggage is
+	// set by upstream services, so only known members should be logged.
+	Keys []string
+
+	// Prefix is prepended to the member keys to build field keys.
+	Prefix string
+
+	// Member returns the value of the member key of the baggage of ctx, and
+	// whether the member is present.
+	Member func(ctx context.Context, key string) (string, bool)
+}
+
+// Run implements the Hook interface.
+func (h BaggageHook) Run(e *Event, level Level, message string) {
+	if h.Member == nil || e.ctx == nil {
+		return
+	}
+	for _, key := range h.Keys {
+		if v, ok := h.Member(e.ctx, key); ok {
+			e.Str(h.Prefix+key, v)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type baggageKey struct{}
+
+func TestBaggageHook(t *testing.T) {
+	hook := BaggageHook{
+		Keys:   []string{"tenant", "plan"},
+		Prefix: "baggage.",
+		Member: func(ctx context.Context, key string) (string, bool) {
+			m, _ := ctx.Value(baggageKey{}).(map[string]string)
+			v, ok := m[key]
+			return v, ok
+		},
+	}
+	ctx := context.WithValue(context.Background(), baggageKey{}, map[string]string{
+		"tenant": "acme",
+		"secret": "s3cr3t",
+	})
+
+	out := &bytes.Buffer{}
+	log := New(out).Hook(hook)
+	log.Info().Ctx(ctx).Msg("")
+	log.Info().Msg("")
+	ctxLog := log.With().Ctx(ctx).Logger()
+	ctxLog.Info().Msg("")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","baggage.tenant":"acme"}` + "\n" +
+		`{"level":"info"}` + "\n" +
+		`{"level":"info","baggage.tenant":"acme"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -3,6 +3,7 @@ package zerolog
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net"
 	"testing"
@@ -224,6 +225,68 @@ func BenchmarkLogWithoutDeDupLong(b *testing.B) {
 		}
 	})
 }
+func BenchmarkLogWithAutoDeDup(b *testing.B) {
+	logger := New(io.Discard).With().Str("foo", "bar").Logger().AutoDeDup(DeDupModeShallow)
+	b.Run("unique", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Str("baz", "qux").Int("n", i).Msg(fakeMessage)
+		}
+	})
+	b.Run("duplicate", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Str("foo", "baz").Int("n", i).Msg(fakeMessage)
+		}
+	})
+}
+
+func BenchmarkGlobalAutoDeDup(b *testing.B) {
+	logger := New(io.Discard).With().Str("foo", "bar").Logger()
+	for _, mode := range []DeDupMode{DeDupModeNone, DeDupModeShallow, DeDupModeDeep} {
+		AutoDeDup = mode
+		b.Run(fmt.Sprintf("mode=%d/unique", mode), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Info().Str("baz", "qux").Int("n", i).Msg(fakeMessage)
+			}
+		})
+		b.Run(fmt.Sprintf("mode=%d/duplicate", mode), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Info().Str("foo", "baz").Int("n", i).Msg(fakeMessage)
+			}
+		})
+	}
+	AutoDeDup = DeDupModeNone
+}
+
+func BenchmarkStripFieldCategories(b *testing.B) {
+	logger := New(io.Discard).With().Str("foo", "bar").Logger()
+	defer func() {
+		fieldCategories.keys = map[string]string{}
+		StripFieldCategories()
+	}()
+	RegisterFieldCategory(CategoryPayload, "body")
+	for _, stripped := range []bool{false, true} {
+		if stripped {
+			StripFieldCategories(CategoryPayload)
+		}
+		b.Run(fmt.Sprintf("stripped=%v/without", stripped), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Info().Str("baz", "qux").Int("n", i).Msg(fakeMessage)
+			}
+		})
+		b.Run(fmt.Sprintf("stripped=%v/with", stripped), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				logger.Info().Str("body", "secret").Int("n", i).Msg(fakeMessage)
+			}
+		})
+	}
+}
+
 func BenchmarkLogWithDeDupDeep(b *testing.B) {
 	logger := New(io.Discard).With().
 		Str("foo", "bar").
@@ -537,3 +600,44 @@ func BenchmarkContextFieldType(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkLogMsgf(b *testing.B) {
+	logger := New(io.Discard)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msgf("request %s took %d ms", "GET /index", 42)
+		}
+	})
+}
+
+func BenchmarkLogMsgfWithHook(b *testing.B) {
+	logger := New(io.Discard).Hook(HookFunc(func(e *Event, level Level, msg string) {}))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msgf("request %s took %d ms", "GET /index", 42)
+		}
+	})
+}
+
+func BenchmarkInternedStr(b *testing.B) {
+	values := []string{"info", "payment-service", "checkout"}
+	b.Run("Str", func(b *testing.B) {
+		logger := New(io.Discard)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Str("service", values[1]).Str("op", values[2]).Msg("")
+		}
+	})
+	b.Run("Interned", func(b *testing.B) {
+		logger := New(io.Discard)
+		service, op := Intern(values[1]), Intern(values[2])
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Interned("service", service).Interned("op", op).Msg("")
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package zerolog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy defines what a Broker does when a subscriber's buffer is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the event being written.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest buffered event to make room for the event
+	// being written.
+	DropOldest
+)
+
+// Broker is a writer fanning out events to dynamically attached subscribers,
+// for in-process live log streaming like an admin UI tail or a websocket
+// endpoint. Writes never block: events are dropped according to each
+// subscription's DropPolicy when its buffer is full.
+//
+// Broker is usually combined with the main output using MultiLevelWriter:
+//
+//	broker := zerolog.NewBroker()
+//	log := zerolog.New(zerolog.MultiLevelWriter(os.Stdout, broker))
+//
+//	sub := broker.Subscribe(100, zerolog.DropOldest)
+//	defer sub.Close()
+//	for p := range sub.C {
+//	    ws.WriteMessage(websocket.TextMessage, p)
+//	}
+type Broker struct {
+	mu     sync.RWMutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription is a subscriber attached to a Broker.
+type Subscription struct {
+	// C delivers the encoded events. It is closed when the subscription or
+	// the broker is closed.
+	C <-chan []byte
+
+	c       chan []byte
+	policy  DropPolicy
+	level   Level
+	mu      sync.Mutex
+	dropped uint64
+	broker  *Broker
+}
+
+// NewBroker creates a Broker with no subscribers.
+func NewBroker() *Broker {
+	return &Broker{subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe attaches a subscriber receiving the events written from now on,
+// buffering up to size events. The subscription must be closed when not used
+// anymore.
+func (b *Broker) Subscribe(size int, policy DropPolicy) *Subscription {
+	return b.SubscribeLevel(size, policy, TraceLevel)
+}
+
+// SubscribeLevel is like Subscribe, but only the events with a level greater
+// than or equal to level, or with no level, are delivered.
+func (b *Broker) SubscribeLevel(size int, policy DropPolicy, level Level) *Subscription {
+	if size < 1 {
+		size = 1
+	}
+	c := make(chan []byte, size)
+	s := &Subscription{C: c, c: c, policy: policy, level: level, broker: b}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(c)
+		return s
+	}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Subscribers returns the number of attached subscribers.
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+// Write implements the io.Writer interface.
+func (b *Broker) Write(p []byte) (n int, err error) {
+	return b.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. A copy of p is delivered
+// to each subscriber.
+func (b *Broker) WriteLevel(level Level, p []byte) (n int, err error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var cp []byte
+	for s := range b.subs {
+		if !level.AtLeast(s.level) && level != NoLevel {
+			continue
+		}
+		if cp == nil {
+			// Subscribers only read the events, they can share a copy.
+			cp = append(make([]byte, 0, len(p)), p...)
+		}
+		s.deliver(cp)
+	}
+	return len(p), nil
+}
+
+// Close closes all the subscriptions. Events written afterwards are
+// discarded.
+func (b *Broker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		close(s.c)
+		delete(b.subs, s)
+	}
+	b.closed = true
+	return nil
+}
+
+func (s *Subscription) deliver(p []byte) {
+	select {
+	case s.c <- p:
+		return
+	default:
+	}
+	if s.policy != DropOldest {
+		atomic.AddUint64(&s.dropped, 1)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		select {
+		case s.c <- p:
+			return
+		default:
+		}
+		select {
+		case <-s.c:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+	}
+}
+
+// Dropped returns the number of events dropped because the subscription's
+// buffer was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// Close detaches the subscriber from the broker and closes C.
+func (s *Subscription) Close() {
+	b := s.broker
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[s]; ok {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
@@ -0,0 +1,61 @@
+package zerolog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func drain(s *Subscription) (events []string) {
+	for {
+		select {
+		case p, ok := <-s.C:
+			if !ok {
+				return events
+			}
+			events = append(events, decodeIfBinaryToString(p))
+		default:
+			return events
+		}
+	}
+}
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	log := New(b)
+	newest := b.Subscribe(2, DropNewest)
+	oldest := b.Subscribe(2, DropOldest)
+	warn := b.SubscribeLevel(10, DropNewest, WarnLevel)
+	if n := b.Subscribers(); n != 3 {
+		t.Fatalf("Subscribers() = %d, want 3", n)
+	}
+
+	log.Info().Int("n", 1).Msg("")
+	log.Info().Int("n", 2).Msg("")
+	log.Warn().Int("n", 3).Msg("")
+
+	if got, want := drain(newest), []string{`{"level":"info","n":1}` + "\n", `{"level":"info","n":2}` + "\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DropNewest got %q, want %q", got, want)
+	}
+	if got, want := drain(oldest), []string{`{"level":"info","n":2}` + "\n", `{"level":"warn","n":3}` + "\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DropOldest got %q, want %q", got, want)
+	}
+	if got, want := drain(warn), []string{`{"level":"warn","n":3}` + "\n"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SubscribeLevel got %q, want %q", got, want)
+	}
+	if newest.Dropped() != 1 || oldest.Dropped() != 1 || warn.Dropped() != 0 {
+		t.Errorf("Dropped() = %d, %d, %d, want 1, 1, 0", newest.Dropped(), oldest.Dropped(), warn.Dropped())
+	}
+
+	newest.Close()
+	log.Info().Msg("")
+	if _, ok := <-newest.C; ok {
+		t.Error("closed subscription received an event")
+	}
+	b.Close()
+	if _, ok := <-oldest.C; !ok {
+		t.Error("buffered event lost on broker close")
+	}
+	if _, ok := <-oldest.C; ok {
+		t.Error("subscription not closed with the broker")
+	}
+}
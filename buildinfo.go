@@ -0,0 +1,57 @@
+package zerolog
+
+import "sync"
+
+type buildInfo struct {
+	version  string
+	revision string
+	dirty    bool
+	hasVCS   bool
+}
+
+var (
+	buildInfoOnce   sync.Once
+	cachedBuildInfo buildInfo
+)
+
+func getBuildInfo() buildInfo {
+	buildInfoOnce.Do(func() {
+		cachedBuildInfo = readBuildInfo()
+	})
+	return cachedBuildInfo
+}
+
+// BuildInfo adds the main module version, VCS revision and dirty flag, as
+// read from debug.ReadBuildInfo, to the logger context using the
+// BuildVersionFieldName, BuildRevisionFieldName and BuildDirtyFieldName keys.
+// Fields not available in the binary (e.g. built without VCS stamping) are
+// omitted. The build information is read once and cached.
+//
+// If key is passed, the fields are nested in a dict under key instead of being
+// added at the top level.
+func (c Context) BuildInfo(key ...string) Context {
+	bi := getBuildInfo()
+	var dst *Event
+	if len(key) > 0 {
+		dst = Dict()
+	} else {
+		dst = newEvent(nil, 0)
+	}
+	if bi.version != "" {
+		dst.Str(BuildVersionFieldName, bi.version)
+	}
+	if bi.hasVCS {
+		if bi.revision != "" {
+			dst.Str(BuildRevisionFieldName, bi.revision)
+		}
+		dst.Bool(BuildDirtyFieldName, bi.dirty)
+	}
+	if len(key) > 0 {
+		return c.Dict(key[0], dst)
+	}
+	if len(dst.buf) > 1 {
+		c.l.context = enc.AppendObjectData(c.l.context, dst.buf)
+	}
+	putEvent(dst)
+	return c
+}
@@ -0,0 +1,28 @@
+//go:build go1.18
+// +build go1.18
+
+package zerolog
+
+import "runtime/debug"
+
+func readBuildInfo() buildInfo {
+	var bi buildInfo
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return bi
+	}
+	if v := info.Main.Version; v != "" && v != "(devel)" {
+		bi.version = v
+	}
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			bi.revision = s.Value
+			bi.hasVCS = true
+		case "vcs.modified":
+			bi.dirty = s.Value == "true"
+			bi.hasVCS = true
+		}
+	}
+	return bi
+}
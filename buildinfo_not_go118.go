@@ -0,0 +1,17 @@
+//go:build !go1.18
+// +build !go1.18
+
+package zerolog
+
+import "runtime/debug"
+
+// VCS information is only stamped in binaries since go 1.18.
+func readBuildInfo() buildInfo {
+	var bi buildInfo
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v := info.Main.Version; v != "" && v != "(devel)" {
+			bi.version = v
+		}
+	}
+	return bi
+}
@@ -0,0 +1,37 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestContextBuildInfo(t *testing.T) {
+	buildInfoOnce.Do(func() {})
+	saved := cachedBuildInfo
+	defer func() {
+		cachedBuildInfo = saved
+	}()
+
+	cachedBuildInfo = buildInfo{version: "v1.2.3", revision: "abc123", dirty: true, hasVCS: true}
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("foo", "bar").BuildInfo().Logger()
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar","version":"v1.2.3","revision":"abc123","dirty":true}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = New(out).With().BuildInfo("build").Logger()
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"build":{"version":"v1.2.3","revision":"abc123","dirty":true}}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	cachedBuildInfo = buildInfo{}
+	out.Reset()
+	log = New(out).With().Str("foo", "bar").BuildInfo().Logger()
+	log.Log().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"foo":"bar"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,108 @@
+package zerolog
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// BytesEncoding selects how Bytes fields are encoded. See BytesFormat.
+type BytesEncoding uint8
+
+const (
+	// BytesString writes the bytes as a string, the default.
+	BytesString BytesEncoding = iota
+
+	// BytesHex writes the bytes as a hex string.
+	BytesHex
+
+	// BytesBase64 writes the bytes as a standard base64 string.
+	BytesBase64
+
+	// BytesBase64URL writes the bytes as an unpadded base64url string.
+	BytesBase64URL
+)
+
+// BytesFormat controls how the byte slice fields added with Bytes and Hex are
+// formatted by a Logger, for instance to log binary protocol payloads. See
+// Logger.BytesFormat.
+type BytesFormat struct {
+	// Encoding is the encoding of Bytes fields. Hex fields are always hex
+	// encoded.
+	Encoding BytesEncoding
+
+	// AutoHex writes the Bytes fields holding non printable data, like
+	// invalid UTF-8 or control characters, as hex strings when Encoding is
+	// BytesString.
+	AutoHex bool
+
+	// MaxLen, if positive, truncates values longer than MaxLen bytes. The
+	// encoded prefix is then followed by the size and the start of the
+	// SHA-256 of the full value, like "...(4096 bytes, sha256:9f86d081884c7d65)",
+	// so identical payloads can still be matched.
+	MaxLen int
+}
+
+// appendBytes appends val using bf if set. If hexField, val is hex encoded.
+func appendBytes(dst, val []byte, hexField bool, bf *BytesFormat) []byte {
+	if bf == nil {
+		if hexField {
+			return enc.AppendHex(dst, val)
+		}
+		return enc.AppendBytes(dst, val)
+	}
+	var suffix []byte
+	if bf.MaxLen > 0 && len(val) > bf.MaxLen {
+		sum := sha256.Sum256(val)
+		suffix = append(suffix, "...("...)
+		suffix = strconv.AppendInt(suffix, int64(len(val)), 10)
+		suffix = append(suffix, " bytes, sha256:"...)
+		suffix = append(suffix, hex.EncodeToString(sum[:8])...)
+		suffix = append(suffix, ')')
+		val = val[:bf.MaxLen]
+	}
+	encoding := bf.Encoding
+	if hexField || encoding == BytesString && bf.AutoHex && !printable(val) {
+		encoding = BytesHex
+	}
+	var s []byte
+	switch encoding {
+	case BytesHex:
+		if suffix == nil {
+			return enc.AppendHex(dst, val)
+		}
+		s = make([]byte, hex.EncodedLen(len(val)), hex.EncodedLen(len(val))+len(suffix))
+		hex.Encode(s, val)
+	case BytesBase64:
+		s = make([]byte, base64.StdEncoding.EncodedLen(len(val)), base64.StdEncoding.EncodedLen(len(val))+len(suffix))
+		base64.StdEncoding.Encode(s, val)
+	case BytesBase64URL:
+		s = make([]byte, base64.RawURLEncoding.EncodedLen(len(val)), base64.RawURLEncoding.EncodedLen(len(val))+len(suffix))
+		base64.RawURLEncoding.Encode(s, val)
+	default:
+		if suffix == nil {
+			return enc.AppendBytes(dst, val)
+		}
+		return enc.AppendBytes(dst, append(append(make([]byte, 0, len(val)+len(suffix)), val...), suffix...))
+	}
+	return enc.AppendString(dst, string(append(s, suffix...)))
+}
+
+// printable returns true if p is valid UTF-8 text without control characters
+// other than tabs and line breaks.
+func printable(p []byte) bool {
+	for len(p) > 0 {
+		r, size := utf8.DecodeRune(p)
+		if r == utf8.RuneError && size <= 1 {
+			return false
+		}
+		if r != '\t' && r != '\n' && r != '\r' && !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+		p = p[size:]
+	}
+	return true
+}
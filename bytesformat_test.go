@@ -0,0 +1,34 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBytesFormat(t *testing.T) {
+	payload := []byte{0xde, 0xad, 0xbe, 0xef, 0xfb, 0xff}
+	tests := []struct {
+		bf   BytesFormat
+		want string
+	}{
+		{BytesFormat{AutoHex: true}, `{"text":"hello","bin":"deadbeeffbff","hex":"deadbeeffbff"}`},
+		{BytesFormat{Encoding: BytesBase64}, `{"text":"aGVsbG8=","bin":"3q2+7/v/","hex":"deadbeeffbff"}`},
+		{BytesFormat{Encoding: BytesBase64URL}, `{"text":"aGVsbG8","bin":"3q2-7_v_","hex":"deadbeeffbff"}`},
+		{BytesFormat{MaxLen: 4, AutoHex: true}, `{"text":"hell...(5 bytes, sha256:2cf24dba5fb0a30e)","bin":"deadbeef...(6 bytes, sha256:fe1475a417c33d9e)","hex":"deadbeef...(6 bytes, sha256:fe1475a417c33d9e)"}`},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		log := New(out).BytesFormat(tt.bf)
+		log.Log().Bytes("text", []byte("hello")).Bytes("bin", payload).Hex("hex", payload).Send()
+		if got := decodeIfBinaryToString(out.Bytes()); got != tt.want+"\n" {
+			t.Errorf("%+v: invalid log output:\ngot:  %v\nwant: %v", tt.bf, got, tt.want)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	log := New(out).BytesFormat(BytesFormat{Encoding: BytesBase64URL}).With().Bytes("id", payload).Logger()
+	log.Log().Send()
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"id":"3q2-7_v_"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
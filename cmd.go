@@ -0,0 +1,141 @@
+package zerolog
+
+import (
+	"os/exec"
+	"strings"
+	"time"
+)
+
+var (
+	// CmdRedactedFlags are the flags which values are replaced by
+	// RedactedValue by Event.Cmd. A flag matches if its name, stripped of its
+	// leading dashes, contains one of them, case insensitively, so "password"
+	// matches "--db-password". The value is either joined to the flag by a
+	// '=' or the next argument.
+	CmdRedactedFlags = []string{"password", "passwd", "secret", "token", "apikey", "api-key"}
+
+	// CmdEnvAllowlist are the environment variables of the command added by
+	// Event.Cmd. Only the variables set in exec.Cmd.Env are considered, not
+	// the inherited environment.
+	CmdEnvAllowlist []string
+)
+
+// Cmd adds the field key with a dict describing the command cmd: its
+// arguments, with the values of the CmdRedactedFlags redacted, its working
+// directory and its environment variables listed in CmdEnvAllowlist:
+//
+//	log.Info().Cmd("cmd", cmd).Msg("starting")
+//	// {"level":"info","cmd":{"args":["pg_dump","--password=[REDACTED]","db"],"dir":"/tmp"},"message":"starting"}
+func (e *Event) Cmd(key string, cmd *exec.Cmd) *Event {
+	if e == nil || cmd == nil {
+		return e
+	}
+	e.guardUse("Cmd")
+	d := Dict()
+	args := cmd.Args
+	if len(args) == 0 {
+		args = []string{cmd.Path}
+	}
+	d.Strs("args", redactCmdArgs(args))
+	if cmd.Dir != "" {
+		d.Str("dir", cmd.Dir)
+	}
+	if env := allowedCmdEnv(cmd.Env); env != nil {
+		d.Dict("env", env)
+	}
+	return e.Dict(key, d)
+}
+
+// redactCmdArgs returns args with the values of the CmdRedactedFlags
+// replaced by RedactedValue.
+func redactCmdArgs(args []string) []string {
+	var out []string
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		name := strings.TrimLeft(arg, "-")
+		eq := strings.IndexByte(arg, '=')
+		if eq >= 0 {
+			name = arg[len(arg)-len(name) : eq]
+		}
+		if !isRedactedFlag(name) {
+			continue
+		}
+		if out == nil {
+			out = append([]string(nil), args...)
+		}
+		if eq >= 0 {
+			out[i] = arg[:eq+1] + RedactedValue
+		} else if i+1 < len(args) {
+			i++
+			out[i] = RedactedValue
+		}
+	}
+	if out == nil {
+		return args
+	}
+	return out
+}
+
+func isRedactedFlag(name string) bool {
+	name = strings.ToLower(name)
+	for _, f := range CmdRedactedFlags {
+		if f != "" && strings.Contains(name, strings.ToLower(f)) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowedCmdEnv returns a dict of the variables of env listed in
+// CmdEnvAllowlist, or nil if there is none.
+func allowedCmdEnv(env []string) *Event {
+	var d *Event
+	for _, kv := range env {
+		eq := strings.IndexByte(kv, '=')
+		if eq < 0 {
+			continue
+		}
+		for _, name := range CmdEnvAllowlist {
+			if kv[:eq] == name {
+				if d == nil {
+					d = Dict()
+				}
+				d.Str(name, kv[eq+1:])
+				break
+			}
+		}
+	}
+	return d
+}
+
+// RunCmd runs cmd and logs its outcome with the cmd field added by Event.Cmd,
+// its duration and its exit code: at InfoLevel if it succeeded, at ErrorLevel
+// with the error otherwise. The exit code is not added if the command could not
+// be started. The error of cmd.Run is returned.
+func (l *Logger) RunCmd(cmd *exec.Cmd) error {
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+	var e *Event
+	if err != nil {
+		e = l.Error().Err(err)
+	} else {
+		e = l.Info()
+	}
+	e.Cmd("cmd", cmd).Dur(CmdDurationFieldName, elapsed)
+	if cmd.ProcessState != nil {
+		e.Int(CmdExitCodeFieldName, cmd.ProcessState.ExitCode())
+	}
+	if err != nil {
+		e.Msg("command failed")
+	} else {
+		e.Msg("command succeeded")
+	}
+	return err
+}
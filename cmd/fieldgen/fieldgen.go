@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// fieldType describes how the fields of a catalogue type are added to the
+// events.
+type fieldType struct {
+	Name   string // name of the generated type
+	GoType string // type of the values
+	Method string // zerolog.Event and zerolog.Context method
+	Import string // package needed by GoType, if any
+}
+
+var fieldTypes = map[string]fieldType{
+	"string":   {"StringField", "string", "Str", ""},
+	"strings":  {"StringsField", "[]string", "Strs", ""},
+	"int":      {"IntField", "int", "Int", ""},
+	"ints":     {"IntsField", "[]int", "Ints", ""},
+	"int64":    {"Int64Field", "int64", "Int64", ""},
+	"uint64":   {"Uint64Field", "uint64", "Uint64", ""},
+	"float64":  {"Float64Field", "float64", "Float64", ""},
+	"bool":     {"BoolField", "bool", "Bool", ""},
+	"duration": {"DurationField", "time.Duration", "Dur", "time"},
+	"time":     {"TimeField", "time.Time", "Time", "time"},
+	"ip":       {"IPField", "net.IP", "IPAddr", "net"},
+	"any":      {"AnyField", "interface{}", "Interface", ""},
+}
+
+// field is an entry of the catalogue.
+type field struct {
+	Name string
+	Key  string
+	Type string
+	Doc  string
+	line int
+}
+
+// catalogue is the parsed field catalogue.
+type catalogue struct {
+	Package string
+	Fields  []field
+}
+
+// parseCatalogue parses the YAML field catalogue read from r:
+//
+//	package: fields
+//	fields:
+//	  - name: UserID
+//	    key: user_id
+//	    type: string
+//	    doc: Identifier of the authenticated user.
+//
+// Only this subset of YAML is supported: scalar values, optionally quoted,
+// and comments. The catalogue must be validated once parsed.
+func parseCatalogue(r io.Reader) (catalogue, error) {
+	var c catalogue
+	var cur *field
+	inFields := false
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := stripComment(scanner.Text())
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		indented := text[0] == ' ' || text[0] == '\t'
+		text = strings.TrimSpace(text)
+		if !indented {
+			inFields = false
+			key, value, err := splitPair(text)
+			if err != nil {
+				return c, fmt.Errorf("line %d: %v", line, err)
+			}
+			switch key {
+			case "package":
+				c.Package = value
+			case "fields":
+				if value != "" {
+					return c, fmt.Errorf("line %d: fields must be a list", line)
+				}
+				inFields = true
+			default:
+				return c, fmt.Errorf("line %d: unknown key %q", line, key)
+			}
+			continue
+		}
+		if !inFields {
+			return c, fmt.Errorf("line %d: unexpected indentation", line)
+		}
+		if strings.HasPrefix(text, "- ") || text == "-" {
+			c.Fields = append(c.Fields, field{line: line})
+			cur = &c.Fields[len(c.Fields)-1]
+			text = strings.TrimSpace(strings.TrimPrefix(text, "-"))
+			if text == "" {
+				continue
+			}
+		}
+		if cur == nil {
+			return c, fmt.Errorf("line %d: expected a list item", line)
+		}
+		key, value, err := splitPair(text)
+		if err != nil {
+			return c, fmt.Errorf("line %d: %v", line, err)
+		}
+		switch key {
+		case "name":
+			cur.Name = value
+		case "key":
+			cur.Key = value
+		case "type":
+			cur.Type = value
+		case "doc":
+			cur.Doc = value
+		default:
+			return c, fmt.Errorf("line %d: unknown field key %q", line, key)
+		}
+	}
+	return c, scanner.Err()
+}
+
+// stripComment removes the comment ending s, if any.
+func stripComment(s string) string {
+	quote := byte(0)
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			} else if ch == '\\' && quote == '"' {
+				i++
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// splitPair splits a "key: value" line, unquoting the value.
+func splitPair(s string) (key, value string, err error) {
+	colon := strings.IndexByte(s, ':')
+	if colon <= 0 {
+		return "", "", fmt.Errorf("expected key: value, got %q", s)
+	}
+	key = strings.TrimSpace(s[:colon])
+	value = strings.TrimSpace(s[colon+1:])
+	switch {
+	case strings.HasPrefix(value, `"`):
+		if value, err = strconv.Unquote(value); err != nil {
+			return "", "", fmt.Errorf("invalid quoted value for %s", key)
+		}
+	case strings.HasPrefix(value, "'"):
+		if len(value) < 2 || !strings.HasSuffix(value, "'") {
+			return "", "", fmt.Errorf("invalid quoted value for %s", key)
+		}
+		value = strings.Replace(value[1:len(value)-1], "''", "'", -1)
+	}
+	return key, value, nil
+}
+
+// validate checks the catalogue has a package and valid, unique fields.
+func (c catalogue) validate() error {
+	if !token.IsIdentifier(c.Package) {
+		return fmt.Errorf("invalid package name %q", c.Package)
+	}
+	names := map[string]bool{}
+	keys := map[string]bool{}
+	for _, f := range c.Fields {
+		switch {
+		case !token.IsIdentifier(f.Name) || !token.IsExported(f.Name):
+			return fmt.Errorf("line %d: invalid field name %q, must be an exported Go identifier", f.line, f.Name)
+		case f.Key == "":
+			return fmt.Errorf("line %d: missing key for field %s", f.line, f.Name)
+		case names[f.Name]:
+			return fmt.Errorf("line %d: duplicate field name %s", f.line, f.Name)
+		case keys[f.Key]:
+			return fmt.Errorf("line %d: duplicate field key %q", f.line, f.Key)
+		}
+		if _, ok := fieldTypes[f.Type]; !ok {
+			return fmt.Errorf("line %d: unknown type %q for field %s", f.line, f.Type, f.Name)
+		}
+		names[f.Name] = true
+		keys[f.Key] = true
+	}
+	return nil
+}
+
+var fileTemplate = template.Must(template.New("fields").Parse(`// Code generated by fieldgen from {{.Source}}. DO NOT EDIT.
+
+// Package {{.Package}} provides the keys of the log fields, typed after
+// their values.
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+
+	"github.com/treavorj/zerolog"
+)
+{{range .Types}}
+// {{.Name}} is the key of a field which values are of type {{.GoType}}.
+type {{.Name}} string
+
+// Key returns the key of the field.
+func (f {{.Name}}) Key() string {
+	return string(f)
+}
+
+// Set adds the field to e with the value v.
+func (f {{.Name}}) Set(e *zerolog.Event, v {{.GoType}}) *zerolog.Event {
+	return e.{{.Method}}(string(f), v)
+}
+
+// Ctx adds the field to the logger context c with the value v.
+func (f {{.Name}}) Ctx(c zerolog.Context, v {{.GoType}}) zerolog.Context {
+	return c.{{.Method}}(string(f), v)
+}
+{{end}}
+const (
+{{- range .Fields}}
+	{{- if .Doc}}
+	// {{.Name}} ({{printf "%q" .Key}}): {{.Doc}}
+	{{- end}}
+	{{.Name}} {{.TypeName}} = {{printf "%q" .Key}}
+{{- end}}
+)
+`))
+
+// generate writes the Go source of the package of c to w.
+func generate(w io.Writer, c catalogue, source string) error {
+	type fieldData struct {
+		Name, Key, Doc, TypeName string
+	}
+	data := struct {
+		Source, Package string
+		Imports         []string
+		Types           []fieldType
+		Fields          []fieldData
+	}{Source: source, Package: c.Package}
+	used := map[string]bool{}
+	imports := map[string]bool{}
+	for _, f := range c.Fields {
+		t := fieldTypes[f.Type]
+		if !used[t.Name] {
+			used[t.Name] = true
+			data.Types = append(data.Types, t)
+			if t.Import != "" && !imports[t.Import] {
+				imports[t.Import] = true
+				data.Imports = append(data.Imports, t.Import)
+			}
+		}
+		data.Fields = append(data.Fields, fieldData{Name: f.Name, Key: f.Key, Doc: f.Doc, TypeName: t.Name})
+	}
+	sort.Strings(data.Imports)
+	sort.Slice(data.Types, func(i, j int) bool { return data.Types[i].Name < data.Types[j].Name })
+
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, data); err != nil {
+		return err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("formatting generated code: %v", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+func main() {
+	in := flag.String("in", "fields.yaml", "field catalogue to read")
+	out := flag.String("out", "", "Go file to write, stdout if empty")
+	pkg := flag.String("package", "", "package name, overriding the one of the catalogue")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fieldgen: %v\n", err)
+		os.Exit(1)
+	}
+	c, err := parseCatalogue(f)
+	f.Close()
+	if *pkg != "" {
+		c.Package = *pkg
+	}
+	if err == nil {
+		err = c.validate()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fieldgen: %s: %v\n", *in, err)
+		os.Exit(1)
+	}
+
+	var buf bytes.Buffer
+	if err := generate(&buf, c, filepath.Base(*in)); err != nil {
+		fmt.Fprintf(os.Stderr, "fieldgen: %v\n", err)
+		os.Exit(1)
+	}
+	if *out == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+	} else {
+		err = ioutil.WriteFile(*out, buf.Bytes(), 0o644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fieldgen: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testCatalogue = `# Fields shared by the services.
+package: fields
+fields:
+  - name: UserID
+    key: user_id
+    type: string
+    doc: "Identifier of the authenticated user."
+  - name: Latency # request duration
+    key: latency
+    type: duration
+  -
+    name: Tags
+    key: 'tags'
+    type: strings
+`
+
+func TestGenerate(t *testing.T) {
+	c, err := parseCatalogue(strings.NewReader(testCatalogue))
+	if err == nil {
+		err = c.validate()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := generate(&out, c, "fields.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	src := out.String()
+	for _, want := range []string{
+		"// Code generated by fieldgen from fields.yaml. DO NOT EDIT.\n",
+		"package fields\n",
+		"\t\"time\"\n",
+		"func (f StringField) Set(e *zerolog.Event, v string) *zerolog.Event {\n\treturn e.Str(string(f), v)\n}",
+		"func (f DurationField) Ctx(c zerolog.Context, v time.Duration) zerolog.Context {\n\treturn c.Dur(string(f), v)\n}",
+		"func (f StringsField) Set(e *zerolog.Event, v []string) *zerolog.Event {\n\treturn e.Strs(string(f), v)\n}",
+		"\t// UserID (\"user_id\"): Identifier of the authenticated user.\n\tUserID  StringField   = \"user_id\"\n",
+		"\tLatency DurationField = \"latency\"\n",
+		"\tTags    StringsField  = \"tags\"\n",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("generated code misses %q:\n%s", want, src)
+		}
+	}
+	if strings.Contains(src, "IntField") {
+		t.Errorf("generated code has unused types:\n%s", src)
+	}
+}
+
+func TestCatalogueErrors(t *testing.T) {
+	tests := []struct {
+		catalogue string
+		err       string
+	}{
+		{"package: fields\nversion: 2\n", `line 2: unknown key "version"`},
+		{"package: fields\nfields:\n  name: UserID\n", "line 3: expected a list item"},
+		{"package: fields\nfields:\n  - name: UserID\n    key: user_id\n    type: uuid\n", `line 3: unknown type "uuid" for field UserID`},
+		{"package: fields\nfields:\n  - name: userID\n    key: user_id\n    type: string\n", `line 3: invalid field name "userID", must be an exported Go identifier`},
+		{"package: fields\nfields:\n  - name: A\n    key: a\n    type: int\n  - name: B\n    key: a\n    type: int\n", `line 6: duplicate field key "a"`},
+		{"fields:\n", `invalid package name ""`},
+	}
+	for _, tt := range tests {
+		c, err := parseCatalogue(strings.NewReader(tt.catalogue))
+		if err == nil {
+			err = c.validate()
+		}
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("parsing %q: got error %v, want %s", tt.catalogue, err, tt.err)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/treavorj/zerolog"
@@ -46,6 +47,24 @@ func main() {
 		"Time format, either 'default' or 'full'",
 	)
 
+	timeZoneFlag := flag.String(
+		"tz",
+		"",
+		"Time zone of the rendered times, like 'UTC' or 'Europe/Paris', local time if empty",
+	)
+
+	timeFieldsFlag := flag.String(
+		"time-fields",
+		"",
+		"Comma separated fields rendered as times, in addition to the timestamp",
+	)
+
+	timeFieldFormatFlag := flag.String(
+		"time-field-format",
+		"",
+		"Format of the time fields of the input: 'unix', 'unixms', 'unixmicro', 'unixnano' or a Go time layout, RFC3339 if empty",
+	)
+
 	flag.Parse()
 
 	timeFormat, ok := timeFormats[*timeFormatFlag]
@@ -55,6 +74,30 @@ func main() {
 
 	writer := zerolog.NewConsoleWriter()
 	writer.TimeFormat = timeFormat
+	if *timeZoneFlag != "" {
+		loc, err := time.LoadLocation(*timeZoneFlag)
+		if err != nil {
+			fmt.Printf("invalid time zone: %v\n", err)
+			os.Exit(1)
+		}
+		writer.TimeLocation = loc
+	}
+	if *timeFieldsFlag != "" {
+		writer.TimeFields = strings.Split(*timeFieldsFlag, ",")
+	}
+	switch strings.ToLower(*timeFieldFormatFlag) {
+	case "":
+	case "unix":
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
+	case "unixms":
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
+	case "unixmicro":
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMicro
+	case "unixnano":
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixNano
+	default:
+		zerolog.TimeFieldFormat = *timeFieldFormatFlag
+	}
 
 	if isInputFromPipe() {
 		_ = processInput(os.Stdin, writer)
@@ -0,0 +1,67 @@
+package zerolog
+
+import (
+	"bytes"
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestEventCmd(t *testing.T) {
+	defer func(l []string) { CmdEnvAllowlist = l }(CmdEnvAllowlist)
+	CmdEnvAllowlist = []string{"LANG"}
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	cmd := exec.Command("pg_dump", "--password=s3cret", "-t", "tok", "--db-token", "abc", "--", "db")
+	cmd.Dir = "/tmp"
+	cmd.Env = []string{"HOME=/root", "LANG=C"}
+	log.Info().Cmd("cmd", cmd).Msg("starting")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","cmd":{"args":["pg_dump","--password=[REDACTED]","-t","tok","--db-token","[REDACTED]","--","db"],"dir":"/tmp","env":{"LANG":"C"}},"message":"starting"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if cmd.Args[1] != "--password=s3cret" {
+		t.Errorf("command arguments modified: %v", cmd.Args)
+	}
+}
+
+func TestRedactCmdArgs(t *testing.T) {
+	args := []string{"curl", "-v", "url"}
+	if got := redactCmdArgs(args); !reflect.DeepEqual(got, args) {
+		t.Errorf("redactCmdArgs() = %v, want %v", got, args)
+	}
+	args = []string{"app", "--API-KEY"}
+	if got := redactCmdArgs(args); !reflect.DeepEqual(got, args) {
+		t.Errorf("redactCmdArgs() = %v, want %v", got, args)
+	}
+}
+
+func TestRunCmd(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no false command")
+	}
+	if _, err := exec.LookPath("false"); err != nil {
+		t.Skip("no false command")
+	}
+	out := &bytes.Buffer{}
+	log := New(out)
+	if err := log.RunCmd(exec.Command("false")); err == nil {
+		t.Fatal("expected an error")
+	}
+	got := decodeIfBinaryToString(out.Bytes())
+	if !strings.HasPrefix(got, `{"level":"error","error":"exit status 1","cmd":{"args":["false"]},"duration":`) ||
+		!strings.HasSuffix(got, `"exit_code":1,"message":"command failed"}`+"\n") {
+		t.Errorf("invalid log output: %v", got)
+	}
+	out.Reset()
+	if err := log.RunCmd(exec.Command("zerolog-no-such-command")); err == nil {
+		t.Fatal("expected an error")
+	}
+	if got := decodeIfBinaryToString(out.Bytes()); strings.Contains(got, "exit_code") {
+		t.Errorf("unexpected exit code: %v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package zerolog
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Well known field categories, to be registered with RegisterFieldCategory.
+const (
+	// CategoryPayload is the category of request and message bodies.
+	CategoryPayload = "payload"
+
+	// CategoryUserContent is the category of the content authored by users.
+	CategoryUserContent = "user_content"
+)
+
+var fieldCategories = struct {
+	sync.Mutex
+	keys     map[string]string // field key to category
+	stripped map[string]bool   // stripped categories
+}{keys: map[string]string{}, stripped: map[string]bool{}}
+
+// stripping is 1 while fields are stripped, atomic. It spares the events the
+// load of strippedKeys when no category is stripped.
+var stripping int32
+
+// strippedKeys holds the *fieldStripper removing the stripped fields.
+var strippedKeys atomic.Value
+
+// RegisterFieldCategory registers keys as the keys of the fields of category,
+// like CategoryPayload. The fields of the categories given to
+// StripFieldCategories are removed from all the events.
+func RegisterFieldCategory(category string, keys ...string) {
+	fieldCategories.Lock()
+	defer fieldCategories.Unlock()
+	for _, key := range keys {
+		fieldCategories.keys[key] = category
+	}
+	updateStrippedKeys()
+}
+
+// StripFieldCategories removes the fields of categories from all the events,
+// replacing the previously stripped categories, so that deployments in
+// regulated regions only log operational metadata:
+//
+//	zerolog.RegisterFieldCategory(zerolog.CategoryPayload, "body", "request_body")
+//	zerolog.RegisterFieldCategory(zerolog.CategoryUserContent, "comment")
+//	if region == "eu" {
+//	    zerolog.StripFieldCategories(zerolog.CategoryPayload, zerolog.CategoryUserContent)
+//	}
+//
+// Fields are removed at any depth, from the logger context and the event,
+// when the event is written. Fields added with Event.Sensitive are not even
+// computed. Binary events are decoded and encoded back, so the fields are
+// stripped whatever the encoding, including in the embedded JSON values.
+//
+// When built with the zerolog_minimal_pii build tag, all the categories are
+// stripped, whatever the categories given to StripFieldCategories.
+func StripFieldCategories(categories ...string) {
+	fieldCategories.Lock()
+	defer fieldCategories.Unlock()
+	fieldCategories.stripped = make(map[string]bool, len(categories))
+	for _, c := range categories {
+		fieldCategories.stripped[c] = true
+	}
+	updateStrippedKeys()
+}
+
+// StrippedFieldCategories returns the categories given to
+// StripFieldCategories, sorted.
+func StrippedFieldCategories() []string {
+	fieldCategories.Lock()
+	defer fieldCategories.Unlock()
+	categories := make([]string, 0, len(fieldCategories.stripped))
+	for c := range fieldCategories.stripped {
+		categories = append(categories, c)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// categoryStripped returns true if the fields of category are stripped.
+func categoryStripped(category string) bool {
+	if minimalPII {
+		return true
+	}
+	fieldCategories.Lock()
+	defer fieldCategories.Unlock()
+	return fieldCategories.stripped[category]
+}
+
+// updateStrippedKeys computes the stripped keys. fieldCategories must be
+// locked.
+func updateStrippedKeys() {
+	s := &fieldStripper{keys: map[string]bool{}}
+	for key, category := range fieldCategories.keys {
+		if minimalPII || fieldCategories.stripped[category] {
+			s.keys[key] = true
+			s.needles = append(s.needles, enc.AppendString(nil, key))
+			if j := appendJSONString(key); !bytes.Equal(j, s.needles[len(s.needles)-1]) {
+				// The key as found in the embedded JSON values of binary
+				// events.
+				s.needles = append(s.needles, j)
+			}
+		}
+	}
+	strippedKeys.Store(s)
+	if len(s.keys) > 0 {
+		atomic.StoreInt32(&stripping, 1)
+	} else {
+		atomic.StoreInt32(&stripping, 0)
+	}
+}
+
+// Sensitive calls fn to add the fields of category to the event, unless the
+// category is stripped by StripFieldCategories, in which case fn is not
+// called and the fields are not computed:
+//
+//	log.Info().
+//	    Str("method", r.Method).
+//	    Sensitive(zerolog.CategoryPayload, func(e *zerolog.Event) {
+//	        e.Bytes("body", body)
+//	    }).
+//	    Msg("request")
+//
+// With the zerolog_minimal_pii build tag, fn is never called.
+func (e *Event) Sensitive(category string, fn func(e *Event)) *Event {
+	if e == nil || minimalPII || categoryStripped(category) {
+		return e
+	}
+	e.guardUse("Sensitive")
+	fn(e)
+	return e
+}
+
+// fieldStripper is the fieldRewriter removing the fields of the stripped
+// categories.
+type fieldStripper struct {
+	keys    map[string]bool
+	needles [][]byte // encoded keys, found in the events having such fields
+}
+
+func (s *fieldStripper) field(key []byte) fieldAction {
+	if s.keys[string(key)] {
+		return dropField
+	}
+	return keepField
+}
+
+func (s *fieldStripper) replace(key string, value []byte) []byte {
+	return value
+}
+
+// stripFields removes the fields of the stripped categories from the event
+// p. Events are only rewritten if they hold one of the encoded keys.
+func stripFields(p []byte) []byte {
+	if atomic.LoadInt32(&stripping) == 0 {
+		return p
+	}
+	s := strippedKeys.Load().(*fieldStripper)
+	for _, needle := range s.needles {
+		if bytes.Contains(p, needle) {
+			return rewriteFields(p, s)
+		}
+	}
+	return p
+}
@@ -0,0 +1,8 @@
+//go:build zerolog_minimal_pii
+// +build zerolog_minimal_pii
+
+package zerolog
+
+// minimalPII is true when building with the zerolog_minimal_pii tag: all the
+// field categories are stripped and Event.Sensitive is a no-op.
+const minimalPII = true
@@ -0,0 +1,68 @@
+//go:build !binary_log && !zerolog_minimal_pii
+// +build !binary_log,!zerolog_minimal_pii
+
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestStripFieldCategories(t *testing.T) {
+	defer func() {
+		fieldCategories.keys = map[string]string{}
+		StripFieldCategories()
+	}()
+	RegisterFieldCategory(CategoryPayload, "body")
+	RegisterFieldCategory(CategoryUserContent, "comment")
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("body", "ctx").Logger()
+	computed := false
+	sensitive := func(e *Event) {
+		computed = true
+		e.Str("extra", "x")
+	}
+	log.Info().Str("comment", "hi").Sensitive(CategoryPayload, sensitive).Msg("kept")
+	if !computed {
+		t.Error("Sensitive fields not computed")
+	}
+
+	StripFieldCategories(CategoryPayload, CategoryUserContent)
+	if got, want := StrippedFieldCategories(), []string{CategoryPayload, CategoryUserContent}; !reflect.DeepEqual(got, want) {
+		t.Errorf("StrippedFieldCategories() = %v, want %v", got, want)
+	}
+	computed = false
+	log.Info().Str("id", "1").Str("comment", "hi").
+		Dict("req", Dict().Str("body", "secret").Int("size", 6)).
+		Sensitive(CategoryPayload, sensitive).Msg("stripped")
+	if computed {
+		t.Error("Sensitive fields computed")
+	}
+	plain := New(out)
+	plain.Info().Str("comment", "hi").Send()
+
+	got := out.String()
+	want := `{"level":"info","body":"ctx","comment":"hi","extra":"x","message":"kept"}` + "\n" +
+		`{"level":"info","id":"1","req":{"size":6},"message":"stripped"}` + "\n" +
+		`{"level":"info"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestStripperRewrite(t *testing.T) {
+	keys := map[string]bool{"a": true}
+	for in, want := range map[string]string{
+		`{"a":1}`:                 `{}`,
+		`{"b":1,"a":1,"c":2}`:     `{"b":1,"c":2}`,
+		`{"l":[{"a":1,"b":2},3]}`: `{"l":[{"b":2},3]}`,
+		`{"a":{"a":1},"b":"a"}`:   `{"b":"a"}`,
+		`{"\u0061":1,"b":2}`:      `{"b":2}`,
+	} {
+		if got := string(appendRewritten(nil, []byte(in), &fieldStripper{keys: keys})); got != want {
+			t.Errorf("appendRewritten(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package zerolog
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// componentLevelUnset is the level of the components using the level of
+// their logger.
+const componentLevelUnset = math.MaxInt32
+
+// LogComponent is a named part of a program, like a package or a subsystem,
+// which level can be adjusted at runtime independently of the others. See
+// Component.
+type LogComponent struct {
+	name  string
+	level int32 // atomic, componentLevelUnset if not set
+}
+
+var components = struct {
+	sync.RWMutex
+	base   *Logger
+	byName map[string]*LogComponent
+}{byName: map[string]*LogComponent{}}
+
+// Component returns the component registered under name, registering it
+// first if needed. The loggers of a component share the configuration set by
+// SetComponentBase and the level of the component:
+//
+//	zerolog.SetComponentBase(zerolog.New(os.Stderr).With().Timestamp().Logger())
+//	dbLog := zerolog.Component("db").Logger()
+//	...
+//	zerolog.SetComponentLevels("db=debug,http=warn")
+//
+// The base is copied by LogComponent.Logger, so it must be set first: a
+// logger created before, like in a package variable initializer, keeps
+// writing to os.Stderr.
+func Component(name string) *LogComponent {
+	components.RLock()
+	c := components.byName[name]
+	components.RUnlock()
+	if c != nil {
+		return c
+	}
+	components.Lock()
+	defer components.Unlock()
+	if c = components.byName[name]; c == nil {
+		c = &LogComponent{name: name, level: componentLevelUnset}
+		components.byName[name] = c
+	}
+	return c
+}
+
+// SetComponentBase sets the logger from which the loggers of the components
+// are derived. Loggers already returned by LogComponent.Logger are not
+// affected. Loggers writing to os.Stderr are used if no base is set.
+func SetComponentBase(l Logger) {
+	components.Lock()
+	defer components.Unlock()
+	components.base = &l
+}
+
+// Name returns the name of the component.
+func (c *LogComponent) Name() string {
+	return c.name
+}
+
+// Logger returns a logger derived from the base set by SetComponentBase at
+// the time of the call, with the name of the component added as
+// ComponentFieldName field. Its level, if set by SetLevel or
+// SetComponentLevels, overrides the level of the logger, even once returned.
+func (c *LogComponent) Logger() Logger {
+	components.RLock()
+	base := components.base
+	components.RUnlock()
+	var l Logger
+	if base != nil {
+		l = *base
+	} else {
+		l = New(os.Stderr)
+	}
+	l = l.With().Str(ComponentFieldName, c.name).Logger()
+	l.comp = c
+	return l
+}
+
+// SetLevel sets the level of the loggers of the component.
+func (c *LogComponent) SetLevel(level Level) {
+	atomic.StoreInt32(&c.level, int32(level))
+}
+
+// ResetLevel makes the loggers of the component use their own level again.
+func (c *LogComponent) ResetLevel() {
+	atomic.StoreInt32(&c.level, componentLevelUnset)
+}
+
+// Level returns the level of the component, and false if it is not set.
+func (c *LogComponent) Level() (Level, bool) {
+	l := atomic.LoadInt32(&c.level)
+	if l == componentLevelUnset {
+		return NoLevel, false
+	}
+	return Level(l), true
+}
+
+// SetComponentLevels sets the levels of the components from a comma
+// separated list of name=level pairs, like "db=debug,http=warn". Components
+// are registered if needed. Nothing is changed, and no component is
+// registered, if spec is invalid.
+func SetComponentLevels(spec string) error {
+	levels := map[string]Level{}
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		eq := strings.IndexByte(pair, '=')
+		if eq <= 0 {
+			return fmt.Errorf("invalid component level %q, want name=level", pair)
+		}
+		level, err := ParseLevel(strings.TrimSpace(pair[eq+1:]))
+		if err != nil {
+			return err
+		}
+		levels[strings.TrimSpace(pair[:eq])] = level
+	}
+	for name, level := range levels {
+		Component(name).SetLevel(level)
+	}
+	return nil
+}
+
+// ComponentLevels returns the components having a level set, in the format
+// of SetComponentLevels, sorted by name.
+func ComponentLevels() string {
+	components.RLock()
+	defer components.RUnlock()
+	var pairs []string
+	for name, c := range components.byName {
+		if level, ok := c.Level(); ok {
+			pairs = append(pairs, name+"="+level.String())
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,67 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestComponent(t *testing.T) {
+	out := &bytes.Buffer{}
+	SetComponentBase(New(out).Level(InfoLevel))
+	defer func() { components.base = nil }()
+
+	db := Component("test-db")
+	if Component("test-db") != db || db.Name() != "test-db" {
+		t.Fatal("component not registered")
+	}
+	log := db.Logger()
+	log.Debug().Msg("hidden")
+	if err := SetComponentLevels("test-db=debug, test-http=warn"); err != nil {
+		t.Fatal(err)
+	}
+	defer Component("test-http").ResetLevel()
+	log.Debug().Msg("shown")
+	httpLog := Component("test-http").Logger()
+	httpLog.Info().Msg("hidden")
+	httpLog.Warn().Msg("shown")
+	db.ResetLevel()
+	log.Debug().Msg("hidden")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"debug","component":"test-db","message":"shown"}` + "\n" +
+		`{"level":"warn","component":"test-http","message":"shown"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSetComponentLevels(t *testing.T) {
+	defer Component("test-a").ResetLevel()
+	defer Component("test-b").ResetLevel()
+	for _, spec := range []string{"test-a", "=debug", "test-a=loud"} {
+		if err := SetComponentLevels(spec); err == nil {
+			t.Errorf("SetComponentLevels(%q) succeeded", spec)
+		}
+	}
+	if err := SetComponentLevels("test-b=error,test-a=trace,"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ComponentLevels(), "test-a=trace,test-b=error"; got != want {
+		t.Errorf("ComponentLevels() = %q, want %q", got, want)
+	}
+	if err := SetComponentLevels("test-a=info,test-b=oops"); err == nil {
+		t.Error("invalid spec accepted")
+	}
+	if level, _ := Component("test-a").Level(); level != TraceLevel {
+		t.Errorf("level changed by an invalid spec: %v", level)
+	}
+	if err := SetComponentLevels("test-unregistered=info,test-b=oops"); err == nil {
+		t.Error("invalid spec accepted")
+	}
+	components.RLock()
+	_, ok := components.byName["test-unregistered"]
+	components.RUnlock()
+	if ok {
+		t.Error("component registered by an invalid spec")
+	}
+}
@@ -6,14 +6,17 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mattn/go-colorable"
+	"github.com/mattn/go-isatty"
 )
 
 const (
@@ -56,6 +59,10 @@ type ConsoleWriter struct {
 	// NoColor disables the colorized output.
 	NoColor bool
 
+	// ForceColor keeps the colorized output when NewConsoleWriter would
+	// disable it because Out is not a terminal. See ColorEnabled.
+	ForceColor bool
+
 	// TimeFormat specifies the format for timestamp in output.
 	TimeFormat string
 
@@ -63,6 +70,11 @@ type ConsoleWriter struct {
 	// how to localize the time.
 	TimeLocation *time.Location
 
+	// TimeFields are the fields rendered, like the timestamp, with TimeFormat
+	// in TimeLocation when their value is a time formatted with
+	// TimeFieldFormat, like the compact TimeFormatUnixMs.
+	TimeFields []string
+
 	// PartsOrder defines the order of parts in output.
 	PartsOrder []string
 
@@ -77,9 +89,28 @@ type ConsoleWriter struct {
 	// FieldsExclude defines contextual fields to not display in output.
 	FieldsExclude []string
 
+	// FuncFieldName is the field rendered as the calling function before the
+	// caller, like one written by a logger configured elsewhere. Defaults to
+	// CallerFuncFieldName.
+	FuncFieldName string
+
+	// FoldMultiline renders the lines following the first one of multiline
+	// messages indented below the log line, instead of inline.
+	FoldMultiline bool
+
+	// FoldIndent is the indentation of the folded message lines, and of the
+	// fields when expanded. Defaults to four spaces.
+	FoldIndent string
+
+	// Expand, if not nil, switches the rendering of the fields at runtime:
+	// when expanded, each field is written on its own line below the log
+	// line, with objects and arrays indented.
+	Expand *ConsoleExpansion
+
 	FormatTimestamp     Formatter
 	FormatLevel         Formatter
 	FormatCaller        Formatter
+	FormatCallerFunc    Formatter
 	FormatMessage       Formatter
 	FormatFieldName     Formatter
 	FormatFieldValue    Formatter
@@ -103,6 +134,10 @@ func NewConsoleWriter(options ...func(w *ConsoleWriter)) ConsoleWriter {
 		opt(&w)
 	}
 
+	if !w.NoColor && !w.ForceColor {
+		w.NoColor = !ColorEnabled(w.Out)
+	}
+
 	// Fix color on Windows
 	if w.Out == os.Stdout || w.Out == os.Stderr {
 		w.Out = colorable.NewColorable(w.Out.(*os.File))
@@ -111,6 +146,97 @@ func NewConsoleWriter(options ...func(w *ConsoleWriter)) ConsoleWriter {
 	return w
 }
 
+// ConsoleExpansion switches the ConsoleWriters using it between compact
+// and expanded field rendering at runtime, without recreating them. It is
+// safe for concurrent use.
+type ConsoleExpansion struct {
+	expanded int32
+}
+
+// Set selects the expanded rendering if expanded is true, the compact one
+// otherwise.
+func (e *ConsoleExpansion) Set(expanded bool) {
+	var v int32
+	if expanded {
+		v = 1
+	}
+	atomic.StoreInt32(&e.expanded, v)
+}
+
+// Toggle switches the rendering and returns true if it is now expanded.
+func (e *ConsoleExpansion) Toggle() bool {
+	for {
+		old := atomic.LoadInt32(&e.expanded)
+		if atomic.CompareAndSwapInt32(&e.expanded, old, 1-old) {
+			return old == 0
+		}
+	}
+}
+
+// Expanded returns true if the expanded rendering is selected. A nil
+// ConsoleExpansion is compact.
+func (e *ConsoleExpansion) Expanded() bool {
+	return e != nil && atomic.LoadInt32(&e.expanded) == 1
+}
+
+// ToggleOnSignal toggles the rendering each time one of sigs is received,
+// like syscall.SIGUSR2, until the returned function is called:
+//
+//	expand := &zerolog.ConsoleExpansion{}
+//	stop := expand.ToggleOnSignal(syscall.SIGUSR2)
+//	defer stop()
+//	log := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr, Expand: expand})
+func (e *ConsoleExpansion) ToggleOnSignal(sigs ...os.Signal) (stop func()) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sigs...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-c:
+				e.Toggle()
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+// ColorEnabled returns true if colorized output should be written to out,
+// following the NO_COLOR (https://no-color.org) and CLICOLOR_FORCE
+// conventions:
+//
+//   - false if the NO_COLOR environment variable is set and not empty,
+//   - true if the CLICOLOR_FORCE environment variable is set and not "0",
+//   - false if the TERM environment variable is "dumb",
+//   - otherwise, true if out is a terminal.
+//
+// NewConsoleWriter uses it to disable colors, unless ForceColor is set, so
+// the same binary does the right thing in terminals, pipes and CI logs.
+func ColorEnabled(out io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if v := os.Getenv("CLICOLOR_FORCE"); v != "" && v != "0" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
 // Write transforms the JSON input with formatters and appends to w.Out.
 func (w ConsoleWriter) Write(p []byte) (n int, err error) {
 	// Fix color on Windows
@@ -144,7 +270,22 @@ func (w ConsoleWriter) Write(p []byte) (n int, err error) {
 		}
 	}
 
+	var folded []string
+	if w.FoldMultiline {
+		if msg, ok := evt[MessageFieldName].(string); ok && strings.ContainsAny(msg, "\r\n") {
+			lines := strings.Split(strings.TrimRight(normalizeNewlines(msg), "\n"), "\n")
+			evt[MessageFieldName] = lines[0]
+			folded = lines[1:]
+		}
+	}
+
+	funcField := w.funcFieldName()
 	for _, p := range w.PartsOrder {
+		if p == CallerFieldName && funcField != "" && !w.hasPart(funcField) {
+			// Unless explicitly ordered, the function goes before the caller
+			// so the file:line location stays clickable in IDE terminals.
+			w.writePart(buf, evt, funcField)
+		}
 		w.writePart(buf, evt, p)
 	}
 
@@ -157,6 +298,18 @@ func (w ConsoleWriter) Write(p []byte) (n int, err error) {
 		}
 	}
 
+	if len(folded) > 0 {
+		indent := w.FoldIndent
+		if indent == "" {
+			indent = "    "
+		}
+		for _, line := range folded {
+			buf.WriteByte('\n')
+			buf.WriteString(indent)
+			buf.WriteString(line)
+		}
+	}
+
 	err = buf.WriteByte('\n')
 	if err != nil {
 		return n, err
@@ -175,9 +328,16 @@ func (w ConsoleWriter) Close() error {
 	return nil
 }
 
+// Flush calls the underlying writer's Flush method if any. Otherwise does
+// nothing.
+func (w ConsoleWriter) Flush() error {
+	return flush(w.Out)
+}
+
 // writeFields appends formatted key-value pairs to buf.
 func (w ConsoleWriter) writeFields(evt map[string]interface{}, buf *bytes.Buffer) {
 	var fields = make([]string, 0, len(evt))
+	funcField := w.funcFieldName()
 	for field := range evt {
 		var isExcluded bool
 		for _, excluded := range w.FieldsExclude {
@@ -194,6 +354,9 @@ func (w ConsoleWriter) writeFields(evt map[string]interface{}, buf *bytes.Buffer
 		case LevelFieldName, TimestampFieldName, MessageFieldName, CallerFieldName:
 			continue
 		}
+		if funcField != "" && field == funcField {
+			continue
+		}
 		fields = append(fields, field)
 	}
 
@@ -203,8 +366,14 @@ func (w ConsoleWriter) writeFields(evt map[string]interface{}, buf *bytes.Buffer
 		sort.Strings(fields)
 	}
 
+	expanded := w.Expand.Expanded()
+	indent := w.FoldIndent
+	if indent == "" {
+		indent = "    "
+	}
+
 	// Write space only if something has already been written to the buffer, and if there are fields.
-	if buf.Len() > 0 && len(fields) > 0 {
+	if buf.Len() > 0 && len(fields) > 0 && !expanded {
 		buf.WriteByte(' ')
 	}
 
@@ -253,32 +422,75 @@ func (w ConsoleWriter) writeFields(evt map[string]interface{}, buf *bytes.Buffer
 			}
 		}
 
+		if expanded {
+			buf.WriteByte('\n')
+			buf.WriteString(indent)
+		}
 		buf.WriteString(fn(field))
 
-		switch fValue := evt[field].(type) {
-		case string:
-			if needsQuote(fValue) {
-				buf.WriteString(fv(strconv.Quote(fValue)))
-			} else {
-				buf.WriteString(fv(fValue))
-			}
-		case json.Number:
-			buf.WriteString(fv(fValue))
-		default:
-			b, err := InterfaceMarshalFunc(fValue)
-			if err != nil {
-				fmt.Fprintf(buf, colorize("[error: %v]", colorRed, w.NoColor), err)
-			} else {
-				fmt.Fprint(buf, fv(b))
-			}
+		if t, ok := w.timeField(field, evt[field]); ok {
+			buf.WriteString(fv(t))
+		} else {
+			w.writeFieldValue(buf, evt[field], fv, expanded, indent)
 		}
 
-		if i < len(fields)-1 { // Skip space for last field
+		if i < len(fields)-1 && !expanded { // Skip space for last field
 			buf.WriteByte(' ')
 		}
 	}
 }
 
+// timeField returns the value of field formatted with TimeFormat, if it is
+// one of the TimeFields holding a time.
+func (w ConsoleWriter) timeField(field string, value interface{}) (string, bool) {
+	for _, f := range w.TimeFields {
+		if f != field {
+			continue
+		}
+		location := w.TimeLocation
+		if location == nil {
+			location = time.Local
+		}
+		t, err := ParseTimeValue(value, TimeFieldFormat, location)
+		if err != nil {
+			return "", false
+		}
+		timeFormat := w.TimeFormat
+		if timeFormat == "" {
+			timeFormat = consoleDefaultTimeFormat
+		}
+		return t.In(location).Format(timeFormat), true
+	}
+	return "", false
+}
+
+// writeFieldValue writes the field value formatted with fv.
+func (w ConsoleWriter) writeFieldValue(buf *bytes.Buffer, value interface{}, fv Formatter, expanded bool, indent string) {
+	switch fValue := value.(type) {
+	case string:
+		if needsQuote(fValue) {
+			buf.WriteString(fv(strconv.Quote(fValue)))
+		} else {
+			buf.WriteString(fv(fValue))
+		}
+	case json.Number:
+		buf.WriteString(fv(fValue))
+	default:
+		b, err := InterfaceMarshalFunc(fValue)
+		if err != nil {
+			fmt.Fprintf(buf, colorize("[error: %v]", colorRed, w.NoColor), err)
+		} else {
+			if expanded {
+				var indented bytes.Buffer
+				if json.Indent(&indented, b, indent, "  ") == nil {
+					b = indented.Bytes()
+				}
+			}
+			fmt.Fprint(buf, fv(b))
+		}
+	}
+}
+
 // writePart appends a formatted part to buf.
 func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt map[string]interface{}, p string) {
 	var f Formatter
@@ -317,7 +529,13 @@ func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt map[string]interface{},
 			f = w.FormatCaller
 		}
 	default:
-		if w.FormatFieldValue == nil {
+		if p == w.funcFieldName() {
+			if w.FormatCallerFunc == nil {
+				f = consoleDefaultFormatCallerFunc(w.NoColor)
+			} else {
+				f = w.FormatCallerFunc
+			}
+		} else if w.FormatFieldValue == nil {
 			f = consoleDefaultFormatFieldValue
 		} else {
 			f = w.FormatFieldValue
@@ -334,6 +552,24 @@ func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt map[string]interface{},
 	}
 }
 
+// funcFieldName returns the name of the calling function field, if any.
+func (w ConsoleWriter) funcFieldName() string {
+	if w.FuncFieldName != "" {
+		return w.FuncFieldName
+	}
+	return CallerFuncFieldName
+}
+
+// hasPart returns true if p is listed in PartsOrder.
+func (w ConsoleWriter) hasPart(p string) bool {
+	for _, part := range w.PartsOrder {
+		if part == p {
+			return true
+		}
+	}
+	return false
+}
+
 // orderFields takes an array of field names and an array representing field order
 // and returns an array with any ordered fields at the beginning, in order,
 // and the remaining fields after in their original order.
@@ -406,33 +642,14 @@ func consoleDefaultFormatTimestamp(timeFormat string, location *time.Location, n
 		t := "<nil>"
 		switch tt := i.(type) {
 		case string:
-			ts, err := time.ParseInLocation(TimeFieldFormat, tt, location)
-			if err != nil {
-				t = tt
-			} else {
-				t = ts.In(location).Format(timeFormat)
-			}
+			t = tt
 		case json.Number:
-			i, err := tt.Int64()
-			if err != nil {
-				t = tt.String()
-			} else {
-				var sec, nsec int64
-
-				switch TimeFieldFormat {
-				case TimeFormatUnixNano:
-					sec, nsec = 0, i
-				case TimeFormatUnixMicro:
-					sec, nsec = 0, int64(time.Duration(i)*time.Microsecond)
-				case TimeFormatUnixMs:
-					sec, nsec = 0, int64(time.Duration(i)*time.Millisecond)
-				default:
-					sec, nsec = i, 0
-				}
-
-				ts := time.Unix(sec, nsec)
-				t = ts.In(location).Format(timeFormat)
-			}
+			t = tt.String()
+		default:
+			return colorize(t, colorDarkGray, noColor)
+		}
+		if ts, err := ParseTimeValue(i, TimeFieldFormat, location); err == nil {
+			t = ts.In(location).Format(timeFormat)
 		}
 		return colorize(t, colorDarkGray, noColor)
 	}
@@ -456,6 +673,11 @@ func consoleDefaultFormatLevel(noColor bool) Formatter {
 			if ok {
 				return colorize(fl, LevelColors[level], noColor)
 			}
+			if level < TraceLevel && level != NoLevel {
+				// Finer trace levels, like TR2 for TraceLevelN(2).
+				fl = "TR" + strconv.Itoa(int(TraceLevel-level)+1)
+				return colorize(fl, LevelColors[TraceLevel], noColor)
+			}
 			return stripLevel(ll)
 		}
 		if i == nil {
@@ -483,6 +705,15 @@ func consoleDefaultFormatCaller(noColor bool) Formatter {
 	}
 }
 
+func consoleDefaultFormatCallerFunc(noColor bool) Formatter {
+	return func(i interface{}) string {
+		if fn, ok := i.(string); ok && fn != "" {
+			return colorize(fn, colorCyan, noColor)
+		}
+		return ""
+	}
+}
+
 func consoleDefaultFormatMessage(noColor bool, level interface{}) Formatter {
 	return func(i interface{}) string {
 		if i == nil || i == "" {
@@ -73,7 +73,71 @@ func TestConsoleLogger(t *testing.T) {
 	})
 }
 
+func TestColorEnabled(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("CLICOLOR_FORCE", "")
+	t.Setenv("TERM", "xterm")
+	buf := &bytes.Buffer{}
+	if zerolog.ColorEnabled(buf) {
+		t.Error("colors enabled for a buffer")
+	}
+	if w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) { w.Out = buf }); !w.NoColor {
+		t.Error("NewConsoleWriter kept colors for a buffer")
+	}
+	if w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) { w.Out = buf; w.ForceColor = true }); w.NoColor {
+		t.Error("NewConsoleWriter disabled colors despite ForceColor")
+	}
+
+	t.Setenv("CLICOLOR_FORCE", "1")
+	if !zerolog.ColorEnabled(buf) {
+		t.Error("colors not forced by CLICOLOR_FORCE")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if zerolog.ColorEnabled(buf) {
+		t.Error("colors not disabled by NO_COLOR")
+	}
+}
+
 func TestConsoleWriter(t *testing.T) {
+	t.Run("Fold multiline message", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, FoldMultiline: true, PartsOrder: []string{"level", "message"}}
+
+		_, err := w.Write([]byte(`{"level": "error", "message": "panic: boom\r\ngoroutine 1:\n\tmain.go:12\n", "foo": "bar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "ERR panic: boom foo=bar\n    goroutine 1:\n    \tmain.go:12\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Expand fields", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		expand := &zerolog.ConsoleExpansion{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, Expand: expand, PartsOrder: []string{"level", "message"}}
+		evt := []byte(`{"level": "info", "message": "hello", "foo": "bar", "obj": {"a": [1, 2]}}`)
+
+		w.Write(evt)
+		if !expand.Toggle() || !expand.Expanded() {
+			t.Fatal("expansion not toggled")
+		}
+		w.Write(evt)
+		expand.Set(false)
+		w.Write(evt)
+
+		expectedOutput := "INF hello foo=bar obj={\"a\":[1,2]}\n" +
+			"INF hello\n    foo=bar\n    obj={\n      \"a\": [\n        1,\n        2\n      ]\n    }\n" +
+			"INF hello foo=bar obj={\"a\":[1,2]}\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
 	t.Run("Default field formatter", func(t *testing.T) {
 		buf := &bytes.Buffer{}
 		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"foo"}}
@@ -187,6 +251,28 @@ func TestConsoleWriter(t *testing.T) {
 		}
 	})
 
+	t.Run("Time fields", func(t *testing.T) {
+		of := zerolog.TimeFieldFormat
+		defer func() {
+			zerolog.TimeFieldFormat = of
+		}()
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
+
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, TimeFormat: time.RFC3339, TimeLocation: time.FixedZone("CET", 3600), TimeFields: []string{"expires", "other"}, NoColor: true}
+
+		_, err := w.Write([]byte(`{"time": 1234567, "level": "debug", "message": "Foobar", "expires": 7200000, "other": "soon", "n": 1}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "1970-01-01T01:20:34+01:00 DBG Foobar expires=1970-01-01T03:00:00+01:00 n=1 other=soon\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
 	t.Run("Unix timestamp us input format", func(t *testing.T) {
 		of := zerolog.TimeFieldFormat
 		defer func() {
@@ -310,6 +396,59 @@ func TestConsoleWriter(t *testing.T) {
 		}
 	})
 
+	t.Run("Write caller func field", func(t *testing.T) {
+		zerolog.CallerFuncFieldName = "func"
+		defer func() { zerolog.CallerFuncFieldName = "" }()
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"level", "caller", "message"}}
+
+		_, err := w.Write([]byte(`{"level": "debug", "message": "Foobar", "foo": "bar", "caller": "bar.go:12", "func": "main.run"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "DBG main.run bar.go:12 > Foobar foo=bar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Write caller func field with a custom name", func(t *testing.T) {
+		zerolog.CallerFuncFieldName = "func"
+		defer func() { zerolog.CallerFuncFieldName = "" }()
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"level", "caller", "message"}, FuncFieldName: "fn"}
+
+		_, err := w.Write([]byte(`{"level": "debug", "message": "Foobar", "func": "bar", "caller": "bar.go:12", "fn": "main.run"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "DBG main.run bar.go:12 > Foobar func=bar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Write finer trace level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"level", "message"}}
+
+		for _, level := range []string{"-2", "trace3"} {
+			if _, err := w.Write([]byte(`{"level": "` + level + `", "message": "Foobar"}`)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+		}
+
+		expectedOutput := "TR2 Foobar\nTR3 Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
 	t.Run("Write JSON field", func(t *testing.T) {
 		buf := &bytes.Buffer{}
 		w := zerolog.ConsoleWriter{Out: buf, NoColor: true}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"math"
 	"net"
+	"strconv"
 	"time"
 )
 
@@ -24,18 +25,46 @@ func (c Context) Logger() Logger {
 // Only map[string]interface{} and []interface{} are accepted. []interface{} must
 // alternate string keys and arbitrary values, and extraneous ones are ignored.
 func (c Context) Fields(fields interface{}) Context {
-	c.l.context = appendFields(c.l.context, fields, c.l.stack)
+	c.l.context = appendFields(c.l.context, fields, c.l.stack, c.l.floatFmt)
 	return c
 }
 
 // Dict adds the field key with the dict to the logger context.
 func (c Context) Dict(key string, dict *Event) Context {
+	dict.closeGroups()
 	dict.buf = enc.AppendEndMarker(dict.buf)
 	c.l.context = append(enc.AppendKey(c.l.context, key), dict.buf...)
 	putEvent(dict)
 	return c
 }
 
+// Group nests the context fields added next under the field key, until
+// EndGroup is called. Groups left open when the logger is created also nest
+// the fields of its events, like slog's Logger.WithGroup:
+//
+//	reqLog := log.With().Str("service", "api").Group("req").Str("id", id).Logger()
+//	reqLog.Info().Int("status", 200).Msg("served")
+//	// {"level":"info","service":"api","req":{"id":"42","status":200},"message":"served"}
+//
+// The groups of the events are closed when they are sent, before the hooks
+// run and the message is added.
+func (c Context) Group(key string) Context {
+	c.l.context = enc.AppendBeginMarker(enc.AppendKey(c.l.context, key))
+	c.l.groups++
+	return c
+}
+
+// EndGroup closes the innermost group opened by Group. It does nothing if no
+// group is open.
+func (c Context) EndGroup() Context {
+	if c.l.groups == 0 {
+		return c
+	}
+	c.l.context = enc.AppendEndMarker(c.l.context)
+	c.l.groups--
+	return c
+}
+
 // Array adds the field key with an array to the event context.
 // Use zerolog.Arr() to create the array or pass a type that
 // implement the LogArrayMarshaler interface.
@@ -50,6 +79,7 @@ func (c Context) Array(key string, arr LogArrayMarshaler) Context {
 		a = aa
 	} else {
 		a = Arr()
+		a.floatFmt = c.l.floatFmt
 		arr.MarshalZerologArray(a)
 	}
 	c.l.context = a.write(c.l.context)
@@ -74,9 +104,20 @@ func (c Context) EmbedObject(obj LogObjectMarshaler) Context {
 	return c
 }
 
+// EmbedRawJSON splices the fields of the already encoded JSON object obj
+// directly into the logger context, at the top level.
+//
+// obj is validated first: if it is not a valid JSON object, no field is added.
+func (c Context) EmbedRawJSON(obj []byte) Context {
+	if isJSONObject(obj) {
+		c.l.context = appendJSONObjectData(c.l.context, obj)
+	}
+	return c
+}
+
 // Str adds the field key with val as a string to the logger context.
 func (c Context) Str(key, val string) Context {
-	c.l.context = enc.AppendString(enc.AppendKey(c.l.context, key), val)
+	c.l.context = appendInternString(enc.AppendKey(c.l.context, key), val)
 	return c
 }
 
@@ -99,13 +140,13 @@ func (c Context) Stringer(key string, val fmt.Stringer) Context {
 
 // Bytes adds the field key with val as a []byte to the logger context.
 func (c Context) Bytes(key string, val []byte) Context {
-	c.l.context = enc.AppendBytes(enc.AppendKey(c.l.context, key), val)
+	c.l.context = appendBytes(enc.AppendKey(c.l.context, key), val, false, c.l.bytesFmt)
 	return c
 }
 
 // Hex adds the field key with val as a hex string to the logger context.
 func (c Context) Hex(key string, val []byte) Context {
-	c.l.context = enc.AppendHex(enc.AppendKey(c.l.context, key), val)
+	c.l.context = appendBytes(enc.AppendKey(c.l.context, key), val, true, c.l.bytesFmt)
 	return c
 }
 
@@ -120,7 +161,7 @@ func (c Context) RawJSON(key string, b []byte) Context {
 
 // AnErr adds the field key with serialized err to the logger context.
 func (c Context) AnErr(key string, err error) Context {
-	switch m := ErrorMarshalFunc(err).(type) {
+	switch m := marshalError(err, c.l.settings).(type) {
 	case nil:
 		return c
 	case LogObjectMarshaler:
@@ -143,7 +184,7 @@ func (c Context) AnErr(key string, err error) Context {
 func (c Context) Errs(key string, errs []error) Context {
 	arr := Arr()
 	for _, err := range errs {
-		switch m := ErrorMarshalFunc(err).(type) {
+		switch m := marshalError(err, c.l.settings).(type) {
 		case LogObjectMarshaler:
 			arr = arr.Object(m)
 		case error:
@@ -162,25 +203,29 @@ func (c Context) Errs(key string, errs []error) Context {
 	return c.Array(key, arr)
 }
 
-// Err adds the field "error" with serialized err to the logger context.
+// Err adds the field "error" with serialized err to the logger context. If
+// zerolog.ErrorClassifier is defined, the classification fields it derives
+// from err are added before the error.
 func (c Context) Err(err error) Context {
-	if c.l.stack && ErrorStackMarshaler != nil {
-		switch m := ErrorStackMarshaler(err).(type) {
+	if stackMarshaler := c.l.settings.errorStackMarshaler(); c.l.stack && stackMarshaler != nil {
+		key := c.l.settings.errorStackFieldName()
+		switch m := stackMarshaler(err).(type) {
 		case nil:
 		case LogObjectMarshaler:
-			c = c.Object(ErrorStackFieldName, m)
+			c = c.Object(key, m)
 		case error:
 			if m != nil && !isNilValue(m) {
-				c = c.Str(ErrorStackFieldName, m.Error())
+				c = c.Str(key, m.Error())
 			}
 		case string:
-			c = c.Str(ErrorStackFieldName, m)
+			c = c.Str(key, m)
 		default:
-			c = c.Interface(ErrorStackFieldName, m)
+			c = c.Interface(key, m)
 		}
 	}
+	c.l.context = appendErrorClass(c.l.context, err)
 
-	return c.AnErr(ErrorFieldName, err)
+	return c.AnErr(c.l.settings.errorFieldName(), err)
 }
 
 // Ctx adds the context.Context to the logger context. The context.Context is
@@ -188,7 +233,7 @@ func (c Context) Err(err error) Context {
 // A typical use case is to extract tracing information from the
 // context.Context.
 func (c Context) Ctx(ctx context.Context) Context {
-	c.l.ctx = ctx
+	c.l.setCtx(ctx)
 	return c
 }
 
@@ -206,13 +251,13 @@ func (c Context) Bools(key string, b []bool) Context {
 
 // Int adds the field key with i as a int to the logger context.
 func (c Context) Int(key string, i int) Context {
-	c.l.context = enc.AppendInt(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendInt64(enc.AppendKey(c.l.context, key), int64(i))
 	return c
 }
 
 // Ints adds the field key with i as a []int to the logger context.
 func (c Context) Ints(key string, i []int) Context {
-	c.l.context = enc.AppendInts(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendInts(enc.AppendKey(c.l.context, key), i)
 	return c
 }
 
@@ -254,25 +299,25 @@ func (c Context) Ints32(key string, i []int32) Context {
 
 // Int64 adds the field key with i as a int64 to the logger context.
 func (c Context) Int64(key string, i int64) Context {
-	c.l.context = enc.AppendInt64(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendInt64(enc.AppendKey(c.l.context, key), i)
 	return c
 }
 
 // Ints64 adds the field key with i as a []int64 to the logger context.
 func (c Context) Ints64(key string, i []int64) Context {
-	c.l.context = enc.AppendInts64(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendInts64(enc.AppendKey(c.l.context, key), i)
 	return c
 }
 
 // Uint adds the field key with i as a uint to the logger context.
 func (c Context) Uint(key string, i uint) Context {
-	c.l.context = enc.AppendUint(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendUint64(enc.AppendKey(c.l.context, key), uint64(i))
 	return c
 }
 
 // Uints adds the field key with i as a []uint to the logger context.
 func (c Context) Uints(key string, i []uint) Context {
-	c.l.context = enc.AppendUints(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendUints(enc.AppendKey(c.l.context, key), i)
 	return c
 }
 
@@ -314,37 +359,65 @@ func (c Context) Uints32(key string, i []uint32) Context {
 
 // Uint64 adds the field key with i as a uint64 to the logger context.
 func (c Context) Uint64(key string, i uint64) Context {
-	c.l.context = enc.AppendUint64(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendUint64(enc.AppendKey(c.l.context, key), i)
+	return c
+}
+
+// Int64Str adds the field key with i as a string to the logger context,
+// regardless of LargeIntAsString.
+func (c Context) Int64Str(key string, i int64) Context {
+	c.l.context = enc.AppendString(enc.AppendKey(c.l.context, key), strconv.FormatInt(i, 10))
+	return c
+}
+
+// Uint64Str adds the field key with i as a string to the logger context,
+// regardless of LargeIntAsString.
+func (c Context) Uint64Str(key string, i uint64) Context {
+	c.l.context = enc.AppendString(enc.AppendKey(c.l.context, key), strconv.FormatUint(i, 10))
 	return c
 }
 
 // Uints64 adds the field key with i as a []uint64 to the logger context.
 func (c Context) Uints64(key string, i []uint64) Context {
-	c.l.context = enc.AppendUints64(enc.AppendKey(c.l.context, key), i)
+	c.l.context = appendUints64(enc.AppendKey(c.l.context, key), i)
 	return c
 }
 
 // Float32 adds the field key with f as a float32 to the logger context.
 func (c Context) Float32(key string, f float32) Context {
-	c.l.context = enc.AppendFloat32(enc.AppendKey(c.l.context, key), f, FloatingPointPrecision)
+	c.l.context = appendFloat32(enc.AppendKey(c.l.context, key), f, c.l.floatFmt)
 	return c
 }
 
 // Floats32 adds the field key with f as a []float32 to the logger context.
 func (c Context) Floats32(key string, f []float32) Context {
-	c.l.context = enc.AppendFloats32(enc.AppendKey(c.l.context, key), f, FloatingPointPrecision)
+	c.l.context = appendFloats32(enc.AppendKey(c.l.context, key), f, c.l.floatFmt)
 	return c
 }
 
 // Float64 adds the field key with f as a float64 to the logger context.
 func (c Context) Float64(key string, f float64) Context {
-	c.l.context = enc.AppendFloat64(enc.AppendKey(c.l.context, key), f, FloatingPointPrecision)
+	c.l.context = appendFloat64(enc.AppendKey(c.l.context, key), f, c.l.floatFmt)
 	return c
 }
 
 // Floats64 adds the field key with f as a []float64 to the logger context.
 func (c Context) Floats64(key string, f []float64) Context {
-	c.l.context = enc.AppendFloats64(enc.AppendKey(c.l.context, key), f, FloatingPointPrecision)
+	c.l.context = appendFloats64(enc.AppendKey(c.l.context, key), f, c.l.floatFmt)
+	return c
+}
+
+// Float32P adds the field key with f as a float32 to the logger context,
+// formatted with prec digits instead of the logger or global precision.
+func (c Context) Float32P(key string, f float32, prec int) Context {
+	c.l.context = appendFloat32P(enc.AppendKey(c.l.context, key), f, prec, c.l.floatFmt)
+	return c
+}
+
+// Float64P adds the field key with f as a float64 to the logger context,
+// formatted with prec digits instead of the logger or global precision.
+func (c Context) Float64P(key string, f float64, prec int) Context {
+	c.l.context = appendFloat64P(enc.AppendKey(c.l.context, key), f, prec, c.l.floatFmt)
 	return c
 }
 
@@ -368,25 +441,25 @@ func (c Context) Timestamp() Context {
 
 // Time adds the field key with t formatted as string using zerolog.TimeFieldFormat.
 func (c Context) Time(key string, t time.Time) Context {
-	c.l.context = enc.AppendTime(enc.AppendKey(c.l.context, key), t, TimeFieldFormat)
+	c.l.context = enc.AppendTime(enc.AppendKey(c.l.context, key), t, c.l.settings.timeFieldFormat())
 	return c
 }
 
 // Times adds the field key with t formatted as string using zerolog.TimeFieldFormat.
 func (c Context) Times(key string, t []time.Time) Context {
-	c.l.context = enc.AppendTimes(enc.AppendKey(c.l.context, key), t, TimeFieldFormat)
+	c.l.context = enc.AppendTimes(enc.AppendKey(c.l.context, key), t, c.l.settings.timeFieldFormat())
 	return c
 }
 
 // Dur adds the fields key with d divided by unit and stored as a float.
 func (c Context) Dur(key string, d time.Duration) Context {
-	c.l.context = enc.AppendDuration(enc.AppendKey(c.l.context, key), d, DurationFieldUnit, DurationFieldInteger, FloatingPointPrecision)
+	c.l.context = enc.AppendDuration(enc.AppendKey(c.l.context, key), d, c.l.settings.durationFieldUnit(), c.l.settings.durationFieldInteger(), FloatingPointPrecision)
 	return c
 }
 
 // Durs adds the fields key with d divided by unit and stored as a float.
 func (c Context) Durs(key string, d []time.Duration) Context {
-	c.l.context = enc.AppendDurations(enc.AppendKey(c.l.context, key), d, DurationFieldUnit, DurationFieldInteger, FloatingPointPrecision)
+	c.l.context = enc.AppendDurations(enc.AppendKey(c.l.context, key), d, c.l.settings.durationFieldUnit(), c.l.settings.durationFieldInteger(), FloatingPointPrecision)
 	return c
 }
 
@@ -410,9 +483,17 @@ func (c Context) Any(key string, i interface{}) Context {
 	return c.Interface(key, i)
 }
 
-// Reset removes all the context fields.
+// Reset removes all the context fields, except the sealed ones.
 func (c Context) Reset() Context {
 	c.l.context = enc.AppendBeginMarker(make([]byte, 0, 500))
+	c.l.lazy = nil
+	c.l.groups = 0
+	for _, s := range c.l.sealed {
+		if len(c.l.context) > 1 {
+			c.l.context = append(c.l.context, ',')
+		}
+		c.l.context = append(append(append(c.l.context, s.key...), ':'), s.value...)
+	}
 	return c
 }
 
@@ -428,7 +509,7 @@ func (ch callerHook) Run(e *Event, level Level, msg string) {
 	switch ch.callerSkipFrameCount {
 	case useGlobalSkipFrameCount:
 		// Extra frames to skip (added by hook infra).
-		e.caller(CallerSkipFrameCount + contextCallerSkipFrameCount)
+		e.caller(e.settings.callerSkipFrameCount() + contextCallerSkipFrameCount)
 	default:
 		// Extra frames to skip (added by hook infra).
 		e.caller(ch.callerSkipFrameCount + contextCallerSkipFrameCount)
@@ -482,9 +563,10 @@ func (c Context) MACAddr(key string, ha net.HardwareAddr) Context {
 
 // DeDup removes duplicate fields and keeps last added field in context.
 //
-// Caution: This is an expensive operation.
+// Caution: This is an expensive operation. It does nothing while a group is
+// open.
 func (c Context) DeDup() Context {
-	if len(c.l.context) <= 1 {
+	if len(c.l.context) <= 1 || c.l.groups > 0 {
 		return c
 	}
 	values := make(map[string][]byte)
@@ -540,8 +622,9 @@ func (c Context) DeDup() Context {
 //
 // Caution: This is an expensive operation.
 // If it fails, it will revert back to the original data with potentially duplicated fields
+// It does nothing while a group is open.
 func (c Context) DeDupDeep() Context {
-	if len(c.l.context) == 0 {
+	if len(c.l.context) == 0 || c.l.groups > 0 {
 		return c
 	}
 
@@ -0,0 +1,156 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CSVWriter parses the JSON input and writes it to Out as CSV (or TSV)
+// records, projecting a fixed list of fields into columns. It is meant to
+// feed legacy tooling and spreadsheets directly.
+type CSVWriter struct {
+	// Out is the output destination.
+	Out io.Writer
+
+	// Columns lists the fields written as columns, in order. Fields missing
+	// from an event are written as empty values.
+	Columns []string
+
+	// Comma is the field delimiter. It defaults to ','. Set it to '\t' to
+	// produce TSV.
+	Comma rune
+
+	// OverflowColumn, if not empty, adds a last column with this name holding
+	// the fields not listed in Columns, encoded as a JSON object. Otherwise,
+	// those fields are dropped.
+	OverflowColumn string
+
+	// NoHeader disables the header record written before the first event.
+	NoHeader bool
+
+	mu          sync.Mutex
+	wroteHeader bool
+}
+
+// NewCSVWriter creates a CSVWriter writing the columns fields of each event
+// to out.
+func NewCSVWriter(out io.Writer, columns []string, options ...func(w *CSVWriter)) *CSVWriter {
+	w := &CSVWriter{
+		Out:     out,
+		Columns: columns,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// Write transforms the JSON input into a CSV record and writes it to w.Out.
+func (w *CSVWriter) Write(p []byte) (n int, err error) {
+	var evt map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(decodeIfBinaryToBytes(p)))
+	d.UseNumber()
+	if err = d.Decode(&evt); err != nil {
+		return n, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	buf := consoleBufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		consoleBufPool.Put(buf)
+	}()
+	cw := csv.NewWriter(buf)
+	if w.Comma != 0 {
+		cw.Comma = w.Comma
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.wroteHeader && !w.NoHeader {
+		header := w.Columns
+		if w.OverflowColumn != "" {
+			header = append(header[:len(header):len(header)], w.OverflowColumn)
+		}
+		if err = cw.Write(header); err != nil {
+			return n, err
+		}
+	}
+
+	record := make([]string, 0, len(w.Columns)+1)
+	for _, col := range w.Columns {
+		v, ok := evt[col]
+		if !ok {
+			record = append(record, "")
+			continue
+		}
+		record = append(record, csvValue(v))
+		delete(evt, col)
+	}
+	if w.OverflowColumn != "" {
+		var overflow string
+		if len(evt) > 0 {
+			b, err := json.Marshal(evt)
+			if err != nil {
+				return n, err
+			}
+			overflow = string(b)
+		}
+		record = append(record, overflow)
+	}
+	if err = cw.Write(record); err != nil {
+		return n, err
+	}
+	cw.Flush()
+	if err = cw.Error(); err != nil {
+		return n, err
+	}
+
+	if _, err = buf.WriteTo(w.Out); err != nil {
+		return n, err
+	}
+	w.wroteHeader = true
+	return len(p), nil
+}
+
+// Close calls the underlying writer's Close method if it is an io.Closer.
+// Otherwise does nothing.
+func (w *CSVWriter) Close() error {
+	if closer, ok := w.Out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Flush calls the underlying writer's Flush method if any. Otherwise does
+// nothing.
+func (w *CSVWriter) Flush() error {
+	return flush(w.Out)
+}
+
+// csvValue formats a decoded JSON value as a CSV field.
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "true"
+		}
+		return "false"
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,39 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCSVWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewCSVWriter(out, []string{"level", "message", "n"}, func(w *CSVWriter) {
+		w.OverflowColumn = "extra"
+	})
+	log := New(w)
+	log.Info().Int("n", 1).Str("foo", "bar").Msg("hello, world")
+	log.Warn().Bool("ok", true).Msg(`say "hi"`)
+	log.Log().Msg("")
+
+	want := "level,message,n,extra\n" +
+		`info,"hello, world",1,"{""foo"":""bar""}"` + "\n" +
+		`warn,"say ""hi""",,"{""ok"":true}"` + "\n" +
+		",,,\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestCSVWriterTSV(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewCSVWriter(out, []string{"level", "obj"}, func(w *CSVWriter) {
+		w.Comma = '\t'
+		w.NoHeader = true
+	})
+	log := New(w)
+	log.Error().Dict("obj", Dict().Int("a", 1)).Str("dropped", "x").Msg("")
+
+	if got, want := out.String(), "error\t\"{\"\"a\"\":1}\"\n"; got != want {
+		t.Errorf("invalid output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -14,6 +14,8 @@ func init() {
 
 type ctxKey struct{}
 
+type ctxLevelKey struct{}
+
 // WithContext returns a copy of ctx with the receiver attached. The Logger
 // attached to the provided Context (if any) will not be effected.  If the
 // receiver's log level is Disabled it will only be attached to the returned
@@ -21,6 +23,10 @@ type ctxKey struct{}
 // provided Context has no attached Logger, a Disabled Logger will not be
 // attached.
 //
+// If TraceContextExtractor is set, ctx also becomes the context of the events
+// of the attached Logger, so they carry the trace context of ctx. If ctx
+// carries a level set by WithCtxLevel, the attached Logger uses it.
+//
 // Note: to modify the existing Logger attached to a Context (instead of
 // replacing it in a new Context), use UpdateContext with the following
 // notation:
@@ -32,21 +38,75 @@ type ctxKey struct{}
 //     })
 //
 func (l Logger) WithContext(ctx context.Context) context.Context {
-	if _, ok := ctx.Value(ctxKey{}).(*Logger); !ok && l.level == Disabled {
+	if _, ok := ctx.Value(ctxKey{}).(*Logger); !ok && l.GetLevel() == Disabled {
 		// Do not store disabled logger.
 		return ctx
 	}
+	if TraceContextExtractor != nil {
+		l.setCtx(ctx)
+	}
+	if lvl, ok := CtxLevel(ctx); ok {
+		l = l.Level(lvl)
+	}
 	return context.WithValue(ctx, ctxKey{}, &l)
 }
 
 // Ctx returns the Logger associated with the ctx. If no logger
 // is associated, DefaultContextLogger is returned, unless DefaultContextLogger
 // is nil, in which case a disabled logger is returned.
+//
+// If ctx carries a level set by WithCtxLevel, the returned Logger uses it.
 func Ctx(ctx context.Context) *Logger {
 	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
 		return l
 	} else if l = DefaultContextLogger; l != nil {
+		if lvl, ok := CtxLevel(ctx); ok && lvl != l.GetLevel() {
+			cp := l.Level(lvl)
+			return &cp
+		}
 		return l
 	}
 	return disabledLogger
 }
+
+// WithCtxLevel returns a copy of ctx carrying level as the log level of the
+// work it represents, like a request marked for debugging by a header
+// validated upstream:
+//
+//	if debugAllowed(r) {
+//	    r = r.WithContext(zerolog.WithCtxLevel(r.Context(), zerolog.DebugLevel))
+//	}
+//
+// The level applies, instead of their own, to the Loggers returned by Ctx and
+// attached with WithContext, and to the loggers which context was added with
+// Context.Ctx. The global level still applies. Events moved to ctx with
+// Event.Ctx are discarded if below level, but not made more verbose.
+func WithCtxLevel(ctx context.Context, level Level) context.Context {
+	ctx = context.WithValue(ctx, ctxLevelKey{}, level)
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok && l.GetLevel() != level {
+		// Attach a copy so the Logger of the parent context is not affected
+		// and the copy is shared by the callers of Ctx.
+		cp := l.Level(level)
+		if cp.ctx != nil {
+			cp.setCtx(ctx)
+		}
+		ctx = context.WithValue(ctx, ctxKey{}, &cp)
+	}
+	return ctx
+}
+
+// setCtx sets ctx as the context of the events of l and resolves the level
+// it carries once, rather than for each event.
+func (l *Logger) setCtx(ctx context.Context) {
+	l.ctx = ctx
+	l.ctxLvSet = false
+	if ctx != nil {
+		l.ctxLevel, l.ctxLvSet = CtxLevel(ctx)
+	}
+}
+
+// CtxLevel returns the level set on ctx by WithCtxLevel, if any.
+func CtxLevel(ctx context.Context) (Level, bool) {
+	lvl, ok := ctx.Value(ctxLevelKey{}).(Level)
+	return lvl, ok
+}
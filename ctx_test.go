@@ -99,3 +99,69 @@ func Test_InterfaceLogObjectMarshaler(t *testing.T) {
 		t.Errorf("got %q, want %q", got, want)
 	}
 }
+
+func TestWithCtxLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Level(InfoLevel)
+	ctx := log.WithContext(context.Background())
+	dctx := WithCtxLevel(ctx, DebugLevel)
+
+	if lvl, ok := CtxLevel(dctx); !ok || lvl != DebugLevel {
+		t.Errorf("CtxLevel() = %v, %v, want debug, true", lvl, ok)
+	}
+	if _, ok := CtxLevel(ctx); ok {
+		t.Error("CtxLevel() of the parent context: got a level")
+	}
+
+	l := Ctx(dctx)
+	if l != Ctx(dctx) {
+		t.Error("Ctx returned different loggers for the same context")
+	}
+	l.UpdateContext(func(c Context) Context { return c.Str("req", "1") })
+	l.Debug().Msg("ctx")
+	Ctx(ctx).Debug().Msg("parent")
+	Ctx(log.WithContext(dctx)).Debug().Msg("attached")
+	cl := log.With().Ctx(dctx).Logger()
+	cl.Debug().Msg("context")
+	cl.Trace().Msg("trace")
+
+	DefaultContextLogger = &log
+	t.Cleanup(func() { DefaultContextLogger = nil })
+	Ctx(WithCtxLevel(context.Background(), DebugLevel)).Debug().Msg("default")
+	if log.GetLevel() != InfoLevel {
+		t.Error("DefaultContextLogger level changed")
+	}
+
+	got := cbor.DecodeIfBinaryToString(out.Bytes())
+	want := `{"level":"debug","req":"1","message":"ctx"}` + "\n" +
+		`{"level":"debug","message":"attached"}` + "\n" +
+		`{"level":"debug","message":"context"}` + "\n" +
+		`{"level":"debug","message":"default"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEventCtxLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Level(DebugLevel)
+	wctx := WithCtxLevel(context.Background(), WarnLevel)
+	dctx := WithCtxLevel(context.Background(), DebugLevel)
+
+	if e := log.Info().Ctx(wctx); e != nil {
+		t.Error("Ctx did not discard an event below the level of the context")
+	}
+	log.Info().Ctx(wctx).Msg("info")
+	log.Warn().Ctx(wctx).Msg("warn")
+	log.Trace().Ctx(dctx).Msg("trace")
+	log.Debug().Ctx(context.Background()).Msg("debug")
+	log.Info().Ctx(nil).Msg("nil") // nolint
+
+	got := cbor.DecodeIfBinaryToString(out.Bytes())
+	want := `{"level":"warn","message":"warn"}` + "\n" +
+		`{"level":"debug","message":"debug"}` + "\n" +
+		`{"level":"info","message":"nil"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
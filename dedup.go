@@ -0,0 +1,332 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"unicode"
+)
+
+// DeDupMode selects the duplicate fields removal automatically applied to
+// the events of a logger.
+type DeDupMode uint8
+
+const (
+	// DeDupModeNone keeps duplicate fields. This is the default.
+	DeDupModeNone DeDupMode = iota
+	// DeDupModeShallow keeps the last value of the duplicate top level keys,
+	// at the position of the first one, like DeDupWithStrategy with
+	// DeDupLastWins.
+	DeDupModeShallow
+	// DeDupModeDeep applies Event.DeDupDeep to events having duplicate keys.
+	DeDupModeDeep
+)
+
+// AutoDeDup returns a logger removing duplicate fields from all its events
+// using mode, so call sites don't have to call DeDup or DeDupDeep. Fields are
+// deduplicated once hooks have run, before the message is added.
+//
+// Events are first scanned for duplicate top level keys, and left untouched
+// if none is found, so the expensive removal is only paid by the events
+// having duplicates. Only the JSON encoding is supported.
+//
+// mode overrides the global AutoDeDup, DeDupModeNone disabling the removal
+// for the logger.
+func (l Logger) AutoDeDup(mode DeDupMode) Logger {
+	l.dedup = mode
+	l.dedupSet = true
+	return l
+}
+
+// autoDeDup applies the removal of duplicate fields selected by mode to e.
+func (e *Event) autoDeDup(mode DeDupMode) {
+	if len(e.buf) == 0 || e.buf[0] != '{' || !hasDuplicateKeys(e.buf) {
+		return
+	}
+	switch mode {
+	case DeDupModeShallow:
+		e.buf = dedupFields(e.buf, DeDupLastWins, nil)
+	case DeDupModeDeep:
+		e.DeDupDeep()
+	}
+}
+
+// DeDupStrategy selects which value of a duplicate field is kept by
+// DeDupWithStrategy.
+type DeDupStrategy uint8
+
+const (
+	// DeDupLastWins keeps the value added last, like DeDup.
+	DeDupLastWins DeDupStrategy = iota
+	// DeDupFirstWins keeps the value added first.
+	DeDupFirstWins
+	// DeDupMerge deep merges the values which are all objects, the fields
+	// added last winning, and keeps the value added last otherwise.
+	DeDupMerge
+)
+
+// DeDupWithStrategy removes the duplicate fields of the event, keeping the
+// value selected by strategy at the position of the first one:
+//
+//	log.Info().
+//	    Dict("http", zerolog.Dict().Str("method", "GET")).
+//	    Dict("http", zerolog.Dict().Int("status", 200)).
+//	    DeDupWithStrategy(zerolog.DeDupMerge).
+//	    Msg("")
+//	// {"level":"info","http":{"method":"GET","status":200}}
+//
+// Only the JSON encoding is supported.
+//
+// Caution: This is an expensive operation.
+func (e *Event) DeDupWithStrategy(strategy DeDupStrategy) *Event {
+	if e == nil || e.groups > 0 {
+		return e
+	}
+	e.guardUse("DeDupWithStrategy")
+	e.buf = dedupFields(e.buf, strategy, nil)
+	return e
+}
+
+// DeDupWithStrategy removes the duplicate fields of the context like
+// Event.DeDupWithStrategy. It does nothing while a group is open.
+func (c Context) DeDupWithStrategy(strategy DeDupStrategy) Context {
+	if c.l.groups > 0 {
+		return c
+	}
+	c.l.context = dedupFields(c.l.context, strategy, nil)
+	return c
+}
+
+// DeDupFunc removes the duplicate fields of the event like DeDup, keys being
+// equal when their normalize results are, like strings.ToLower for case
+// insensitive keys or SnakeCase for "userId" and "user_id". The last added
+// field is kept, at the position of the first one:
+//
+//	log.Info().Str("userId", "1").Str("user_id", "2").DeDupFunc(zerolog.SnakeCase).Msg("")
+//	// {"level":"info","user_id":"2"}
+//
+// Only the JSON encoding is supported.
+//
+// Caution: This is an expensive operation.
+func (e *Event) DeDupFunc(normalize func(key string) string) *Event {
+	if e == nil || e.groups > 0 {
+		return e
+	}
+	e.guardUse("DeDupFunc")
+	e.buf = dedupFields(e.buf, DeDupLastWins, normalize)
+	return e
+}
+
+// DeDupFunc removes the duplicate fields of the context like
+// Event.DeDupFunc. It does nothing while a group is open.
+func (c Context) DeDupFunc(normalize func(key string) string) Context {
+	if c.l.groups > 0 {
+		return c
+	}
+	c.l.context = dedupFields(c.l.context, DeDupLastWins, normalize)
+	return c
+}
+
+// SnakeCase returns key in snake case, like "user_id" for "userId", "UserID",
+// "user-id" or "USER_ID". It is meant to be used with DeDupFunc.
+func SnakeCase(key string) string {
+	runes := []rune(key)
+	out := make([]rune, 0, len(runes)+4)
+	for i, r := range runes {
+		switch {
+		case r == '-' || r == ' ' || r == '.':
+			r = '_'
+		case unicode.IsUpper(r):
+			if i > 0 && out[len(out)-1] != '_' {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+				if unicode.IsLower(prev) || unicode.IsDigit(prev) || unicode.IsUpper(prev) && nextLower {
+					out = append(out, '_')
+				}
+			}
+			r = unicode.ToLower(r)
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+// jsonField is a field of a JSON object.
+type jsonField struct {
+	key, value []byte
+}
+
+// dedupFields returns the unterminated JSON object buf without its duplicate
+// top level keys, compared once normalized if normalize is not nil, or buf if
+// it is not a JSON object.
+func dedupFields(buf []byte, strategy DeDupStrategy, normalize func(string) string) []byte {
+	if len(buf) <= 1 || buf[0] != '{' || normalize == nil && !hasDuplicateKeys(buf) {
+		return buf
+	}
+	fields, ok := objectFields(buf)
+	if !ok {
+		return buf
+	}
+	fields = mergeFields(fields, strategy, normalize)
+	out := make([]byte, 0, len(buf))
+	return appendJSONFields(append(out, '{'), fields)
+}
+
+// objectFields returns the fields of the unterminated JSON object buf.
+func objectFields(buf []byte) (fields []jsonField, ok bool) {
+	for i := skipSpace(buf, 1); i < len(buf); i = skipSpace(buf, i) {
+		if buf[i] != '"' {
+			return nil, false
+		}
+		end := skipString(buf, i)
+		colon := skipSpace(buf, end)
+		if colon >= len(buf) || buf[colon] != ':' {
+			return nil, false
+		}
+		next := skipValue(buf, colon+1)
+		fields = append(fields, jsonField{key: buf[i:end], value: trimSpace(buf[colon+1 : next])})
+		if next < len(buf) && buf[next] == ',' {
+			next++
+		}
+		i = next
+	}
+	return fields, true
+}
+
+// mergeFields removes the duplicate keys of fields according to strategy,
+// keeping the order of the first occurrences. Keys are compared once
+// normalized if normalize is not nil.
+func mergeFields(fields []jsonField, strategy DeDupStrategy, normalize func(string) string) []jsonField {
+	out := make([]jsonField, 0, len(fields))
+	index := make(map[string]int, len(fields))
+	for _, f := range fields {
+		key := string(f.key)
+		if normalize != nil {
+			key = normalize(unquoteKey(f.key))
+		}
+		i, dup := index[key]
+		if !dup {
+			index[key] = len(out)
+			out = append(out, f)
+			continue
+		}
+		switch strategy {
+		case DeDupFirstWins:
+		case DeDupMerge:
+			out[i].value = mergeObjects(out[i].value, f.value)
+		default:
+			out[i] = f
+		}
+	}
+	return out
+}
+
+// unquoteKey returns the JSON string key unquoted.
+func unquoteKey(key []byte) string {
+	if bytes.IndexByte(key, '\\') < 0 {
+		return string(key[1 : len(key)-1])
+	}
+	var s string
+	if json.Unmarshal(key, &s) != nil {
+		return string(key)
+	}
+	return s
+}
+
+// mergeObjects deep merges the JSON objects a and b, the fields of b winning.
+// It returns b if any is not an object.
+func mergeObjects(a, b []byte) []byte {
+	if len(a) < 2 || len(b) < 2 || a[0] != '{' || b[0] != '{' {
+		return b
+	}
+	fa, ok := objectFields(a[:len(a)-1])
+	if !ok {
+		return b
+	}
+	fb, ok := objectFields(b[:len(b)-1])
+	if !ok {
+		return b
+	}
+	fields := mergeFields(append(fa[:len(fa):len(fa)], fb...), DeDupMerge, nil)
+	out := appendJSONFields(append(make([]byte, 0, len(a)+len(b)), '{'), fields)
+	return append(out, '}')
+}
+
+// appendJSONFields appends fields to dst, separated by commas.
+func appendJSONFields(dst []byte, fields []jsonField) []byte {
+	for i, f := range fields {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(append(append(dst, f.key...), ':'), f.value...)
+	}
+	return dst
+}
+
+// hasDuplicateKeys returns true if the unterminated JSON object buf has
+// duplicate top level keys.
+func hasDuplicateKeys(buf []byte) bool {
+	var (
+		stack [16][]byte
+		keys  = stack[:0]
+	)
+	for i := 1; i < len(buf); {
+		if buf[i] != '"' {
+			// Malformed or unsupported content, assume there are duplicates
+			// to let DeDup deal with it.
+			return true
+		}
+		end := skipString(buf, i)
+		key := buf[i:end]
+		for _, k := range keys {
+			if bytes.Equal(k, key) {
+				return true
+			}
+		}
+		keys = append(keys, key)
+		// Skip the colon and the value.
+		i = skipValue(buf, end+1)
+		if i < len(buf) && buf[i] == ',' {
+			i++
+		}
+	}
+	return false
+}
+
+// skipString returns the index following the JSON string starting at i.
+func skipString(buf []byte, i int) int {
+	for i++; i < len(buf); i++ {
+		switch buf[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(buf)
+}
+
+// skipValue returns the index of the comma or of the closing brace of the
+// enclosing object ending the JSON value starting at i, or len(buf).
+func skipValue(buf []byte, i int) int {
+	depth := 0
+	for i < len(buf) {
+		switch buf[i] {
+		case '"':
+			i = skipString(buf, i)
+			continue
+		case '{', '[':
+			depth++
+		case '}', ']':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case ',':
+			if depth == 0 {
+				return i
+			}
+		}
+		i++
+	}
+	return i
+}
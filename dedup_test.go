@@ -0,0 +1,155 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHasDuplicateKeys(t *testing.T) {
+	tests := []struct {
+		buf  string
+		want bool
+	}{
+		{`{`, false},
+		{`{"a":1`, false},
+		{`{"a":1,"b":"x,\"a\":2","c":{"a":[1,{"a":2}]}`, false},
+		{`{"a":1,"b":2,"a":3`, true},
+		{`{"a":{"b":1},"a":"}"`, true},
+	}
+	for _, tt := range tests {
+		if got := hasDuplicateKeys([]byte(tt.buf)); got != tt.want {
+			t.Errorf("hasDuplicateKeys(%s) = %v, want %v", tt.buf, got, tt.want)
+		}
+	}
+}
+
+func TestAutoDeDup(t *testing.T) {
+	for _, mode := range []DeDupMode{DeDupModeShallow, DeDupModeDeep} {
+		out := &bytes.Buffer{}
+		log := New(out).With().Str("foo", "bar").Logger().AutoDeDup(mode)
+		log.Info().Str("baz", "qux").Msg("unique")
+		if got, want := out.String(), `{"level":"info","foo":"bar","baz":"qux","message":"unique"}`+"\n"; got != want {
+			t.Errorf("mode %d: invalid log output:\ngot:  %v\nwant: %v", mode, got, want)
+		}
+
+		out.Reset()
+		log.Info().Str("foo", "baz").Msg("dup")
+		var got map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+			t.Fatalf("mode %d: %v: %s", mode, err, out.String())
+		}
+		want := map[string]interface{}{"level": "info", "foo": "baz", "message": "dup"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("mode %d: got %v, want %v", mode, got, want)
+		}
+	}
+}
+
+func TestAutoDeDupSeparatorsInStrings(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).AutoDeDup(DeDupModeShallow)
+	log.Info().Str("k", "1").Str("k", "2").Str("url", "http://a,b").Str("kv", `a:1,"b":{2}`).Msg("")
+	if got, want := out.String(), `{"level":"info","k":"2","url":"http://a,b","kv":"a:1,\"b\":{2}"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestGlobalAutoDeDup(t *testing.T) {
+	AutoDeDup = DeDupModeShallow
+	defer func() { AutoDeDup = DeDupModeNone }()
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("foo", "bar").Logger()
+	log.Info().Str("foo", "baz").Msg("dup")
+	var got map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if want := map[string]interface{}{"level": "info", "foo": "baz", "message": "dup"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	out.Reset()
+	log.Info().Str("url", "http://a,b").Str("foo", "x:y,z").Str("url", "http://c:8080/d,e").Msg("")
+	if got, want := out.String(), `{"level":"info","foo":"x:y,z","url":"http://c:8080/d,e"}`+"\n"; got != want {
+		t.Errorf("invalid log output with separators in strings:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = log.AutoDeDup(DeDupModeNone)
+	log.Info().Str("foo", "baz").Msg("dup")
+	if got, want := out.String(), `{"level":"info","foo":"bar","foo":"baz","message":"dup"}`+"\n"; got != want {
+		t.Errorf("invalid log output with auto dedup disabled:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDeDupWithStrategy(t *testing.T) {
+	tests := []struct {
+		strategy DeDupStrategy
+		want     string
+	}{
+		{DeDupLastWins, `{"level":"info","foo":"baz","http":{"status":200,"headers":{"b":"2"}},"n":1,"message":"dup"}` + "\n"},
+		{DeDupFirstWins, `{"level":"info","foo":"bar","http":{"method":"GET","headers":{"a":"1"}},"n":1,"message":"dup"}` + "\n"},
+		{DeDupMerge, `{"level":"info","foo":"baz","http":{"method":"GET","headers":{"a":"1","b":"2"},"status":200},"n":1,"message":"dup"}` + "\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		log := New(out).With().Str("foo", "bar").Logger()
+		log.Info().
+			Dict("http", Dict().Str("method", "GET").Dict("headers", Dict().Str("a", "1"))).
+			Str("foo", "baz").
+			Int("n", 1).
+			Dict("http", Dict().Int("status", 200).Dict("headers", Dict().Str("b", "2"))).
+			DeDupWithStrategy(tt.strategy).
+			Msg("dup")
+		if got := out.String(); got != tt.want {
+			t.Errorf("strategy %d: invalid log output:\ngot:  %v\nwant: %v", tt.strategy, got, tt.want)
+		}
+	}
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("foo", "bar").Int("n", 1).Str("foo", "baz").RawJSON("raw", []byte(` { "a" : 1 } `)).
+		RawJSON("raw", []byte(`{"b":[2,"}"]}`)).DeDupWithStrategy(DeDupMerge).Logger()
+	log.Info().Msg("")
+	if got, want := out.String(), `{"level":"info","foo":"baz","n":1,"raw":{"a":1,"b":[2,"}"]}}`+"\n"; got != want {
+		t.Errorf("invalid context output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for key, want := range map[string]string{
+		"user_id":    "user_id",
+		"userId":     "user_id",
+		"UserID":     "user_id",
+		"USER_ID":    "user_id",
+		"user-id":    "user_id",
+		"HTTPServer": "http_server",
+		"ipv4Addr":   "ipv4_addr",
+	} {
+		if got := SnakeCase(key); got != want {
+			t.Errorf("SnakeCase(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestDeDupFunc(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("userId", "1").Str("Service", "api").Logger()
+	log.Info().Str("user_id", "2").Str("service", "web").Int("n", 1).DeDupFunc(SnakeCase).Msg("")
+	if got, want := out.String(), `{"level":"info","user_id":"2","service":"web","n":1}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = log.With().Str("SERVICE", "db").DeDupFunc(strings.ToLower).Logger()
+	log.Info().Msg("")
+	if got, want := out.String(), `{"level":"info","userId":"1","SERVICE":"db"}`+"\n"; got != want {
+		t.Errorf("invalid context output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
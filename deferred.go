@@ -0,0 +1,69 @@
+package zerolog
+
+import "bytes"
+
+// DeferredEvent is an event which outcome is decided when the function
+// logging it returns. It is created by Event.Deferred.
+type DeferredEvent struct {
+	e *Event
+}
+
+// Deferred returns the event as a DeferredEvent, to be sent by a deferred call
+// to MsgIfErr logging either the success or the failure of the function with
+// the fields shared by both outcomes:
+//
+//	func (s *Store) Save(id string) (err error) {
+//	    ev := log.Info().Str("id", id).Deferred()
+//	    defer ev.MsgIfErr(&err, "save failed", "saved")
+//	    ...
+//	}
+//
+// As the event is created with its success level, nothing is logged if this
+// level is disabled, even on failure.
+func (e *Event) Deferred() *DeferredEvent {
+	if e == nil {
+		return nil
+	}
+	e.guardUse("Deferred")
+	return &DeferredEvent{e: e}
+}
+
+// MsgIfErr sends the event with okMsg as message if *errp is nil. Otherwise,
+// the event is sent at ErrorLevel, unless its level is higher or it has no
+// level, with the error added as the error field and errMsg as message.
+//
+// NOTICE: once this method is called, the *DeferredEvent should be disposed.
+func (d *DeferredEvent) MsgIfErr(errp *error, errMsg, okMsg string) {
+	if d == nil || d.e == nil {
+		return
+	}
+	e := d.e
+	d.e = nil
+	if errp == nil || *errp == nil {
+		e.Msg(okMsg)
+		return
+	}
+	if !e.level.AtLeast(ErrorLevel) {
+		e.setLevel(ErrorLevel)
+	}
+	e.Err(*errp).Msg(errMsg)
+}
+
+// setLevel changes the level of e, rewriting the level field added first by
+// Logger.initEvent.
+func (e *Event) setLevel(level Level) {
+	name := e.settings.levelFieldName()
+	if name == "" {
+		e.level = level
+		return
+	}
+	old := enc.AppendString(enc.AppendKey(enc.AppendBeginMarker(nil), name), e.settings.levelFieldValue(e.level))
+	if !bytes.HasPrefix(e.buf, old) {
+		// The level field was removed or is not first, leave it as is.
+		e.level = level
+		return
+	}
+	buf := enc.AppendString(enc.AppendKey(enc.AppendBeginMarker(nil), name), e.settings.levelFieldValue(level))
+	e.buf = append(e.buf[:0], append(buf, e.buf[len(old):]...)...)
+	e.level = level
+}
@@ -0,0 +1,45 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestDeferredMsgIfErr(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	op := func(fail bool) (err error) {
+		ev := log.Info().Str("id", "42").Deferred()
+		defer ev.MsgIfErr(&err, "save failed", "saved")
+		if fail {
+			return errors.New("disk full")
+		}
+		return nil
+	}
+	op(false)
+	op(true)
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","id":"42","message":"saved"}` + "\n" +
+		`{"level":"error","id":"42","error":"disk full","message":"save failed"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDeferredMsgIfErrLevels(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Level(WarnLevel)
+	err := errors.New("boom")
+	log.Info().Deferred().MsgIfErr(&err, "failed", "ok")
+	log.WithLevel(PanicLevel).Deferred().MsgIfErr(&err, "failed", "ok")
+	log.Log().Deferred().MsgIfErr(&err, "failed", "ok")
+	var d *DeferredEvent
+	d.MsgIfErr(&err, "failed", "ok")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"panic","error":"boom","message":"failed"}` + "\n" +
+		`{"error":"boom","message":"failed"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,347 @@
+// Package dictcompress provides a writer compressing zerolog events in small
+// independent frames with a shared dictionary, and a helper to train the
+// dictionary from sample events.
+//
+// Compressing a few kilobytes at a time keeps the loss bounded when a device
+// loses power mid-write, but leaves little repetition for the compressor to
+// find on its own. Events are however highly repetitive from one to the
+// other, especially in the binary (CBOR) encoding where field names and
+// constant values dominate: a dictionary trained on sample events recovers
+// most of the ratio of large frames.
+//
+//	dict := dictcompress.Train(samples, 0)
+//	w, err := dictcompress.NewWriter(file, dictcompress.Options{Dict: dict})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// The dictionaries are raw content dictionaries: besides the deflate Codec
+// provided, they can be used by any compressor supporting them, like zstd,
+// through the Codec interface.
+package dictcompress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+)
+
+// Codec compresses and decompresses frames using a dictionary.
+type Codec interface {
+	// Compress appends the compressed src to dst.
+	Compress(dst, src, dict []byte) ([]byte, error)
+
+	// Decompress appends the decompressed src to dst.
+	Decompress(dst, src, dict []byte) ([]byte, error)
+}
+
+// Deflate is the default Codec, compressing frames with deflate. Only the
+// last 32KB of dictionaries are used.
+var Deflate Codec = deflateCodec{level: flate.BestCompression}
+
+type deflateCodec struct {
+	level int
+}
+
+func (c deflateCodec) Compress(dst, src, dict []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	fw, err := flate.NewWriterDict(buf, c.level, dict)
+	if err != nil {
+		return dst, err
+	}
+	fw.Write(src)
+	if err := fw.Close(); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c deflateCodec) Decompress(dst, src, dict []byte) ([]byte, error) {
+	fr := flate.NewReaderDict(bytes.NewReader(src), dict)
+	defer fr.Close()
+	buf := bytes.NewBuffer(dst)
+	if _, err := buf.ReadFrom(fr); err != nil {
+		return dst, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DefaultFrameSize is the default uncompressed size of frames.
+const DefaultFrameSize = 16 << 10
+
+// frameHeaderSize is the size of the frame header: the dictionary id and the
+// length of the compressed data, both 32 bits big endian integers.
+const frameHeaderSize = 8
+
+// ErrDictMismatch is returned by the Reader when a frame was compressed with
+// another dictionary.
+var ErrDictMismatch = errors.New("dictcompress: frame compressed with another dictionary")
+
+// DictID returns the identifier of dict stored in the frame headers, so
+// frames are not decompressed with the wrong dictionary.
+func DictID(dict []byte) uint32 {
+	if len(dict) == 0 {
+		return 0
+	}
+	sum := sha256.Sum256(dict)
+	return binary.BigEndian.Uint32(sum[:4])
+}
+
+// Options configures a Writer.
+type Options struct {
+	// Dict is the compression dictionary, usually built with Train. Frames
+	// are compressed without dictionary if empty.
+	Dict []byte
+
+	// Codec is the compression algorithm. Defaults to Deflate.
+	Codec Codec
+
+	// FrameSize is the uncompressed size above which the buffered events are
+	// compressed and written as a frame. Defaults to DefaultFrameSize.
+	FrameSize int
+}
+
+// Writer is a io.Writer compressing events in frames. Events are buffered
+// until the frame is full, Flush or Close is called.
+type Writer struct {
+	out  io.Writer
+	opts Options
+	id   uint32
+
+	mu  sync.Mutex
+	buf []byte
+	z   []byte
+}
+
+// NewWriter returns a Writer writing frames to out.
+func NewWriter(out io.Writer, opts Options) (*Writer, error) {
+	if out == nil {
+		return nil, errors.New("dictcompress: missing output")
+	}
+	if opts.Codec == nil {
+		opts.Codec = Deflate
+	}
+	if opts.FrameSize <= 0 {
+		opts.FrameSize = DefaultFrameSize
+	}
+	return &Writer{out: out, opts: opts, id: DictID(opts.Dict)}, nil
+}
+
+// Write implements io.Writer. An event is never split across frames.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	if len(w.buf) >= w.opts.FrameSize {
+		if err := w.flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush compresses and writes the buffered events as a frame.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Close flushes the buffered events. The output is not closed.
+func (w *Writer) Close() error {
+	return w.Flush()
+}
+
+func (w *Writer) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	z, err := w.opts.Codec.Compress(append(w.z[:0], make([]byte, frameHeaderSize)...), w.buf, w.opts.Dict)
+	if err != nil {
+		return fmt.Errorf("dictcompress: %v", err)
+	}
+	w.z = z
+	binary.BigEndian.PutUint32(z[0:4], w.id)
+	binary.BigEndian.PutUint32(z[4:8], uint32(len(z)-frameHeaderSize))
+	w.buf = w.buf[:0]
+	_, err = w.out.Write(z)
+	return err
+}
+
+// Reader decompresses the frames written by a Writer.
+type Reader struct {
+	r     *bufio.Reader
+	dict  []byte
+	id    uint32
+	codec Codec
+	buf   []byte
+	z     []byte
+	err   error
+}
+
+// NewReader returns a Reader decompressing the frames read from r with dict
+// and codec, Deflate if nil.
+func NewReader(r io.Reader, dict []byte, codec Codec) *Reader {
+	if codec == nil {
+		codec = Deflate
+	}
+	return &Reader{r: bufio.NewReader(r), dict: dict, id: DictID(dict), codec: codec}
+}
+
+// Read implements io.Reader, returning the decompressed events.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	n = copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next decompresses the next frame in r.buf.
+func (r *Reader) next() error {
+	var hdr [frameHeaderSize]byte
+	if _, err := io.ReadFull(r.r, hdr[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("dictcompress: truncated frame header")
+		}
+		return err
+	}
+	if binary.BigEndian.Uint32(hdr[0:4]) != r.id {
+		return ErrDictMismatch
+	}
+	size := binary.BigEndian.Uint32(hdr[4:8])
+	if uint32(cap(r.z)) < size {
+		r.z = make([]byte, size)
+	}
+	r.z = r.z[:size]
+	if _, err := io.ReadFull(r.r, r.z); err != nil {
+		return fmt.Errorf("dictcompress: truncated frame: %v", err)
+	}
+	buf, err := r.codec.Decompress(r.buf[:0], r.z, r.dict)
+	if err != nil {
+		return fmt.Errorf("dictcompress: %v", err)
+	}
+	r.buf = buf
+	return nil
+}
+
+// DefaultDictSize is the default size of the dictionaries built by Train,
+// the window size of deflate.
+const DefaultDictSize = 32 << 10
+
+const (
+	gramSize    = 8  // size of the substrings counted by Train
+	segmentSize = 32 // size of the segments selected by Train
+)
+
+// Train builds a raw content dictionary of at most size bytes, or
+// DefaultDictSize if size is not positive, from sample events, like the
+// output of a logger on a representative workload. Each sample should be a
+// single event, in the encoding the dictionary will be used with.
+//
+// The dictionary is made of the segments of the samples holding the
+// substrings common to most samples, the most common last as compressors
+// encode closer matches more compactly.
+func Train(samples [][]byte, size int) []byte {
+	if size <= 0 {
+		size = DefaultDictSize
+	}
+	// Count in how many samples each substring appears.
+	freq := map[string]int{}
+	seen := map[string]bool{}
+	for _, s := range samples {
+		for k := range seen {
+			delete(seen, k)
+		}
+		for i := 0; i+gramSize <= len(s); i++ {
+			g := string(s[i : i+gramSize])
+			if !seen[g] {
+				seen[g] = true
+				freq[g]++
+			}
+		}
+	}
+
+	// Score the segments of the samples by the frequency of their substrings.
+	type segment struct {
+		data  []byte
+		score int
+	}
+	var segments []segment
+	for _, s := range samples {
+		for i := 0; i < len(s); i += segmentSize / 2 {
+			end := i + segmentSize
+			if end > len(s) {
+				end = len(s)
+			}
+			if end-i < gramSize {
+				break
+			}
+			score := 0
+			for j := i; j+gramSize <= end; j++ {
+				if f := freq[string(s[j:j+gramSize])]; f > 1 {
+					score += f
+				}
+			}
+			if score > 0 {
+				segments = append(segments, segment{s[i:end], score})
+			}
+		}
+	}
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].score > segments[j].score
+	})
+
+	// Select the best segments not already covered by the selected ones.
+	covered := map[string]bool{}
+	var selected [][]byte
+	total := 0
+	for _, seg := range segments {
+		if total+len(seg.data) > size {
+			continue
+		}
+		grams, fresh := 0, 0
+		for j := 0; j+gramSize <= len(seg.data); j++ {
+			grams++
+			if !covered[string(seg.data[j:j+gramSize])] {
+				fresh++
+			}
+		}
+		if fresh*2 <= grams {
+			continue
+		}
+		for j := 0; j+gramSize <= len(seg.data); j++ {
+			covered[string(seg.data[j:j+gramSize])] = true
+		}
+		selected = append(selected, seg.data)
+		if total += len(seg.data); total+gramSize > size {
+			break
+		}
+	}
+
+	dict := make([]byte, 0, total)
+	for i := len(selected) - 1; i >= 0; i-- {
+		dict = append(dict, selected[i]...)
+	}
+	return dict
+}
+
+// ReadAll decompresses all the frames of r, a helper for tools reading back
+// the events.
+func ReadAll(r io.Reader, dict []byte, codec Codec) ([]byte, error) {
+	return ioutil.ReadAll(NewReader(r, dict, codec))
+}
@@ -0,0 +1,90 @@
+package dictcompress
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/treavorj/zerolog"
+)
+
+// events returns n sample events, one per element.
+func events(n int) [][]byte {
+	var out [][]byte
+	buf := &bytes.Buffer{}
+	log := zerolog.New(buf)
+	for i := 0; i < n; i++ {
+		buf.Reset()
+		log.Info().
+			Str("service", "checkout").
+			Str("method", []string{"GET", "POST", "PUT"}[i%3]).
+			Str("path", fmt.Sprintf("/api/v1/orders/%d", i*7919%10007)).
+			Int("status", []int{200, 201, 404}[i%3]).
+			Int("duration_ms", i*31%997).
+			Msg("request handled")
+		out = append(out, append([]byte(nil), buf.Bytes()...))
+	}
+	return out
+}
+
+func compressed(t *testing.T, evts [][]byte, dict []byte) int {
+	t.Helper()
+	out := &bytes.Buffer{}
+	w, err := NewWriter(out, Options{Dict: dict, FrameSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range evts {
+		w.Write(e)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Len()
+}
+
+func TestTrain(t *testing.T) {
+	dict := Train(events(500), 4096)
+	if len(dict) == 0 || len(dict) > 4096 {
+		t.Fatalf("invalid dictionary size %d", len(dict))
+	}
+	evts := events(2000)[1000:]
+	with, without := compressed(t, evts, dict), compressed(t, evts, nil)
+	if with >= without*3/4 {
+		t.Errorf("dictionary compressed size %d, want well below %d", with, without)
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	evts := events(300)
+	dict := Train(evts[:100], 0)
+	out := &bytes.Buffer{}
+	w, err := NewWriter(out, Options{Dict: dict, FrameSize: 512})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want []byte
+	for _, e := range evts {
+		want = append(want, e...)
+		if _, err := w.Write(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadAll(bytes.NewReader(out.Bytes()), dict, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("decompressed events differ: got %d bytes, want %d", len(got), len(want))
+	}
+
+	if _, err := ReadAll(bytes.NewReader(out.Bytes()), dict[1:], nil); err != ErrDictMismatch {
+		t.Errorf("ReadAll with another dictionary: got %v, want %v", err, ErrDictMismatch)
+	}
+	if _, err := ReadAll(bytes.NewReader(out.Bytes()[:out.Len()-3]), dict, nil); err == nil {
+		t.Error("ReadAll of a truncated frame: expected an error")
+	}
+}
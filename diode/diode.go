@@ -8,6 +8,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/treavorj/zerolog"
 	"github.com/treavorj/zerolog/diode/internal/diodes"
 )
 
@@ -31,6 +32,23 @@ type Writer struct {
 	d    diodeFetcher
 	c    context.CancelFunc
 	done chan struct{}
+
+	pd     *diodes.ManyToOne // priority lane, nil if disabled
+	plevel zerolog.Level
+	wake   func()
+}
+
+// lanes is a diode reading its priority lane before its normal one.
+type lanes struct {
+	*diodes.ManyToOne
+	priority *diodes.ManyToOne
+}
+
+func (l lanes) TryNext() (diodes.GenericDataType, bool) {
+	if data, ok := l.priority.TryNext(); ok {
+		return data, true
+	}
+	return l.ManyToOne.TryNext()
 }
 
 // NewWriter creates a writer wrapping w with a many-to-one diode in order to
@@ -49,6 +67,35 @@ type Writer struct {
 //
 // See code.cloudfoundry.org/go-diodes for more info on diode.
 func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Writer {
+	return NewWriterWithDeadLetter(w, size, pollInterval, f, nil)
+}
+
+// NewWriterWithDeadLetter is like NewWriter, but copies of the events dropped
+// by the diode are written to deadLetter, so what is lost when the writer
+// can't keep up can be audited.
+//
+// deadLetter is called from the log producers' go-routines when an unread
+// event is overwritten: it must be thread safe and should not block. If
+// deadLetter is nil, dropped events are discarded.
+func NewWriterWithDeadLetter(w io.Writer, size int, pollInterval time.Duration, f Alerter, deadLetter io.Writer) Writer {
+	return newWriter(w, size, 0, zerolog.Disabled, pollInterval, f, deadLetter)
+}
+
+// NewWriterWithPriority is like NewWriter, but the events at or above
+// priorityLevel go through a separate priority lane of prioritySize events,
+// which is always read before the normal one. Important events then neither
+// wait behind a backlog of less important ones nor get dropped when the
+// normal lane is saturated.
+//
+//	wr := diode.NewWriterWithPriority(w, 1000, 100, zerolog.ErrorLevel, 0, nil)
+//
+// The priority lane is only used by loggers passing the event level, as
+// zerolog does.
+func NewWriterWithPriority(w io.Writer, size, prioritySize int, priorityLevel zerolog.Level, pollInterval time.Duration, f Alerter) Writer {
+	return newWriter(w, size, prioritySize, priorityLevel, pollInterval, f, nil)
+}
+
+func newWriter(w io.Writer, size, prioritySize int, priorityLevel zerolog.Level, pollInterval time.Duration, f Alerter, deadLetter io.Writer) Writer {
 	ctx, cancel := context.WithCancel(context.Background())
 	dw := Writer{
 		w:    w,
@@ -59,13 +106,29 @@ func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Wri
 		f = func(int) {}
 	}
 	d := diodes.NewManyToOne(size, diodes.AlertFunc(f))
+	if deadLetter != nil {
+		d.OnDrop = func(data diodes.GenericDataType) {
+			p := *(*[]byte)(data)
+			deadLetter.Write(p)
+			putBuf(p)
+		}
+	}
+	var lane diodes.Diode = d
+	if prioritySize > 0 {
+		dw.pd = diodes.NewManyToOne(prioritySize, diodes.AlertFunc(f))
+		dw.plevel = priorityLevel
+		lane = lanes{ManyToOne: d, priority: dw.pd}
+	}
 	if pollInterval > 0 {
-		dw.d = diodes.NewPoller(d,
+		dw.d = diodes.NewPoller(lane,
 			diodes.WithPollingInterval(pollInterval),
 			diodes.WithPollingContext(ctx))
+		dw.wake = func() {}
 	} else {
-		dw.d = diodes.NewWaiter(d,
+		waiter := diodes.NewWaiter(lane,
 			diodes.WithWaiterContext(ctx))
+		dw.d = waiter
+		dw.wake = waiter.Wake
 	}
 	go dw.poll()
 	return dw
@@ -79,6 +142,18 @@ func (dw Writer) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// WriteLevel implements zerolog.LevelWriter. Events at or above the priority
+// level go through the priority lane, if any.
+func (dw Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if dw.pd == nil || !level.AtLeast(dw.plevel) || level.AtLeast(zerolog.NoLevel) {
+		return dw.Write(p)
+	}
+	p = append(bufPool.Get().([]byte), p...)
+	dw.pd.Set(diodes.GenericDataType(&p))
+	dw.wake()
+	return len(p), nil
+}
+
 // Close releases the diode poller and call Close on the wrapped writer if
 // io.Closer is implemented.
 func (dw Writer) Close() error {
@@ -99,16 +174,19 @@ func (dw Writer) poll() {
 		}
 		p := *(*[]byte)(d)
 		dw.w.Write(p)
+		putBuf(p)
+	}
+}
 
-		// Proper usage of a sync.Pool requires each entry to have approximately
-		// the same memory cost. To obtain this property when the stored type
-		// contains a variably-sized buffer, we add a hard limit on the maximum buffer
-		// to place back in the pool.
-		//
-		// See https://golang.org/issue/23199
-		const maxSize = 1 << 16 // 64KiB
-		if cap(p) <= maxSize {
-			bufPool.Put(p[:0])
-		}
+func putBuf(p []byte) {
+	// Proper usage of a sync.Pool requires each entry to have approximately
+	// the same memory cost. To obtain this property when the stored type
+	// contains a variably-sized buffer, we add a hard limit on the maximum buffer
+	// to place back in the pool.
+	//
+	// See https://golang.org/issue/23199
+	const maxSize = 1 << 16 // 64KiB
+	if cap(p) <= maxSize {
+		bufPool.Put(p[:0])
 	}
 }
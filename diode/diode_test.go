@@ -7,11 +7,14 @@ import (
 	"log"
 	"os"
 	"os/exec"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/treavorj/zerolog"
 	"github.com/treavorj/zerolog/diode"
+	"github.com/treavorj/zerolog/diode/internal/diodes"
 	"github.com/treavorj/zerolog/internal/cbor"
 )
 
@@ -39,6 +42,99 @@ func TestClose(t *testing.T) {
 	w.Close()
 }
 
+func TestDeadLetter(t *testing.T) {
+	d := diodes.NewManyToOne(2, nil)
+	var dropped []int
+	d.OnDrop = func(data diodes.GenericDataType) {
+		dropped = append(dropped, *(*int)(data))
+	}
+	for i := 0; i < 5; i++ {
+		i := i
+		d.Set(diodes.GenericDataType(&i))
+	}
+	for {
+		if _, ok := d.TryNext(); !ok {
+			break
+		}
+	}
+	if want := []int{0, 1, 2, 3}; !reflect.DeepEqual(dropped, want) {
+		t.Errorf("dropped %v, want %v", dropped, want)
+	}
+}
+
+// blockingWriter records the writes, blocking the first one until release is
+// closed.
+type blockingWriter struct {
+	mu      sync.Mutex
+	writes  []string
+	started chan struct{}
+	release chan struct{}
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	first := len(w.writes) == 0
+	w.writes = append(w.writes, cbor.DecodeIfBinaryToString(p))
+	w.mu.Unlock()
+	if first {
+		close(w.started)
+		<-w.release
+	}
+	return len(p), nil
+}
+
+func TestPriorityLane(t *testing.T) {
+	bw := &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+	var missed int
+	w := diode.NewWriterWithPriority(bw, 4, 4, zerolog.ErrorLevel, 0, func(n int) { missed += n })
+	log := zerolog.New(w)
+	log.Info().Msg("first")
+	<-bw.started
+	for i := 0; i < 20; i++ {
+		log.Info().Int("i", i).Msg("backlog")
+	}
+	log.Error().Msg("important")
+	close(bw.release)
+	w.Close()
+
+	if len(bw.writes) < 3 {
+		t.Fatalf("got %d writes, want at least 3", len(bw.writes))
+	}
+	if want := `{"level":"error","message":"important"}` + "\n"; bw.writes[1] != want {
+		t.Errorf("second write = %q, want %q", bw.writes[1], want)
+	}
+	if missed == 0 {
+		t.Error("expected dropped backlog events")
+	}
+}
+
+func TestPriorityLaneCustomLevel(t *testing.T) {
+	critical, err := zerolog.ParseLevel("critical")
+	if err != nil {
+		if critical, err = zerolog.RegisterLevel("critical", zerolog.ErrorLevel, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	bw := &blockingWriter{started: make(chan struct{}), release: make(chan struct{})}
+	w := diode.NewWriterWithPriority(bw, 4, 4, zerolog.ErrorLevel, 0, func(int) {})
+	log := zerolog.New(w)
+	log.Info().Msg("first")
+	<-bw.started
+	for i := 0; i < 20; i++ {
+		log.Info().Int("i", i).Msg("backlog")
+	}
+	log.WithLevel(critical).Msg("important")
+	close(bw.release)
+	w.Close()
+
+	if len(bw.writes) < 3 {
+		t.Fatalf("got %d writes, want at least 3", len(bw.writes))
+	}
+	if want := `{"level":"critical","message":"important"}` + "\n"; bw.writes[1] != want {
+		t.Errorf("second write = %q, want %q", bw.writes[1], want)
+	}
+}
+
 func TestFatal(t *testing.T) {
 	if os.Getenv("TEST_FATAL") == "1" {
 		w := diode.NewWriter(os.Stderr, 1000, 0, func(missed int) {
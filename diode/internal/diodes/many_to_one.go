@@ -13,6 +13,12 @@ type ManyToOne struct {
 	readIndex  uint64
 	buffer     []unsafe.Pointer
 	alerter    Alerter
+
+	// OnDrop, if set, is called with the data dropped by the diode. It is
+	// invoked on the writer's go-routine when data is overwritten, or on the
+	// reader's go-routine when stale data is skipped. It must be set before
+	// the diode is used.
+	OnDrop func(data GenericDataType)
 }
 
 // NewManyToOne creates a new diode (ring buffer). The ManyToOne diode
@@ -61,6 +67,10 @@ func (d *ManyToOne) Set(data GenericDataType) {
 			continue
 		}
 
+		if old != nil && d.OnDrop != nil {
+			// Readers swap read buckets with nil, so old was never read.
+			d.OnDrop((*bucket)(old).data)
+		}
 		return
 	}
 }
@@ -93,6 +103,9 @@ func (d *ManyToOne) TryNext() (data GenericDataType, ok bool) {
 	//    `| 4 | 5 | 2 | 3 |` r: 7, w: 6
 	//
 	if result.seq < d.readIndex {
+		if d.OnDrop != nil {
+			d.OnDrop(result.data)
+		}
 		return nil, false
 	}
 
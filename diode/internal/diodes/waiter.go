@@ -57,6 +57,12 @@ func (w *Waiter) Set(data GenericDataType) {
 	w.c.Broadcast()
 }
 
+// Wake wakes up the readers, like Set does, for data added to the wrapped
+// diode by other means.
+func (w *Waiter) Wake() {
+	w.c.Broadcast()
+}
+
 // Next returns the next data point on the wrapped diode. If there is not any
 // new data, it will Wait for set to be called or the context to be done.
 // If the context is done, then nil will be returned.
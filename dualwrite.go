@@ -0,0 +1,162 @@
+package zerolog
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"sync/atomic"
+)
+
+// DualWriteOptions configures a DualWriteWriter.
+type DualWriteOptions struct {
+	// Sink, if not nil, receives a WriteFailure error for each failed write
+	// of a destination. Failures are otherwise reported to ErrorHandler.
+	Sink ErrorSink
+
+	// Metrics, if not nil, receives the zerolog_dual_write_events_total
+	// counter, labeled with the destination, "primary" or "secondary", and
+	// the result, "ok" or "failed", of each write.
+	Metrics MetricsBackend
+}
+
+// DualWriteStats are the write counts of a destination of a
+// DualWriteWriter.
+type DualWriteStats struct {
+	Written uint64
+	Failed  uint64
+}
+
+// DualWriteWriter is a LevelWriter writing each event to two destinations,
+// like the old and the new log backends during a migration. See
+// NewDualWriteWriter.
+type DualWriteWriter struct {
+	dests  [2]LevelWriter
+	opts   DualWriteOptions
+	prefix string
+	seq    uint64
+	stats  [2]DualWriteStats // atomic
+}
+
+var dualWriteDestinations = [2]string{"primary", "secondary"}
+
+// NewDualWriteWriter returns a DualWriteWriter writing each event to
+// primary and secondary, with the DualWriteIDFieldName field set to an id
+// unique to the event, so the events received by both destinations can be
+// de-duplicated downstream:
+//
+//	w := zerolog.NewDualWriteWriter(oldBackend, newBackend, zerolog.DualWriteOptions{Sink: sink})
+//	log := zerolog.New(w)
+//	log.Info().Msg("hello")
+//	// {"level":"info","message":"hello","write_id":"5f1c0e7a9b2d4c38-1"}
+//
+// A failing destination doesn't prevent the other from receiving the event:
+// its failures are reported to opts.Sink and counted by Stats, and the write
+// only fails if both destinations failed.
+func NewDualWriteWriter(primary, secondary io.Writer, opts DualWriteOptions) *DualWriteWriter {
+	w := &DualWriteWriter{opts: opts}
+	for i, d := range []io.Writer{primary, secondary} {
+		lw, ok := d.(LevelWriter)
+		if !ok {
+			lw = LevelWriterAdapter{d}
+		}
+		w.dests[i] = lw
+	}
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err == nil {
+		w.prefix = hex.EncodeToString(b[:]) + "-"
+	}
+	return w
+}
+
+// Write implements the io.Writer interface.
+func (w *DualWriteWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The event is written to
+// both destinations with a write id field added.
+func (w *DualWriteWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	id := w.prefix + strconv.FormatUint(atomic.AddUint64(&w.seq, 1), 10)
+	stamped := appendWriteID(p, id)
+	var errs MultiWriteError
+	for i, d := range w.dests {
+		n, err := d.WriteLevel(level, stamped)
+		if err == nil && n != len(stamped) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			atomic.AddUint64(&w.stats[i].Failed, 1)
+			w.count(i, "failed")
+			reportError(w.opts.Sink, nil, WriteFailure, level, fmt.Errorf("%s destination: %w", dualWriteDestinations[i], err))
+			errs = append(errs, err)
+			continue
+		}
+		atomic.AddUint64(&w.stats[i].Written, 1)
+		w.count(i, "ok")
+	}
+	if len(errs) == len(w.dests) {
+		return 0, errs
+	}
+	return len(p), nil
+}
+
+// count updates the metrics of the destination i, if any.
+func (w *DualWriteWriter) count(i int, result string) {
+	if w.opts.Metrics == nil {
+		return
+	}
+	w.opts.Metrics.AddCounter("zerolog_dual_write_events_total", map[string]string{
+		"destination": dualWriteDestinations[i],
+		"result":      result,
+	}, 1)
+}
+
+// appendWriteID returns a copy of the event p with the write id field added
+// last.
+func appendWriteID(p []byte, id string) []byte {
+	end := enc.AppendLineBreak(enc.AppendEndMarker(nil))
+	if !bytes.HasSuffix(p, end) {
+		return p
+	}
+	dst := make([]byte, 0, len(p)+len(DualWriteIDFieldName)+len(id)+8)
+	dst = append(dst, p[:len(p)-len(end)]...)
+	dst = enc.AppendString(enc.AppendKey(dst, DualWriteIDFieldName), id)
+	return append(dst, end...)
+}
+
+// Stats returns the write counts of the primary and the secondary
+// destinations.
+func (w *DualWriteWriter) Stats() (primary, secondary DualWriteStats) {
+	load := func(s *DualWriteStats) DualWriteStats {
+		return DualWriteStats{
+			Written: atomic.LoadUint64(&s.Written),
+			Failed:  atomic.LoadUint64(&s.Failed),
+		}
+	}
+	return load(&w.stats[0]), load(&w.stats[1])
+}
+
+// Flush flushes both destinations and returns the first error.
+func (w *DualWriteWriter) Flush() error {
+	err := flush(w.dests[0])
+	if err2 := flush(w.dests[1]); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// Close closes the destinations that are io.Closers and returns the first
+// error.
+func (w *DualWriteWriter) Close() (err error) {
+	for _, d := range w.dests {
+		if closer, ok := d.(io.Closer); ok {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,84 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDualWriteWriter(t *testing.T) {
+	primary, secondary := &bytes.Buffer{}, &bytes.Buffer{}
+	w := NewDualWriteWriter(primary, secondary, DualWriteOptions{})
+	log := New(w)
+	log.Info().Str("foo", "bar").Msg("one")
+	log.Info().Msg("two")
+
+	p := strings.Split(strings.TrimSpace(decodeIfBinaryToString(primary.Bytes())), "\n")
+	s := strings.Split(strings.TrimSpace(decodeIfBinaryToString(secondary.Bytes())), "\n")
+	if len(p) != 2 || len(s) != 2 {
+		t.Fatalf("got %d and %d events, want 2 and 2", len(p), len(s))
+	}
+	ids := map[string]bool{}
+	for i := range p {
+		if p[i] != s[i] {
+			t.Errorf("destinations received different events:\n%s\n%s", p[i], s[i])
+		}
+		var evt map[string]interface{}
+		if err := json.Unmarshal([]byte(p[i]), &evt); err != nil {
+			t.Fatal(err)
+		}
+		id, _ := evt[DualWriteIDFieldName].(string)
+		if id == "" {
+			t.Fatalf("missing write id in %s", p[i])
+		}
+		ids[id] = true
+	}
+	if len(ids) != 2 {
+		t.Errorf("write ids are not unique: %v", ids)
+	}
+	if want := `{"level":"info","foo":"bar","message":"one","write_id":`; !strings.HasPrefix(p[0], want) {
+		t.Errorf("invalid event:\ngot:  %s\nwant: %s...", p[0], want)
+	}
+}
+
+func TestDualWriteWriterFailures(t *testing.T) {
+	secondary := &bytes.Buffer{}
+	var reported []string
+	sink := ErrorSinkFunc(func(err *InternalError) {
+		if err.Kind != WriteFailure {
+			t.Errorf("invalid error kind %v", err.Kind)
+		}
+		reported = append(reported, err.Err.Error())
+	})
+	metrics := &testMetrics{metrics: map[string]float64{}}
+	w := NewDualWriteWriter(errWriter{errors.New("backend down")}, secondary, DualWriteOptions{Sink: sink, Metrics: metrics})
+	log := New(w)
+	log.Info().Msg("one")
+	log.Info().Msg("two")
+
+	if got := strings.Count(decodeIfBinaryToString(secondary.Bytes()), "\n"); got != 2 {
+		t.Errorf("secondary received %d events, want 2", got)
+	}
+	if want := []string{"primary destination: backend down", "primary destination: backend down"}; strings.Join(reported, "|") != strings.Join(want, "|") {
+		t.Errorf("reported %q, want %q", reported, want)
+	}
+	p, s := w.Stats()
+	if p != (DualWriteStats{Failed: 2}) || s != (DualWriteStats{Written: 2}) {
+		t.Errorf("invalid stats: primary %+v, secondary %+v", p, s)
+	}
+	for key, want := range map[string]float64{
+		"zerolog_dual_write_events_total{destination=primary,result=failed}": 2,
+		"zerolog_dual_write_events_total{destination=secondary,result=ok}":   2,
+	} {
+		if got := metrics.metrics[key]; got != want {
+			t.Errorf("%s = %v, want %v", key, got, want)
+		}
+	}
+
+	both := NewDualWriteWriter(errWriter{errors.New("a")}, errWriter{errors.New("b")}, DualWriteOptions{Sink: sink})
+	if _, err := both.Write([]byte("{}\n")); err == nil || err.Error() != "a; b" {
+		t.Errorf("got error %v, want a; b", err)
+	}
+}
@@ -0,0 +1,137 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// ECS is a Transformer making the events follow the Elastic Common Schema,
+// so they can be ingested by Elasticsearch without an ingest pipeline:
+//
+//	log := zerolog.New(os.Stdout).Transform(zerolog.ECS{Version: "8.11.0"})
+//	log.Error().Err(err).Str("http.request.method", "GET").Msg("failed")
+//	// Output: {"log":{"level":"error"},"error":{"message":"timeout"},"http":{"request":{"method":"GET"}},"message":"failed","ecs":{"version":"8.11.0"}}
+//
+// The core fields are renamed: the TimestampFieldName field to @timestamp,
+// LevelFieldName to log.level, ErrorFieldName to error.message and
+// ErrorStackFieldName to error.stack_trace, which is written as a string.
+// The MessageFieldName field is written as message. Then the top level
+// fields with dotted keys are nested into objects. A dotted key conflicting
+// with a field, like "a.b" with "a":1, is left as is.
+//
+// Only the JSON encoding is supported, binary events are left untouched.
+type ECS struct {
+	// Version, if not empty, is added as the ecs.version field.
+	Version string
+}
+
+// ecsNode is a field of a transformed event, either a value or an object of
+// nested fields.
+type ecsNode struct {
+	key      string
+	value    []byte // encoded value, nil for objects
+	children []*ecsNode
+}
+
+// child returns the child object key of n, creating it if needed. It returns
+// nil if n has a value field named key.
+func (n *ecsNode) child(key string) *ecsNode {
+	for _, c := range n.children {
+		if c.key == key {
+			if c.value != nil {
+				return nil
+			}
+			return c
+		}
+	}
+	c := &ecsNode{key: key}
+	n.children = append(n.children, c)
+	return c
+}
+
+// add adds the field path with value to n. It returns false on conflict.
+func (n *ecsNode) add(path []string, value []byte) bool {
+	for _, key := range path[:len(path)-1] {
+		if n = n.child(key); n == nil {
+			return false
+		}
+	}
+	key := path[len(path)-1]
+	for _, c := range n.children {
+		if c.key == key {
+			return false
+		}
+	}
+	n.children = append(n.children, &ecsNode{key: key, value: value})
+	return true
+}
+
+// appendTo appends the fields of n as a JSON object to dst.
+func (n *ecsNode) appendTo(dst []byte) []byte {
+	dst = append(dst, '{')
+	for i, c := range n.children {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, appendJSONString(c.key)...)
+		dst = append(dst, ':')
+		if c.value != nil {
+			dst = append(dst, c.value...)
+		} else {
+			dst = c.appendTo(dst)
+		}
+	}
+	return append(dst, '}')
+}
+
+// Transform implements the Transformer interface.
+func (s ECS) Transform(level Level, p []byte) ([]byte, bool) {
+	if len(p) == 0 || p[0] != '{' {
+		return p, true
+	}
+	renames := map[string]string{
+		TimestampFieldName:  "@timestamp",
+		LevelFieldName:      "log.level",
+		MessageFieldName:    "message",
+		ErrorFieldName:      "error.message",
+		ErrorStackFieldName: "error.stack_trace",
+	}
+	root := &ecsNode{}
+	var flat []*ecsNode // conflicting dotted fields
+	for i := 1; i < len(p) && p[i] == '"'; {
+		keyEnd := skipString(p, i)
+		start := keyEnd + 1 // after the colon
+		end := skipValue(p, start)
+		key := ecsKey(p[i:keyEnd])
+		value := p[start:end]
+		if name, ok := renames[key]; ok && key != "" {
+			key = name
+			if name == "error.stack_trace" && value[0] != '"' {
+				value = appendJSONString(string(value))
+			}
+		}
+		if !root.add(strings.Split(key, "."), value) {
+			flat = append(flat, &ecsNode{key: key, value: value})
+		}
+		if i = end; i < len(p) && p[i] == ',' {
+			i++
+		}
+	}
+	if s.Version != "" {
+		root.add([]string{"ecs", "version"}, appendJSONString(s.Version))
+	}
+	root.children = append(root.children, flat...)
+	out := root.appendTo(make([]byte, 0, len(p)+32))
+	return append(out, p[bytes.LastIndexByte(p, '}')+1:]...), true
+}
+
+// ecsKey decodes the JSON string key.
+func ecsKey(key []byte) string {
+	if bytes.IndexByte(key, '\\') < 0 {
+		return string(key[1 : len(key)-1])
+	}
+	var s string
+	json.Unmarshal(key, &s)
+	return s
+}
@@ -0,0 +1,25 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestECS(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Transform(ECS{Version: "8.11.0"})
+	log.Error().Err(errors.New("timeout")).Str("http.request.method", "GET").Int("http.response.status_code", 504).Msg("failed")
+	log.Info().Time("time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)).Int("a", 1).Int("a.b", 2).Send()
+	log.Log().Interface("stack", []string{"main.go:1"}).Msg("")
+	want := `{"log":{"level":"error"},"error":{"message":"timeout"},"http":{"request":{"method":"GET"},"response":{"status_code":504}},"message":"failed","ecs":{"version":"8.11.0"}}` + "\n" +
+		`{"log":{"level":"info"},"@timestamp":"2024-01-02T03:04:05Z","a":1,"ecs":{"version":"8.11.0"},"a.b":2}` + "\n" +
+		`{"error":{"stack_trace":"[\"main.go:1\"]"},"ecs":{"version":"8.11.0"}}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -20,6 +20,7 @@ type encoder interface {
 	AppendFloat64(dst []byte, val float64, precision int) []byte
 	AppendFloats32(dst []byte, vals []float32, precision int) []byte
 	AppendFloats64(dst []byte, vals []float64, precision int) []byte
+	AppendFloatFmt(dst []byte, val float64, bitSize int, fmt byte, precision int, trimZeros bool) []byte
 	AppendHex(dst, s []byte) []byte
 	AppendIPAddr(dst []byte, ip net.IP) []byte
 	AppendIPPrefix(dst []byte, pfx net.IPNet) []byte
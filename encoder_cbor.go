@@ -6,6 +6,9 @@ package zerolog
 // This file contains bindings to do binary encoding.
 
 import (
+	"bytes"
+	"encoding/json"
+
 	"github.com/treavorj/zerolog/internal/cbor"
 )
 
@@ -22,9 +25,27 @@ func init() {
 	}
 }
 
+// appendStringBytes appends b as a string.
+func appendStringBytes(dst []byte, b []byte) []byte {
+	return enc.AppendStringBytes(dst, b)
+}
+
 func appendJSON(dst []byte, j []byte) []byte {
 	return cbor.AppendEmbeddedJSON(dst, j)
 }
+
+// appendJSONObjectData decodes the JSON object j and appends its fields to
+// dst. j is expected to be a valid JSON object.
+func appendJSONObjectData(dst []byte, j []byte) []byte {
+	var fields map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	if err := d.Decode(&fields); err != nil {
+		return dst
+	}
+	return appendFields(dst, fields, false, nil)
+}
+
 func appendCBOR(dst []byte, c []byte) []byte {
 	return cbor.AppendEmbeddedCBOR(dst, c)
 }
@@ -7,7 +7,9 @@ package zerolog
 // JSON encoded byte stream.
 
 import (
+	"bytes"
 	"encoding/base64"
+
 	"github.com/treavorj/zerolog/internal/json"
 )
 
@@ -27,6 +29,26 @@ func init() {
 func appendJSON(dst []byte, j []byte) []byte {
 	return append(dst, j...)
 }
+
+// appendStringBytes appends b as a string.
+func appendStringBytes(dst []byte, b []byte) []byte {
+	return enc.AppendBytes(dst, b)
+}
+
+// appendJSONObjectData splices the fields of the JSON object j into dst.
+// j is expected to be a valid JSON object.
+func appendJSONObjectData(dst []byte, j []byte) []byte {
+	j = bytes.TrimSpace(j)
+	j = bytes.TrimSpace(j[1 : len(j)-1])
+	if len(j) == 0 {
+		return dst
+	}
+	if dst[len(dst)-1] != '{' {
+		dst = append(dst, ',')
+	}
+	return append(dst, j...)
+}
+
 func appendCBOR(dst []byte, cbor []byte) []byte {
 	dst = append(dst, []byte("\"data:application/cbor;base64,")...)
 	l := len(dst)
@@ -50,3 +72,9 @@ func decodeObjectToStr(in []byte) string {
 func decodeIfBinaryToBytes(in []byte) []byte {
 	return in
 }
+
+// rewriteBinaryFields returns p untouched, as binary events are only
+// produced with the binary_log build tag.
+func rewriteBinaryFields(p []byte, rw fieldRewriter) []byte {
+	return p
+}
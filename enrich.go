@@ -0,0 +1,207 @@
+package zerolog
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// EnrichmentProvider looks up the fields to add to the events from an
+// external source, like a cache or a database. See NewEnrichHook.
+type EnrichmentProvider interface {
+	// Lookup returns the fields to add to the events which key field has the
+	// value key. It must give up when ctx is done.
+	Lookup(ctx context.Context, key string) (map[string]interface{}, error)
+}
+
+// EnrichmentProviderFunc is an adaptor to allow the use of an ordinary
+// function as an EnrichmentProvider.
+type EnrichmentProviderFunc func(ctx context.Context, key string) (map[string]interface{}, error)
+
+// Lookup implements the EnrichmentProvider interface.
+func (f EnrichmentProviderFunc) Lookup(ctx context.Context, key string) (map[string]interface{}, error) {
+	return f(ctx, key)
+}
+
+// EnrichOptions configures the hook returned by NewEnrichHook.
+type EnrichOptions struct {
+	// KeyField is the event field which value is looked up, like "user_id".
+	KeyField string
+
+	// Provider looks up the fields to add.
+	Provider EnrichmentProvider
+
+	// Budget is the maximum time an event waits for a lookup not in the
+	// cache. If zero, events never wait: they are only enriched from the
+	// cache, which is filled in the background.
+	Budget time.Duration
+
+	// LookupTimeout is the maximum duration of a lookup. Defaults to one
+	// second.
+	LookupTimeout time.Duration
+
+	// TTL is how long the looked up fields, and the lookup failures, are
+	// cached. Defaults to one minute.
+	TTL time.Duration
+
+	// MaxEntries is the maximum number of cached keys. Defaults to 10000.
+	MaxEntries int
+}
+
+type enrichEntry struct {
+	fields  map[string]interface{}
+	expires time.Time
+}
+
+// enrichLookup is a lookup in progress, shared by the events of its key.
+type enrichLookup struct {
+	done   chan struct{}
+	fields map[string]interface{}
+}
+
+type enrichHook struct {
+	opts EnrichOptions
+	key  []byte // JSON encoded KeyField
+
+	mu       sync.Mutex
+	cache    map[string]enrichEntry
+	inflight map[string]*enrichLookup
+}
+
+// NewEnrichHook returns a hook adding to the events the fields looked up by
+// opts.Provider from the value of their opts.KeyField field, like the tier of
+// a user from its id:
+//
+//	log := zerolog.New(os.Stdout).Hook(zerolog.NewEnrichHook(zerolog.EnrichOptions{
+//	    KeyField: "user_id",
+//	    Provider: zerolog.EnrichmentProviderFunc(func(ctx context.Context, id string) (map[string]interface{}, error) {
+//	        return map[string]interface{}{"user_tier": tiers.Get(id)}, nil
+//	    }),
+//	    Budget: time.Millisecond,
+//	}))
+//	log.Info().Str("user_id", "42").Msg("checkout")
+//	// {"level":"info","user_id":"42","user_tier":"gold","message":"checkout"}
+//
+// The looked up fields are cached. A key is looked up once at a time, in the
+// background, and the events wait at most opts.Budget for it: lookups can't
+// stall logging. The events missing the budget, and those for which the
+// lookup failed, are logged without the fields. The key field must be added
+// to the event or the logger context before the hook runs.
+func NewEnrichHook(opts EnrichOptions) Hook {
+	if opts.LookupTimeout <= 0 {
+		opts.LookupTimeout = time.Second
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Minute
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 10000
+	}
+	return &enrichHook{
+		opts:     opts,
+		key:      appendJSONString(opts.KeyField),
+		cache:    map[string]enrichEntry{},
+		inflight: map[string]*enrichLookup{},
+	}
+}
+
+// Run implements the Hook interface.
+func (h *enrichHook) Run(e *Event, level Level, msg string) {
+	key, ok := h.keyValue(e)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	h.mu.Lock()
+	if entry, ok := h.cache[key]; ok && now.Before(entry.expires) {
+		h.mu.Unlock()
+		if len(entry.fields) > 0 {
+			e.Fields(entry.fields)
+		}
+		return
+	}
+	l := h.inflight[key]
+	if l == nil {
+		l = &enrichLookup{done: make(chan struct{})}
+		h.inflight[key] = l
+		go h.lookup(key, l)
+	}
+	h.mu.Unlock()
+	if h.opts.Budget <= 0 {
+		return
+	}
+	timer := time.NewTimer(h.opts.Budget)
+	defer timer.Stop()
+	select {
+	case <-l.done:
+		if len(l.fields) > 0 {
+			e.Fields(l.fields)
+		}
+	case <-timer.C:
+	}
+}
+
+// keyValue returns the value of the key field of e, unquoted if it is a
+// string.
+func (h *enrichHook) keyValue(e *Event) (string, bool) {
+	var raw []byte
+	if len(e.buf) > 0 && e.buf[0] == '{' {
+		raw = lastValue(e.buf, h.key)
+	} else if fields, err := DecodeEvent(enc.AppendEndMarker(append([]byte(nil), e.buf...))); err == nil {
+		// Binary encoding.
+		if v, ok := fields[h.opts.KeyField]; ok {
+			raw, _ = json.Marshal(v)
+		}
+	}
+	if len(raw) == 0 {
+		return "", false
+	}
+	if raw[0] == '"' {
+		var s string
+		if json.Unmarshal(raw, &s) != nil {
+			return "", false
+		}
+		return s, true
+	}
+	return string(raw), true
+}
+
+// lookup looks up key with the provider and caches the result.
+func (h *enrichHook) lookup(key string, l *enrichLookup) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.opts.LookupTimeout)
+	fields, err := h.opts.Provider.Lookup(ctx, key)
+	cancel()
+	if err != nil {
+		fields = nil
+	}
+	l.fields = fields
+	h.mu.Lock()
+	delete(h.inflight, key)
+	if len(h.cache) >= h.opts.MaxEntries {
+		h.evict()
+	}
+	h.cache[key] = enrichEntry{fields: fields, expires: time.Now().Add(h.opts.TTL)}
+	h.mu.Unlock()
+	close(l.done)
+}
+
+// evict removes the expired entries of the cache, or an arbitrary half of
+// them if none is expired. h.mu must be held.
+func (h *enrichHook) evict() {
+	now := time.Now()
+	for key, entry := range h.cache {
+		if !now.Before(entry.expires) {
+			delete(h.cache, key)
+		}
+	}
+	if len(h.cache) < h.opts.MaxEntries {
+		return
+	}
+	for key := range h.cache {
+		if len(h.cache) < h.opts.MaxEntries/2 {
+			break
+		}
+		delete(h.cache, key)
+	}
+}
@@ -0,0 +1,89 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEnrichHook(t *testing.T) {
+	var lookups int32
+	provider := EnrichmentProviderFunc(func(ctx context.Context, key string) (map[string]interface{}, error) {
+		atomic.AddInt32(&lookups, 1)
+		switch key {
+		case "42":
+			return map[string]interface{}{"user_tier": "gold"}, nil
+		case "slow":
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return nil, errors.New("unknown user")
+	})
+	out := &bytes.Buffer{}
+	log := New(out).Hook(NewEnrichHook(EnrichOptions{
+		KeyField:      "user_id",
+		Provider:      provider,
+		Budget:        time.Second,
+		LookupTimeout: 20 * time.Millisecond,
+	}))
+	log.Info().Str("user_id", "42").Msg("first")
+	log.Info().Str("user_id", "42").Msg("cached")
+	log.Info().Str("user_id", "0").Msg("failed")
+	log.Info().Str("user_id", "0").Msg("failure cached")
+	log.Info().Str("user_id", "slow").Msg("timed out")
+	log.Info().Msg("no key")
+	ctxLog := log.With().Str("user_id", "42").Logger()
+	ctxLog.Info().Msg("context")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","user_id":"42","user_tier":"gold","message":"first"}` + "\n" +
+		`{"level":"info","user_id":"42","user_tier":"gold","message":"cached"}` + "\n" +
+		`{"level":"info","user_id":"0","message":"failed"}` + "\n" +
+		`{"level":"info","user_id":"0","message":"failure cached"}` + "\n" +
+		`{"level":"info","user_id":"slow","message":"timed out"}` + "\n" +
+		`{"level":"info","message":"no key"}` + "\n" +
+		`{"level":"info","user_id":"42","user_tier":"gold","message":"context"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if n := atomic.LoadInt32(&lookups); n != 3 {
+		t.Errorf("got %d lookups, want 3", n)
+	}
+}
+
+func TestEnrichHookBudget(t *testing.T) {
+	release := make(chan struct{})
+	provider := EnrichmentProviderFunc(func(ctx context.Context, key string) (map[string]interface{}, error) {
+		<-release
+		return map[string]interface{}{"tier": "gold"}, nil
+	})
+	hook := NewEnrichHook(EnrichOptions{KeyField: "id", Provider: provider})
+	out := &bytes.Buffer{}
+	log := New(out).Hook(hook)
+	start := time.Now()
+	log.Info().Int("id", 7).Msg("not waiting")
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("event waited %v for the lookup", d)
+	}
+	close(release)
+	h := hook.(*enrichHook)
+	for i := 0; i < 100; i++ {
+		h.mu.Lock()
+		_, cached := h.cache["7"]
+		h.mu.Unlock()
+		if cached {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	log.Info().Int("id", 7).Msg("cached")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","id":7,"message":"not waiting"}` + "\n" +
+		`{"level":"info","id":7,"tier":"gold","message":"cached"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
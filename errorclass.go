@@ -0,0 +1,58 @@
+package zerolog
+
+// ErrorClass is the classification of an error returned by ErrorClassifier.
+type ErrorClass struct {
+	// Kind is the kind of error, like "timeout" or "not_found", added as the
+	// ErrorKindFieldName field if not empty.
+	Kind string
+
+	// Retryable tells if the failed operation can be retried. It is added as
+	// the ErrorRetryableFieldName field.
+	Retryable bool
+
+	// HTTPStatus is the HTTP status code matching the error, added as the
+	// ErrorHTTPStatusFieldName field if not zero.
+	HTTPStatus int
+
+	// GRPCCode is the name of the gRPC status code matching the error, like
+	// "Unavailable", added as the ErrorGRPCCodeFieldName field if not empty.
+	GRPCCode string
+}
+
+// ErrorClassifier, if set, is called by Err with non-nil errors to derive
+// standard classification fields, centralizing the error taxonomy of an
+// application instead of repeating it in every handler. It returns false for
+// the errors it doesn't classify. It must be thread safe.
+//
+//	zerolog.ErrorClassifier = func(err error) (zerolog.ErrorClass, bool) {
+//	    switch {
+//	    case errors.Is(err, context.DeadlineExceeded):
+//	        return zerolog.ErrorClass{Kind: "timeout", Retryable: true, HTTPStatus: 504, GRPCCode: "DeadlineExceeded"}, true
+//	    case errors.Is(err, sql.ErrNoRows):
+//	        return zerolog.ErrorClass{Kind: "not_found", HTTPStatus: 404, GRPCCode: "NotFound"}, true
+//	    }
+//	    return zerolog.ErrorClass{}, false
+//	}
+var ErrorClassifier func(err error) (ErrorClass, bool)
+
+// appendErrorClass appends the classification fields of err to dst.
+func appendErrorClass(dst []byte, err error) []byte {
+	if ErrorClassifier == nil || err == nil || isNilValue(err) {
+		return dst
+	}
+	c, ok := ErrorClassifier(err)
+	if !ok {
+		return dst
+	}
+	if c.Kind != "" {
+		dst = enc.AppendString(enc.AppendKey(dst, ErrorKindFieldName), c.Kind)
+	}
+	dst = enc.AppendBool(enc.AppendKey(dst, ErrorRetryableFieldName), c.Retryable)
+	if c.HTTPStatus != 0 {
+		dst = enc.AppendInt(enc.AppendKey(dst, ErrorHTTPStatusFieldName), c.HTTPStatus)
+	}
+	if c.GRPCCode != "" {
+		dst = enc.AppendString(enc.AppendKey(dst, ErrorGRPCCodeFieldName), c.GRPCCode)
+	}
+	return dst
+}
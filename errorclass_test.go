@@ -0,0 +1,45 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestErrorClassifier(t *testing.T) {
+	defer func(c func(error) (ErrorClass, bool)) { ErrorClassifier = c }(ErrorClassifier)
+	ErrorClassifier = func(err error) (ErrorClass, bool) {
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrorClass{Kind: "timeout", Retryable: true, HTTPStatus: 504, GRPCCode: "DeadlineExceeded"}, true
+		case err.Error() == "bad input":
+			return ErrorClass{Kind: "invalid"}, true
+		}
+		return ErrorClass{}, false
+	}
+
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{context.DeadlineExceeded, `{"error_kind":"timeout","retryable":true,"http_status":504,"grpc_code":"DeadlineExceeded","error":"context deadline exceeded"}` + "\n"},
+		{errors.New("bad input"), `{"error_kind":"invalid","retryable":false,"error":"bad input"}` + "\n"},
+		{errors.New("other"), `{"error":"other"}` + "\n"},
+		{nil, `{}` + "\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.Log().Err(tt.err).Send()
+		if got := decodeIfBinaryToString(out.Bytes()); got != tt.want {
+			t.Errorf("Event.Err(%v): invalid log output:\ngot:  %v\nwant: %v", tt.err, got, tt.want)
+		}
+		out.Reset()
+		log = log.With().Err(tt.err).Logger()
+		log.Log().Send()
+		if got := decodeIfBinaryToString(out.Bytes()); got != tt.want {
+			t.Errorf("Context.Err(%v): invalid log output:\ngot:  %v\nwant: %v", tt.err, got, tt.want)
+		}
+	}
+}
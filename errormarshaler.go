@@ -0,0 +1,45 @@
+package zerolog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// errorMarshalers holds the []func(error) (interface{}, bool) registered with
+// RegisterErrorMarshalerFunc.
+var (
+	errorMarshalers   atomic.Value
+	errorMarshalersMu sync.Mutex
+)
+
+// RegisterErrorMarshalerFunc registers fn to serialize the errors passed to
+// Err, AnErr and Errs. fn returns false for the errors it doesn't handle.
+// Registered functions are tried in registration order and ErrorMarshalFunc
+// is used when none handles an error.
+//
+// The returned value is serialized like the values returned by
+// ErrorMarshalFunc: a LogObjectMarshaler or any value supported by Interface
+// (like a map) produces a structured object.
+//
+// See RegisterErrorMarshaler to register a function for an error type.
+func RegisterErrorMarshalerFunc(fn func(err error) (interface{}, bool)) {
+	errorMarshalersMu.Lock()
+	defer errorMarshalersMu.Unlock()
+	fns, _ := errorMarshalers.Load().([]func(error) (interface{}, bool))
+	fns = append(fns[:len(fns):len(fns)], fn)
+	errorMarshalers.Store(fns)
+}
+
+// marshalError serializes err using the registered error marshalers or the
+// ErrorMarshalFunc of s.
+func marshalError(err error, s *Settings) interface{} {
+	if err != nil {
+		fns, _ := errorMarshalers.Load().([]func(error) (interface{}, bool))
+		for _, fn := range fns {
+			if v, ok := fn(err); ok {
+				return v
+			}
+		}
+	}
+	return s.errorMarshalFunc()(err)
+}
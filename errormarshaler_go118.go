@@ -0,0 +1,25 @@
+//go:build go1.18
+// +build go1.18
+
+package zerolog
+
+import "errors"
+
+// RegisterErrorMarshaler registers fn to serialize the errors of type T
+// passed to Err, AnErr and Errs, like *pq.Error or validation errors. Wrapped
+// errors are matched using errors.As.
+//
+//	zerolog.RegisterErrorMarshaler(func(err *pq.Error) interface{} {
+//	    return map[string]interface{}{"code": err.Code, "message": err.Message, "table": err.Table}
+//	})
+//
+// See RegisterErrorMarshalerFunc for the precedence rules.
+func RegisterErrorMarshaler[T error](fn func(err T) interface{}) {
+	RegisterErrorMarshalerFunc(func(err error) (interface{}, bool) {
+		var target T
+		if errors.As(err, &target) {
+			return fn(target), true
+		}
+		return nil, false
+	})
+}
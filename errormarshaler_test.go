@@ -0,0 +1,43 @@
+//go:build go1.18 && !binary_log
+// +build go1.18,!binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type validationError struct {
+	Field string
+}
+
+func (e *validationError) Error() string {
+	return "invalid " + e.Field
+}
+
+func TestRegisterErrorMarshaler(t *testing.T) {
+	defer errorMarshalers.Store([]func(error) (interface{}, bool)(nil))
+	RegisterErrorMarshaler(func(err *validationError) interface{} {
+		return map[string]string{"field": err.Field}
+	})
+
+	verr := &validationError{Field: "email"}
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().Err(verr).Msg("")
+	log.Log().AnErr("cause", fmt.Errorf("wrapped: %w", verr)).Msg("")
+	log.Log().Errs("errors", []error{verr, errors.New("plain")}).Msg("")
+	ctxLog := log.With().Err(verr).Logger()
+	ctxLog.Log().Msg("")
+
+	want := `{"error":{"field":"email"}}` + "\n" +
+		`{"cause":{"field":"email"}}` + "\n" +
+		`{"errors":[{"field":"email"},"plain"]}` + "\n" +
+		`{"error":{"field":"email"}}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
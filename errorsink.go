@@ -0,0 +1,131 @@
+package zerolog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// InternalErrorKind classifies the errors of the logging subsystem itself.
+type InternalErrorKind uint8
+
+const (
+	// WriteFailure is reported when the writer fails to write, or to
+	// acknowledge, an event.
+	WriteFailure InternalErrorKind = iota + 1
+
+	// MarshalPanic is reported when a LogObjectMarshaler, or the marshaling
+	// of an Interface field, panics. The field value is replaced by the
+	// panic message.
+	MarshalPanic
+
+	// Truncation is reported when a field value is truncated, like by
+	// FieldOverflow.
+	Truncation
+
+	// Drop is reported when an event, or one of its fields, is dropped, like
+	// the invalid events of a strict logger or the overrides of sealed
+	// fields.
+	Drop
+)
+
+// String returns the name of the kind.
+func (k InternalErrorKind) String() string {
+	switch k {
+	case WriteFailure:
+		return "write_failure"
+	case MarshalPanic:
+		return "marshal_panic"
+	case Truncation:
+		return "truncation"
+	case Drop:
+		return "drop"
+	}
+	return "unknown"
+}
+
+// InternalError is an error of the logging subsystem, as received by an
+// ErrorSink.
+type InternalError struct {
+	// Kind classifies the error.
+	Kind InternalErrorKind
+
+	// Level is the level of the event concerned.
+	Level Level
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *InternalError) Error() string {
+	return e.Kind.String() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *InternalError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorSink receives the internal errors of a logger, so the health of the
+// logging subsystem can be exposed, for instance as metrics:
+//
+//	log := zerolog.New(w).ErrorSink(zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+//	    loggingErrors.WithLabelValues(err.Kind.String()).Inc()
+//	}))
+//
+// HandleError must be thread safe and non-blocking.
+type ErrorSink interface {
+	HandleError(err *InternalError)
+}
+
+// ErrorSinkFunc is an adapter to use a function as an ErrorSink.
+type ErrorSinkFunc func(err *InternalError)
+
+// HandleError calls f(err).
+func (f ErrorSinkFunc) HandleError(err *InternalError) {
+	f(err)
+}
+
+// ErrorSink returns a logger reporting its internal errors to s instead of
+// ErrorHandler. With an error sink, the panics of object marshalers are also
+// recovered and reported as MarshalPanic errors instead of crashing the
+// program.
+func (l Logger) ErrorSink(s ErrorSink) Logger {
+	l.sink = s
+	return l
+}
+
+// ReportError reports err of the given kind to sink or, if nil, to
+// ErrorHandler, falling back to stderr when neither is set. It lets the
+// writers of other packages report their failures like the ones of this
+// package.
+func ReportError(sink ErrorSink, kind InternalErrorKind, level Level, err error) {
+	reportError(sink, nil, kind, level, err)
+}
+
+// reportError reports err of the given kind to sink or, if nil, to the
+// ErrorHandler of s.
+func reportError(sink ErrorSink, s *Settings, kind InternalErrorKind, level Level, err error) {
+	if sink != nil {
+		sink.HandleError(&InternalError{Kind: kind, Level: level, Err: err})
+	} else if h := s.errorHandler(); h != nil {
+		h(err)
+	} else if kind == WriteFailure {
+		fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
+	} else {
+		fmt.Fprintf(os.Stderr, "zerolog: %v\n", err)
+	}
+}
+
+// recoverMarshal, deferred while marshaling the value starting at start in
+// e.buf, replaces the value by the panic message and reports it.
+func (e *Event) recoverMarshal(start int) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	msg := fmt.Sprintf("marshal panic: %v", r)
+	e.buf = enc.AppendString(e.buf[:start], msg)
+	reportError(e.sink, e.settings, MarshalPanic, e.level, errors.New(msg))
+}
@@ -0,0 +1,89 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type panicMarshaler struct{}
+
+func (panicMarshaler) MarshalZerologObject(e *Event) {
+	e.Str("partial", "value")
+	panic("boom")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestErrorSink(t *testing.T) {
+	var errs []*InternalError
+	sink := ErrorSinkFunc(func(err *InternalError) {
+		errs = append(errs, err)
+	})
+
+	out := &bytes.Buffer{}
+	log := New(out).ErrorSink(sink)
+	log.Info().Object("obj", panicMarshaler{}).Str("foo", "bar").Msg("")
+	if got, want := out.String(), `{"level":"info","obj":"marshal panic: boom","foo":"bar"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	strict := log.Strict(NonFiniteString)
+	strict.Warn().RawJSON("raw", []byte("{")).Msg("")
+	if out.Len() != 0 {
+		t.Errorf("invalid event written: %s", out.Bytes())
+	}
+
+	failing := log.Output(failingWriter{})
+	failing.Error().Msg("")
+
+	overflow := New(out).Transform(&FieldOverflow{MaxSize: 8, Sink: sink})
+	overflow.Info().Str("big", "0123456789").Msg("")
+
+	want := []struct {
+		kind  InternalErrorKind
+		level Level
+		msg   string
+	}{
+		{MarshalPanic, InfoLevel, "marshal panic: boom"},
+		{Drop, WarnLevel, "invalid JSON event dropped"},
+		{WriteFailure, ErrorLevel, "disk full"},
+		{Truncation, InfoLevel, `field "big" truncated from 12 bytes`},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+	for i, w := range want {
+		if errs[i].Kind != w.kind || errs[i].Level != w.level || !strings.Contains(errs[i].Err.Error(), w.msg) {
+			t.Errorf("error %d: got %v (%v), want %v %q (%v)", i, errs[i].Kind, errs[i].Level, w.kind, w.msg, w.level)
+		}
+	}
+}
+
+func TestErrorSinkFallback(t *testing.T) {
+	defer func(h func(error)) { ErrorHandler = h }(ErrorHandler)
+	var got error
+	ErrorHandler = func(err error) { got = err }
+	log := New(failingWriter{})
+	log.Info().Msg("")
+	if got == nil || got.Error() != "disk full" {
+		t.Errorf("ErrorHandler got %v, want disk full", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("marshaler panic recovered without error sink")
+		}
+	}()
+	plain := New(&bytes.Buffer{})
+	plain.Info().Object("obj", panicMarshaler{}).Msg("")
+}
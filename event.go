@@ -1,12 +1,14 @@
 package zerolog
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net"
-	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -25,14 +27,40 @@ type Event struct {
 	buf       []byte
 	w         LevelWriter
 	done      func(msg string)
-	ch        []Hook // hooks from context
-	stack     bool   // enable error stack trace
+	ch        []Hook        // hooks from context
+	tr        []Transformer // transformers from logger
+	stack     bool          // enable error stack trace
 	level     Level
 	skipFrame int             // The number of additional frames to skip when printing the caller.
 	ctx       context.Context // Optional Go context for event
-}
+	floatFmt  *FloatFormat    // Optional float formatting from the logger
+	settings  *Settings       // Optional settings from the logger
+	bytesFmt  *BytesFormat    // Optional bytes formatting from the logger
+	ack       *AckPolicy      // Set for critical events only
+	hash      bool            // Add a content hash on Msg
+	hashKeys  []string        // Fields included in the content hash
+	at        time.Time       // Timestamp override set by At
+	atState   uint8           // atUnset, atPending or atWritten
+	dedup     DeDupMode       // Duplicate fields removal from the logger
+	sealed    []sealedField   // Context fields that can't be overridden
+	strict    bool            // Drop the events which are not valid JSON
+	sink      ErrorSink       // Internal errors receiver from the logger
+	sentBy    string          // Finish call site, with the zerolog_debug tag
+	groups    int             // Groups opened by Group and not yet closed
+}
+
+// States of the timestamp override of an event.
+const (
+	atUnset uint8 = iota
+	atPending
+	atWritten
+)
 
 func putEvent(e *Event) {
+	if eventGuard {
+		// Keep sent events out of the pool so their reuse is detected.
+		return
+	}
 	// Proper usage of a sync.Pool requires each entry to have approximately
 	// the same memory cost. To obtain this property when the stored type
 	// contains a variably-sized buffer, we add a hard limit on the maximum buffer
@@ -59,14 +87,35 @@ type LogArrayMarshaler interface {
 }
 
 func newEvent(w LevelWriter, level Level) *Event {
-	e := eventPool.Get().(*Event)
-	e.buf = e.buf[:0]
+	e := getEvent(w, level)
 	e.ch = nil
-	e.buf = enc.AppendBeginMarker(e.buf)
+	e.tr = nil
+	e.floatFmt = nil
+	e.settings = nil
+	e.bytesFmt = nil
+	e.dedup = DeDupModeNone
+	e.sealed = nil
+	e.strict = false
+	e.sink = nil
+	e.groups = 0
+	return e
+}
+
+// getEvent returns an event of the pool with its own state reset. The state
+// inherited from the logger is left as is, for Logger.initEvent to set it
+// without resetting it first.
+func getEvent(w LevelWriter, level Level) *Event {
+	e := eventPool.Get().(*Event)
+	e.buf = enc.AppendBeginMarker(e.buf[:0])
 	e.w = w
 	e.level = level
 	e.stack = false
 	e.skipFrame = 0
+	e.ack = nil
+	e.hash = false
+	e.hashKeys = nil
+	e.atState = atUnset
+	e.sentBy = ""
 	return e
 }
 
@@ -77,8 +126,20 @@ func (e *Event) write() (err error) {
 	if e.level != Disabled {
 		e.buf = enc.AppendEndMarker(e.buf)
 		e.buf = enc.AppendLineBreak(e.buf)
-		if e.w != nil {
-			_, err = e.w.WriteLevel(e.level, e.buf)
+		if e.strict && !validEvent(e.buf) {
+			putEvent(e)
+			return errInvalidEvent
+		}
+		p, ok := stripFields(e.buf), true
+		if len(e.tr) > 0 {
+			p, ok = transform(e.tr, e.level, p)
+		}
+		if ok && e.w != nil {
+			if e.ack != nil {
+				err = writeAck(e.w, e.level, p, *e.ack)
+			} else {
+				_, err = e.w.WriteLevel(e.level, p)
+			}
 		}
 	}
 	putEvent(e)
@@ -96,7 +157,9 @@ func (e *Event) Discard() *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Discard")
 	e.level = Disabled
+	e.guardSent("Discard")
 	return nil
 }
 
@@ -108,7 +171,9 @@ func (e *Event) Msg(msg string) {
 	if e == nil {
 		return
 	}
+	e.guardUse("Msg")
 	e.msg(msg)
+	e.guardSent("Msg")
 }
 
 // Send is equivalent to calling Msg("").
@@ -118,7 +183,9 @@ func (e *Event) Send() {
 	if e == nil {
 		return
 	}
+	e.guardUse("Send")
 	e.msg("")
+	e.guardSent("Send")
 }
 
 // Msgf sends the event with formatted msg added as the message field if not empty.
@@ -129,33 +196,118 @@ func (e *Event) Msgf(format string, v ...interface{}) {
 	if e == nil {
 		return
 	}
-	e.msg(fmt.Sprintf(format, v...))
+	e.guardUse("Msgf")
+	if len(e.ch) > 0 || e.done != nil || e.hash || NormalizeMessageNewlines || eventProbeEnabled() {
+		// Hooks, done callbacks, newlines normalization and the USDT probe
+		// work on the message as a string.
+		e.msg(fmt.Sprintf(format, v...))
+		e.guardSent("Msgf")
+		return
+	}
+	// Format in a pooled buffer and append it directly to the event,
+	// avoiding the allocation of the intermediate string.
+	buf := msgfBufPool.Get().(*bytes.Buffer)
+	fmt.Fprintf(buf, format, v...)
+	e.closeGroups()
+	e.prepareMsg("")
+	if buf.Len() > 0 {
+		e.buf = appendStringBytes(enc.AppendKey(e.buf, e.settings.messageFieldName()), buf.Bytes())
+	}
+	if buf.Cap() <= 1<<16 {
+		buf.Reset()
+		msgfBufPool.Put(buf)
+	}
+	e.send()
+	e.guardSent("Msgf")
+}
+
+var msgfBufPool = sync.Pool{
+	New: func() interface{} {
+		return bytes.NewBuffer(make([]byte, 0, 256))
+	},
 }
 
 func (e *Event) MsgFunc(createMsg func() string) {
 	if e == nil {
 		return
 	}
+	e.guardUse("MsgFunc")
 	e.msg(createMsg())
+	e.guardSent("MsgFunc")
 }
 
 func (e *Event) msg(msg string) {
+	if NormalizeMessageNewlines {
+		msg = strings.TrimRight(normalizeNewlines(msg), "\n")
+	}
+	e.closeGroups()
 	for _, hook := range e.ch {
 		hook.Run(e, e.level, msg)
 	}
+	e.prepareMsg(msg)
 	if msg != "" {
-		e.buf = enc.AppendString(enc.AppendKey(e.buf, MessageFieldName), msg)
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, e.settings.messageFieldName()), msg)
 	}
 	if e.done != nil {
 		defer e.done(msg)
 	}
-	if err := e.write(); err != nil {
-		if ErrorHandler != nil {
-			ErrorHandler(err)
-		} else {
-			fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
-		}
+	fireEventProbe(e.level, msg)
+	e.send()
+}
+
+// normalizeNewlines converts the CRLF and CR line breaks of s to LF.
+func normalizeNewlines(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	return strings.Replace(strings.Replace(s, "\r\n", "\n", -1), "\r", "\n", -1)
+}
+
+// prepareMsg adds the fields computed once hooks have run, right before the
+// message.
+func (e *Event) prepareMsg(msg string) {
+	if TraceContextExtractor != nil && e.ctx != nil {
+		e.appendTraceContext()
+	}
+	if e.atState == atPending {
+		e.Timestamp()
+	}
+	if len(e.sealed) > 0 {
+		e.enforceSealed()
+	}
+	if e.dedup != DeDupModeNone {
+		e.autoDeDup(e.dedup)
+	}
+	if e.hash {
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, EventHashFieldName), contentHash(e.buf, e.hashKeys, msg))
+	}
+}
+
+// send writes the event, reporting errors to the logger's ErrorSink or to
+// ErrorHandler.
+func (e *Event) send() {
+	sink, settings, level := e.sink, e.settings, e.level
+	if err := e.write(); err == errInvalidEvent {
+		reportError(sink, settings, Drop, level, err)
+	} else if err != nil {
+		reportError(sink, settings, WriteFailure, level, err)
+	}
+}
+
+// ContentHash adds, when the event is sent, a deterministic hash of the
+// message and of the values of the given fields as the EventHashFieldName
+// field. As the hash only depends on the selected content, events logged by
+// different processes can be deduplicated by the aggregation layer. Fields
+// must be added before Msg is called, either to the event, the logger context
+// or by hooks; missing fields are hashed as absent.
+func (e *Event) ContentHash(fields ...string) *Event {
+	if e == nil {
+		return e
 	}
+	e.guardUse("ContentHash")
+	e.hash = true
+	e.hashKeys = fields
+	return e
 }
 
 // Fields is a helper function to use a map or slice to set fields using type assertion.
@@ -165,7 +317,8 @@ func (e *Event) Fields(fields interface{}) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = appendFields(e.buf, fields, e.stack)
+	e.guardUse("Fields")
+	e.buf = appendFields(e.buf, fields, e.stack, e.floatFmt)
 	return e
 }
 
@@ -175,12 +328,53 @@ func (e *Event) Dict(key string, dict *Event) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Dict")
+	dict.closeGroups()
 	dict.buf = enc.AppendEndMarker(dict.buf)
 	e.buf = append(enc.AppendKey(e.buf, key), dict.buf...)
 	putEvent(dict)
 	return e
 }
 
+// Group nests the fields added next under the field key, until EndGroup is
+// called, without building a Dict first:
+//
+//	log.Info().Str("method", "GET").
+//	    Group("req").Str("path", "/").Int("size", 42).EndGroup().
+//	    Msg("served")
+//	// {"level":"info","method":"GET","req":{"path":"/","size":42},"message":"served"}
+//
+// Groups can be nested. Those left open are closed when the event is sent,
+// before the hooks run and the message is added.
+func (e *Event) Group(key string) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Group")
+	e.buf = enc.AppendBeginMarker(enc.AppendKey(e.buf, key))
+	e.groups++
+	return e
+}
+
+// EndGroup closes the innermost group opened by Group, including the groups
+// of the logger context. It does nothing if no group is open.
+func (e *Event) EndGroup() *Event {
+	if e == nil || e.groups == 0 {
+		return e
+	}
+	e.guardUse("EndGroup")
+	e.buf = enc.AppendEndMarker(e.buf)
+	e.groups--
+	return e
+}
+
+// closeGroups closes the groups left open.
+func (e *Event) closeGroups() {
+	for ; e.groups > 0; e.groups-- {
+		e.buf = enc.AppendEndMarker(e.buf)
+	}
+}
+
 // Dict creates an Event to be used with the *Event.Dict method.
 // Call usual field methods like Str, Int etc to add fields to this
 // event and give it as argument the *Event.Dict method.
@@ -195,12 +389,14 @@ func (e *Event) Array(key string, arr LogArrayMarshaler) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Array")
 	e.buf = enc.AppendKey(e.buf, key)
 	var a *Array
 	if aa, ok := arr.(*Array); ok {
 		a = aa
 	} else {
 		a = Arr()
+		a.floatFmt = e.floatFmt
 		arr.MarshalZerologArray(a)
 	}
 	e.buf = a.write(e.buf)
@@ -208,6 +404,9 @@ func (e *Event) Array(key string, arr LogArrayMarshaler) *Event {
 }
 
 func (e *Event) appendObject(obj LogObjectMarshaler) {
+	if e.sink != nil {
+		defer e.recoverMarshal(len(e.buf))
+	}
 	e.buf = enc.AppendBeginMarker(e.buf)
 	obj.MarshalZerologObject(e)
 	e.buf = enc.AppendEndMarker(e.buf)
@@ -218,6 +417,7 @@ func (e *Event) Object(key string, obj LogObjectMarshaler) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Object")
 	e.buf = enc.AppendKey(e.buf, key)
 	if obj == nil {
 		e.buf = enc.AppendNil(e.buf)
@@ -232,6 +432,7 @@ func (e *Event) Object(key string, obj LogObjectMarshaler) *Event {
 // Func allows an anonymous func to run only if the event is enabled.
 func (e *Event) Func(f func(e *Event)) *Event {
 	if e != nil && e.Enabled() {
+		e.guardUse("Func")
 		f(e)
 	}
 	return e
@@ -242,6 +443,7 @@ func (e *Event) EmbedObject(obj LogObjectMarshaler) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("EmbedObject")
 	if obj == nil {
 		return e
 	}
@@ -249,12 +451,28 @@ func (e *Event) EmbedObject(obj LogObjectMarshaler) *Event {
 	return e
 }
 
+// EmbedRawJSON splices the fields of the already encoded JSON object obj
+// directly into the *Event context, at the top level.
+//
+// obj is validated first: if it is not a valid JSON object, no field is added.
+func (e *Event) EmbedRawJSON(obj []byte) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("EmbedRawJSON")
+	if isJSONObject(obj) {
+		e.buf = appendJSONObjectData(e.buf, obj)
+	}
+	return e
+}
+
 // Str adds the field key with val as a string to the *Event context.
 func (e *Event) Str(key, val string) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendString(enc.AppendKey(e.buf, key), val)
+	e.guardUse("Str")
+	e.buf = appendInternString(enc.AppendKey(e.buf, key), val)
 	return e
 }
 
@@ -263,16 +481,41 @@ func (e *Event) Strs(key string, vals []string) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Strs")
 	e.buf = enc.AppendStrings(enc.AppendKey(e.buf, key), vals)
 	return e
 }
 
+// StrsFunc adds the field key with an array of n strings to the *Event
+// context, the string at index i being returned by fn(i). The array is
+// encoded element by element, without materializing a []string:
+//
+//	log.Debug().StrsFunc("items", len(items), func(i int) string {
+//	    return items[i].Name
+//	}).Msg("cache content")
+func (e *Event) StrsFunc(key string, n int, fn func(i int) string) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("StrsFunc")
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		e.buf = enc.AppendString(e.buf, fn(i))
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
+	return e
+}
+
 // Stringer adds the field key with val.String() (or null if val is nil)
 // to the *Event context.
 func (e *Event) Stringer(key string, val fmt.Stringer) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Stringer")
 	e.buf = enc.AppendStringer(enc.AppendKey(e.buf, key), val)
 	return e
 }
@@ -284,6 +527,7 @@ func (e *Event) Stringers(key string, vals []fmt.Stringer) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Stringers")
 	e.buf = enc.AppendStringers(enc.AppendKey(e.buf, key), vals)
 	return e
 }
@@ -296,7 +540,8 @@ func (e *Event) Bytes(key string, val []byte) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendBytes(enc.AppendKey(e.buf, key), val)
+	e.guardUse("Bytes")
+	e.buf = appendBytes(enc.AppendKey(e.buf, key), val, false, e.bytesFmt)
 	return e
 }
 
@@ -305,7 +550,8 @@ func (e *Event) Hex(key string, val []byte) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendHex(enc.AppendKey(e.buf, key), val)
+	e.guardUse("Hex")
+	e.buf = appendBytes(enc.AppendKey(e.buf, key), val, true, e.bytesFmt)
 	return e
 }
 
@@ -317,6 +563,7 @@ func (e *Event) RawJSON(key string, b []byte) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("RawJSON")
 	e.buf = appendJSON(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -329,6 +576,7 @@ func (e *Event) RawCBOR(key string, b []byte) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("RawCBOR")
 	e.buf = appendCBOR(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -339,7 +587,8 @@ func (e *Event) AnErr(key string, err error) *Event {
 	if e == nil {
 		return e
 	}
-	switch m := ErrorMarshalFunc(err).(type) {
+	e.guardUse("AnErr")
+	switch m := marshalError(err, e.settings).(type) {
 	case nil:
 		return e
 	case LogObjectMarshaler:
@@ -363,9 +612,10 @@ func (e *Event) Errs(key string, errs []error) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Errs")
 	arr := Arr()
 	for _, err := range errs {
-		switch m := ErrorMarshalFunc(err).(type) {
+		switch m := marshalError(err, e.settings).(type) {
 		case LogObjectMarshaler:
 			arr = arr.Object(m)
 		case error:
@@ -388,26 +638,32 @@ func (e *Event) Errs(key string, errs []error) *Event {
 // If Stack() has been called before and zerolog.ErrorStackMarshaler is defined,
 // the err is passed to ErrorStackMarshaler and the result is appended to the
 // zerolog.ErrorStackFieldName.
+//
+// If zerolog.ErrorClassifier is defined, the classification fields it derives
+// from err are added before the error.
 func (e *Event) Err(err error) *Event {
 	if e == nil {
 		return e
 	}
-	if e.stack && ErrorStackMarshaler != nil {
-		switch m := ErrorStackMarshaler(err).(type) {
+	e.guardUse("Err")
+	if stackMarshaler := e.settings.errorStackMarshaler(); e.stack && stackMarshaler != nil {
+		key := e.settings.errorStackFieldName()
+		switch m := stackMarshaler(err).(type) {
 		case nil:
 		case LogObjectMarshaler:
-			e.Object(ErrorStackFieldName, m)
+			e.Object(key, m)
 		case error:
 			if m != nil && !isNilValue(m) {
-				e.Str(ErrorStackFieldName, m.Error())
+				e.Str(key, m.Error())
 			}
 		case string:
-			e.Str(ErrorStackFieldName, m)
+			e.Str(key, m)
 		default:
-			e.Interface(ErrorStackFieldName, m)
+			e.Interface(key, m)
 		}
 	}
-	return e.AnErr(ErrorFieldName, err)
+	e.buf = appendErrorClass(e.buf, err)
+	return e.AnErr(e.settings.errorFieldName(), err)
 }
 
 // Stack enables stack trace printing for the error passed to Err().
@@ -415,6 +671,7 @@ func (e *Event) Err(err error) *Event {
 // ErrorStackMarshaler must be set for this method to do something.
 func (e *Event) Stack() *Event {
 	if e != nil {
+		e.guardUse("Stack")
 		e.stack = true
 	}
 	return e
@@ -424,10 +681,21 @@ func (e *Event) Stack() *Event {
 // in the output message, but is available to hooks and to Func() calls via the
 // GetCtx() accessor. A typical use case is to extract tracing information from
 // the Go Ctx.
+//
+// If ctx carries a level set by WithCtxLevel, the event is discarded when its
+// level is below it. Ctx can't make the event more verbose though: an event
+// below the level of its logger was already filtered out when it was created.
 func (e *Event) Ctx(ctx context.Context) *Event {
-	if e != nil {
-		e.ctx = ctx
+	if e == nil {
+		return e
 	}
+	e.guardUse("Ctx")
+	if ctx != nil {
+		if lvl, ok := CtxLevel(ctx); ok && !e.level.AtLeast(lvl) {
+			return e.Discard()
+		}
+	}
+	e.ctx = ctx
 	return e
 }
 
@@ -447,6 +715,7 @@ func (e *Event) Bool(key string, b bool) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Bool")
 	e.buf = enc.AppendBool(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -456,6 +725,7 @@ func (e *Event) Bools(key string, b []bool) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Bools")
 	e.buf = enc.AppendBools(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -465,7 +735,8 @@ func (e *Event) Int(key string, i int) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendInt(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Int")
+	e.buf = appendInt64(enc.AppendKey(e.buf, key), int64(i))
 	return e
 }
 
@@ -474,7 +745,8 @@ func (e *Event) Ints(key string, i []int) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendInts(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Ints")
+	e.buf = appendInts(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
@@ -483,6 +755,7 @@ func (e *Event) Int8(key string, i int8) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Int8")
 	e.buf = enc.AppendInt8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -492,6 +765,7 @@ func (e *Event) Ints8(key string, i []int8) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Ints8")
 	e.buf = enc.AppendInts8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -501,6 +775,7 @@ func (e *Event) Int16(key string, i int16) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Int16")
 	e.buf = enc.AppendInt16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -510,6 +785,7 @@ func (e *Event) Ints16(key string, i []int16) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Ints16")
 	e.buf = enc.AppendInts16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -519,6 +795,7 @@ func (e *Event) Int32(key string, i int32) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Int32")
 	e.buf = enc.AppendInt32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -528,6 +805,7 @@ func (e *Event) Ints32(key string, i []int32) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Ints32")
 	e.buf = enc.AppendInts32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -537,7 +815,8 @@ func (e *Event) Int64(key string, i int64) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendInt64(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Int64")
+	e.buf = appendInt64(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
@@ -546,7 +825,8 @@ func (e *Event) Ints64(key string, i []int64) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendInts64(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Ints64")
+	e.buf = appendInts64(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
@@ -555,7 +835,8 @@ func (e *Event) Uint(key string, i uint) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendUint(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Uint")
+	e.buf = appendUint64(enc.AppendKey(e.buf, key), uint64(i))
 	return e
 }
 
@@ -564,7 +845,8 @@ func (e *Event) Uints(key string, i []uint) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendUints(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Uints")
+	e.buf = appendUints(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
@@ -573,6 +855,7 @@ func (e *Event) Uint8(key string, i uint8) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Uint8")
 	e.buf = enc.AppendUint8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -582,6 +865,7 @@ func (e *Event) Uints8(key string, i []uint8) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Uints8")
 	e.buf = enc.AppendUints8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -591,6 +875,7 @@ func (e *Event) Uint16(key string, i uint16) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Uint16")
 	e.buf = enc.AppendUint16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -600,6 +885,7 @@ func (e *Event) Uints16(key string, i []uint16) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Uints16")
 	e.buf = enc.AppendUints16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -609,6 +895,7 @@ func (e *Event) Uint32(key string, i uint32) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Uint32")
 	e.buf = enc.AppendUint32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -618,6 +905,7 @@ func (e *Event) Uints32(key string, i []uint32) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Uints32")
 	e.buf = enc.AppendUints32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -627,7 +915,30 @@ func (e *Event) Uint64(key string, i uint64) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendUint64(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Uint64")
+	e.buf = appendUint64(enc.AppendKey(e.buf, key), i)
+	return e
+}
+
+// Int64Str adds the field key with i as a string to the *Event context,
+// regardless of LargeIntAsString.
+func (e *Event) Int64Str(key string, i int64) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Int64Str")
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, key), strconv.FormatInt(i, 10))
+	return e
+}
+
+// Uint64Str adds the field key with i as a string to the *Event context,
+// regardless of LargeIntAsString.
+func (e *Event) Uint64Str(key string, i uint64) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Uint64Str")
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, key), strconv.FormatUint(i, 10))
 	return e
 }
 
@@ -636,7 +947,8 @@ func (e *Event) Uints64(key string, i []uint64) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendUints64(enc.AppendKey(e.buf, key), i)
+	e.guardUse("Uints64")
+	e.buf = appendUints64(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
@@ -645,7 +957,8 @@ func (e *Event) Float32(key string, f float32) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendFloat32(enc.AppendKey(e.buf, key), f, FloatingPointPrecision)
+	e.guardUse("Float32")
+	e.buf = appendFloat32(enc.AppendKey(e.buf, key), f, e.floatFmt)
 	return e
 }
 
@@ -654,7 +967,8 @@ func (e *Event) Floats32(key string, f []float32) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendFloats32(enc.AppendKey(e.buf, key), f, FloatingPointPrecision)
+	e.guardUse("Floats32")
+	e.buf = appendFloats32(enc.AppendKey(e.buf, key), f, e.floatFmt)
 	return e
 }
 
@@ -663,7 +977,8 @@ func (e *Event) Float64(key string, f float64) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendFloat64(enc.AppendKey(e.buf, key), f, FloatingPointPrecision)
+	e.guardUse("Float64")
+	e.buf = appendFloat64(enc.AppendKey(e.buf, key), f, e.floatFmt)
 	return e
 }
 
@@ -672,7 +987,30 @@ func (e *Event) Floats64(key string, f []float64) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendFloats64(enc.AppendKey(e.buf, key), f, FloatingPointPrecision)
+	e.guardUse("Floats64")
+	e.buf = appendFloats64(enc.AppendKey(e.buf, key), f, e.floatFmt)
+	return e
+}
+
+// Float32P adds the field key with f as a float32 to the *Event context,
+// formatted with prec digits instead of the logger or global precision.
+func (e *Event) Float32P(key string, f float32, prec int) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Float32P")
+	e.buf = appendFloat32P(enc.AppendKey(e.buf, key), f, prec, e.floatFmt)
+	return e
+}
+
+// Float64P adds the field key with f as a float64 to the *Event context,
+// formatted with prec digits instead of the logger or global precision.
+func (e *Event) Float64P(key string, f float64, prec int) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Float64P")
+	e.buf = appendFloat64P(enc.AppendKey(e.buf, key), f, prec, e.floatFmt)
 	return e
 }
 
@@ -685,7 +1023,36 @@ func (e *Event) Timestamp() *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTime(enc.AppendKey(e.buf, TimestampFieldName), TimestampFunc(), TimeFieldFormat)
+	e.guardUse("Timestamp")
+	t := e.settings.now()
+	switch e.atState {
+	case atWritten:
+		return e
+	case atPending:
+		t = e.at
+		e.atState = atWritten
+	}
+	e.buf = enc.AppendTime(enc.AppendKey(e.buf, e.settings.timestampFieldName()), t, e.settings.timeFieldFormat())
+	return e
+}
+
+// At sets t as the timestamp of the event, for instance when replaying
+// historical data or logging an event which logical time differs from its
+// emission time. The timestamp added by Context.Timestamp is replaced by t,
+// and if the logger doesn't add timestamps, t is added with the "time" key
+// when the event is sent.
+//
+// At must be called before Timestamp for the latter to use t. Once written,
+// the timestamp is not added again by Timestamp.
+func (e *Event) At(t time.Time) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("At")
+	e.at = t
+	if e.atState == atUnset {
+		e.atState = atPending
+	}
 	return e
 }
 
@@ -694,7 +1061,8 @@ func (e *Event) Time(key string, t time.Time) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTime(enc.AppendKey(e.buf, key), t, TimeFieldFormat)
+	e.guardUse("Time")
+	e.buf = enc.AppendTime(enc.AppendKey(e.buf, key), t, e.settings.timeFieldFormat())
 	return e
 }
 
@@ -703,7 +1071,8 @@ func (e *Event) Times(key string, t []time.Time) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTimes(enc.AppendKey(e.buf, key), t, TimeFieldFormat)
+	e.guardUse("Times")
+	e.buf = enc.AppendTimes(enc.AppendKey(e.buf, key), t, e.settings.timeFieldFormat())
 	return e
 }
 
@@ -714,7 +1083,28 @@ func (e *Event) Dur(key string, d time.Duration) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger, FloatingPointPrecision)
+	e.guardUse("Dur")
+	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, e.settings.durationFieldUnit(), e.settings.durationFieldInteger(), FloatingPointPrecision)
+	return e
+}
+
+// Retention adds a hint of how long the event should be kept by the stores
+// receiving it, like 3 days for debug events and 7 years for audit events
+// logged to the same stream:
+//
+//	log.Info().Retention(7 * 365 * 24 * time.Hour).Str("action", "delete_user").Msg("audit")
+//	// {"level":"info","retention":220752000,"action":"delete_user","message":"audit"}
+//
+// The hint is added as RetentionFieldName field, in whole seconds regardless of
+// DurationFieldUnit so every store reads it the same way. It is honored by
+// the sqlitelog writer. The file writers, like rollingwriter, keep whole
+// files and ignore it: their retention is set by their own options.
+func (e *Event) Retention(d time.Duration) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Retention")
+	e.buf = enc.AppendInt64(enc.AppendKey(e.buf, RetentionFieldName), int64(d/time.Second))
 	return e
 }
 
@@ -725,10 +1115,64 @@ func (e *Event) Durs(key string, d []time.Duration) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendDurations(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger, FloatingPointPrecision)
+	e.guardUse("Durs")
+	e.buf = enc.AppendDurations(enc.AppendKey(e.buf, key), d, e.settings.durationFieldUnit(), e.settings.durationFieldInteger(), FloatingPointPrecision)
+	return e
+}
+
+// DurBucket adds the field key with duration d like Dur, and the field key
+// suffixed by DurationBucketFieldSuffix with the label of the exponential
+// bucket d falls in. Buckets bounds are base times the powers of two, so
+// with a 1ms base, 10ms is labeled "8-16ms" and 0.5ms "0-1ms". Labels let log
+// systems unable to do math at query time count latency distributions.
+//
+// Bounds are expressed in the DurationFieldUnit of the logger's Settings. If
+// base is not positive or d is negative, only the duration is added.
+func (e *Event) DurBucket(key string, d, base time.Duration) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("DurBucket")
+	e.Dur(key, d)
+	if base > 0 && d >= 0 {
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, key+DurationBucketFieldSuffix), durationBucket(d, base, e.settings.durationFieldUnit()))
+	}
 	return e
 }
 
+// durationBucket returns the label of the exponential bucket of d.
+func durationBucket(d, base, unit time.Duration) string {
+	lower, upper := time.Duration(0), base
+	if d >= base {
+		lower = base
+		for lower <= d/2 {
+			lower *= 2
+		}
+		upper = lower * 2
+	}
+	var suffix string
+	switch unit {
+	case time.Nanosecond:
+		suffix = "ns"
+	case time.Microsecond:
+		suffix = "us"
+	case time.Millisecond:
+		suffix = "ms"
+	case time.Second:
+		suffix = "s"
+	case time.Minute:
+		suffix = "m"
+	case time.Hour:
+		suffix = "h"
+	default:
+		return lower.String() + "-" + upper.String()
+	}
+	b := strconv.AppendFloat(nil, float64(lower)/float64(unit), 'f', -1, 64)
+	b = append(b, '-')
+	b = strconv.AppendFloat(b, float64(upper)/float64(unit), 'f', -1, 64)
+	return string(append(b, suffix...))
+}
+
 // TimeDiff adds the field key with positive duration between time t and start.
 // If time t is not greater than start, duration will be 0.
 // Duration format follows the same principle as Dur().
@@ -736,11 +1180,12 @@ func (e *Event) TimeDiff(key string, t time.Time, start time.Time) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("TimeDiff")
 	var d time.Duration
 	if t.After(start) {
 		d = t.Sub(start)
 	}
-	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger, FloatingPointPrecision)
+	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, e.settings.durationFieldUnit(), e.settings.durationFieldInteger(), FloatingPointPrecision)
 	return e
 }
 
@@ -754,10 +1199,15 @@ func (e *Event) Interface(key string, i interface{}) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Interface")
 	if obj, ok := i.(LogObjectMarshaler); ok {
 		return e.Object(key, obj)
 	}
-	e.buf = enc.AppendInterface(enc.AppendKey(e.buf, key), i)
+	e.buf = enc.AppendKey(e.buf, key)
+	if e.sink != nil {
+		defer e.recoverMarshal(len(e.buf))
+	}
+	e.buf = enc.AppendInterface(e.buf, i)
 	return e
 }
 
@@ -766,6 +1216,7 @@ func (e *Event) Type(key string, val interface{}) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Type")
 	e.buf = enc.AppendType(enc.AppendKey(e.buf, key), val)
 	return e
 }
@@ -776,6 +1227,7 @@ func (e *Event) CallerSkipFrame(skip int) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("CallerSkipFrame")
 	e.skipFrame += skip
 	return e
 }
@@ -784,9 +1236,9 @@ func (e *Event) CallerSkipFrame(skip int) *Event {
 // The argument skip is the number of stack frames to ascend
 // Skip If not passed, use the global variable CallerSkipFrameCount
 func (e *Event) Caller(skip ...int) *Event {
-	sk := CallerSkipFrameCount
+	sk := e.settings.callerSkipFrameCount()
 	if len(skip) > 0 {
-		sk = skip[0] + CallerSkipFrameCount
+		sk += skip[0]
 	}
 	return e.caller(sk)
 }
@@ -795,19 +1247,38 @@ func (e *Event) caller(skip int) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("Caller")
 	pc, file, line, ok := runtime.Caller(skip + e.skipFrame)
 	if !ok {
 		return e
 	}
-	e.buf = enc.AppendString(enc.AppendKey(e.buf, CallerFieldName), CallerMarshalFunc(pc, file, line))
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, e.settings.callerFieldName()), e.settings.callerMarshalFunc()(pc, file, line))
+	if CallerFuncFieldName != "" {
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, CallerFuncFieldName), CallerFuncMarshalFunc(pc))
+	}
 	return e
 }
 
+// shortFuncName returns the name of the function containing pc, stripped of
+// its package path.
+func shortFuncName(pc uintptr) string {
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
 // IPAddr adds IPv4 or IPv6 Address to the event
 func (e *Event) IPAddr(key string, ip net.IP) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("IPAddr")
 	e.buf = enc.AppendIPAddr(enc.AppendKey(e.buf, key), ip)
 	return e
 }
@@ -817,6 +1288,7 @@ func (e *Event) IPPrefix(key string, pfx net.IPNet) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("IPPrefix")
 	e.buf = enc.AppendIPPrefix(enc.AppendKey(e.buf, key), pfx)
 	return e
 }
@@ -826,6 +1298,7 @@ func (e *Event) MACAddr(key string, ha net.HardwareAddr) *Event {
 	if e == nil {
 		return e
 	}
+	e.guardUse("MACAddr")
 	e.buf = enc.AppendMACAddr(enc.AppendKey(e.buf, key), ha)
 	return e
 }
@@ -834,6 +1307,7 @@ func (e *Event) MACAddr(key string, ha net.HardwareAddr) *Event {
 //
 // Caution: This is an expensive operation.
 func (e *Event) DeDup() *Event {
+	e.guardUse("DeDup")
 	if len(e.buf) <= 1 {
 		return e
 	}
@@ -891,6 +1365,7 @@ func (e *Event) DeDup() *Event {
 // Caution: This is an expensive operation.
 // If it fails, it will revert back to the original data with potentially duplicated fields
 func (e *Event) DeDupDeep() *Event {
+	e.guardUse("DeDupDeep")
 	if len(e.buf) == 0 {
 		return e
 	}
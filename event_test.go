@@ -4,9 +4,11 @@ package zerolog
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"strings"
 	"testing"
+	"time"
 )
 
 type nilError struct{}
@@ -51,6 +53,20 @@ func TestEvent_ObjectWithNil(t *testing.T) {
 	}
 }
 
+func TestEvent_Retention(t *testing.T) {
+	var buf bytes.Buffer
+	e := newEvent(LevelWriterAdapter{&buf}, DebugLevel)
+	e.settings = &Settings{DurationFieldUnit: time.Millisecond}
+	_ = e.Retention(72*time.Hour + 500*time.Millisecond)
+	_ = e.write()
+
+	want := `{"retention":259200}`
+	got := strings.TrimSpace(buf.String())
+	if got != want {
+		t.Errorf("Event.Retention() = %q, want %q", got, want)
+	}
+}
+
 func TestEvent_EmbedObjectWithNil(t *testing.T) {
 	var buf bytes.Buffer
 	e := newEvent(LevelWriterAdapter{&buf}, DebugLevel)
@@ -63,3 +79,61 @@ func TestEvent_EmbedObjectWithNil(t *testing.T) {
 		t.Errorf("Event.EmbedObject() = %q, want %q", got, want)
 	}
 }
+
+func TestEvent_EmbedRawJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  string
+		want string
+	}{
+		{"object", ` {"a":1, "b":{"c":"d"}} `, `{"foo":"bar","a":1, "b":{"c":"d"}}`},
+		{"empty object", `{ }`, `{"foo":"bar"}`},
+		{"array", `[1,2]`, `{"foo":"bar"}`},
+		{"invalid", `{"a":`, `{"foo":"bar"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := newEvent(LevelWriterAdapter{&buf}, DebugLevel)
+			e.Str("foo", "bar").EmbedRawJSON([]byte(tt.obj))
+			_ = e.write()
+			if got, want := strings.TrimSpace(buf.String()), tt.want; got != want {
+				t.Errorf("Event.EmbedRawJSON() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvent_ContentHash(t *testing.T) {
+	hashOf := func(f func(log Logger)) string {
+		var buf bytes.Buffer
+		f(New(&buf))
+		var evt map[string]interface{}
+		if err := json.Unmarshal([]byte(decodeIfBinaryToString(buf.Bytes())), &evt); err != nil {
+			t.Fatal(err)
+		}
+		s, _ := evt[EventHashFieldName].(string)
+		if len(s) != 16 {
+			t.Fatalf("invalid hash %q", s)
+		}
+		return s
+	}
+	ref := hashOf(func(log Logger) { log.Info().Str("user", "bob").Int("n", 1).ContentHash("user").Msg("login") })
+
+	same := hashOf(func(log Logger) {
+		l := log.With().Str("user", "bob").Logger()
+		l.Warn().Int("n", 2).ContentHash("user").Msg("login")
+	})
+	if same != ref {
+		t.Errorf("hash of same content differs: %s != %s", same, ref)
+	}
+	for name, f := range map[string]func(log Logger){
+		"value":   func(log Logger) { log.Info().Str("user", "alice").ContentHash("user").Msg("login") },
+		"message": func(log Logger) { log.Info().Str("user", "bob").ContentHash("user").Msg("logout") },
+		"missing": func(log Logger) { log.Info().ContentHash("user").Msg("login") },
+	} {
+		if h := hashOf(f); h == ref {
+			t.Errorf("%s: hash of different content is equal", name)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// AsMap returns the fields added to the event so far, decoded as a map. It
+// is meant for hooks and test helpers needing to inspect an event without
+// parsing its JSON by hand. Numbers are decoded as json.Number to preserve
+// their precision. Duplicate keys hold their last value.
+//
+// The event is left untouched and can still be sent.
+func (e *Event) AsMap() (map[string]interface{}, error) {
+	if e == nil {
+		return nil, nil
+	}
+	e.guardUse("AsMap")
+	buf := enc.AppendEndMarker(append(make([]byte, 0, len(e.buf)+1), e.buf...))
+	return DecodeEvent(buf)
+}
+
+// DecodeEvent decodes an event as written by a logger, in JSON or in the
+// binary encoding, into a map. Numbers are decoded as json.Number to
+// preserve their precision.
+func DecodeEvent(p []byte) (map[string]interface{}, error) {
+	var evt map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(decodeIfBinaryToBytes(p)))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return nil, fmt.Errorf("cannot decode event: %s", err)
+	}
+	return evt, nil
+}
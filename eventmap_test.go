@@ -0,0 +1,48 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestEventAsMap(t *testing.T) {
+	var got map[string]interface{}
+	out := &bytes.Buffer{}
+	log := New(out).Hook(HookFunc(func(e *Event, level Level, msg string) {
+		var err error
+		if got, err = e.AsMap(); err != nil {
+			t.Error(err)
+		}
+	})).With().Str("foo", "bar").Logger()
+	log.Info().Int("n", 42).Dict("d", Dict().Bool("b", true)).Msg("hello")
+
+	want := map[string]interface{}{
+		"level": "info",
+		"foo":   "bar",
+		"n":     json.Number("42"),
+		"d":     map[string]interface{}{"b": true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AsMap() = %#v, want %#v", got, want)
+	}
+
+	// The event is still sent as is.
+	evt, err := DecodeEvent(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want["message"] = "hello"
+	if !reflect.DeepEqual(evt, want) {
+		t.Errorf("DecodeEvent() = %#v, want %#v", evt, want)
+	}
+
+	var e *Event
+	if m, err := e.AsMap(); m != nil || err != nil {
+		t.Errorf("nil event AsMap() = %v, %v", m, err)
+	}
+	if _, err := DecodeEvent([]byte("{")); err == nil {
+		t.Error("DecodeEvent() of invalid event returned no error")
+	}
+}
@@ -1,24 +1,114 @@
 package zerolog
 
 import (
+	"bytes"
 	"encoding/json"
 	"net"
 	"sort"
+	"strconv"
 	"time"
 	"unsafe"
 )
 
+// isJSONObject returns true if b is a valid JSON encoded object.
+func isJSONObject(b []byte) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) >= 2 && b[0] == '{' && json.Valid(b)
+}
+
+// maxSafeInt is the largest integer a IEEE 754 double can represent exactly.
+const maxSafeInt = 1 << 53
+
+// appendInt64 appends i as a number, or as a string if LargeIntAsString is
+// set and i is out of the safe integer range.
+func appendInt64(dst []byte, i int64) []byte {
+	if LargeIntAsString && (i > maxSafeInt || i < -maxSafeInt) {
+		return enc.AppendString(dst, strconv.FormatInt(i, 10))
+	}
+	return enc.AppendInt64(dst, i)
+}
+
+// appendUint64 appends i as a number, or as a string if LargeIntAsString is
+// set and i is out of the safe integer range.
+func appendUint64(dst []byte, i uint64) []byte {
+	if LargeIntAsString && i > maxSafeInt {
+		return enc.AppendString(dst, strconv.FormatUint(i, 10))
+	}
+	return enc.AppendUint64(dst, i)
+}
+
+// appendInts appends vals as an array of numbers, the values out of the safe
+// integer range being appended as strings if LargeIntAsString is set.
+func appendInts(dst []byte, vals []int) []byte {
+	if !LargeIntAsString {
+		return enc.AppendInts(dst, vals)
+	}
+	dst = enc.AppendArrayStart(dst)
+	for i, v := range vals {
+		if i > 0 {
+			dst = enc.AppendArrayDelim(dst)
+		}
+		dst = appendInt64(dst, int64(v))
+	}
+	return enc.AppendArrayEnd(dst)
+}
+
+// appendInts64 is like appendInts for int64 values.
+func appendInts64(dst []byte, vals []int64) []byte {
+	if !LargeIntAsString {
+		return enc.AppendInts64(dst, vals)
+	}
+	dst = enc.AppendArrayStart(dst)
+	for i, v := range vals {
+		if i > 0 {
+			dst = enc.AppendArrayDelim(dst)
+		}
+		dst = appendInt64(dst, v)
+	}
+	return enc.AppendArrayEnd(dst)
+}
+
+// appendUints is like appendInts for uint values.
+func appendUints(dst []byte, vals []uint) []byte {
+	if !LargeIntAsString {
+		return enc.AppendUints(dst, vals)
+	}
+	dst = enc.AppendArrayStart(dst)
+	for i, v := range vals {
+		if i > 0 {
+			dst = enc.AppendArrayDelim(dst)
+		}
+		dst = appendUint64(dst, uint64(v))
+	}
+	return enc.AppendArrayEnd(dst)
+}
+
+// appendUints64 is like appendInts for uint64 values.
+func appendUints64(dst []byte, vals []uint64) []byte {
+	if !LargeIntAsString {
+		return enc.AppendUints64(dst, vals)
+	}
+	dst = enc.AppendArrayStart(dst)
+	for i, v := range vals {
+		if i > 0 {
+			dst = enc.AppendArrayDelim(dst)
+		}
+		dst = appendUint64(dst, v)
+	}
+	return enc.AppendArrayEnd(dst)
+}
+
 func isNilValue(i interface{}) bool {
 	return (*[2]uintptr)(unsafe.Pointer(&i))[1] == 0
 }
 
-func appendFields(dst []byte, fields interface{}, stack bool) []byte {
+func appendFields(dst []byte, fields interface{}, stack bool, ff *FloatFormat) []byte {
 	switch fields := fields.(type) {
 	case []interface{}:
 		if n := len(fields); n&0x1 == 1 { // odd number
 			fields = fields[:n-1]
 		}
-		dst = appendFieldList(dst, fields, stack)
+		dst = appendFieldList(dst, fields, stack, ff)
 	case map[string]interface{}:
 		keys := make([]string, 0, len(fields))
 		for key := range fields {
@@ -28,13 +118,13 @@ func appendFields(dst []byte, fields interface{}, stack bool) []byte {
 		kv := make([]interface{}, 2)
 		for _, key := range keys {
 			kv[0], kv[1] = key, fields[key]
-			dst = appendFieldList(dst, kv, stack)
+			dst = appendFieldList(dst, kv, stack, ff)
 		}
 	}
 	return dst
 }
 
-func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
+func appendFieldList(dst []byte, kvList []interface{}, stack bool, ff *FloatFormat) []byte {
 	for i, n := 0, len(kvList); i < n; i += 2 {
 		key, val := kvList[i], kvList[i+1]
 		if key, ok := key.(string); ok {
@@ -45,6 +135,7 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		if val, ok := val.(LogObjectMarshaler); ok {
 			e := newEvent(nil, 0)
 			e.buf = e.buf[:0]
+			e.floatFmt = ff
 			e.appendObject(val)
 			dst = append(dst, e.buf...)
 			putEvent(e)
@@ -56,10 +147,11 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case []byte:
 			dst = enc.AppendBytes(dst, val)
 		case error:
-			switch m := ErrorMarshalFunc(val).(type) {
+			switch m := marshalError(val, nil).(type) {
 			case LogObjectMarshaler:
 				e := newEvent(nil, 0)
 				e.buf = e.buf[:0]
+				e.floatFmt = ff
 				e.appendObject(m)
 				dst = append(dst, e.buf...)
 				putEvent(e)
@@ -92,10 +184,11 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case []error:
 			dst = enc.AppendArrayStart(dst)
 			for i, err := range val {
-				switch m := ErrorMarshalFunc(err).(type) {
+				switch m := marshalError(err, nil).(type) {
 				case LogObjectMarshaler:
 					e := newEvent(nil, 0)
 					e.buf = e.buf[:0]
+					e.floatFmt = ff
 					e.appendObject(m)
 					dst = append(dst, e.buf...)
 					putEvent(e)
@@ -119,7 +212,7 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case bool:
 			dst = enc.AppendBool(dst, val)
 		case int:
-			dst = enc.AppendInt(dst, val)
+			dst = appendInt64(dst, int64(val))
 		case int8:
 			dst = enc.AppendInt8(dst, val)
 		case int16:
@@ -127,9 +220,9 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case int32:
 			dst = enc.AppendInt32(dst, val)
 		case int64:
-			dst = enc.AppendInt64(dst, val)
+			dst = appendInt64(dst, val)
 		case uint:
-			dst = enc.AppendUint(dst, val)
+			dst = appendUint64(dst, uint64(val))
 		case uint8:
 			dst = enc.AppendUint8(dst, val)
 		case uint16:
@@ -137,11 +230,11 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case uint32:
 			dst = enc.AppendUint32(dst, val)
 		case uint64:
-			dst = enc.AppendUint64(dst, val)
+			dst = appendUint64(dst, val)
 		case float32:
-			dst = enc.AppendFloat32(dst, val, FloatingPointPrecision)
+			dst = appendFloat32(dst, val, ff)
 		case float64:
-			dst = enc.AppendFloat64(dst, val, FloatingPointPrecision)
+			dst = appendFloat64(dst, val, ff)
 		case time.Time:
 			dst = enc.AppendTime(dst, val, TimeFieldFormat)
 		case time.Duration:
@@ -160,7 +253,7 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 			}
 		case *int:
 			if val != nil {
-				dst = enc.AppendInt(dst, *val)
+				dst = appendInt64(dst, int64(*val))
 			} else {
 				dst = enc.AppendNil(dst)
 			}
@@ -184,13 +277,13 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 			}
 		case *int64:
 			if val != nil {
-				dst = enc.AppendInt64(dst, *val)
+				dst = appendInt64(dst, *val)
 			} else {
 				dst = enc.AppendNil(dst)
 			}
 		case *uint:
 			if val != nil {
-				dst = enc.AppendUint(dst, *val)
+				dst = appendUint64(dst, uint64(*val))
 			} else {
 				dst = enc.AppendNil(dst)
 			}
@@ -214,19 +307,19 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 			}
 		case *uint64:
 			if val != nil {
-				dst = enc.AppendUint64(dst, *val)
+				dst = appendUint64(dst, *val)
 			} else {
 				dst = enc.AppendNil(dst)
 			}
 		case *float32:
 			if val != nil {
-				dst = enc.AppendFloat32(dst, *val, FloatingPointPrecision)
+				dst = appendFloat32(dst, *val, ff)
 			} else {
 				dst = enc.AppendNil(dst)
 			}
 		case *float64:
 			if val != nil {
-				dst = enc.AppendFloat64(dst, *val, FloatingPointPrecision)
+				dst = appendFloat64(dst, *val, ff)
 			} else {
 				dst = enc.AppendNil(dst)
 			}
@@ -247,7 +340,7 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case []bool:
 			dst = enc.AppendBools(dst, val)
 		case []int:
-			dst = enc.AppendInts(dst, val)
+			dst = appendInts(dst, val)
 		case []int8:
 			dst = enc.AppendInts8(dst, val)
 		case []int16:
@@ -255,9 +348,9 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case []int32:
 			dst = enc.AppendInts32(dst, val)
 		case []int64:
-			dst = enc.AppendInts64(dst, val)
+			dst = appendInts64(dst, val)
 		case []uint:
-			dst = enc.AppendUints(dst, val)
+			dst = appendUints(dst, val)
 		// case []uint8:
 		// 	dst = enc.AppendUints8(dst, val)
 		case []uint16:
@@ -265,11 +358,11 @@ func appendFieldList(dst []byte, kvList []interface{}, stack bool) []byte {
 		case []uint32:
 			dst = enc.AppendUints32(dst, val)
 		case []uint64:
-			dst = enc.AppendUints64(dst, val)
+			dst = appendUints64(dst, val)
 		case []float32:
-			dst = enc.AppendFloats32(dst, val, FloatingPointPrecision)
+			dst = appendFloats32(dst, val, ff)
 		case []float64:
-			dst = enc.AppendFloats64(dst, val, FloatingPointPrecision)
+			dst = appendFloats64(dst, val, ff)
 		case []time.Time:
 			dst = enc.AppendTimes(dst, val, TimeFieldFormat)
 		case []time.Duration:
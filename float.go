@@ -0,0 +1,115 @@
+package zerolog
+
+import "math"
+
+// FloatFormat controls how float fields are formatted by a Logger. See
+// Logger.FloatFormat.
+type FloatFormat struct {
+	// Fmt is the format passed to strconv.FormatFloat: 'f', 'g', 'e', etc.
+	// If zero, 'f' is used.
+	Fmt byte
+
+	// Precision is the number of digits passed to strconv.FormatFloat.
+	// -1 uses the smallest number of digits necessary to represent the value
+	// exactly.
+	Precision int
+
+	// TrimZeros removes trailing zeros after the decimal point, as well as the
+	// decimal point itself if no digits remain after it.
+	TrimZeros bool
+
+	// useGlobal formats the floats like without FloatFormat, set when only
+	// nullNonFinite is needed.
+	useGlobal bool
+
+	// nullNonFinite encodes NaN and infinities as null. See Logger.Strict.
+	nullNonFinite bool
+}
+
+// precision returns the precision of the floats added without explicit
+// precision.
+func (ff *FloatFormat) precision() int {
+	if ff.useGlobal {
+		return FloatingPointPrecision
+	}
+	return ff.Precision
+}
+
+func (ff *FloatFormat) appendFloat(dst []byte, f float64, bitSize, precision int) []byte {
+	if ff.nullNonFinite && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return enc.AppendNil(dst)
+	}
+	if ff.useGlobal {
+		if bitSize == 32 {
+			return enc.AppendFloat32(dst, float32(f), precision)
+		}
+		return enc.AppendFloat64(dst, f, precision)
+	}
+	fmt := ff.Fmt
+	if fmt == 0 {
+		fmt = 'f'
+	}
+	return enc.AppendFloatFmt(dst, f, bitSize, fmt, precision, ff.TrimZeros)
+}
+
+// appendFloat32 appends f using ff if set, or the global FloatingPointPrecision
+// otherwise.
+func appendFloat32(dst []byte, f float32, ff *FloatFormat) []byte {
+	if ff == nil {
+		return enc.AppendFloat32(dst, f, FloatingPointPrecision)
+	}
+	return ff.appendFloat(dst, float64(f), 32, ff.precision())
+}
+
+// appendFloat64 appends f using ff if set, or the global FloatingPointPrecision
+// otherwise.
+func appendFloat64(dst []byte, f float64, ff *FloatFormat) []byte {
+	if ff == nil {
+		return enc.AppendFloat64(dst, f, FloatingPointPrecision)
+	}
+	return ff.appendFloat(dst, f, 64, ff.precision())
+}
+
+// appendFloat32P appends f with the given precision, formatted using ff if set.
+func appendFloat32P(dst []byte, f float32, precision int, ff *FloatFormat) []byte {
+	if ff == nil {
+		return enc.AppendFloat32(dst, f, precision)
+	}
+	return ff.appendFloat(dst, float64(f), 32, precision)
+}
+
+// appendFloat64P appends f with the given precision, formatted using ff if set.
+func appendFloat64P(dst []byte, f float64, precision int, ff *FloatFormat) []byte {
+	if ff == nil {
+		return enc.AppendFloat64(dst, f, precision)
+	}
+	return ff.appendFloat(dst, f, 64, precision)
+}
+
+func appendFloats32(dst []byte, vals []float32, ff *FloatFormat) []byte {
+	if ff == nil {
+		return enc.AppendFloats32(dst, vals, FloatingPointPrecision)
+	}
+	dst = enc.AppendArrayStart(dst)
+	for i, f := range vals {
+		if i > 0 {
+			dst = enc.AppendArrayDelim(dst)
+		}
+		dst = ff.appendFloat(dst, float64(f), 32, ff.precision())
+	}
+	return enc.AppendArrayEnd(dst)
+}
+
+func appendFloats64(dst []byte, vals []float64, ff *FloatFormat) []byte {
+	if ff == nil {
+		return enc.AppendFloats64(dst, vals, FloatingPointPrecision)
+	}
+	dst = enc.AppendArrayStart(dst)
+	for i, f := range vals {
+		if i > 0 {
+			dst = enc.AppendArrayDelim(dst)
+		}
+		dst = ff.appendFloat(dst, f, 64, ff.precision())
+	}
+	return enc.AppendArrayEnd(dst)
+}
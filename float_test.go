@@ -0,0 +1,62 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFloatFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).FloatFormat(FloatFormat{Precision: 3, TrimZeros: true})
+	log.Log().
+		Float64("a", 1.23456).
+		Float64("b", 2).
+		Floats64("c", []float64{0.5, 1.0001}).
+		Float64P("d", 1.23456, 1).
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"a":1.235,"b":2,"c":[0.5,1],"d":1.2}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = New(out).FloatFormat(FloatFormat{Fmt: 'g', Precision: 2}).With().Float64("ctx", 123.456).Logger()
+	log.Log().Float32("a", 0.000123).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"ctx":1.2e+02,"a":0.00012}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = New(out)
+	log.Log().Float64P("a", 1.23456, 2).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"a":1.23}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = New(out).FloatFormat(FloatFormat{Precision: 1})
+	log.Log().
+		Fields(map[string]interface{}{"f": 1.25, "fs": []float64{0.25}}).
+		Array("arr", floatArray{1.25}).
+		Object("obj", floatObject{1.25}).
+		Msg("")
+	if got, want := out.String(), `{"f":1.2,"fs":[0.2],"arr":[1.2,{"f":1.2}],"obj":{"f":1.2}}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type floatArray []float64
+
+func (a floatArray) MarshalZerologArray(arr *Array) {
+	for _, f := range a {
+		arr.Float64(f).Object(floatObject{f})
+	}
+}
+
+type floatObject struct{ f float64 }
+
+func (o floatObject) MarshalZerologObject(e *Event) {
+	e.Float64("f", o.f)
+}
@@ -0,0 +1,320 @@
+// Package fluent provides a zerolog writer speaking the Fluentd Forward
+// protocol, sending events straight to fluentd or fluent-bit aggregators
+// without a local tail-and-parse step.
+//
+//	w, err := fluent.NewWriter(fluent.Options{
+//	    Address:    "fluentd.internal:24224",
+//	    Tag:        "app.api",
+//	    RequireAck: true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Events are buffered in memory and sent in batches using the Forward mode of
+// the protocol. Failed batches are retried on a new connection.
+package fluent
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+// ErrBufferFull is returned by Write when the event buffer is full.
+var ErrBufferFull = errors.New("fluent: buffer full, event dropped")
+
+// Options configures a Writer.
+type Options struct {
+	// Network is the network of the aggregator, "tcp" (default) or "unix".
+	Network string
+
+	// Address is the address of the aggregator, like "localhost:24224".
+	Address string
+
+	// TLSConfig, if not nil, enables TLS on tcp connections.
+	TLSConfig *tls.Config
+
+	// Tag is the Fluentd tag of the events.
+	Tag string
+
+	// RequireAck enables the at-least-once delivery semantic: the aggregator
+	// must acknowledge each batch, which is otherwise retried.
+	RequireAck bool
+
+	// AckTimeout is the maximum time waited for an acknowledgement. Defaults
+	// to 10s.
+	AckTimeout time.Duration
+
+	// DialTimeout is the connection timeout. Defaults to 5s.
+	DialTimeout time.Duration
+
+	// BufferSize is the number of events buffered while waiting to be sent.
+	// Events written when the buffer is full are dropped. Defaults to 8192.
+	BufferSize int
+
+	// BatchSize is the maximum number of events sent in a single message.
+	// Defaults to 256.
+	BatchSize int
+
+	// FlushInterval is the maximum time an event is buffered before being
+	// sent. Defaults to 1s.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a failed batch is retried before being
+	// dropped. Defaults to 3, a negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubled on each
+	// following retry. Defaults to 500ms.
+	RetryBackoff time.Duration
+
+	// Sink, if not nil, receives a WriteFailure error for each dropped batch.
+	// Drops are otherwise reported to ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+// Writer is a zerolog.LevelWriter sending events to a Fluentd aggregator.
+type Writer struct {
+	opts Options
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan []byte
+	flush  chan chan struct{}
+	done   chan struct{}
+
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewWriter returns a Writer sending events to the aggregator described by
+// opts. The connection is established lazily by a background go-routine
+// running until Close is called.
+func NewWriter(opts Options) (*Writer, error) {
+	if opts.Address == "" {
+		return nil, errors.New("fluent: missing address")
+	}
+	if opts.Network == "" {
+		opts.Network = "tcp"
+	}
+	if opts.AckTimeout <= 0 {
+		opts.AckTimeout = 10 * time.Second
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 8192
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 256
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	w := &Writer{
+		opts:  opts,
+		queue: make(chan []byte, opts.BufferSize),
+		flush: make(chan chan struct{}),
+		done:  make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. The event is converted to a
+// Forward entry and buffered until the next batch is sent.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	var evt map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(cbor.DecodeIfBinaryToBytes(p)))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("fluent: cannot decode event: %v", err)
+	}
+	entry := appendEventTime(appendArrayHeader(nil, 2), time.Now())
+	entry = appendValue(entry, evt)
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errors.New("fluent: writer closed")
+	}
+	select {
+	case w.queue <- entry:
+		return len(p), nil
+	default:
+		return 0, ErrBufferFull
+	}
+}
+
+// Flush sends the buffered events and returns once they are sent or dropped.
+func (w *Writer) Flush() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errors.New("fluent: writer closed")
+	}
+	c := make(chan struct{})
+	w.flush <- c
+	<-c
+	return nil
+}
+
+// Close sends the buffered events, stops the background go-routine and closes
+// the connection.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("fluent: writer already closed")
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	<-w.done
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	var batch [][]byte
+	for {
+		select {
+		case entry, ok := <-w.queue:
+			if !ok {
+				w.send(batch)
+				if w.conn != nil {
+					w.conn.Close()
+				}
+				return
+			}
+			if batch = append(batch, entry); len(batch) >= w.opts.BatchSize {
+				w.send(batch)
+				batch = nil
+			}
+		case c := <-w.flush:
+			for n := len(w.queue); n > 0; n-- {
+				if batch = append(batch, <-w.queue); len(batch) >= w.opts.BatchSize {
+					w.send(batch)
+					batch = nil
+				}
+			}
+			w.send(batch)
+			batch = nil
+			close(c)
+		case <-ticker.C:
+			w.send(batch)
+			batch = nil
+		}
+	}
+}
+
+// send sends batch in Forward mode, retrying according to the options.
+func (w *Writer) send(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	msg := appendString(appendArrayHeader(nil, 3), w.opts.Tag)
+	msg = appendArrayHeader(msg, len(batch))
+	for _, entry := range batch {
+		msg = append(msg, entry...)
+	}
+	var chunk string
+	if w.opts.RequireAck {
+		var id [16]byte
+		rand.Read(id[:])
+		chunk = base64.StdEncoding.EncodeToString(id[:])
+		msg = appendString(appendMapHeader(msg, 2), "size")
+		msg = appendString(appendInt(msg, int64(len(batch))), "chunk")
+		msg = appendString(msg, chunk)
+	} else {
+		msg = appendString(appendMapHeader(msg, 1), "size")
+		msg = appendInt(msg, int64(len(batch)))
+	}
+
+	var err error
+	backoff := w.opts.RetryBackoff
+	for attempt := 0; attempt <= w.opts.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		if err = w.sendOnce(msg, chunk); err == nil {
+			return
+		}
+		if w.conn != nil {
+			w.conn.Close()
+			w.conn = nil
+		}
+	}
+	zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel,
+		fmt.Errorf("fluent: dropped %d events: %v", len(batch), err))
+}
+
+func (w *Writer) sendOnce(msg []byte, chunk string) error {
+	if w.conn == nil {
+		if err := w.dial(); err != nil {
+			return err
+		}
+	}
+	if _, err := w.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+	w.conn.SetReadDeadline(time.Now().Add(w.opts.AckTimeout))
+	resp, err := readValue(w.r)
+	if err != nil {
+		return fmt.Errorf("cannot read ack: %v", err)
+	}
+	if m, ok := resp.(map[string]interface{}); !ok || m["ack"] != chunk {
+		return fmt.Errorf("invalid ack: %v", resp)
+	}
+	return nil
+}
+
+func (w *Writer) dial() (err error) {
+	dialer := &net.Dialer{Timeout: w.opts.DialTimeout}
+	var conn net.Conn
+	if w.opts.TLSConfig != nil && w.opts.Network == "tcp" {
+		conn, err = tls.DialWithDialer(dialer, w.opts.Network, w.opts.Address, w.opts.TLSConfig)
+	} else {
+		conn, err = dialer.Dial(w.opts.Network, w.opts.Address)
+	}
+	if err != nil {
+		return err
+	}
+	w.conn = conn
+	w.r = bufio.NewReader(conn)
+	return nil
+}
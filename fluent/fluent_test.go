@@ -0,0 +1,145 @@
+package fluent
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+// serve accepts connections on l and sends the received messages to msgs.
+// The first dropFirst messages are not acknowledged and their connection is
+// closed.
+func serve(t *testing.T, l net.Listener, msgs chan<- []interface{}, dropFirst int32) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go func(conn net.Conn) {
+			defer conn.Close()
+			r := bufio.NewReader(conn)
+			for {
+				v, err := readValue(r)
+				if err != nil {
+					return
+				}
+				msg, _ := v.([]interface{})
+				if atomic.AddInt32(&dropFirst, -1) >= 0 {
+					return
+				}
+				msgs <- msg
+				if len(msg) == 3 {
+					if opts, _ := msg[2].(map[string]interface{}); opts["chunk"] != nil {
+						conn.Write(appendString(appendString(appendMapHeader(nil, 1), "ack"), opts["chunk"].(string)))
+					}
+				}
+			}
+		}(conn)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer l.Close()
+	msgs := make(chan []interface{}, 10)
+	go serve(t, l, msgs, 1)
+
+	w, err := NewWriter(Options{
+		Address:      l.Addr().String(),
+		Tag:          "app.test",
+		RequireAck:   true,
+		AckTimeout:   time.Second,
+		RetryBackoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+	log.Info().Int("n", 1).Msg("first")
+	log.Warn().Msg("second")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case msg := <-msgs:
+		if msg[0] != "app.test" {
+			t.Errorf("tag = %v, want app.test", msg[0])
+		}
+		entries, _ := msg[1].([]interface{})
+		var records []interface{}
+		for _, e := range entries {
+			entry := e.([]interface{})
+			if _, ok := entry[0].(time.Time); !ok {
+				t.Errorf("invalid event time %#v", entry[0])
+			}
+			records = append(records, entry[1])
+		}
+		want := []interface{}{
+			map[string]interface{}{"level": "info", "n": int64(1), "message": "first"},
+			map[string]interface{}{"level": "warn", "message": "second"},
+		}
+		if !reflect.DeepEqual(records, want) {
+			t.Errorf("records = %#v, want %#v", records, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	var errs []*zerolog.InternalError
+	w, err := NewWriter(Options{
+		Address:    addr,
+		MaxRetries: -1,
+		Sink: zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+			errs = append(errs, err)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+	log.Info().Msg("lost")
+	w.Close()
+
+	if len(errs) != 1 || errs[0].Kind != zerolog.WriteFailure {
+		t.Errorf("errs = %v, want a single WriteFailure", errs)
+	}
+}
+
+func TestMsgpack(t *testing.T) {
+	in := map[string]interface{}{
+		"nil":   nil,
+		"bool":  true,
+		"neg":   int64(-1000),
+		"big":   uint64(1 << 63),
+		"float": 1.5,
+		"long":  "a string longer than thirty-two bytes",
+		"array": []interface{}{int64(1), "two"},
+	}
+	b := appendValue(nil, in)
+	out, err := readValue(bufio.NewReader(bytes.NewReader(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("got %#v, want %#v", out, in)
+	}
+}
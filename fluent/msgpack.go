@@ -0,0 +1,263 @@
+package fluent
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// This file implements the subset of MessagePack needed by the Forward
+// protocol: the types produced by decoding JSON events, and the EventTime
+// extension.
+
+// eventTimeExt is the MessagePack extension type of the Forward EventTime.
+const eventTimeExt = 0
+
+func appendNil(dst []byte) []byte {
+	return append(dst, 0xc0)
+}
+
+func appendBool(dst []byte, b bool) []byte {
+	if b {
+		return append(dst, 0xc3)
+	}
+	return append(dst, 0xc2)
+}
+
+func appendInt(dst []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i < 128:
+		return append(dst, byte(i))
+	case i < 0 && i >= -32:
+		return append(dst, byte(i))
+	default:
+		return appendUint64(append(dst, 0xd3), uint64(i))
+	}
+}
+
+func appendUint(dst []byte, u uint64) []byte {
+	if u < 128 {
+		return append(dst, byte(u))
+	}
+	return appendUint64(append(dst, 0xcf), u)
+}
+
+func appendFloat(dst []byte, f float64) []byte {
+	return appendUint64(append(dst, 0xcb), math.Float64bits(f))
+}
+
+func appendUint64(dst []byte, u uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], u)
+	return append(dst, b[:]...)
+}
+
+func appendHeader(dst []byte, n int, fix, fixMax, h16, h32 byte) []byte {
+	switch {
+	case n < int(fixMax):
+		return append(dst, fix|byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, h16, byte(n>>8), byte(n))
+	default:
+		return append(dst, h32, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+func appendString(dst []byte, s string) []byte {
+	if n := len(s); n >= 32 && n <= math.MaxUint8 {
+		dst = append(dst, 0xd9, byte(n))
+	} else {
+		dst = appendHeader(dst, n, 0xa0, 32, 0xda, 0xdb)
+	}
+	return append(dst, s...)
+}
+
+func appendArrayHeader(dst []byte, n int) []byte {
+	return appendHeader(dst, n, 0x90, 16, 0xdc, 0xdd)
+}
+
+func appendMapHeader(dst []byte, n int) []byte {
+	return appendHeader(dst, n, 0x80, 16, 0xde, 0xdf)
+}
+
+// appendEventTime appends t as a Forward EventTime.
+func appendEventTime(dst []byte, t time.Time) []byte {
+	dst = append(dst, 0xd7, eventTimeExt)
+	var b [8]byte
+	binary.BigEndian.PutUint32(b[:4], uint32(t.Unix()))
+	binary.BigEndian.PutUint32(b[4:], uint32(t.Nanosecond()))
+	return append(dst, b[:]...)
+}
+
+// appendValue appends v, a value decoded from JSON with UseNumber or
+// returned by readValue. Map keys
+// are sorted to produce a deterministic output.
+func appendValue(dst []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return appendNil(dst)
+	case bool:
+		return appendBool(dst, v)
+	case string:
+		return appendString(dst, v)
+	case int64:
+		return appendInt(dst, v)
+	case uint64:
+		return appendUint(dst, v)
+	case float64:
+		return appendFloat(dst, v)
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendInt(dst, i)
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendUint(dst, u)
+		}
+		f, _ := v.Float64()
+		return appendFloat(dst, f)
+	case []interface{}:
+		dst = appendArrayHeader(dst, len(v))
+		for _, e := range v {
+			dst = appendValue(dst, e)
+		}
+		return dst
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		dst = appendMapHeader(dst, len(v))
+		for _, k := range keys {
+			dst = appendValue(appendString(dst, k), v[k])
+		}
+		return dst
+	default:
+		return appendString(dst, fmt.Sprint(v))
+	}
+}
+
+var errUnsupported = errors.New("unsupported msgpack type")
+
+// readValue decodes a value from r. Integers are returned as int64 or uint64,
+// EventTimes as time.Time.
+func readValue(r *bufio.Reader) (interface{}, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xe0 == 0xa0:
+		return readString(r, int(c&0x1f))
+	case c&0xf0 == 0x90:
+		return readArray(r, int(c&0x0f))
+	case c&0xf0 == 0x80:
+		return readMap(r, int(c&0x0f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		return readUint(r, 1<<(c-0xcc))
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		u, err := readUint(r, 1<<(c-0xd0))
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*(1<<(c-0xd0))
+		return int64(u<<shift) >> shift, nil
+	case 0xca:
+		u, err := readUint(r, 4)
+		return float64(math.Float32frombits(uint32(u))), err
+	case 0xcb:
+		u, err := readUint(r, 8)
+		return math.Float64frombits(u), err
+	case 0xd9, 0xda, 0xdb:
+		n, err := readUint(r, 1<<(c-0xd9))
+		if err != nil {
+			return nil, err
+		}
+		return readString(r, int(n))
+	case 0xdc, 0xdd:
+		n, err := readUint(r, 2<<(c-0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return readArray(r, int(n))
+	case 0xde, 0xdf:
+		n, err := readUint(r, 2<<(c-0xde))
+		if err != nil {
+			return nil, err
+		}
+		return readMap(r, int(n))
+	case 0xd7:
+		var b [9]byte
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return nil, err
+		}
+		if b[0] != eventTimeExt {
+			return nil, errUnsupported
+		}
+		sec := binary.BigEndian.Uint32(b[1:5])
+		nsec := binary.BigEndian.Uint32(b[5:])
+		return time.Unix(int64(sec), int64(nsec)), nil
+	}
+	return nil, errUnsupported
+}
+
+func readUint(r *bufio.Reader, size int) (uint64, error) {
+	var b [8]byte
+	if _, err := io.ReadFull(r, b[8-size:]); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(b[:]), nil
+}
+
+func readString(r *bufio.Reader, n int) (string, error) {
+	b := make([]byte, n)
+	_, err := io.ReadFull(r, b)
+	return string(b), err
+}
+
+func readArray(r *bufio.Reader, n int) ([]interface{}, error) {
+	a := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		v, err := readValue(r)
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+	return a, nil
+}
+
+func readMap(r *bufio.Reader, n int) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		k, err := readValue(r)
+		if err != nil {
+			return nil, err
+		}
+		v, err := readValue(r)
+		if err != nil {
+			return nil, err
+		}
+		m[fmt.Sprint(k)] = v
+	}
+	return m, nil
+}
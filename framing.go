@@ -0,0 +1,111 @@
+package zerolog
+
+import (
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// Framing selects how the events written by a FramedWriter are delimited.
+type Framing uint8
+
+const (
+	// FramingNewline terminates each event with a line feed, as in NDJSON.
+	// This is the default output of loggers.
+	FramingNewline Framing = iota
+
+	// FramingJSONSeq prefixes each event with a record separator (0x1E) and
+	// terminates it with a line feed, as in the application/json-seq format
+	// of RFC 7464.
+	FramingJSONSeq
+
+	// FramingLengthPrefix prefixes each event with its length as a 4 bytes
+	// big endian unsigned integer, without line feed.
+	FramingLengthPrefix
+)
+
+// recordSeparator is the RS character starting the RFC 7464 records.
+const recordSeparator = 0x1e
+
+var framePool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// FramedWriter is a LevelWriter delimiting the events it writes according to
+// its Framing, for consumers requiring application/json-seq or length
+// prefixed streams:
+//
+//	log := zerolog.New(zerolog.NewFramedWriter(conn, zerolog.FramingLengthPrefix))
+//
+// Each event is written with a single Write call to the wrapped writer,
+// framing included.
+type FramedWriter struct {
+	w       LevelWriter
+	framing Framing
+}
+
+// NewFramedWriter returns a FramedWriter writing to w the events delimited
+// by framing.
+func NewFramedWriter(w io.Writer, framing Framing) *FramedWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	return &FramedWriter{w: lw, framing: framing}
+}
+
+// Write implements the io.Writer interface.
+func (w *FramedWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The line feed ending p,
+// if any, is replaced by the framing.
+func (w *FramedWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	event := p
+	if len(event) > 0 && event[len(event)-1] == '\n' {
+		event = event[:len(event)-1]
+	}
+	bp := framePool.Get().(*[]byte)
+	defer func() {
+		if cap(*bp) <= 1<<16 {
+			framePool.Put(bp)
+		}
+	}()
+	buf := (*bp)[:0]
+	switch w.framing {
+	case FramingJSONSeq:
+		buf = append(buf, recordSeparator)
+		buf = append(buf, event...)
+		buf = append(buf, '\n')
+	case FramingLengthPrefix:
+		var size [4]byte
+		binary.BigEndian.PutUint32(size[:], uint32(len(event)))
+		buf = append(buf, size[:]...)
+		buf = append(buf, event...)
+	default:
+		buf = append(buf, event...)
+		buf = append(buf, '\n')
+	}
+	*bp = buf
+	if _, err = w.w.WriteLevel(level, buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the wrapped writer if it implements io.Closer.
+func (w *FramedWriter) Close() error {
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Flush flushes the wrapped writer.
+func (w *FramedWriter) Flush() error {
+	return flush(w.w)
+}
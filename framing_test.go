@@ -0,0 +1,48 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+func TestFramedWriter(t *testing.T) {
+	events := [][]byte{[]byte("{\"a\":1}\n"), []byte("{}")}
+	for _, tt := range []struct {
+		framing Framing
+		want    string
+	}{
+		{FramingNewline, "{\"a\":1}\n{}\n"},
+		{FramingJSONSeq, "\x1e{\"a\":1}\n\x1e{}\n"},
+		{FramingLengthPrefix, "\x00\x00\x00\x07{\"a\":1}\x00\x00\x00\x02{}"},
+	} {
+		out := &bytes.Buffer{}
+		w := NewFramedWriter(out, tt.framing)
+		for _, e := range events {
+			if n, err := w.Write(e); err != nil || n != len(e) {
+				t.Errorf("Write() = %d, %v", n, err)
+			}
+		}
+		if got := out.String(); got != tt.want {
+			t.Errorf("framing %d: got %q, want %q", tt.framing, got, tt.want)
+		}
+	}
+}
+
+func TestFramedWriterLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(NewFramedWriter(out, FramingLengthPrefix))
+	log.Info().Str("foo", "bar").Msg("hello")
+	log.Warn().Send()
+	var got []string
+	b := out.Bytes()
+	for len(b) >= 4 {
+		n := binary.BigEndian.Uint32(b)
+		got = append(got, strings.TrimSuffix(decodeIfBinaryToString(b[4:4+n]), "\n"))
+		b = b[4+n:]
+	}
+	if len(got) != 2 || got[0] != `{"level":"info","foo":"bar","message":"hello"}` || got[1] != `{"level":"warn"}` {
+		t.Errorf("invalid frames: %q", got)
+	}
+}
@@ -0,0 +1,192 @@
+package zerolog
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// GCP is a Transformer making the events follow the structured logging
+// format of Google Cloud Logging, so their level, trace, source location and
+// operation are recognized when written to stdout on Cloud Run, GKE or Cloud
+// Functions:
+//
+//	log := zerolog.New(os.Stdout).Transform(zerolog.GCP{ProjectID: "my-project"})
+//	log.Warn().Caller().Msg("slow")
+//	// Output: {"severity":"WARNING","message":"slow","logging.googleapis.com/sourceLocation":{"file":"main.go","line":"12"}}
+//
+// The LevelFieldName field is replaced by the severity field, with the
+// Cloud Logging names of the levels. The TimestampFieldName and
+// MessageFieldName fields are renamed time and message. The fields added by
+// TraceContextExtractor become the logging.googleapis.com/trace, spanId and
+// trace_sampled fields, the CallerFieldName and CallerFuncFieldName fields
+// the logging.googleapis.com/sourceLocation object and the fields of the
+// Progress events the logging.googleapis.com/operation object.
+//
+// Only the JSON encoding is supported, binary events are left untouched.
+type GCP struct {
+	// ProjectID is the Google Cloud project of the traces, used to build the
+	// trace resource name. The trace id is written as is if empty.
+	ProjectID string
+
+	// Producer, if not empty, is set as the producer of the operations.
+	Producer string
+}
+
+// gcpSeverity returns the Cloud Logging severity of level.
+func gcpSeverity(level Level) string {
+	switch level {
+	case TraceLevel, DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "CRITICAL"
+	case PanicLevel:
+		return "ALERT"
+	}
+	switch {
+	case level < TraceLevel:
+		return "DEBUG"
+	case level.AtLeast(NoLevel):
+		return "DEFAULT"
+	case level.AtLeast(PanicLevel):
+		return "ALERT"
+	case level.AtLeast(FatalLevel):
+		return "CRITICAL"
+	case level.AtLeast(ErrorLevel):
+		return "ERROR"
+	case level.AtLeast(WarnLevel):
+		return "WARNING"
+	case level.AtLeast(InfoLevel):
+		// Custom levels like NOTICE, between INFO and WARNING.
+		return "NOTICE"
+	}
+	return "DEBUG"
+}
+
+// Transform implements the Transformer interface.
+func (g GCP) Transform(level Level, p []byte) ([]byte, bool) {
+	if len(p) == 0 || p[0] != '{' {
+		return p, true
+	}
+	out := make([]byte, 0, len(p)+128)
+	out = append(out, `{"severity":"`...)
+	out = append(out, gcpSeverity(level)...)
+	out = append(out, '"')
+	var traceID, spanID, flags, caller, fn, opID string
+	var seq []byte
+	var done bool
+	for i := 1; i < len(p) && p[i] == '"'; {
+		keyEnd := skipString(p, i)
+		start := keyEnd + 1 // after the colon
+		end := skipValue(p, start)
+		key := ecsKey(p[i:keyEnd])
+		value := p[start:end]
+		if i = end; i < len(p) && p[i] == ',' {
+			i++
+		}
+		switch key {
+		case LevelFieldName:
+			continue
+		case TraceIDFieldName:
+			traceID = gcpString(value)
+			continue
+		case SpanIDFieldName:
+			spanID = gcpString(value)
+			continue
+		case TraceFlagsFieldName:
+			flags = gcpString(value)
+			continue
+		case CallerFieldName:
+			caller = gcpString(value)
+			continue
+		case ProgressIDFieldName:
+			opID = gcpString(value)
+			continue
+		case ProgressSeqFieldName:
+			seq = value
+			continue
+		case ProgressDoneFieldName:
+			done = string(value) == "true"
+			continue
+		case "":
+			// kept as is, CallerFuncFieldName is empty when disabled
+		case CallerFuncFieldName:
+			fn = gcpString(value)
+			continue
+		case TimestampFieldName:
+			key = "time"
+		case MessageFieldName:
+			key = "message"
+		}
+		out = append(out, ',')
+		out = append(out, appendJSONString(key)...)
+		out = append(out, ':')
+		out = append(out, value...)
+	}
+
+	if traceID != "" {
+		if g.ProjectID != "" {
+			traceID = "projects/" + g.ProjectID + "/traces/" + traceID
+		}
+		out = append(out, `,"logging.googleapis.com/trace":`...)
+		out = append(out, appendJSONString(traceID)...)
+		if spanID != "" {
+			out = append(out, `,"logging.googleapis.com/spanId":`...)
+			out = append(out, appendJSONString(spanID)...)
+		}
+		if f, err := strconv.ParseUint(flags, 16, 8); err == nil {
+			out = append(out, `,"logging.googleapis.com/trace_sampled":`...)
+			out = strconv.AppendBool(out, f&1 == 1)
+		}
+	}
+	if caller != "" || fn != "" {
+		out = append(out, `,"logging.googleapis.com/sourceLocation":{`...)
+		file, line := caller, ""
+		if i := strings.LastIndexByte(caller, ':'); i >= 0 {
+			file, line = caller[:i], caller[i+1:]
+		}
+		out = append(out, `"file":`...)
+		out = append(out, appendJSONString(file)...)
+		if line != "" {
+			out = append(out, `,"line":`...)
+			out = append(out, appendJSONString(line)...)
+		}
+		if fn != "" {
+			out = append(out, `,"function":`...)
+			out = append(out, appendJSONString(fn)...)
+		}
+		out = append(out, '}')
+	}
+	if opID != "" {
+		out = append(out, `,"logging.googleapis.com/operation":{"id":`...)
+		out = append(out, appendJSONString(opID)...)
+		if g.Producer != "" {
+			out = append(out, `,"producer":`...)
+			out = append(out, appendJSONString(g.Producer)...)
+		}
+		if string(seq) == "1" {
+			out = append(out, `,"first":true`...)
+		}
+		if done {
+			out = append(out, `,"last":true`...)
+		}
+		out = append(out, '}')
+	}
+	out = append(out, '}')
+	return append(out, p[bytes.LastIndexByte(p, '}')+1:]...), true
+}
+
+// gcpString returns the string held by the encoded value, or the encoded
+// value itself if not a string.
+func gcpString(value []byte) string {
+	if len(value) > 0 && value[0] == '"' {
+		return ecsKey(value)
+	}
+	return string(value)
+}
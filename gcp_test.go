@@ -0,0 +1,35 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGCP(t *testing.T) {
+	defer func(f func(ctx context.Context) (TraceContext, bool)) { TraceContextExtractor = f }(TraceContextExtractor)
+	TraceContextExtractor = func(ctx context.Context) (TraceContext, bool) {
+		return TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Flags: 1}, true
+	}
+	defer func(f func(pc uintptr, file string, line int) string) { CallerMarshalFunc = f }(CallerMarshalFunc)
+	CallerMarshalFunc = func(pc uintptr, file string, line int) string { return "main.go:12" }
+
+	out := &bytes.Buffer{}
+	log := New(out).Transform(GCP{ProjectID: "my-project", Producer: "importer"})
+	log.Warn().Caller().Time("time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)).Int("n", 1).Msg("slow")
+	log.Log().Ctx(context.Background()).Msg("traced")
+	p := log.Progress(InfoLevel, "op-1")
+	p.Tick().Msg("start")
+	p.Done().Msg("end")
+	want := `{"severity":"WARNING","time":"2024-01-02T03:04:05Z","n":1,"message":"slow","logging.googleapis.com/sourceLocation":{"file":"main.go","line":"12"}}` + "\n" +
+		`{"severity":"DEFAULT","message":"traced","logging.googleapis.com/trace":"projects/my-project/traces/4bf92f3577b34da6a3ce929d0e0e4736","logging.googleapis.com/spanId":"00f067aa0ba902b7","logging.googleapis.com/trace_sampled":true}` + "\n" +
+		`{"severity":"INFO","message":"start","logging.googleapis.com/operation":{"id":"op-1","producer":"importer","first":true}}` + "\n" +
+		`{"severity":"INFO","message":"end","logging.googleapis.com/operation":{"id":"op-1","producer":"importer","last":true}}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
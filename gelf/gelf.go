@@ -0,0 +1,376 @@
+// Package gelf provides a GELF 1.1 encoder and a writer sending zerolog
+// events to Graylog over UDP or TCP.
+//
+//	w, err := gelf.NewWriter(gelf.Options{
+//	    Address:     "graylog.internal:12201",
+//	    Compression: gelf.CompressGzip,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// The message field becomes short_message, the level the matching syslog
+// level and the timestamp field the GELF timestamp. The other top level
+// fields are sent as additional fields, prefixed by an underscore.
+package gelf
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+// Compression is the compression of the UDP messages.
+type Compression int
+
+const (
+	// CompressNone sends messages uncompressed.
+	CompressNone Compression = iota
+	// CompressGzip compresses messages with gzip.
+	CompressGzip
+	// CompressZlib compresses messages with zlib.
+	CompressZlib
+)
+
+const (
+	// DefaultChunkSize is the default maximum size of UDP datagrams, fitting
+	// in the usual 1500 bytes Ethernet MTU.
+	DefaultChunkSize = 1420
+
+	chunkHeaderSize = 12
+	maxChunks       = 128
+)
+
+// ErrTooLarge is returned by Write when a message does not fit in the
+// maximum number of UDP chunks.
+var ErrTooLarge = errors.New("gelf: message too large")
+
+// Encoder converts zerolog events to GELF 1.1 messages.
+type Encoder struct {
+	// Host is the host field of the messages. Defaults to os.Hostname.
+	Host string
+}
+
+// Encode appends the GELF message of the event p to dst. If level is
+// NoLevel, it is read from the event.
+func (e Encoder) Encode(dst []byte, level zerolog.Level, p []byte) ([]byte, error) {
+	evt, err := zerolog.DecodeEvent(p)
+	if err != nil {
+		return dst, fmt.Errorf("gelf: %v", err)
+	}
+	if level == zerolog.NoLevel {
+		s, _ := evt[zerolog.LevelFieldName].(string)
+		level, _ = zerolog.ParseLevel(s)
+	}
+	host := e.Host
+	if host == "" {
+		host, _ = os.Hostname()
+	}
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	short, full := msg, ""
+	if i := strings.IndexByte(msg, '\n'); i >= 0 {
+		short, full = msg[:i], msg
+	}
+	if short == "" {
+		short = "-" // short_message is required to be non empty
+	}
+
+	dst = append(dst, `{"version":"1.1","host":`...)
+	dst = appendJSON(dst, host)
+	dst = append(dst, `,"short_message":`...)
+	dst = appendJSON(dst, short)
+	if full != "" {
+		dst = append(dst, `,"full_message":`...)
+		dst = appendJSON(dst, full)
+	}
+	dst = append(dst, `,"timestamp":`...)
+	dst = strconv.AppendFloat(dst, float64(timestamp(evt[zerolog.TimestampFieldName]).UnixNano()/1e6)/1e3, 'f', -1, 64)
+	dst = append(dst, `,"level":`...)
+	dst = strconv.AppendInt(dst, int64(syslogLevel(level)), 10)
+
+	keys := make([]string, 0, len(evt))
+	for key := range evt {
+		switch key {
+		case zerolog.MessageFieldName, zerolog.LevelFieldName, zerolog.TimestampFieldName:
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := evt[key]
+		if v == nil {
+			continue
+		}
+		dst = append(dst, ',')
+		dst = appendJSON(dst, fieldName(key))
+		dst = append(dst, ':')
+		switch v := v.(type) {
+		case string:
+			dst = appendJSON(dst, v)
+		case json.Number:
+			dst = append(dst, v...)
+		default:
+			b, err := zerolog.InterfaceMarshalFunc(v)
+			if err != nil {
+				b = []byte(fmt.Sprintf("[error: %v]", err))
+			}
+			dst = appendJSON(dst, string(b))
+		}
+	}
+	return append(dst, '}'), nil
+}
+
+// fieldName returns the name of the additional field for key: key prefixed
+// by an underscore, its characters other than letters, digits, underscores,
+// dashes and dots replaced by underscores. The reserved _id becomes __id.
+func fieldName(key string) string {
+	b := make([]byte, 1, len(key)+2)
+	b[0] = '_'
+	if key == "id" {
+		b = append(b, '_')
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.') {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return string(b)
+}
+
+// timestamp returns the time of the timestamp field value v, as formatted
+// according to zerolog.TimeFieldFormat, or the current time.
+func timestamp(v interface{}) time.Time {
+	switch v := v.(type) {
+	case string:
+		if t, err := time.Parse(zerolog.TimeFieldFormat, v); err == nil {
+			return t
+		}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			break
+		}
+		switch zerolog.TimeFieldFormat {
+		case zerolog.TimeFormatUnixMs:
+			f /= 1e3
+		case zerolog.TimeFormatUnixMicro:
+			f /= 1e6
+		case zerolog.TimeFormatUnixNano:
+			f /= 1e9
+		}
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9))
+	}
+	return time.Now()
+}
+
+// syslogLevel returns the syslog severity of level.
+func syslogLevel(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return 7
+	case zerolog.InfoLevel:
+		return 6
+	case zerolog.WarnLevel:
+		return 4
+	case zerolog.ErrorLevel:
+		return 3
+	case zerolog.FatalLevel:
+		return 2
+	case zerolog.PanicLevel:
+		return 0
+	}
+	if level < zerolog.TraceLevel {
+		return 7
+	}
+	return 5
+}
+
+func appendJSON(dst []byte, s string) []byte {
+	b, _ := json.Marshal(s)
+	return append(dst, b...)
+}
+
+// Options configures a Writer.
+type Options struct {
+	// Network is "udp" (default) or "tcp".
+	Network string
+
+	// Address is the address of the Graylog GELF input, like
+	// "localhost:12201".
+	Address string
+
+	// Host is the host field of the messages. Defaults to os.Hostname.
+	Host string
+
+	// Compression is the compression of UDP messages. GELF over TCP does not
+	// support compression.
+	Compression Compression
+
+	// ChunkSize is the maximum size of UDP datagrams. Larger messages are
+	// split in up to 128 chunks. Defaults to DefaultChunkSize.
+	ChunkSize int
+}
+
+// Writer is a zerolog.LevelWriter sending events to a Graylog GELF input.
+type Writer struct {
+	enc  Encoder
+	opts Options
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewWriter returns a Writer connected to the GELF input of opts.
+func NewWriter(opts Options) (*Writer, error) {
+	if opts.Address == "" {
+		return nil, errors.New("gelf: missing address")
+	}
+	switch opts.Network {
+	case "":
+		opts.Network = "udp"
+	case "udp":
+	case "tcp":
+		if opts.Compression != CompressNone {
+			return nil, errors.New("gelf: compression is not supported over tcp")
+		}
+	default:
+		return nil, fmt.Errorf("gelf: unsupported network %q", opts.Network)
+	}
+	if opts.ChunkSize <= chunkHeaderSize {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	if opts.Host == "" {
+		opts.Host, _ = os.Hostname()
+	}
+	w := &Writer{enc: Encoder{Host: opts.Host}, opts: opts}
+	var err error
+	if w.conn, err = net.Dial(opts.Network, opts.Address); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write implements io.Writer. The level is read from the event.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	msg, err := w.enc.Encode(nil, level, p)
+	if err != nil {
+		return 0, err
+	}
+	if w.opts.Network == "tcp" {
+		msg = append(msg, 0)
+	} else if msg, err = compress(msg, w.opts.Compression); err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if w.conn, err = net.Dial(w.opts.Network, w.opts.Address); err != nil {
+				continue
+			}
+		}
+		if w.opts.Network == "tcp" {
+			_, err = w.conn.Write(msg)
+		} else {
+			err = w.writeChunked(msg)
+		}
+		if err == nil {
+			return len(p), nil
+		}
+		if err == ErrTooLarge {
+			return 0, err
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return 0, err
+}
+
+// writeChunked sends msg as a single datagram, or split in chunks if it
+// exceeds the chunk size.
+func (w *Writer) writeChunked(msg []byte) error {
+	if len(msg) <= w.opts.ChunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+	size := w.opts.ChunkSize - chunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > maxChunks {
+		return ErrTooLarge
+	}
+	chunk := make([]byte, chunkHeaderSize, w.opts.ChunkSize)
+	chunk[0], chunk[1] = 0x1e, 0x0f
+	if _, err := rand.Read(chunk[2:10]); err != nil {
+		return err
+	}
+	chunk[11] = byte(count)
+	for i := 0; i < count; i++ {
+		chunk[10] = byte(i)
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		if _, err := w.conn.Write(append(chunk[:chunkHeaderSize], msg[i*size:end]...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the connection.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func compress(msg []byte, c Compression) ([]byte, error) {
+	var buf bytes.Buffer
+	switch c {
+	case CompressGzip:
+		zw := gzip.NewWriter(&buf)
+		zw.Write(msg)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	case CompressZlib:
+		zw := zlib.NewWriter(&buf)
+		zw.Write(msg)
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return msg, nil
+	}
+	return buf.Bytes(), nil
+}
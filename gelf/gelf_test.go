@@ -0,0 +1,161 @@
+//go:build !binary_log
+// +build !binary_log
+
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+func TestEncoder(t *testing.T) {
+	enc := Encoder{Host: "web-1"}
+	for _, tt := range []struct {
+		level zerolog.Level
+		in    string
+		want  string
+	}{
+		{
+			zerolog.NoLevel,
+			`{"level":"warn","time":"2023-11-14T22:13:20Z","id":"x","http.method":"GET","n":1.5,"ok":true,"req":{"a":1},"null":null,"message":"hello"}`,
+			`{"version":"1.1","host":"web-1","short_message":"hello","timestamp":1700000000,"level":4,"_http.method":"GET","__id":"x","_n":1.5,"_ok":"true","_req":"{\"a\":1}"}`,
+		},
+		{
+			zerolog.ErrorLevel,
+			`{"time":"2023-11-14T22:13:20Z","message":"failed\nstack"}`,
+			`{"version":"1.1","host":"web-1","short_message":"failed","full_message":"failed\nstack","timestamp":1700000000,"level":3}`,
+		},
+		{
+			zerolog.InfoLevel,
+			`{"time":"2023-11-14T22:13:20.123456Z","a b":"c"}`,
+			`{"version":"1.1","host":"web-1","short_message":"-","timestamp":1700000000.123,"level":6,"_a_b":"c"}`,
+		},
+	} {
+		got, err := enc.Encode(nil, tt.level, []byte(tt.in))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("Encode(%s):\ngot:  %s\nwant: %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriterUDPChunked(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer pc.Close()
+	w, err := NewWriter(Options{Address: pc.LocalAddr().String(), Host: "h", Compression: CompressGzip, ChunkSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := zerolog.New(w)
+	big := strings.Repeat("abcdefghij", 200)
+	log.Info().Str("data", big).Msg("hello")
+
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var chunks [][]byte
+	buf := make([]byte, 2048)
+	for count := -1; count != len(chunks); {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunk := append([]byte(nil), buf[:n]...)
+		if n > 100 || chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("invalid chunk: %q", chunk)
+		}
+		count = int(chunk[11])
+		chunks = append(chunks, chunk)
+	}
+	var msg []byte
+	for i, chunk := range chunks {
+		if int(chunk[10]) != i || !bytes.Equal(chunk[2:10], chunks[0][2:10]) {
+			t.Fatalf("invalid chunk header %d: %x", i, chunk[:12])
+		}
+		msg = append(msg, chunk[12:]...)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["short_message"] != "hello" || got["_data"] != big || got["level"] != 6.0 {
+		t.Errorf("invalid message: %s", b)
+	}
+}
+
+func TestWriterTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("cannot listen: %v", err)
+	}
+	defer l.Close()
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			msg, err := r.ReadString(0)
+			if err != nil {
+				return
+			}
+			msgs <- msg
+		}
+	}()
+	w, err := NewWriter(Options{Network: "tcp", Address: l.Addr().String(), Host: "h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := zerolog.New(w)
+	log.Warn().Msg("one")
+	log.Error().Msg("two")
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-msgs:
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSuffix(msg, "\x00")), &m); err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, m["short_message"].(string))
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout")
+		}
+	}
+	if want := []string{"one", "two"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNewWriterTCPCompression(t *testing.T) {
+	if _, err := NewWriter(Options{Network: "tcp", Address: "127.0.0.1:1", Compression: CompressGzip}); err == nil {
+		t.Error("expected an error")
+	}
+}
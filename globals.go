@@ -2,6 +2,7 @@ package zerolog
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"strconv"
 	"sync/atomic"
@@ -48,6 +49,11 @@ var (
 	// LevelPanicValue is the value used for the panic level field.
 	LevelPanicValue = "panic"
 
+	// TraceLevelNames, if true, marshals the levels finer than TraceLevel as
+	// LevelTraceValue followed by their depth, like "trace2", instead of
+	// numbers. See TraceLevelN.
+	TraceLevelNames = false
+
 	// LevelFieldMarshalFunc allows customization of global level field marshaling.
 	LevelFieldMarshalFunc = func(l Level) string {
 		return l.String()
@@ -62,6 +68,23 @@ var (
 	// CallerFieldName is the field name used for caller field.
 	CallerFieldName = "caller"
 
+	// BuildVersionFieldName is the field name used for the module version
+	// added by Context.BuildInfo.
+	BuildVersionFieldName = "version"
+
+	// BuildRevisionFieldName is the field name used for the VCS revision added
+	// by Context.BuildInfo.
+	BuildRevisionFieldName = "revision"
+
+	// BuildDirtyFieldName is the field name used for the VCS dirty flag added
+	// by Context.BuildInfo.
+	BuildDirtyFieldName = "dirty"
+
+	// CallerFuncFieldName is the field name used for the short name of the
+	// caller's function, added along with the caller field. The function name
+	// is not added when empty, which is the default.
+	CallerFuncFieldName = ""
+
 	// CallerSkipFrameCount is the number of stack frames to skip to find the caller.
 	CallerSkipFrameCount = 2
 
@@ -70,6 +93,89 @@ var (
 		return file + ":" + strconv.Itoa(line)
 	}
 
+	// CallerFuncMarshalFunc allows customization of global caller function
+	// marshaling. By default, the function name is stripped of its package
+	// path, like "zerolog.(*Logger).Info".
+	CallerFuncMarshalFunc = func(pc uintptr) string {
+		return shortFuncName(pc)
+	}
+
+	// MarkMonotonicFieldName is the field name used for the monotonic
+	// nanoseconds added by Logger.Mark.
+	MarkMonotonicFieldName = "mono_ns"
+
+	// MarkGoroutineFieldName is the field name used for the goroutine id added
+	// by Logger.Mark.
+	MarkGoroutineFieldName = "goroutine"
+
+	// EventHashFieldName is the field name used for the hash added by
+	// Event.ContentHash.
+	EventHashFieldName = "event_hash"
+
+	// ProgressIDFieldName is the field name used for the operation id of the
+	// events of a Progress.
+	ProgressIDFieldName = "operation_id"
+
+	// ProgressSeqFieldName is the field name used for the sequence number of
+	// the events of a Progress.
+	ProgressSeqFieldName = "seq"
+
+	// ProgressDoneFieldName is the field name used to flag the final event of
+	// a Progress.
+	ProgressDoneFieldName = "done"
+
+	// RuntimeStatsFieldName is the field name used for the runtime statistics
+	// added by Event.RuntimeStats.
+	RuntimeStatsFieldName = "runtime"
+
+	// ErrorKindFieldName is the field name used for the error kind derived
+	// by ErrorClassifier.
+	ErrorKindFieldName = "error_kind"
+
+	// ErrorRetryableFieldName is the field name used for the retryable flag
+	// derived by ErrorClassifier.
+	ErrorRetryableFieldName = "retryable"
+
+	// ErrorHTTPStatusFieldName is the field name used for the HTTP status
+	// derived by ErrorClassifier.
+	ErrorHTTPStatusFieldName = "http_status"
+
+	// ErrorGRPCCodeFieldName is the field name used for the gRPC code derived
+	// by ErrorClassifier.
+	ErrorGRPCCodeFieldName = "grpc_code"
+
+	// TraceIDFieldName is the field name used for the trace id added by
+	// TraceContextExtractor.
+	TraceIDFieldName = "trace_id"
+
+	// SpanIDFieldName is the field name used for the span id added by
+	// TraceContextExtractor.
+	SpanIDFieldName = "span_id"
+
+	// TraceFlagsFieldName is the field name used for the trace flags added by
+	// TraceContextExtractor.
+	TraceFlagsFieldName = "trace_flags"
+
+	// ComponentFieldName is the field name used for the name of the
+	// component of the loggers returned by LogComponent.Logger.
+	ComponentFieldName = "component"
+
+	// CmdDurationFieldName is the field name used for the duration of the
+	// commands run by Logger.RunCmd.
+	CmdDurationFieldName = "duration"
+
+	// CmdExitCodeFieldName is the field name used for the exit code of the
+	// commands run by Logger.RunCmd.
+	CmdExitCodeFieldName = "exit_code"
+
+	// RetentionFieldName is the field name used for the retention hints added
+	// by Event.Retention.
+	RetentionFieldName = "retention"
+
+	// DualWriteIDFieldName is the field name used for the write id added by
+	// DualWriteWriter.
+	DualWriteIDFieldName = "write_id"
+
 	// ErrorStackFieldName is the field name used for error stacks.
 	ErrorStackFieldName = "stack"
 
@@ -115,11 +221,26 @@ var (
 	// set to true.
 	DurationFieldInteger = false
 
+	// DurationBucketFieldSuffix is appended to the key of DurBucket fields to
+	// build the key of the bucket label field.
+	DurationBucketFieldSuffix = "_bucket"
+
+	// NormalizeMessageNewlines, if true, converts the CRLF and CR line breaks
+	// of messages to LF and trims their trailing line breaks, so multiline
+	// messages are encoded consistently whatever their origin.
+	NormalizeMessageNewlines = false
+
 	// ErrorHandler is called whenever zerolog fails to write an event on its
 	// output. If not set, an error is printed on the stderr. This handler must
 	// be thread safe and non-blocking.
 	ErrorHandler func(err error)
 
+	// TraceContextExtractor, if set, returns the trace context of the span
+	// carried by a context.Context, and whether there is one. The trace
+	// context of the event's context, attached with Event.Ctx, Context.Ctx or
+	// Logger.WithContext, is then added to each event. See TraceContext.
+	TraceContextExtractor func(ctx context.Context) (TraceContext, bool)
+
 	// DefaultContextLogger is returned from Ctx() if there is no logger associated
 	// with the context.
 	DefaultContextLogger *Logger
@@ -156,6 +277,22 @@ var (
 	// of digits when formatting float numbers in JSON. See strconv.FormatFloat for
 	// more details.
 	FloatingPointPrecision = -1
+
+	// LargeIntAsString, if set to true, renders integer fields and integer
+	// slice elements whose value can't be exactly represented by a IEEE 754
+	// double (absolute value above 2^53) as strings. JavaScript consumers and
+	// some JSON parsers silently corrupt such values, like snowflake IDs, when
+	// logged as numbers.
+	LargeIntAsString = false
+
+	// AutoDeDup is the duplicate fields removal applied to the events of the
+	// loggers for which Logger.AutoDeDup wasn't called, so call sites don't
+	// have to call DeDup. Each event is then scanned for duplicate top level
+	// keys before being written, making small events without duplicates about
+	// 1.5 times slower to log. Events having duplicates also pay for the
+	// removal, several times the cost of the event with DeDupModeDeep: see
+	// BenchmarkGlobalAutoDeDup. It must be set before logging.
+	AutoDeDup = DeDupModeNone
 )
 
 var (
@@ -5,6 +5,7 @@ go 1.15
 require (
 	github.com/coreos/go-systemd/v22 v22.5.0
 	github.com/mattn/go-colorable v0.1.13
+	github.com/mattn/go-isatty v0.0.16
 	github.com/pkg/errors v0.9.1
 	github.com/rs/xid v1.6.0
 )
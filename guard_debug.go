@@ -0,0 +1,29 @@
+//go:build zerolog_debug
+// +build zerolog_debug
+
+package zerolog
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+)
+
+// eventGuard is true when building with the zerolog_debug tag: sent events
+// are not recycled and their reuse panics.
+const eventGuard = true
+
+// guardUse panics if e has already been sent.
+func (e *Event) guardUse(op string) {
+	if e.sentBy != "" {
+		panic(fmt.Sprintf("zerolog: %s called on an event already sent by %s", op, e.sentBy))
+	}
+}
+
+// guardSent records op and its call site as the sender of e.
+func (e *Event) guardSent(op string) {
+	e.sentBy = op
+	if _, file, line, ok := runtime.Caller(2); ok {
+		e.sentBy += " at " + file + ":" + strconv.Itoa(line)
+	}
+}
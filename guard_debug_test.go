@@ -0,0 +1,50 @@
+//go:build zerolog_debug
+// +build zerolog_debug
+
+package zerolog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEventGuard(t *testing.T) {
+	log := New(&bytes.Buffer{})
+	tests := map[string]func(e *Event){
+		"Msg":     func(e *Event) { e.Msg("again") },
+		"Send":    func(e *Event) { e.Send() },
+		"Msgf":    func(e *Event) { e.Msgf("%s", "again") },
+		"Discard": func(e *Event) { e.Discard() },
+		"Str":     func(e *Event) { e.Str("foo", "bar") },
+		"Int":     func(e *Event) { e.Int("n", 1) },
+		"Dict":    func(e *Event) { e.Dict("d", Dict()) },
+		"Err":     func(e *Event) { e.Err(nil) },
+		"Caller":  func(e *Event) { e.Caller() },
+	}
+	for name, reuse := range tests {
+		t.Run(name, func(t *testing.T) {
+			e := log.Info()
+			e.Msgf("first")
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, "already sent by Msgf at") || !strings.Contains(msg, "guard_debug_test.go") {
+					t.Errorf("unexpected panic: %q", msg)
+				}
+			}()
+			reuse(e)
+		})
+	}
+
+	t.Run("discarded", func(t *testing.T) {
+		e := log.Info()
+		e.Discard()
+		defer func() {
+			msg, _ := recover().(string)
+			if !strings.Contains(msg, "Msg called on an event already sent by Discard at") {
+				t.Errorf("unexpected panic: %q", msg)
+			}
+		}()
+		e.Msg("discarded")
+	})
+}
@@ -0,0 +1,27 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+)
+
+// contentHash returns the hex encoded 64-bit FNV-1a hash of msg and of the
+// values of the keys fields found in the unterminated event buf. Values are
+// hashed in their JSON encoding, which zerolog produces deterministically.
+func contentHash(buf []byte, keys []string, msg string) string {
+	h := fnv.New64a()
+	if len(keys) > 0 {
+		obj := enc.AppendEndMarker(append(make([]byte, 0, len(buf)+1), buf...))
+		var fields map[string]json.RawMessage
+		_ = json.Unmarshal(decodeIfBinaryToBytes(obj), &fields)
+		for _, key := range keys {
+			h.Write([]byte(key))
+			h.Write([]byte{0})
+			h.Write(fields[key])
+			h.Write([]byte{0})
+		}
+	}
+	h.Write([]byte(msg))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
@@ -0,0 +1,70 @@
+package hlog
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/treavorj/zerolog"
+)
+
+// budget is a zerolog.Transformer dropping the events exceeding a number of
+// events or bytes.
+type budget struct {
+	maxEvents, maxBytes int
+
+	mu            sync.Mutex
+	events, bytes int
+	droppedEvents int
+	droppedBytes  int
+	spent         bool
+}
+
+// Transform implements zerolog.Transformer.
+func (b *budget) Transform(level zerolog.Level, p []byte) ([]byte, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.spent {
+		return p, true
+	}
+	if (b.maxEvents > 0 && b.events+1 > b.maxEvents) || (b.maxBytes > 0 && b.bytes+len(p) > b.maxBytes) {
+		b.droppedEvents++
+		b.droppedBytes += len(p)
+		return nil, false
+	}
+	b.events++
+	b.bytes += len(p)
+	return p, true
+}
+
+// close stops enforcing the budget and returns the dropped events and bytes.
+func (b *budget) close() (events, bytes int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent = true
+	return b.droppedEvents, b.droppedBytes
+}
+
+// BudgetHandler caps the number of events and bytes the context's logger may
+// emit while handling a request, protecting the logging pipeline from
+// handlers logging in a loop. A zero limit is not enforced. The events
+// exceeding the budget are dropped and, once the request is handled, a
+// warning event summarizes them with the dropped_events and dropped_bytes
+// fields.
+//
+// The handler must be installed after NewHandler.
+func BudgetHandler(maxEvents, maxBytes int) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b := &budget{maxEvents: maxEvents, maxBytes: maxBytes}
+			l := zerolog.Ctx(r.Context()).Transform(b)
+			r = r.WithContext(l.WithContext(r.Context()))
+			next.ServeHTTP(w, r)
+			if events, bytes := b.close(); events > 0 {
+				l.Warn().
+					Int("dropped_events", events).
+					Int("dropped_bytes", bytes).
+					Msg("request log budget exceeded")
+			}
+		})
+	}
+}
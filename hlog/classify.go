@@ -0,0 +1,139 @@
+package hlog
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/treavorj/zerolog"
+)
+
+// Request classes set by the default classification.
+const (
+	ClassPreflight   = "preflight"
+	ClassGRPCWeb     = "grpc_web"
+	ClassHealthCheck = "health_check"
+	ClassStatic      = "static"
+	ClassAPI         = "api"
+)
+
+// ClassMatcher assigns Class to the requests matched by Match.
+type ClassMatcher struct {
+	Class string
+	Match func(r *http.Request) bool
+}
+
+// DefaultClassMatchers are the matchers used by RequestClassHandler when none
+// are given. Requests not matched by any are of class ClassAPI.
+var DefaultClassMatchers = []ClassMatcher{
+	{Class: ClassPreflight, Match: IsPreflight},
+	{Class: ClassGRPCWeb, Match: IsGRPCWeb},
+	{Class: ClassHealthCheck, Match: IsHealthCheck},
+	{Class: ClassStatic, Match: IsStaticAsset},
+}
+
+// HealthCheckPaths are the request paths considered health checks by
+// IsHealthCheck.
+var HealthCheckPaths = []string{"/health", "/healthz", "/livez", "/readyz", "/ping"}
+
+// healthCheckAgents are the user agent prefixes of common health checkers.
+var healthCheckAgents = []string{"kube-probe/", "ELB-HealthChecker/", "GoogleHC/", "Consul Health Check"}
+
+// StaticAssetExts are the extensions of the paths considered static assets by
+// IsStaticAsset.
+var StaticAssetExts = map[string]bool{
+	".css": true, ".js": true, ".map": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".eot": true,
+}
+
+// IsPreflight returns true if r is a CORS preflight request.
+func IsPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// IsGRPCWeb returns true if r is a gRPC-Web call.
+func IsGRPCWeb(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc-web")
+}
+
+// IsHealthCheck returns true if the path of r is one of HealthCheckPaths, or
+// if r comes from a well known health checker, like the Kubernetes probes.
+func IsHealthCheck(r *http.Request) bool {
+	for _, p := range HealthCheckPaths {
+		if r.URL.Path == p {
+			return true
+		}
+	}
+	ua := r.UserAgent()
+	for _, prefix := range healthCheckAgents {
+		if strings.HasPrefix(ua, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaticAsset returns true if r is a GET or HEAD request of a path which
+// extension is in StaticAssetExts.
+func IsStaticAsset(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	return StaticAssetExts[strings.ToLower(path.Ext(r.URL.Path))]
+}
+
+// PathPrefixClass returns a matcher assigning class to the requests which
+// path starts with one of prefixes.
+func PathPrefixClass(class string, prefixes ...string) ClassMatcher {
+	return ClassMatcher{
+		Class: class,
+		Match: func(r *http.Request) bool {
+			for _, p := range prefixes {
+				if strings.HasPrefix(r.URL.Path, p) {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// ClassifyRequest returns the class of the first of matchers matching r, or
+// ClassAPI if none does.
+func ClassifyRequest(r *http.Request, matchers []ClassMatcher) string {
+	for _, m := range matchers {
+		if m.Match(r) {
+			return m.Class
+		}
+	}
+	return ClassAPI
+}
+
+// RequestClassHandler adds the class of the request, like preflight,
+// health_check, static or api, as a field to the context's logger using
+// fieldKey as field key, conventionally "request_class". This lets dashboards
+// separate noise from meaningful traffic. Matchers are tried in order, and
+// default to DefaultClassMatchers:
+//
+//	h = hlog.RequestClassHandler("request_class",
+//	    hlog.PathPrefixClass("admin", "/admin/"),
+//	    hlog.ClassMatcher{Class: hlog.ClassPreflight, Match: hlog.IsPreflight},
+//	)(h)
+func RequestClassHandler(fieldKey string, matchers ...ClassMatcher) func(next http.Handler) http.Handler {
+	if len(matchers) == 0 {
+		matchers = DefaultClassMatchers
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			class := ClassifyRequest(r, matchers)
+			log := zerolog.Ctx(r.Context())
+			log.UpdateContext(func(c zerolog.Context) zerolog.Context {
+				return c.Str(fieldKey, class)
+			})
+			next.ServeHTTP(w, r)
+		})
+	}
+}
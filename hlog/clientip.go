@@ -0,0 +1,178 @@
+package hlog
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/treavorj/zerolog"
+)
+
+// Headers understood by ClientIPConfig to resolve the client IP.
+const (
+	// HeaderXForwardedFor is the de-facto standard proxy header, also used by
+	// AWS load balancers (ALB/ELB).
+	HeaderXForwardedFor = "X-Forwarded-For"
+	// HeaderXRealIP is the header set by nginx's realip module.
+	HeaderXRealIP = "X-Real-Ip"
+	// HeaderForwarded is the RFC 7239 Forwarded header.
+	HeaderForwarded = "Forwarded"
+	// HeaderCFConnectingIP is the header set by Cloudflare.
+	HeaderCFConnectingIP = "Cf-Connecting-Ip"
+	// HeaderTrueClientIP is the header set by Cloudflare Enterprise and Akamai.
+	HeaderTrueClientIP = "True-Client-Ip"
+)
+
+// ClientIPConfig configures how the client IP of a request is resolved when
+// the server sits behind proxies.
+type ClientIPConfig struct {
+	// PeerFieldKey is the field key used for the IP of the direct peer
+	// (RemoteAddr). If empty, the peer IP is not logged.
+	PeerFieldKey string
+
+	// ClientFieldKey is the field key used for the resolved client IP. If
+	// empty, the client IP is not logged.
+	ClientFieldKey string
+
+	// TrustedProxies lists the networks of the proxies allowed to set the
+	// client IP through Headers. Headers are ignored unless the direct peer
+	// is part of one of these networks, as they are otherwise trivially
+	// spoofed. See ParseCIDRs.
+	TrustedProxies []*net.IPNet
+
+	// Headers lists the headers used to resolve the client IP, in order of
+	// preference. The first header yielding a valid IP wins. For list headers
+	// (X-Forwarded-For and Forwarded), the rightmost address not belonging to
+	// a trusted proxy is used.
+	Headers []string
+}
+
+// ParseCIDRs parses a list of CIDR notation networks, like "10.0.0.0/8",
+// to be used as ClientIPConfig.TrustedProxies.
+func ParseCIDRs(cidrs ...string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// ClientIP returns the IP of the direct peer of r and the resolved client IP.
+// Both are nil if RemoteAddr can't be parsed. The client IP is the peer IP if
+// no trusted header could resolve it.
+func (cfg ClientIPConfig) ClientIP(r *http.Request) (peer, client net.IP) {
+	peer = parseIP(r.RemoteAddr)
+	if peer == nil || !cfg.trusted(peer) {
+		return peer, peer
+	}
+	for _, h := range cfg.Headers {
+		var ip net.IP
+		switch http.CanonicalHeaderKey(h) {
+		case HeaderXForwardedFor:
+			ip = cfg.rightmostUntrusted(splitList(r.Header.Values(HeaderXForwardedFor)))
+		case HeaderForwarded:
+			ip = cfg.rightmostUntrusted(forwardedFor(r.Header.Values(HeaderForwarded)))
+		default:
+			ip = parseIP(r.Header.Get(h))
+		}
+		if ip != nil {
+			return peer, ip
+		}
+	}
+	return peer, peer
+}
+
+func (cfg ClientIPConfig) trusted(ip net.IP) bool {
+	for _, n := range cfg.TrustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// rightmostUntrusted walks the list of addresses from the closest hop and
+// returns the first one not belonging to a trusted proxy, or the farthest
+// one if all hops are trusted. It returns nil when an invalid address is
+// found before.
+func (cfg ClientIPConfig) rightmostUntrusted(addrs []string) net.IP {
+	var ip net.IP
+	for i := len(addrs) - 1; i >= 0; i-- {
+		if ip = parseIP(addrs[i]); ip == nil {
+			return nil
+		}
+		if !cfg.trusted(ip) {
+			return ip
+		}
+	}
+	return ip
+}
+
+// splitList splits the comma separated values of a list header.
+func splitList(values []string) []string {
+	var addrs []string
+	for _, v := range values {
+		for _, a := range strings.Split(v, ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				addrs = append(addrs, a)
+			}
+		}
+	}
+	return addrs
+}
+
+// forwardedFor extracts the for= parameters of RFC 7239 Forwarded header
+// values.
+func forwardedFor(values []string) []string {
+	var addrs []string
+	for _, elem := range splitList(values) {
+		for _, pair := range strings.Split(elem, ";") {
+			pair = strings.TrimSpace(pair)
+			if len(pair) > 4 && strings.EqualFold(pair[:4], "for=") {
+				addrs = append(addrs, pair[4:])
+			}
+		}
+	}
+	return addrs
+}
+
+// parseIP parses an IP address optionally quoted, bracketed or followed by a
+// port.
+func parseIP(s string) net.IP {
+	s = strings.Trim(strings.TrimSpace(s), `"`)
+	if ip := net.ParseIP(s); ip != nil {
+		return ip
+	}
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		return net.ParseIP(host)
+	}
+	return net.ParseIP(strings.TrimSuffix(strings.TrimPrefix(s, "["), "]"))
+}
+
+// ClientIPHandler adds the direct peer IP and the client IP, resolved
+// according to cfg, as fields to the context's logger. Unlike
+// RemoteIPHandler, it gives the correct client IP behind proxies.
+func ClientIPHandler(cfg ClientIPConfig) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			peer, client := cfg.ClientIP(r)
+			if peer != nil {
+				log := zerolog.Ctx(r.Context())
+				log.UpdateContext(func(c zerolog.Context) zerolog.Context {
+					if cfg.PeerFieldKey != "" {
+						c = c.Str(cfg.PeerFieldKey, peer.String())
+					}
+					if cfg.ClientFieldKey != "" {
+						c = c.Str(cfg.ClientFieldKey, client.String())
+					}
+					return c
+				})
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package hlog
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/treavorj/zerolog"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ParseCIDRs("10.0.0.0/8", "fd00::/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := ClientIPConfig{
+		TrustedProxies: trusted,
+		Headers:        []string{HeaderCFConnectingIP, HeaderForwarded, HeaderXForwardedFor, HeaderXRealIP},
+	}
+	tests := []struct {
+		name       string
+		remoteAddr string
+		header     http.Header
+		want       string
+	}{
+		{"no header", "1.2.3.4:1234", nil, "1.2.3.4"},
+		{"untrusted peer", "1.2.3.4:1234", http.Header{"X-Real-Ip": {"5.6.7.8"}}, "1.2.3.4"},
+		{"x-real-ip", "10.0.0.1:1234", http.Header{"X-Real-Ip": {"5.6.7.8"}}, "5.6.7.8"},
+		{"x-forwarded-for", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"6.6.6.6, 5.6.7.8", "10.0.0.2"}}, "5.6.7.8"},
+		{"x-forwarded-for all trusted", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		{"x-forwarded-for invalid", "10.0.0.1:1234", http.Header{"X-Forwarded-For": {"garbage"}, "X-Real-Ip": {"5.6.7.8"}}, "5.6.7.8"},
+		{"forwarded", "10.0.0.1:1234", http.Header{"Forwarded": {`for="[2001:db8::1]:4711";proto=https, for=10.0.0.2;by=10.0.0.1`}}, "2001:db8::1"},
+		{"cloudflare", "[fd00::1]:1234", http.Header{"Cf-Connecting-Ip": {"5.6.7.8"}, "X-Real-Ip": {"6.6.6.6"}}, "5.6.7.8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{RemoteAddr: tt.remoteAddr, Header: tt.header}
+			if _, got := cfg.ClientIP(r); got.String() != tt.want {
+				t.Errorf("ClientIP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIPHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	trusted, _ := ParseCIDRs("10.0.0.0/8")
+	r := &http.Request{
+		RemoteAddr: "10.0.0.1:1234",
+		Header:     http.Header{"X-Forwarded-For": {"1.2.3.4"}},
+	}
+	h := ClientIPHandler(ClientIPConfig{
+		PeerFieldKey:   "peer_ip",
+		ClientFieldKey: "client_ip",
+		TrustedProxies: trusted,
+		Headers:        []string{HeaderXForwardedFor},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Log().Msg("")
+	}))
+	h = NewHandler(zerolog.New(out))(h)
+	h.ServeHTTP(nil, r)
+	if want, got := `{"peer_ip":"10.0.0.1","client_ip":"1.2.3.4"}`+"\n", decodeIfBinary(out); want != got {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package hlog
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/treavorj/zerolog/hlog/internal/mutil"
+)
+
+// combinedTimeFormat is the time format of the NCSA common log format.
+const combinedTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// AppendCombinedLog appends to dst the Apache/NCSA combined log format line,
+// including the trailing line break, describing the request r, started at
+// start and answered with status and size bytes:
+//
+//	127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08"
+func AppendCombinedLog(dst []byte, r *http.Request, status, size int, start time.Time) []byte {
+	dst = append(dst, combinedField(getHost(r.RemoteAddr))...)
+	dst = append(dst, " - "...)
+	user := ""
+	if r.URL != nil && r.URL.User != nil {
+		user = r.URL.User.Username()
+	} else if u, _, ok := r.BasicAuth(); ok {
+		user = u
+	}
+	dst = append(dst, combinedField(user)...)
+	dst = append(dst, " ["...)
+	dst = start.AppendFormat(dst, combinedTimeFormat)
+	dst = append(dst, `] "`...)
+	dst = append(dst, combinedQuoted(r.Method+" "+r.RequestURI+" "+r.Proto)...)
+	dst = append(dst, `" `...)
+	dst = strconv.AppendInt(dst, int64(status), 10)
+	dst = append(dst, ' ')
+	if size > 0 {
+		dst = strconv.AppendInt(dst, int64(size), 10)
+	} else {
+		dst = append(dst, '-')
+	}
+	dst = append(dst, ` "`...)
+	dst = append(dst, combinedQuoted(r.Referer())...)
+	dst = append(dst, `" "`...)
+	dst = append(dst, combinedQuoted(r.UserAgent())...)
+	return append(dst, "\"\n"...)
+}
+
+// combinedField returns s, or "-" if s is empty.
+func combinedField(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// combinedQuoted escapes s to be written between double quotes.
+func combinedQuoted(s string) string {
+	if !strings.ContainsAny(s, "\"\\\n") {
+		return s
+	}
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// CombinedLogHandler writes an Apache/NCSA combined log format line to out
+// after each request, for compatibility with legacy analyzers like GoAccess
+// or AWStats. It can be used in addition to, or instead of, a JSON access log
+// written with AccessHandler. Each line is written with a single call to
+// out's Write method.
+func CombinedLogHandler(out io.Writer) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			lw := mutil.WrapWriter(w)
+			defer func() {
+				status := lw.Status()
+				if status == 0 {
+					status = http.StatusOK
+				}
+				out.Write(AppendCombinedLog(nil, r, status, lw.BytesWritten(), start))
+			}()
+			next.ServeHTTP(lw, r)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package hlog
+
+import (
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/treavorj/zerolog"
+)
+
+// DefaultMaskedHeaders are the headers which values are masked by
+// CustomHeadersHandler when HeadersOptions.Mask is nil.
+var DefaultMaskedHeaders = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Api-Key",
+	"X-Auth-Token",
+}
+
+// DefaultHeaderMaxSize is the size in bytes above which header values are
+// truncated by CustomHeadersHandler when HeadersOptions.MaxSize is zero.
+const DefaultHeaderMaxSize = 256
+
+// HeaderMask replaces the value of masked headers. The authentication scheme
+// of Authorization headers is kept, like "Bearer ***".
+const HeaderMask = "***"
+
+// HeadersOptions configures CustomHeadersHandler.
+type HeadersOptions struct {
+	// Separator joins the values of headers set multiple times. Defaults to
+	// ", ".
+	Separator string
+
+	// MaxSize is the size in bytes above which values are truncated and
+	// suffixed by "...". Defaults to DefaultHeaderMaxSize, a negative value
+	// disables truncation.
+	MaxSize int
+
+	// Mask are the headers which values are replaced by HeaderMask. Defaults
+	// to DefaultMaskedHeaders, set an empty slice to mask nothing.
+	Mask []string
+}
+
+// CustomHeadersHandler adds the given request headers as a dictionary under
+// fieldKey to the context's logger. The dictionary keys are the canonical
+// header names, like "X-Request-Id" for "x-request-id". Missing headers are
+// omitted, and nothing is added when all are missing.
+func CustomHeadersHandler(fieldKey string, headers []string, opts HeadersOptions) func(next http.Handler) http.Handler {
+	names := make([]string, len(headers))
+	for i, h := range headers {
+		names[i] = http.CanonicalHeaderKey(h)
+	}
+	if opts.Separator == "" {
+		opts.Separator = ", "
+	}
+	if opts.MaxSize == 0 {
+		opts.MaxSize = DefaultHeaderMaxSize
+	}
+	mask := opts.Mask
+	if mask == nil {
+		mask = DefaultMaskedHeaders
+	}
+	masked := make(map[string]bool, len(mask))
+	for _, h := range mask {
+		masked[http.CanonicalHeaderKey(h)] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var dict *zerolog.Event
+			for _, name := range names {
+				values := r.Header[name]
+				if len(values) == 0 {
+					continue
+				}
+				val := strings.Join(values, opts.Separator)
+				if masked[name] {
+					val = maskHeader(name, val)
+				} else if opts.MaxSize > 0 && len(val) > opts.MaxSize {
+					val = truncate(val, opts.MaxSize) + "..."
+				}
+				if dict == nil {
+					dict = zerolog.Dict()
+				}
+				dict.Str(name, val)
+			}
+			if dict != nil {
+				log := zerolog.Ctx(r.Context())
+				log.UpdateContext(func(c zerolog.Context) zerolog.Context {
+					return c.Dict(fieldKey, dict)
+				})
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// maskHeader returns the masked value of the header name.
+func maskHeader(name, val string) string {
+	if name == "Authorization" || name == "Proxy-Authorization" {
+		if i := strings.IndexByte(val, ' '); i > 0 {
+			return val[:i+1] + HeaderMask
+		}
+	}
+	return HeaderMask
+}
+
+// truncate returns the longest prefix of s of at most n bytes not splitting
+// a UTF-8 sequence.
+func truncate(s string, n int) string {
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -210,7 +210,7 @@ func CtxWithID(ctx context.Context, id xid.ID) context.Context {
 // The generated id is a URL safe base64 encoded mongo object-id-like unique id.
 // Mongo unique id generation algorithm has been selected as a trade-off between
 // size and ease of use: UUID is less space efficient and snowflake requires machine
-// configuration.
+// configuration. NewID and FormatID mint ids consistent with this handler.
 func RequestIDHandler(fieldKey, headerName string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -6,13 +6,19 @@ package hlog
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/rs/xid"
 	"github.com/treavorj/zerolog"
@@ -305,6 +311,61 @@ func TestHTTPVersionHandler(t *testing.T) {
 	}
 }
 
+func TestTLSHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	r := &http.Request{
+		TLS: &tls.ConnectionState{
+			Version:            tls.VersionTLS13,
+			CipherSuite:        tls.TLS_AES_128_GCM_SHA256,
+			ServerName:         "example.com",
+			NegotiatedProtocol: "h2",
+			PeerCertificates: []*x509.Certificate{{
+				Subject: pkix.Name{CommonName: "client"},
+				Issuer:  pkix.Name{CommonName: "ca"},
+			}},
+		},
+	}
+	h := TLSHandler("tls")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Log().Msg("")
+	}))
+	h = NewHandler(zerolog.New(out))(h)
+	h.ServeHTTP(nil, r)
+	h.ServeHTTP(nil, &http.Request{})
+	want := `{"tls":{"version":"TLS 1.3","cipher_suite":"TLS_AES_128_GCM_SHA256","server_name":"example.com","alpn":"h2","resumed":false,"client_subject":"CN=client","client_issuer":"CN=ca"}}` + "\n" + "{}\n"
+	if got := cbor.DecodeIfBinaryToString(out.Bytes()); want != got {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestAppendCombinedLog(t *testing.T) {
+	r := httptest.NewRequest("GET", "/apache_pb.gif?a=1", nil)
+	r.RemoteAddr = "127.0.0.1:4321"
+	r.Proto = "HTTP/1.0"
+	r.SetBasicAuth("frank", "secret")
+	r.Header.Set("Referer", "http://www.example.com/start.html")
+	r.Header.Set("User-Agent", `Mozilla/4.08 "quoted"`)
+	start := time.Date(2000, time.October, 10, 13, 55, 36, 0, time.FixedZone("", -7*3600))
+	got := string(AppendCombinedLog(nil, r, 200, 2326, start))
+	want := `127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif?a=1 HTTP/1.0" 200 2326 "http://www.example.com/start.html" "Mozilla/4.08 \"quoted\""` + "\n"
+	if got != want {
+		t.Errorf("Invalid log line, got: %s, want: %s", got, want)
+	}
+}
+
+func TestCombinedLogHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := CombinedLogHandler(out)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	r := httptest.NewRequest("POST", "/missing", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if got := out.String(); !strings.HasPrefix(got, "10.0.0.1 - - [") || !strings.HasSuffix(got, `] "POST /missing HTTP/1.1" 404 - "" ""`+"\n") {
+		t.Errorf("Invalid log line: %s", got)
+	}
+}
+
 func TestCombinedHandlers(t *testing.T) {
 	out := &bytes.Buffer{}
 	r := &http.Request{
@@ -433,3 +494,124 @@ func TestGetHost(t *testing.T) {
 		})
 	}
 }
+
+func TestBudgetHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := BudgetHandler(2, 0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		for i := 0; i < 5; i++ {
+			l.Log().Int("i", i).Msg("")
+		}
+	}))
+	h = NewHandler(zerolog.New(out))(h)
+	h.ServeHTTP(nil, &http.Request{})
+
+	// The size of the dropped events depends on the encoding.
+	dropped := &bytes.Buffer{}
+	l := zerolog.New(dropped)
+	for i := 2; i < 5; i++ {
+		l.Log().Int("i", i).Msg("")
+	}
+	want := `{"i":0}` + "\n" + `{"i":1}` + "\n" +
+		`{"level":"warn","dropped_events":3,"dropped_bytes":` + strconv.Itoa(dropped.Len()) + `,"message":"request log budget exceeded"}` + "\n"
+	if got := cbor.DecodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("Invalid log output, got: %s, want: %s", got, want)
+	}
+}
+
+func TestFormatID(t *testing.T) {
+	id, _ := xid.FromString("9m4e2mr0ui3e8a215n4g")
+	tests := []struct {
+		enc  IDEncoding
+		want string
+	}{
+		{IDBase32, "9m4e2mr0ui3e8a215n4g"},
+		{IDBase62, "0VCs04xTJMQCMA3B3"},
+		{IDUUID, "4d88e15b-60f4-886e-90a1-04b724000000"},
+	}
+	for _, tt := range tests {
+		if got := FormatID(id, tt.enc); got != tt.want {
+			t.Errorf("FormatID(%v) = %q, want %q", tt.enc, got, tt.want)
+		}
+	}
+	if a, b := FormatID(NewID(), IDBase62), NewIDString(IDBase62); len(a) != 17 || a >= b {
+		t.Errorf("base62 ids not fixed width or ordered: %q, %q", a, b)
+	}
+}
+
+func TestRequestClassHandler(t *testing.T) {
+	preflight := httptest.NewRequest("OPTIONS", "/api/items", nil)
+	preflight.Header.Set("Origin", "https://example.com")
+	preflight.Header.Set("Access-Control-Request-Method", "POST")
+	grpcWeb := httptest.NewRequest("POST", "/pkg.Service/Method", nil)
+	grpcWeb.Header.Set("Content-Type", "application/grpc-web+proto")
+	probe := httptest.NewRequest("GET", "/", nil)
+	probe.Header.Set("User-Agent", "kube-probe/1.29")
+	tests := []struct {
+		r    *http.Request
+		want string
+	}{
+		{preflight, ClassPreflight},
+		{grpcWeb, ClassGRPCWeb},
+		{httptest.NewRequest("GET", "/healthz", nil), ClassHealthCheck},
+		{probe, ClassHealthCheck},
+		{httptest.NewRequest("GET", "/assets/app.CSS", nil), ClassStatic},
+		{httptest.NewRequest("POST", "/upload.png", nil), ClassAPI},
+		{httptest.NewRequest("OPTIONS", "/api/items", nil), ClassAPI},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		h := RequestClassHandler("request_class")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			l := FromRequest(r)
+			l.Log().Msg("")
+		}))
+		h = NewHandler(zerolog.New(out))(h)
+		h.ServeHTTP(nil, tt.r)
+		if want := `{"request_class":"` + tt.want + `"}` + "\n"; decodeIfBinary(out) != want {
+			t.Errorf("%s %s: invalid log output, got: %s, want: %s", tt.r.Method, tt.r.URL, decodeIfBinary(out), want)
+		}
+	}
+
+	matchers := []ClassMatcher{PathPrefixClass("admin", "/admin/")}
+	if got := ClassifyRequest(httptest.NewRequest("GET", "/admin/users", nil), matchers); got != "admin" {
+		t.Errorf("ClassifyRequest() = %q, want admin", got)
+	}
+	if got := ClassifyRequest(httptest.NewRequest("GET", "/healthz", nil), matchers); got != ClassAPI {
+		t.Errorf("ClassifyRequest() = %q, want %q", got, ClassAPI)
+	}
+}
+
+func TestCustomHeadersHandler(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("x-request-id", "abc")
+	r.Header.Add("Accept", "text/html")
+	r.Header.Add("Accept", "application/json")
+	r.Header.Set("Authorization", "Bearer secret")
+	r.Header.Set("Cookie", "session=secret")
+	r.Header.Set("User-Agent", strings.Repeat("é", 10))
+	out := &bytes.Buffer{}
+	h := CustomHeadersHandler("headers",
+		[]string{"X-REQUEST-ID", "accept", "authorization", "cookie", "user-agent", "x-missing"},
+		HeadersOptions{MaxSize: 5},
+	)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Log().Msg("")
+	}))
+	h = NewHandler(zerolog.New(out))(h)
+	h.ServeHTTP(nil, r)
+	want := `{"headers":{"X-Request-Id":"abc","Accept":"text/...","Authorization":"Bearer ***","Cookie":"***","User-Agent":"éé..."}}` + "\n"
+	if got := decodeIfBinary(out); got != want {
+		t.Errorf("invalid log output, got: %s, want: %s", got, want)
+	}
+
+	out.Reset()
+	h = CustomHeadersHandler("headers", []string{"x-missing"}, HeadersOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l := FromRequest(r)
+		l.Log().Msg("")
+	}))
+	h = NewHandler(zerolog.New(out))(h)
+	h.ServeHTTP(nil, r)
+	if got, want := decodeIfBinary(out), "{}\n"; got != want {
+		t.Errorf("invalid log output, got: %s, want: %s", got, want)
+	}
+}
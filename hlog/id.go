@@ -0,0 +1,85 @@
+package hlog
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/rs/xid"
+)
+
+// IDEncoding is the text encoding of a request id.
+type IDEncoding int
+
+const (
+	// IDBase32 is the 20 characters base32hex encoding used by
+	// RequestIDHandler.
+	IDBase32 IDEncoding = iota
+	// IDBase62 is a 17 characters alphanumeric encoding, fixed width and
+	// preserving the order of the ids.
+	IDBase62
+	// IDUUID is the canonical UUID form of an RFC 9562 version 8 (custom)
+	// UUID. The bits of the id are laid out in order around the version and
+	// variant bits, the trailing bits being zero, which preserves the order
+	// of the ids.
+	IDUUID
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// NewID generates a new unique id, using the same algorithm as
+// RequestIDHandler.
+func NewID() xid.ID {
+	return xid.New()
+}
+
+// NewIDString generates a new unique id, using the same algorithm as
+// RequestIDHandler, and returns it encoded with enc.
+func NewIDString(enc IDEncoding) string {
+	return FormatID(xid.New(), enc)
+}
+
+// FormatID returns id encoded with enc.
+func FormatID(id xid.ID, enc IDEncoding) string {
+	switch enc {
+	case IDBase62:
+		var n big.Int
+		n.SetBytes(id[:])
+		var buf [17]byte
+		base := big.NewInt(62)
+		var mod big.Int
+		for i := len(buf) - 1; i >= 0; i-- {
+			n.DivMod(&n, base, &mod)
+			buf[i] = base62Alphabet[mod.Int64()]
+		}
+		return string(buf[:])
+	case IDUUID:
+		// Copy the bits of the id in order, skipping the version bits 48-51
+		// and the variant bits 64-65.
+		var u [16]byte
+		j := 0
+		for i := 0; i < len(id)*8; i++ {
+			for j >= 48 && j < 52 || j >= 64 && j < 66 {
+				j++
+			}
+			if id[i/8]&(0x80>>(i%8)) != 0 {
+				u[j/8] |= 0x80 >> (j % 8)
+			}
+			j++
+		}
+		u[6] |= 0x80
+		u[8] |= 0x80
+		var buf [36]byte
+		hex.Encode(buf[0:8], u[0:4])
+		buf[8] = '-'
+		hex.Encode(buf[9:13], u[4:6])
+		buf[13] = '-'
+		hex.Encode(buf[14:18], u[6:8])
+		buf[18] = '-'
+		hex.Encode(buf[19:23], u[8:10])
+		buf[23] = '-'
+		hex.Encode(buf[24:36], u[10:16])
+		return string(buf[:])
+	default:
+		return id.String()
+	}
+}
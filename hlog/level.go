@@ -0,0 +1,88 @@
+package hlog
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+
+	"github.com/treavorj/zerolog"
+)
+
+// levelState is the JSON representation of the levels exposed by
+// LevelHandler.
+type levelState struct {
+	Level  string `json:"level,omitempty"`
+	Global string `json:"global,omitempty"`
+}
+
+// LevelHandler returns a handler exposing the level of the logger registered
+// under name with zerolog.Register or zerolog.Replace, and the global level
+// set by zerolog.SetGlobalLevel, like zap's AtomicLevel endpoint. GET requests
+// return the levels as a JSON object. PUT requests set the levels given by a
+// JSON object or by the level and global form values, and return the new
+// levels:
+//
+//	zerolog.Register("app", &log)
+//	http.Handle("/debug/level", hlog.LevelHandler("app"))
+//
+//	curl -X PUT -d '{"level":"debug"}' http://localhost:8080/debug/level
+//	{"level":"debug","global":"trace"}
+//
+// The level is set with zerolog.SetRegisteredLevel, so it is safe to change
+// while the logger is used. Only the global level is exposed if name is
+// empty.
+//
+// The handler allows changing the logging configuration: it should only be
+// exposed on an administrative endpoint.
+func LevelHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelState
+			if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct == "application/x-www-form-urlencoded" {
+				req.Level, req.Global = r.FormValue("level"), r.FormValue("global")
+			} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if req.Level != "" && name == "" {
+				http.Error(w, "no logger level to set", http.StatusBadRequest)
+				return
+			}
+			level, global := zerolog.NoLevel, zerolog.NoLevel
+			var err error
+			if req.Level != "" {
+				if level, err = zerolog.ParseLevel(req.Level); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			if req.Global != "" {
+				if global, err = zerolog.ParseLevel(req.Global); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+			if req.Level != "" {
+				if err := zerolog.SetRegisteredLevel(name, level); err != nil {
+					http.Error(w, err.Error(), http.StatusNotFound)
+					return
+				}
+			}
+			if req.Global != "" {
+				zerolog.SetGlobalLevel(global)
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		state := levelState{Global: zerolog.GlobalLevel().String()}
+		if name != "" {
+			state.Level = zerolog.Get(name).GetLevel().String()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+}
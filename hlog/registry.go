@@ -0,0 +1,41 @@
+package hlog
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/treavorj/zerolog"
+)
+
+// RegistryHandler returns a handler exposing the loggers registered with
+// zerolog.Register. GET requests return the description of the loggers as a
+// JSON array. POST requests set the level of the logger given by the name
+// form value to the level form value, like:
+//
+//	curl -d name=db -d level=debug http://localhost:8080/debug/loggers
+//
+// The handler allows changing the logging configuration: it should only be
+// exposed on an administrative endpoint.
+func RegistryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			level, err := zerolog.ParseLevel(r.FormValue("level"))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := zerolog.SetRegisteredLevel(r.FormValue("name"), level); err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(zerolog.Loggers())
+	})
+}
@@ -0,0 +1,156 @@
+package hlog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/treavorj/zerolog"
+)
+
+func TestRegistryHandler(t *testing.T) {
+	l := zerolog.New(&bytes.Buffer{}).Level(zerolog.InfoLevel)
+	zerolog.Register("test", &l)
+	defer zerolog.Unregister("test")
+	h := RegistryHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := w.Body.String(), `[{"name":"test","level":"info","writer":{"type":"*bytes.Buffer"}}]`+"\n"; got != want {
+		t.Errorf("GET body = %s, want %s", got, want)
+	}
+
+	form := url.Values{"name": {"test"}, "level": {"debug"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || l.GetLevel() != zerolog.DebugLevel {
+		t.Errorf("POST status = %d, level = %v", w.Code, l.GetLevel())
+	}
+
+	form = url.Values{"name": {"missing"}, "level": {"debug"}}
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("POST unknown status = %d, want 404", w.Code)
+	}
+}
+
+func TestRegistryHandlerConcurrent(t *testing.T) {
+	l := zerolog.New(zerolog.SyncWriter(&bytes.Buffer{})).Level(zerolog.InfoLevel)
+	zerolog.Register("concurrent", &l)
+	defer zerolog.Unregister("concurrent")
+	h := RegistryHandler()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i, level := range []string{"debug", "warn", "info", "error"} {
+			form := url.Values{"name": {"concurrent"}, "level": {level}}
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != http.StatusOK {
+				t.Errorf("POST %d status = %d", i, w.Code)
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		l.Info().Int("i", i).Msg("")
+		sub := l.With().Str("sub", "x").Logger()
+		sub.Warn().Msg("")
+	}
+	<-done
+	if l.GetLevel() != zerolog.ErrorLevel {
+		t.Errorf("level = %v, want error", l.GetLevel())
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	defer zerolog.SetGlobalLevel(zerolog.GlobalLevel())
+	l := zerolog.New(&bytes.Buffer{}).Level(zerolog.InfoLevel)
+	zerolog.Register("level", &l)
+	defer zerolog.Unregister("level")
+	h := LevelHandler("level")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := w.Body.String(), `{"level":"info","global":"trace"}`+"\n"; got != want {
+		t.Errorf("GET body = %s, want %s", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"debug","global":"info"}`)))
+	if got, want := w.Body.String(), `{"level":"debug","global":"info"}`+"\n"; got != want {
+		t.Errorf("PUT body = %s, want %s", got, want)
+	}
+	if l.GetLevel() != zerolog.DebugLevel || zerolog.GlobalLevel() != zerolog.InfoLevel {
+		t.Errorf("levels = %v, %v", l.GetLevel(), zerolog.GlobalLevel())
+	}
+
+	form := url.Values{"level": {"warn"}}
+	r := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || l.GetLevel() != zerolog.WarnLevel || zerolog.GlobalLevel() != zerolog.InfoLevel {
+		t.Errorf("PUT form status = %d, levels = %v, %v", w.Code, l.GetLevel(), zerolog.GlobalLevel())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"loud"}`)))
+	if w.Code != http.StatusBadRequest || l.GetLevel() != zerolog.WarnLevel {
+		t.Errorf("PUT invalid status = %d, level = %v", w.Code, l.GetLevel())
+	}
+
+	w = httptest.NewRecorder()
+	LevelHandler("").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got, want := w.Body.String(), `{"global":"info"}`+"\n"; got != want {
+		t.Errorf("GET global body = %s, want %s", got, want)
+	}
+
+	w = httptest.NewRecorder()
+	LevelHandler("missing").ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"debug"}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("PUT unknown status = %d, want 404", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want 405", w.Code)
+	}
+}
+
+func TestLevelHandlerConcurrent(t *testing.T) {
+	l := zerolog.New(zerolog.SyncWriter(&bytes.Buffer{})).Level(zerolog.InfoLevel)
+	zerolog.Register("level-concurrent", &l)
+	defer zerolog.Unregister("level-concurrent")
+	h := LevelHandler("level-concurrent")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i, level := range []string{"debug", "warn", "info", "error"} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"`+level+`"}`)))
+			if w.Code != http.StatusOK {
+				t.Errorf("PUT %d status = %d", i, w.Code)
+			}
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		l.Info().Int("i", i).Msg("")
+	}
+	<-done
+	if l.GetLevel() != zerolog.ErrorLevel {
+		t.Errorf("level = %v, want error", l.GetLevel())
+	}
+}
@@ -0,0 +1,59 @@
+package hlog
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strconv"
+
+	"github.com/treavorj/zerolog"
+)
+
+// tlsVersionName returns the name of the TLS version v.
+func tlsVersionName(v uint16) string {
+	switch v {
+	case tls.VersionTLS10:
+		return "TLS 1.0"
+	case tls.VersionTLS11:
+		return "TLS 1.1"
+	case tls.VersionTLS12:
+		return "TLS 1.2"
+	case tls.VersionTLS13:
+		return "TLS 1.3"
+	}
+	return "0x" + strconv.FormatUint(uint64(v), 16)
+}
+
+// TLSHandler adds the details of the TLS connection of the request as a
+// nested object to the context's logger using fieldKey as field key. The
+// object holds the version, cipher_suite, server_name (SNI), alpn and
+// resumed fields and, when the client presented a certificate, the
+// client_subject and client_issuer fields. Nothing is added for requests not
+// received over TLS.
+func TLSHandler(fieldKey string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cs := r.TLS; cs != nil {
+				log := zerolog.Ctx(r.Context())
+				log.UpdateContext(func(c zerolog.Context) zerolog.Context {
+					d := zerolog.Dict().
+						Str("version", tlsVersionName(cs.Version)).
+						Str("cipher_suite", tls.CipherSuiteName(cs.CipherSuite))
+					if cs.ServerName != "" {
+						d = d.Str("server_name", cs.ServerName)
+					}
+					if cs.NegotiatedProtocol != "" {
+						d = d.Str("alpn", cs.NegotiatedProtocol)
+					}
+					d = d.Bool("resumed", cs.DidResume)
+					if len(cs.PeerCertificates) > 0 {
+						cert := cs.PeerCertificates[0]
+						d = d.Str("client_subject", cert.Subject.String()).
+							Str("client_issuer", cert.Issuer.String())
+					}
+					return c.Dict(fieldKey, d)
+				})
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,387 @@
+// Package httpwriter provides a zerolog writer shipping events in batches to
+// an HTTP endpoint, like a log collector, an OpenSearch or Elasticsearch bulk
+// API or a managed cloud logging endpoint.
+//
+//	w, err := httpwriter.NewWriter(httpwriter.Options{
+//	    URL:       "https://search-logs.eu-west-1.es.amazonaws.com/_bulk",
+//	    BulkIndex: "app-logs",
+//	    Signer:    &httpwriter.SigV4{Region: "eu-west-1", Service: "es", AccessKeyID: id, SecretAccessKey: secret},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Events are buffered in memory and posted by a background go-routine as
+// newline delimited JSON, binary events being converted. Connections are
+// pooled and reused, HTTP/2 is negotiated with TLS endpoints, and the proxy
+// is read from the environment by default. Failed batches are retried,
+// including the requests refused by a server going away (HTTP/2 GOAWAY) or
+// throttling (429 and 5xx responses, honoring Retry-After). The batches
+// refused with another status, like 400 or 403, are dropped without retry.
+package httpwriter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+// ErrBufferFull is returned by Write when the event buffer is full.
+var ErrBufferFull = errors.New("httpwriter: buffer full, event dropped")
+
+// Signer signs the requests before they are sent, like SigV4 for AWS
+// endpoints. body is the request body, as sent.
+type Signer interface {
+	Sign(req *http.Request, body []byte) error
+}
+
+// SignerFunc is an adapter to use a function as a Signer.
+type SignerFunc func(req *http.Request, body []byte) error
+
+// Sign calls f(req, body).
+func (f SignerFunc) Sign(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+// Options configures a Writer.
+type Options struct {
+	// URL is the endpoint the batches are posted to.
+	URL string
+
+	// Header holds additional request headers, like an API key.
+	Header http.Header
+
+	// BulkIndex, if not empty, prefixes each event with an index action for
+	// the Elasticsearch and OpenSearch bulk API, in the BulkIndex index.
+	BulkIndex string
+
+	// Compress compresses the request bodies with gzip.
+	Compress bool
+
+	// Signer, if not nil, signs each request.
+	Signer Signer
+
+	// Proxy returns the proxy of a request, as http.Transport.Proxy.
+	// Defaults to http.ProxyFromEnvironment.
+	Proxy func(*http.Request) (*url.URL, error)
+
+	// TLSConfig is the TLS configuration of the connections.
+	TLSConfig *tls.Config
+
+	// MaxConns is the maximum number of connections to the endpoint, kept
+	// open to be reused. Defaults to 2.
+	MaxConns int
+
+	// IdleConnTimeout is the time an idle connection is kept open. Defaults
+	// to 90s.
+	IdleConnTimeout time.Duration
+
+	// Timeout is the timeout of each request. Defaults to 30s.
+	Timeout time.Duration
+
+	// Client, if not nil, is used to send the requests instead of a client
+	// built from Proxy, TLSConfig, MaxConns, IdleConnTimeout and Timeout.
+	Client *http.Client
+
+	// BufferSize is the number of events buffered while waiting to be sent.
+	// Events written when the buffer is full are dropped. Defaults to 8192.
+	BufferSize int
+
+	// BatchSize is the maximum number of events sent in a single request.
+	// Defaults to 500.
+	BatchSize int
+
+	// FlushInterval is the maximum time an event is buffered before being
+	// sent. Defaults to 1s.
+	FlushInterval time.Duration
+
+	// MaxRetries is the number of times a failed batch is retried before
+	// being dropped. Defaults to 3, a negative value disables retries.
+	MaxRetries int
+
+	// RetryBackoff is the delay before the first retry, doubled on each
+	// following retry. Defaults to 500ms.
+	RetryBackoff time.Duration
+
+	// Sink, if not nil, receives a WriteFailure error for each dropped batch.
+	// Drops are otherwise reported to ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+// Writer is a zerolog.LevelWriter posting events to an HTTP endpoint.
+type Writer struct {
+	opts   Options
+	client *http.Client
+	action []byte // bulk index action line
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan []byte
+	flush  chan chan struct{}
+	stop   chan struct{} // closed by Close to abort the retries
+	done   chan struct{}
+}
+
+// NewWriter returns a Writer posting events to opts.URL from a background
+// go-routine running until Close is called.
+func NewWriter(opts Options) (*Writer, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("httpwriter: invalid URL %q", opts.URL)
+	}
+	if opts.Proxy == nil {
+		opts.Proxy = http.ProxyFromEnvironment
+	}
+	if opts.MaxConns <= 0 {
+		opts.MaxConns = 2
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = 90 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 8192
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	} else if opts.MaxRetries == 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = 500 * time.Millisecond
+	}
+	w := &Writer{
+		opts:   opts,
+		client: opts.Client,
+		queue:  make(chan []byte, opts.BufferSize),
+		flush:  make(chan chan struct{}),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if w.client == nil {
+		w.client = &http.Client{
+			Transport: &http.Transport{
+				Proxy: opts.Proxy,
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				TLSClientConfig:     opts.TLSConfig,
+				ForceAttemptHTTP2:   true,
+				MaxIdleConns:        opts.MaxConns,
+				MaxIdleConnsPerHost: opts.MaxConns,
+				MaxConnsPerHost:     opts.MaxConns,
+				IdleConnTimeout:     opts.IdleConnTimeout,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+			Timeout: opts.Timeout,
+		}
+	}
+	if opts.BulkIndex != "" {
+		w.action = []byte(`{"index":{"_index":` + strconv.Quote(opts.BulkIndex) + "}}\n")
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. The event is copied, converted
+// to JSON if binary, and buffered until the next batch is sent.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	evt := append([]byte(nil), cbor.DecodeIfBinaryToBytes(p)...)
+	if len(evt) == 0 || evt[len(evt)-1] != '\n' {
+		evt = append(evt, '\n')
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errors.New("httpwriter: writer closed")
+	}
+	select {
+	case w.queue <- evt:
+		return len(p), nil
+	default:
+		return 0, ErrBufferFull
+	}
+}
+
+// Flush sends the buffered events and returns once they are sent or dropped.
+func (w *Writer) Flush() error {
+	c := make(chan struct{})
+	select {
+	case w.flush <- c:
+	case <-w.done:
+		return errors.New("httpwriter: writer closed")
+	}
+	<-c
+	return nil
+}
+
+// Close sends the buffered events, stops the background go-routine and
+// closes the idle connections. The failed batches are not retried anymore,
+// and dropped.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("httpwriter: writer already closed")
+	}
+	w.closed = true
+	close(w.queue)
+	close(w.stop)
+	w.mu.Unlock()
+	<-w.done
+	w.client.CloseIdleConnections()
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	var batch [][]byte
+	for {
+		select {
+		case evt, ok := <-w.queue:
+			if !ok {
+				w.send(batch)
+				return
+			}
+			if batch = append(batch, evt); len(batch) >= w.opts.BatchSize {
+				w.send(batch)
+				batch = nil
+			}
+		case c := <-w.flush:
+			for n := len(w.queue); n > 0; n-- {
+				if batch = append(batch, <-w.queue); len(batch) >= w.opts.BatchSize {
+					w.send(batch)
+					batch = nil
+				}
+			}
+			w.send(batch)
+			batch = nil
+			close(c)
+		case <-ticker.C:
+			w.send(batch)
+			batch = nil
+		}
+	}
+}
+
+// send posts batch, retrying according to the options.
+func (w *Writer) send(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+	var body bytes.Buffer
+	var out io.Writer = &body
+	var zw *gzip.Writer
+	if w.opts.Compress {
+		zw = gzip.NewWriter(&body)
+		out = zw
+	}
+	for _, evt := range batch {
+		out.Write(w.action)
+		out.Write(evt)
+	}
+	if zw != nil {
+		zw.Close()
+	}
+
+	var err error
+	backoff := w.opts.RetryBackoff
+retry:
+	for attempt := 0; ; attempt++ {
+		var wait time.Duration
+		var retryable bool
+		if wait, retryable, err = w.post(body.Bytes()); err == nil {
+			return
+		}
+		if !retryable || attempt == w.opts.MaxRetries {
+			break
+		}
+		if wait <= 0 {
+			wait = backoff
+		}
+		backoff *= 2
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-w.stop:
+			t.Stop()
+			break retry
+		}
+	}
+	zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel,
+		fmt.Errorf("httpwriter: dropped %d events: %v", len(batch), err))
+}
+
+// post sends a request with body. It returns, on failure, whether the
+// request can be retried, after the delay asked by the server if any: only
+// transport errors and 429 and 5xx responses are retried.
+func (w *Writer) post(body []byte) (wait time.Duration, retryable bool, err error) {
+	// The body is rewindable (GetBody is set), so the transport retries the
+	// requests refused by an HTTP/2 server going away on a new connection.
+	req, err := http.NewRequest(http.MethodPost, w.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, false, err
+	}
+	for k, v := range w.opts.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if w.opts.Compress {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if w.opts.Signer != nil {
+		if err := w.opts.Signer.Sign(req, body); err != nil {
+			return 0, false, fmt.Errorf("cannot sign request: %v", err)
+		}
+	}
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, true, err
+	}
+	// Drain the body so the connection can be reused.
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	if resp.StatusCode/100 == 2 {
+		return 0, false, nil
+	}
+	err = fmt.Errorf("unexpected status %s", resp.Status)
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode/100 != 5 {
+		return 0, false, err
+	}
+	if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait = time.Duration(s) * time.Second
+	}
+	return wait, true, err
+}
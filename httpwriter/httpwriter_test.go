@@ -0,0 +1,184 @@
+//go:build !binary_log
+// +build !binary_log
+
+package httpwriter
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+func TestSigV4(t *testing.T) {
+	// get-vanilla case of the AWS Signature Version 4 test suite.
+	req, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+	s := &SigV4{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region:          "us-east-1",
+		Service:         "service",
+		Now:             func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+	if err := s.Sign(req, nil); err != nil {
+		t.Fatal(err)
+	}
+	want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization:\ngot:  %s\nwant: %s", got, want)
+	}
+}
+
+type recorder struct {
+	mu      sync.Mutex
+	bodies  []string
+	protos  []int
+	remotes map[string]bool
+	fail    int // number of requests to fail with 503
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.fail > 0 {
+		rec.fail--
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		body = zr
+	}
+	b, _ := ioutil.ReadAll(body)
+	if r.Header.Get("X-Api-Key") != "secret" || r.Header.Get("X-Signed") != "yes" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	rec.bodies = append(rec.bodies, string(b))
+	rec.protos = append(rec.protos, r.ProtoMajor)
+	rec.remotes[r.RemoteAddr] = true
+}
+
+func TestWriter(t *testing.T) {
+	rec := &recorder{remotes: map[string]bool{}, fail: 1}
+	srv := httptest.NewUnstartedServer(rec)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	w, err := NewWriter(Options{
+		URL:          srv.URL + "/_bulk",
+		Header:       http.Header{"X-Api-Key": {"secret"}},
+		BulkIndex:    "logs",
+		Compress:     true,
+		TLSConfig:    srv.Client().Transport.(*http.Transport).TLSClientConfig,
+		RetryBackoff: time.Millisecond,
+		Signer: SignerFunc(func(req *http.Request, body []byte) error {
+			req.Header.Set("X-Signed", "yes")
+			return nil
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+	log.Info().Msg("one")
+	log.Warn().Msg("two")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	log.Error().Msg("three")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`{"index":{"_index":"logs"}}` + "\n" + `{"level":"info","message":"one"}` + "\n" +
+			`{"index":{"_index":"logs"}}` + "\n" + `{"level":"warn","message":"two"}` + "\n",
+		`{"index":{"_index":"logs"}}` + "\n" + `{"level":"error","message":"three"}` + "\n",
+	}
+	if strings.Join(rec.bodies, "|") != strings.Join(want, "|") {
+		t.Errorf("invalid bodies:\ngot:  %q\nwant: %q", rec.bodies, want)
+	}
+	for _, proto := range rec.protos {
+		if proto != 2 {
+			t.Errorf("request sent over HTTP/%d, want HTTP/2", proto)
+		}
+	}
+	if len(rec.remotes) != 1 {
+		t.Errorf("requests sent over %d connections, want 1", len(rec.remotes))
+	}
+}
+
+func TestNewWriterInvalidURL(t *testing.T) {
+	if _, err := NewWriter(Options{URL: "localhost:9200"}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestWriterRetries(t *testing.T) {
+	errs := make(chan error, 10)
+	sink := zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+		if err.Kind == zerolog.WriteFailure {
+			errs <- err.Err
+		}
+	})
+
+	var mu sync.Mutex
+	var requests int
+	status := http.StatusBadRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	w, err := NewWriter(Options{URL: srv.URL, RetryBackoff: time.Hour, Sink: sink})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte(`{"message":"refused"}`))
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if requests != 1 {
+		t.Errorf("batch refused with 400 sent %d times, want 1", requests)
+	}
+	if err := <-errs; !strings.Contains(err.Error(), "400") {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	status = http.StatusServiceUnavailable
+	mu.Unlock()
+	w.Write([]byte(`{"message":"throttled"}`))
+	start := time.Now()
+	go func() {
+		// Close while the batch waits to be retried.
+		time.Sleep(50 * time.Millisecond)
+		w.Close()
+	}()
+	w.Flush()
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("Close waited for the retry backoff: %v", d)
+	}
+	if err := <-errs; !strings.Contains(err.Error(), "503") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
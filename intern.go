@@ -0,0 +1,96 @@
+package zerolog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	internMu  sync.Mutex
+	internOn  int32        // set once values are registered
+	internMap atomic.Value // map[string][]byte of encoded values
+)
+
+// InternStrings registers values repeated across many events, like level,
+// component or enum-like values, so their encoded form is computed once:
+// string fields added with Str, including the level field, are then appended
+// from the cached encoding when their value is registered, skipping the
+// escaping work.
+//
+//	zerolog.InternStrings("debug", "info", "warn", "error", "api", "worker")
+//
+// The registry is meant for a bounded set of values registered at startup,
+// each registration copies it. Before the first registration, the lookup
+// costs a single atomic load.
+func InternStrings(values ...string) {
+	internMu.Lock()
+	defer internMu.Unlock()
+	old, _ := internMap.Load().(map[string][]byte)
+	m := make(map[string][]byte, len(old)+len(values))
+	for s, b := range old {
+		m[s] = b
+	}
+	for _, s := range values {
+		m[s] = enc.AppendString(nil, s)
+	}
+	internMap.Store(m)
+	atomic.StoreInt32(&internOn, 1)
+}
+
+// appendInternString appends the encoding of s, from the registry of
+// InternStrings if s is registered.
+func appendInternString(dst []byte, s string) []byte {
+	if atomic.LoadInt32(&internOn) != 0 {
+		if b, ok := internMap.Load().(map[string][]byte)[s]; ok {
+			return append(dst, b...)
+		}
+	}
+	return enc.AppendString(dst, s)
+}
+
+// Interned is a string value encoded once, for the hottest logging paths
+// where even the registry lookup of InternStrings matters:
+//
+//	var componentAPI = zerolog.Intern("api")
+//
+//	log.Info().Interned("component", componentAPI).Msg("request")
+type Interned struct {
+	s string
+	b []byte
+}
+
+// Intern returns s with its encoding precomputed.
+func Intern(s string) Interned {
+	return Interned{s: s, b: enc.AppendString(nil, s)}
+}
+
+// String returns the interned string.
+func (i Interned) String() string {
+	return i.s
+}
+
+// appendTo appends the encoding of i to dst.
+func (i Interned) appendTo(dst []byte) []byte {
+	if i.b == nil {
+		return enc.AppendString(dst, i.s)
+	}
+	return append(dst, i.b...)
+}
+
+// Interned adds the field key with the interned string val to the *Event
+// context.
+func (e *Event) Interned(key string, val Interned) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Interned")
+	e.buf = val.appendTo(enc.AppendKey(e.buf, key))
+	return e
+}
+
+// Interned adds the field key with the interned string val to the logger
+// context.
+func (c Context) Interned(key string, val Interned) Context {
+	c.l.context = val.appendTo(enc.AppendKey(c.l.context, key))
+	return c
+}
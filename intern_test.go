@@ -0,0 +1,25 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInternStrings(t *testing.T) {
+	defer func(m map[string][]byte) {
+		internMap.Store(m)
+	}(func() map[string][]byte { m, _ := internMap.Load().(map[string][]byte); return m }())
+	InternStrings("info", "api", `quo"te`)
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("component", "api").Interned("kind", Intern("ab\tc")).Logger()
+	log.Info().Str("q", `quo"te`).Str("other", "x").Interned("zero", Interned{}).Msg("")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","component":"api","kind":"ab\tc","q":"quo\"te","other":"x","zero":""}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if s := Intern("api").String(); s != "api" {
+		t.Errorf("Interned.String() = %q, want api", s)
+	}
+}
@@ -0,0 +1,175 @@
+// Package benchx provides realistic end-to-end benchmarks of zerolog
+// configurations, to size logging setups, along with a helper to measure the
+// per-call overhead of logging. It is internal as it imports the testing
+// package, the scenarios being run as regular Go benchmarks with:
+//
+//	go test -bench . github.com/treavorj/zerolog/internal/benchx
+package benchx
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/diode"
+)
+
+// Scenario is a logging configuration and a representative logging call.
+type Scenario struct {
+	// Name identifies the scenario.
+	Name string
+
+	// Logger creates the logger writing to w. It returns a function releasing
+	// the resources of the logger, or nil.
+	Logger func(w io.Writer) (zerolog.Logger, func())
+
+	// Log emits one event with l.
+	Log func(l *zerolog.Logger)
+}
+
+var errExample = errors.New("connection reset by peer")
+
+// logRequest emits a typical request event.
+func logRequest(l *zerolog.Logger) {
+	l.Info().
+		Str("method", "GET").
+		Str("path", "/api/v1/users/42").
+		Int("status", 200).
+		Int64("size", 5123).
+		Dur("duration", 1234*time.Microsecond).
+		Str("user_agent", "Mozilla/5.0 (X11; Linux x86_64)").
+		Msg("request handled")
+}
+
+func newLogger(w io.Writer) zerolog.Logger {
+	return zerolog.New(w).With().Timestamp().Str("service", "api").Logger()
+}
+
+// Scenarios returns the built-in scenarios:
+//
+//   - plain: JSON output with timestamp and context fields.
+//   - disabled: events below the logger level.
+//   - sampled: 1 event out of 10 kept by a BasicSampler.
+//   - dedup: automatic removal of duplicate fields.
+//   - error: error event with a stack-less error.
+//   - console: human-friendly ConsoleWriter output.
+//   - async: output through a non-blocking diode writer.
+func Scenarios() []Scenario {
+	return []Scenario{
+		{
+			Name:   "plain",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) { return newLogger(w), nil },
+			Log:    logRequest,
+		},
+		{
+			Name: "disabled",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) {
+				return newLogger(w).Level(zerolog.WarnLevel), nil
+			},
+			Log: logRequest,
+		},
+		{
+			Name: "sampled",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) {
+				return newLogger(w).Sample(&zerolog.BasicSampler{N: 10}), nil
+			},
+			Log: logRequest,
+		},
+		{
+			Name: "dedup",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) {
+				return newLogger(w).AutoDeDup(zerolog.DeDupModeShallow), nil
+			},
+			Log: func(l *zerolog.Logger) {
+				l.Info().Str("service", "worker").Int("job", 42).Msg("job done")
+			},
+		},
+		{
+			Name:   "error",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) { return newLogger(w), nil },
+			Log: func(l *zerolog.Logger) {
+				l.Error().Err(errExample).Str("peer", "10.0.0.1:5432").Msg("query failed")
+			},
+		},
+		{
+			Name: "console",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) {
+				return newLogger(zerolog.ConsoleWriter{Out: w, NoColor: true}), nil
+			},
+			Log: logRequest,
+		},
+		{
+			Name: "async",
+			Logger: func(w io.Writer) (zerolog.Logger, func()) {
+				dw := diode.NewWriter(w, 1000, 10*time.Millisecond, func(missed int) {})
+				return newLogger(dw), func() { dw.Close() }
+			},
+			Log: logRequest,
+		},
+	}
+}
+
+// Result is the outcome of a benchmark.
+type Result struct {
+	Name        string
+	N           int
+	NsPerOp     int64
+	AllocsPerOp int64
+	BytesPerOp  int64
+}
+
+// String formats r like the go test benchmark output.
+func (r Result) String() string {
+	return fmt.Sprintf("%-12s %10d %10d ns/op %8d B/op %6d allocs/op", r.Name, r.N, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+}
+
+func result(name string, br testing.BenchmarkResult) Result {
+	return Result{
+		Name:        name,
+		N:           br.N,
+		NsPerOp:     br.NsPerOp(),
+		AllocsPerOp: br.AllocsPerOp(),
+		BytesPerOp:  br.AllocedBytesPerOp(),
+	}
+}
+
+// Bench runs s as a benchmark with b, writing to io.Discard.
+func Bench(b *testing.B, s Scenario) {
+	l, release := s.Logger(io.Discard)
+	if release != nil {
+		defer release()
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.Log(&l)
+	}
+}
+
+// Run benchmarks the scenarios, each for about one second, and returns their
+// results.
+func Run(scenarios ...Scenario) []Result {
+	results := make([]Result, 0, len(scenarios))
+	for _, s := range scenarios {
+		s := s
+		results = append(results, result(s.Name, testing.Benchmark(func(b *testing.B) {
+			Bench(b, s)
+		})))
+	}
+	return results
+}
+
+// Overhead measures the per-call cost of fn, like a logging call of an
+// application with its production logger configuration, for about one
+// second.
+func Overhead(name string, fn func()) Result {
+	return result(name, testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			fn()
+		}
+	}))
+}
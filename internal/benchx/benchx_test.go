@@ -0,0 +1,29 @@
+package benchx
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestScenarios(t *testing.T) {
+	for _, s := range Scenarios() {
+		buf := &bytes.Buffer{}
+		l, release := s.Logger(buf)
+		s.Log(&l)
+		if release != nil {
+			release()
+		}
+		if s.Name != "disabled" && s.Name != "sampled" && buf.Len() == 0 {
+			t.Errorf("%s: no output", s.Name)
+		}
+	}
+}
+
+func BenchmarkScenarios(b *testing.B) {
+	for _, s := range Scenarios() {
+		s := s
+		b.Run(s.Name, func(b *testing.B) {
+			Bench(b, s)
+		})
+	}
+}
@@ -57,6 +57,18 @@ func (e Encoder) AppendStringer(dst []byte, val fmt.Stringer) []byte {
 	return e.AppendString(dst, val.String())
 }
 
+// AppendStringBytes encodes and adds the bytes s as a text string to the dst
+// byte array, like AppendString without the string conversion.
+func (Encoder) AppendStringBytes(dst, s []byte) []byte {
+	l := len(s)
+	if l <= additionalMax {
+		dst = append(dst, majorTypeUtf8String|byte(l))
+	} else {
+		dst = appendCborTypePrefix(dst, majorTypeUtf8String, uint64(l))
+	}
+	return append(dst, s...)
+}
+
 // AppendBytes encodes and adds an array of bytes to the dst byte array.
 func (Encoder) AppendBytes(dst, s []byte) []byte {
 	major := majorTypeByteString
@@ -351,6 +351,16 @@ func (e Encoder) AppendUints64(dst []byte, vals []uint64) []byte {
 	return dst
 }
 
+// AppendFloatFmt encodes and inserts a float value into the dst byte array,
+// as a single precision float if bitSize is 32 or as a double precision float
+// otherwise. Formatting options are ignored as the value is stored in binary.
+func (e Encoder) AppendFloatFmt(dst []byte, val float64, bitSize int, fmt byte, precision int, trimZeros bool) []byte {
+	if bitSize == 32 {
+		return e.AppendFloat32(dst, float32(val), precision)
+	}
+	return e.AppendFloat64(dst, val, precision)
+}
+
 // AppendFloat32 encodes and inserts a single precision float value into the dst byte array.
 func (Encoder) AppendFloat32(dst []byte, val float32, unused int) []byte {
 	switch {
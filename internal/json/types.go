@@ -335,6 +335,51 @@ func appendFloat(dst []byte, val float64, bitSize, precision int) []byte {
 	return dst
 }
 
+// AppendFloatFmt converts the input float to a string using the strconv
+// format fmt and precision, and appends the encoded string to the input byte
+// slice. If trimZeros is true, trailing zeros after the decimal point are
+// removed.
+func (Encoder) AppendFloatFmt(dst []byte, val float64, bitSize int, fmt byte, precision int, trimZeros bool) []byte {
+	switch {
+	case math.IsNaN(val):
+		return append(dst, `"NaN"`...)
+	case math.IsInf(val, 1):
+		return append(dst, `"+Inf"`...)
+	case math.IsInf(val, -1):
+		return append(dst, `"-Inf"`...)
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, val, fmt, precision, bitSize)
+	if !trimZeros {
+		return dst
+	}
+	end := len(dst)
+	for i := start; i < len(dst); i++ {
+		if dst[i] == 'e' || dst[i] == 'E' {
+			end = i
+			break
+		}
+	}
+	dot := -1
+	for i := start; i < end; i++ {
+		if dst[i] == '.' {
+			dot = i
+			break
+		}
+	}
+	if dot < 0 {
+		return dst
+	}
+	trim := end
+	for trim > dot+1 && dst[trim-1] == '0' {
+		trim--
+	}
+	if trim == dot+1 {
+		trim = dot
+	}
+	return append(dst[:trim], dst[end:]...)
+}
+
 // AppendFloat32 converts the input float32 to a string and
 // appends the encoded string to the input byte slice.
 func (Encoder) AppendFloat32(dst []byte, val float32, precision int) []byte {
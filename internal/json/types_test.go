@@ -551,3 +551,28 @@ func BenchmarkEncoder_AppendFloat64(b *testing.B) {
 		}
 	}
 }
+
+func TestAppendFloatFmt(t *testing.T) {
+	tests := []struct {
+		val       float64
+		bitSize   int
+		fmt       byte
+		precision int
+		trimZeros bool
+		want      string
+	}{
+		{1.5, 64, 'f', 4, false, "1.5000"},
+		{1.5, 64, 'f', 4, true, "1.5"},
+		{2, 64, 'f', 3, true, "2"},
+		{100, 64, 'f', -1, true, "100"},
+		{0.123456789, 64, 'g', 3, false, "0.123"},
+		{1.2e21, 64, 'e', 4, true, "1.2e+21"},
+		{math.NaN(), 64, 'f', 2, true, `"NaN"`},
+		{float64(float32(0.1)), 32, 'f', -1, false, "0.1"},
+	}
+	for _, tt := range tests {
+		if got := string(enc.AppendFloatFmt([]byte{}, tt.val, tt.bitSize, tt.fmt, tt.precision, tt.trimZeros)); got != tt.want {
+			t.Errorf("AppendFloatFmt(%v, %d, %c, %d, %v) = %s, want %s", tt.val, tt.bitSize, tt.fmt, tt.precision, tt.trimZeros, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+//go:build !windows
+// +build !windows
+
+// Package journaldwriter provides a zerolog writer sending events to the
+// systemd journal over its native protocol, without depending on libsystemd
+// or go-systemd.
+//
+//	w := journaldwriter.NewWriter(journaldwriter.Options{})
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Each event becomes a journal entry: the message field is sent as MESSAGE,
+// the level as the matching syslog PRIORITY and the other top level fields
+// under their key uppercased, with the characters journald does not accept
+// replaced by underscores, like "http.method" as HTTP_METHOD. String values
+// are sent as is, other values as their JSON encoding. The timestamp field is
+// dropped as the journal timestamps entries itself.
+//
+// When the journal socket is missing, as outside of systemd, events are
+// written unchanged to the fallback writer, os.Stderr by default.
+package journaldwriter
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/treavorj/zerolog"
+)
+
+// DefaultSocketPath is the path of the journal native protocol socket.
+const DefaultSocketPath = "/run/systemd/journal/socket"
+
+// Options configures a Writer.
+type Options struct {
+	// SocketPath is the path of the journal socket. Defaults to
+	// DefaultSocketPath.
+	SocketPath string
+
+	// Identifier is sent as SYSLOG_IDENTIFIER. Defaults to the base name of
+	// the executable.
+	Identifier string
+
+	// Fallback receives the events when the journal is not available.
+	// Defaults to os.Stderr. If it implements zerolog.LevelWriter, the level
+	// of the event is passed.
+	Fallback io.Writer
+
+	// Sink, if not nil, receives a WriteFailure error for each entry that
+	// cannot be sent to the journal. They are otherwise reported to
+	// ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+// Writer is a zerolog.LevelWriter sending events to the systemd journal.
+type Writer struct {
+	opts Options
+	addr *net.UnixAddr
+	conn *net.UnixConn // nil when the journal is not available
+}
+
+// NewWriter returns a Writer sending events to the journal socket of opts,
+// or to opts.Fallback if the socket does not exist.
+func NewWriter(opts Options) *Writer {
+	if opts.SocketPath == "" {
+		opts.SocketPath = DefaultSocketPath
+	}
+	if opts.Identifier == "" {
+		opts.Identifier = filepath.Base(os.Args[0])
+	}
+	if opts.Fallback == nil {
+		opts.Fallback = os.Stderr
+	}
+	w := &Writer{
+		opts: opts,
+		addr: &net.UnixAddr{Name: opts.SocketPath, Net: "unixgram"},
+	}
+	if _, err := os.Stat(opts.SocketPath); err == nil {
+		w.conn, _ = net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+	}
+	return w
+}
+
+// Enabled reports whether events are sent to the journal rather than to the
+// fallback writer.
+func (w *Writer) Enabled() bool {
+	return w.conn != nil
+}
+
+// Write implements io.Writer. The level is read from the event.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. If the entry can't be sent, the
+// error is reported to opts.Sink or zerolog.ErrorHandler and the event is
+// written to the fallback writer.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	if w.conn == nil {
+		return w.fallback(level, p)
+	}
+	evt, err := zerolog.DecodeEvent(p)
+	if err != nil {
+		return 0, fmt.Errorf("journaldwriter: %v", err)
+	}
+	if level == zerolog.NoLevel {
+		s, _ := evt[zerolog.LevelFieldName].(string)
+		level, _ = zerolog.ParseLevel(s)
+	}
+	if err := w.send(w.entry(level, evt)); err != nil {
+		zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, level, fmt.Errorf("journaldwriter: %v", err))
+		return w.fallback(level, p)
+	}
+	return len(p), nil
+}
+
+// Close closes the connection to the journal.
+func (w *Writer) Close() error {
+	if w.conn == nil {
+		return nil
+	}
+	return w.conn.Close()
+}
+
+func (w *Writer) fallback(level zerolog.Level, p []byte) (int, error) {
+	if lw, ok := w.opts.Fallback.(zerolog.LevelWriter); ok {
+		return lw.WriteLevel(level, p)
+	}
+	return w.opts.Fallback.Write(p)
+}
+
+// entry serializes evt in the journal native format.
+func (w *Writer) entry(level zerolog.Level, evt map[string]interface{}) []byte {
+	var b []byte
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	b = appendField(b, "MESSAGE", msg)
+	b = appendField(b, "PRIORITY", strconv.Itoa(priority(level)))
+	b = appendField(b, "SYSLOG_IDENTIFIER", w.opts.Identifier)
+	keys := make([]string, 0, len(evt))
+	for key := range evt {
+		switch key {
+		case zerolog.MessageFieldName, zerolog.LevelFieldName, zerolog.TimestampFieldName:
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		b = appendField(b, fieldName(key), fieldValue(evt[key]))
+	}
+	return b
+}
+
+// send writes entry to the journal. Entries too large for a datagram are
+// written to a temporary file which descriptor is passed instead.
+func (w *Writer) send(entry []byte) error {
+	_, _, err := w.conn.WriteMsgUnix(entry, nil, w.addr)
+	if err == nil || !(errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)) {
+		return err
+	}
+	f, err := ioutil.TempFile("/dev/shm", "journal.")
+	if err != nil {
+		if f, err = ioutil.TempFile("", "journal."); err != nil {
+			return err
+		}
+	}
+	defer f.Close()
+	if err := os.Remove(f.Name()); err != nil {
+		return err
+	}
+	if _, err := f.Write(entry); err != nil {
+		return err
+	}
+	_, _, err = w.conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), w.addr)
+	return err
+}
+
+// priority returns the syslog priority of level.
+func priority(level zerolog.Level) int {
+	switch level {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return 7 // debug
+	case zerolog.InfoLevel:
+		return 6 // info
+	case zerolog.WarnLevel:
+		return 4 // warning
+	case zerolog.ErrorLevel:
+		return 3 // err
+	case zerolog.FatalLevel:
+		return 2 // crit
+	case zerolog.PanicLevel:
+		return 0 // emerg
+	}
+	if level < zerolog.TraceLevel {
+		return 7
+	}
+	return 5 // notice
+}
+
+// fieldName converts key to a valid journal field name: uppercase letters,
+// digits and underscores, not starting with an underscore or a digit, at most
+// 64 characters long.
+func fieldName(key string) string {
+	b := make([]byte, 0, len(key))
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			c = '_'
+		}
+		if c == '_' && len(b) == 0 {
+			continue // leading underscores are reserved to trusted fields
+		}
+		b = append(b, c)
+	}
+	if len(b) == 0 || b[0] >= '0' && b[0] <= '9' {
+		b = append([]byte("FIELD_"), b...)
+	}
+	if len(b) > 64 {
+		b = b[:64]
+	}
+	return string(b)
+}
+
+// fieldValue returns the journal value of an event field value.
+func fieldValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	b, err := zerolog.InterfaceMarshalFunc(v)
+	if err != nil {
+		return fmt.Sprintf("[error: %v]", err)
+	}
+	return string(b)
+}
+
+// appendField appends a field to b. Values holding a newline are written in
+// the binary form: the name, a newline, the length of the value as a 64 bits
+// little endian integer and the value.
+func appendField(b []byte, name, value string) []byte {
+	b = append(b, name...)
+	if strings.IndexByte(value, '\n') < 0 {
+		b = append(b, '=')
+	} else {
+		b = append(b, '\n')
+		var n [8]byte
+		binary.LittleEndian.PutUint64(n[:], uint64(len(value)))
+		b = append(b, n[:]...)
+	}
+	b = append(b, value...)
+	return append(b, '\n')
+}
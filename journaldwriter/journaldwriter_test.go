@@ -0,0 +1,174 @@
+//go:build linux && !binary_log
+// +build linux,!binary_log
+
+package journaldwriter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/treavorj/zerolog"
+)
+
+// parseEntry decodes an entry in the journal native format.
+func parseEntry(t *testing.T, b []byte) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for len(b) > 0 {
+		i := bytes.IndexAny(b, "=\n")
+		if i < 0 {
+			t.Fatalf("invalid entry: %q", b)
+		}
+		name := string(b[:i])
+		if b[i] == '=' {
+			j := bytes.IndexByte(b, '\n')
+			fields[name] = string(b[i+1 : j])
+			b = b[j+1:]
+			continue
+		}
+		n := int(binary.LittleEndian.Uint64(b[i+1 : i+9]))
+		fields[name] = string(b[i+9 : i+9+n])
+		b = b[i+9+n+1:]
+	}
+	return fields
+}
+
+func listen(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "socket")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+func TestWriter(t *testing.T) {
+	conn, path := listen(t)
+	w := NewWriter(Options{SocketPath: path, Identifier: "test"})
+	defer w.Close()
+	if !w.Enabled() {
+		t.Fatal("Enabled() = false, want true")
+	}
+	log := zerolog.New(w).With().Timestamp().Logger()
+	log.Warn().
+		Str("http.method", "GET").
+		Int("_n", 1).
+		Str("stack", "a\nb").
+		Dict("req", zerolog.Dict().Str("id", "x")).
+		Msg("hello")
+
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := parseEntry(t, buf[:n])
+	want := map[string]string{
+		"MESSAGE":           "hello",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "test",
+		"HTTP_METHOD":       "GET",
+		"N":                 "1",
+		"STACK":             "a\nb",
+		"REQ":               `{"id":"x"}`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("invalid entry:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestWriterLargeEntry(t *testing.T) {
+	conn, path := listen(t)
+	w := NewWriter(Options{SocketPath: path})
+	defer w.Close()
+	log := zerolog.New(w)
+	big := strings.Repeat("x", 1<<20)
+	log.Error().Msg(big)
+
+	oob := make([]byte, syscall.CmsgSpace(4))
+	_, oobn, _, _, err := conn.ReadMsgUnix(nil, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("no descriptor received: %v", err)
+	}
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	if err != nil || len(fds) != 1 {
+		t.Fatalf("no descriptor received: %v", err)
+	}
+	f := os.NewFile(uintptr(fds[0]), "entry")
+	defer f.Close()
+	f.Seek(0, 0)
+	b, err := ioutil.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := parseEntry(t, b); got["MESSAGE"] != big || got["PRIORITY"] != "3" {
+		t.Errorf("invalid entry: PRIORITY=%q len(MESSAGE)=%d", got["PRIORITY"], len(got["MESSAGE"]))
+	}
+}
+
+func TestWriterFallback(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewWriter(Options{SocketPath: filepath.Join(t.TempDir(), "missing"), Fallback: out})
+	defer w.Close()
+	if w.Enabled() {
+		t.Fatal("Enabled() = true, want false")
+	}
+	log := zerolog.New(w)
+	log.Info().Msg("hello")
+	if got, want := out.String(), `{"level":"info","message":"hello"}`+"\n"; got != want {
+		t.Errorf("invalid fallback output:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	conn, path := listen(t)
+	out := &bytes.Buffer{}
+	var errs []*zerolog.InternalError
+	w := NewWriter(Options{
+		SocketPath: path,
+		Fallback:   out,
+		Sink: zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+			errs = append(errs, err)
+		}),
+	})
+	defer w.Close()
+	// The journal goes away after the writer is created.
+	conn.Close()
+	log := zerolog.New(w)
+	log.Warn().Msg("hello")
+	if len(errs) != 1 || errs[0].Kind != zerolog.WriteFailure || errs[0].Level != zerolog.WarnLevel {
+		t.Errorf("errs = %v, want a single warn WriteFailure", errs)
+	}
+	if out.Len() == 0 {
+		t.Error("event not written to the fallback writer")
+	}
+}
+
+func TestFieldName(t *testing.T) {
+	for key, want := range map[string]string{
+		"message":               "MESSAGE",
+		"http.status-code":      "HTTP_STATUS_CODE",
+		"__private":             "PRIVATE",
+		"2xx":                   "FIELD_2XX",
+		"":                      "FIELD_",
+		strings.Repeat("a", 70): strings.Repeat("A", 64),
+	} {
+		if got := fieldName(key); got != want {
+			t.Errorf("fieldName(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
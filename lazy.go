@@ -0,0 +1,37 @@
+package zerolog
+
+// lazyField is a context field evaluated for each event.
+type lazyField struct {
+	key string
+	fn  func() interface{}
+}
+
+// Lazy adds the field key with the value returned by fn, only calling fn if
+// the event is enabled, that is neither filtered by its level nor sampled
+// away. Use it for values expensive to compute. The value is added like
+// with Fields.
+func (e *Event) Lazy(key string, fn func() interface{}) *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("Lazy")
+	e.buf = appendFields(e.buf, []interface{}{key, fn()}, e.stack, e.floatFmt)
+	return e
+}
+
+// Lazy adds the field key to the logger context, with the value returned by
+// fn evaluated for each event of the logger, after its level and sampling
+// checks passed: fn is not called for the disabled events. Lazy fields are
+// added after the other context fields, in order. The value is added like
+// with Fields. fn must be safe for concurrent use.
+func (c Context) Lazy(key string, fn func() interface{}) Context {
+	c.l.lazy = append(c.l.lazy[:len(c.l.lazy):len(c.l.lazy)], lazyField{key: key, fn: fn})
+	return c
+}
+
+// appendLazy evaluates and adds the lazy context fields to e.
+func (e *Event) appendLazy(fields []lazyField) {
+	for _, f := range fields {
+		e.Lazy(f.key, f.fn)
+	}
+}
@@ -0,0 +1,44 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	calls := 0
+	value := func() interface{} {
+		calls++
+		return calls
+	}
+	out := &bytes.Buffer{}
+	log := New(out).Level(InfoLevel).With().Lazy("ctx", value).Str("foo", "bar").Logger()
+
+	log.Debug().Lazy("evt", value).Msg("disabled")
+	if calls != 0 {
+		t.Errorf("fn called %d times for a disabled event", calls)
+	}
+	log.Info().Lazy("evt", value).Lazy("err", func() interface{} { return errors.New("boom") }).Msg("")
+	reset := log.With().Reset().Logger()
+	reset.Info().Msg("")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := strings.Join([]string{
+		`{"level":"info","foo":"bar","ctx":1,"evt":2,"err":"boom"}`,
+		`{"level":"info"}`,
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+
+	sampled := log.Sample(&BasicSampler{N: 2})
+	calls = 0
+	for i := 0; i < 4; i++ {
+		sampled.Info().Msg("")
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times for 2 sampled events", calls)
+	}
+}
@@ -0,0 +1,111 @@
+package zerolog
+
+import (
+	"fmt"
+	"strings"
+)
+
+// levelRanks orders the levels, indexed by uint8(level): a level is enabled
+// if its rank is at least the rank of the logger and global levels. The
+// builtin levels are spaced to leave room for the custom levels registered
+// above them.
+var levelRanks [256]int16
+
+// customLevels are the names of the levels added by RegisterLevel.
+var customLevels = map[Level]string{}
+
+// nextCustomLevel is the value of the next level added by RegisterLevel,
+// above the builtin ones.
+var nextCustomLevel = int(Disabled) + 1
+
+const levelRankStep = 16
+
+func init() {
+	for i := range levelRanks {
+		levelRanks[i] = int16(int8(i)) * levelRankStep
+	}
+}
+
+// rank returns the rank of l in the ordering of the levels.
+func (l Level) rank() int16 {
+	return levelRanks[uint8(l)]
+}
+
+// RegisterLevel adds a level named name, ordered right above the level above
+// and below the next builtin level, like a NOTICE level between InfoLevel and
+// WarnLevel. Levels registered above the same level are ordered by
+// registration. color is the color of the level in ConsoleWriter, like 35
+// for magenta, 0 for none:
+//
+//	var NoticeLevel, _ = zerolog.RegisterLevel("notice", zerolog.InfoLevel, 36)
+//	...
+//	log.WithLevel(NoticeLevel).Msg("certificate renewed")
+//	// {"level":"notice","message":"certificate renewed"}
+//
+// The level is honored by ParseLevel, Level.String, ConsoleWriter, the level
+// filtering of loggers, whose level can be set to it, and the writers
+// comparing levels with Level.AtLeast. Its numeric value is above all the
+// builtin levels.
+//
+// RegisterLevel must be called during initialization, before logging.
+func RegisterLevel(name string, above Level, color int) (Level, error) {
+	if name == "" {
+		return NoLevel, fmt.Errorf("empty level name")
+	}
+	if above < TraceLevelN(128) || above > PanicLevel {
+		return NoLevel, fmt.Errorf("cannot register a level above %v", above)
+	}
+	if _, err := ParseLevel(name); err == nil {
+		return NoLevel, fmt.Errorf("level %q already exists", name)
+	}
+	if nextCustomLevel > 127 {
+		return NoLevel, fmt.Errorf("too many custom levels")
+	}
+	rank := above.rank() + 1
+	for rankUsed(rank) {
+		rank++
+	}
+	if rank >= above.rank()+levelRankStep {
+		return NoLevel, fmt.Errorf("too many custom levels above %v", above)
+	}
+	l := Level(nextCustomLevel)
+	nextCustomLevel++
+	levelRanks[uint8(l)] = rank
+	customLevels[l] = name
+	LevelColors[l] = color
+	abbr := strings.ToUpper(name)
+	if len(abbr) > 3 {
+		abbr = abbr[:3]
+	}
+	FormattedLevels[l] = abbr
+	return l, nil
+}
+
+// AtLeast returns true if l is ordered at or above min, taking the levels
+// added by RegisterLevel into account: a NOTICE level registered above
+// InfoLevel is at least InfoLevel but not WarnLevel, whatever its numeric
+// value. Writers filtering the events by level must use it rather than
+// comparing levels numerically.
+func (l Level) AtLeast(min Level) bool {
+	return l.rank() >= min.rank()
+}
+
+// rankUsed returns true if a level has the rank r.
+func rankUsed(r int16) bool {
+	for _, used := range levelRanks {
+		if used == r {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCustomLevel returns the custom level named s, if any.
+func parseCustomLevel(s string) (Level, bool) {
+	for l, name := range customLevels {
+		if strings.EqualFold(s, LevelFieldMarshalFunc(l)) || strings.EqualFold(s, name) {
+			return l, true
+		}
+	}
+	return NoLevel, false
+}
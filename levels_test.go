@@ -0,0 +1,135 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+// registerTestLevel registers a level once per test binary, whatever the
+// -count flag.
+func registerTestLevel(t *testing.T, name string, above Level, color int) Level {
+	if l, err := ParseLevel(name); err == nil {
+		return l
+	}
+	l, err := RegisterLevel(name, above, color)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestRegisterLevel(t *testing.T) {
+	notice := registerTestLevel(t, "notice", InfoLevel, colorCyan)
+	audit := registerTestLevel(t, "audit", InfoLevel, 0)
+	if _, err := RegisterLevel("NOTICE", DebugLevel, 0); err == nil {
+		t.Error("duplicate level registered")
+	}
+	if _, err := RegisterLevel("above", Disabled, 0); err == nil {
+		t.Error("level registered above Disabled")
+	}
+	if notice.String() != "notice" {
+		t.Errorf("String() = %q", notice.String())
+	}
+	if l, err := ParseLevel("Notice"); err != nil || l != notice {
+		t.Errorf("ParseLevel() = %v, %v", l, err)
+	}
+	if !(notice.rank() > InfoLevel.rank() && audit.rank() > notice.rank() && audit.rank() < WarnLevel.rank()) {
+		t.Errorf("invalid ordering: %d, %d", notice.rank(), audit.rank())
+	}
+
+	out := &bytes.Buffer{}
+	log := New(out).Level(notice)
+	log.Info().Msg("hidden")
+	log.WithLevel(notice).Msg("notice")
+	log.WithLevel(audit).Msg("audit")
+	log.Warn().Msg("warn")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"notice","message":"notice"}` + "\n" +
+		`{"level":"audit","message":"audit"}` + "\n" +
+		`{"level":"warn","message":"warn"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	cw := ConsoleWriter{Out: out, NoColor: true, PartsOrder: []string{LevelFieldName, MessageFieldName}}
+	cw.Write([]byte(`{"level":"notice","message":"renewed"}`))
+	if got, want := out.String(), "NOT renewed\n"; got != want {
+		t.Errorf("console output = %q, want %q", got, want)
+	}
+}
+
+func TestLevelAtLeast(t *testing.T) {
+	notice := registerTestLevel(t, "notice", InfoLevel, colorCyan)
+	tests := []struct {
+		l, min Level
+		want   bool
+	}{
+		{notice, InfoLevel, true},
+		{notice, notice, true},
+		{notice, WarnLevel, false},
+		{notice, ErrorLevel, false},
+		{InfoLevel, notice, false},
+		{WarnLevel, notice, true},
+		{TraceLevel, DebugLevel, false},
+		{ErrorLevel, WarnLevel, true},
+	}
+	for _, tt := range tests {
+		if got := tt.l.AtLeast(tt.min); got != tt.want {
+			t.Errorf("%v.AtLeast(%v) = %v, want %v", tt.l, tt.min, got, tt.want)
+		}
+	}
+}
+
+func TestCustomLevelFilters(t *testing.T) {
+	notice := registerTestLevel(t, "notice", InfoLevel, colorCyan)
+	p := []byte("event\n")
+
+	var filtered, threshold bytes.Buffer
+	fw := &FilteredLevelWriter{Writer: LevelWriterAdapter{&filtered}, Level: ErrorLevel}
+	tw := LevelThreshold(&threshold, WarnLevel)
+	for _, w := range []LevelWriter{fw, tw} {
+		w.WriteLevel(notice, p)
+	}
+	if filtered.Len() != 0 || threshold.Len() != 0 {
+		t.Errorf("notice passed the filters: %q, %q", filtered.String(), threshold.String())
+	}
+	fw.Level, tw.Level = InfoLevel, InfoLevel
+	for _, w := range []LevelWriter{fw, tw} {
+		w.WriteLevel(notice, p)
+	}
+	if filtered.Len() == 0 || threshold.Len() == 0 {
+		t.Error("notice filtered by info filters")
+	}
+
+	var info, warn bytes.Buffer
+	router := NewLevelWriterRouter().
+		Route(DebugLevel, InfoLevel, &info).
+		Route(WarnLevel, PanicLevel, &warn)
+	router.WriteLevel(notice, p)
+	if info.Len() != 0 || warn.Len() != 0 {
+		t.Errorf("notice routed: %q, %q", info.String(), warn.String())
+	}
+
+	b := NewBroker()
+	sub := b.SubscribeLevel(1, DropNewest, WarnLevel)
+	defer sub.Close()
+	b.WriteLevel(notice, p)
+	select {
+	case got := <-sub.C:
+		t.Errorf("notice delivered to warn subscription: %q", got)
+	default:
+	}
+
+	var sampled bytes.Buffer
+	ts := &TailSamplingWriter{Writer: &sampled, Key: "id", ConditionalLevel: DebugLevel, TriggerLevel: ErrorLevel}
+	ts.WriteLevel(DebugLevel, []byte(`{"id":"1","level":"debug"}`+"\n"))
+	ts.WriteLevel(notice, []byte(`{"id":"1","level":"notice"}`+"\n"))
+	if got, want := sampled.String(), `{"id":"1","level":"notice"}`+"\n"; got != want {
+		t.Errorf("notice triggered or buffered by tail sampling: %q", got)
+	}
+
+	if got := gcpSeverity(notice); got != "NOTICE" {
+		t.Errorf("gcpSeverity(notice) = %q", got)
+	}
+}
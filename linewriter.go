@@ -0,0 +1,79 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// lineWriterMaxLine is the size after which an unterminated line is logged
+// as is.
+const lineWriterMaxLine = 64 << 10
+
+// lineWriter logs each line written to it as an event.
+type lineWriter struct {
+	l     Logger
+	level Level
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// WriterLevel returns an io.WriteCloser logging each line written to it as
+// an event at level, with fields (see Event.Fields) attached. It is meant to
+// redirect the output of subprocesses into structured logs:
+//
+//	cmd := exec.Command("backup.sh")
+//	stdout := log.WriterLevel(zerolog.InfoLevel, "cmd", "backup")
+//	stderr := log.WriterLevel(zerolog.WarnLevel, "cmd", "backup")
+//	cmd.Stdout, cmd.Stderr = stdout, stderr
+//	err := cmd.Run()
+//	stdout.Close()
+//	stderr.Close()
+//
+// Partial lines are buffered until their line break is written, or until
+// Close is called. Empty lines are skipped. It is safe for concurrent use.
+func (l Logger) WriterLevel(level Level, fields ...interface{}) io.WriteCloser {
+	if len(fields) > 0 {
+		l = l.With().Fields(fields).Logger()
+	}
+	return &lineWriter{l: l, level: level}
+}
+
+// Write implements io.Writer.
+func (w *lineWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	n = len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.buf = append(w.buf, p...)
+			if len(w.buf) >= lineWriterMaxLine {
+				w.flush()
+			}
+			break
+		}
+		w.buf = append(w.buf, p[:i]...)
+		w.flush()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// flush logs the buffered line. w.mu must be held.
+func (w *lineWriter) flush() {
+	line := bytes.TrimSuffix(w.buf, []byte{'\r'})
+	if len(line) > 0 {
+		w.l.WithLevel(w.level).Msg(string(line))
+	}
+	w.buf = w.buf[:0]
+}
+
+// Close logs the buffered partial line, if any.
+func (w *lineWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.flush()
+	return nil
+}
@@ -0,0 +1,28 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLoggerWriterLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	w := log.WriterLevel(WarnLevel, "cmd", "backup")
+	io.WriteString(w, "first line\r\nsecond ")
+	io.WriteString(w, "line\n\nlast")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := decodeIfBinaryToString(out.Bytes())
+	want := strings.Join([]string{
+		`{"level":"warn","cmd":"backup","message":"first line"}`,
+		`{"level":"warn","cmd":"backup","message":"second line"}`,
+		`{"level":"warn","cmd":"backup","message":"last"}`,
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
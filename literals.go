@@ -0,0 +1,62 @@
+package zerolog
+
+// LiteralStyle is a Transformer rewriting the JSON boolean and null literals
+// of the events, for legacy fixed-schema consumers unable to handle them.
+// Install it per logger with Logger.Transform:
+//
+//	log := zerolog.New(os.Stdout).Transform(zerolog.LiteralStyle{
+//	    BoolAsInt:   true,
+//	    NullAsEmpty: true,
+//	})
+//	log.Log().Bool("ok", true).Interface("v", nil).Send()
+//	// Output: {"ok":1,"v":""}
+//
+// All the literals are rewritten, including the ones nested in objects and
+// arrays. Only the JSON encoding is supported, binary events are left
+// untouched.
+type LiteralStyle struct {
+	// BoolAsInt writes booleans as 1 and 0.
+	BoolAsInt bool
+
+	// NullAsEmpty writes nulls as empty strings.
+	NullAsEmpty bool
+}
+
+// Transform implements the Transformer interface.
+func (s LiteralStyle) Transform(level Level, p []byte) ([]byte, bool) {
+	if !s.BoolAsInt && !s.NullAsEmpty || len(p) == 0 || p[0] != '{' {
+		return p, true
+	}
+	// Replacements are never longer than the literals, so p is rewritten in
+	// place.
+	out := p[:0]
+	for i := 0; i < len(p); {
+		c := p[i]
+		switch {
+		case c == '"':
+			end := skipString(p, i)
+			out = append(out, p[i:end]...)
+			i = end
+			continue
+		case s.BoolAsInt && c == 't' && hasLiteral(p, i, "true"):
+			out = append(out, '1')
+			i += 4
+			continue
+		case s.BoolAsInt && c == 'f' && hasLiteral(p, i, "false"):
+			out = append(out, '0')
+			i += 5
+			continue
+		case s.NullAsEmpty && c == 'n' && hasLiteral(p, i, "null"):
+			out = append(out, '"', '"')
+			i += 4
+			continue
+		}
+		out = append(out, c)
+		i++
+	}
+	return out, true
+}
+
+func hasLiteral(p []byte, i int, lit string) bool {
+	return len(p)-i >= len(lit) && string(p[i:i+len(lit)]) == lit
+}
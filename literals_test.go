@@ -0,0 +1,35 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLiteralStyle(t *testing.T) {
+	tests := []struct {
+		style LiteralStyle
+		want  string
+	}{
+		{LiteralStyle{}, `{"ok":true,"v":null,"a":[false,true],"o":{"n":null},"s":"true null"}` + "\n"},
+		{LiteralStyle{BoolAsInt: true}, `{"ok":1,"v":null,"a":[0,1],"o":{"n":null},"s":"true null"}` + "\n"},
+		{LiteralStyle{NullAsEmpty: true}, `{"ok":true,"v":"","a":[false,true],"o":{"n":""},"s":"true null"}` + "\n"},
+		{LiteralStyle{BoolAsInt: true, NullAsEmpty: true}, `{"ok":1,"v":"","a":[0,1],"o":{"n":""},"s":"true null"}` + "\n"},
+	}
+	for _, tt := range tests {
+		out := &bytes.Buffer{}
+		log := New(out).Transform(tt.style)
+		log.Log().
+			Bool("ok", true).
+			Interface("v", nil).
+			Bools("a", []bool{false, true}).
+			Interface("o", map[string]interface{}{"n": nil}).
+			Str("s", "true null").
+			Send()
+		if got := out.String(); got != tt.want {
+			t.Errorf("%+v: invalid log output:\ngot:  %v\nwant: %v", tt.style, got, tt.want)
+		}
+	}
+}
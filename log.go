@@ -121,6 +121,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
 // Level defines log levels.
@@ -146,9 +147,28 @@ const (
 
 	// TraceLevel defines trace log level.
 	TraceLevel Level = -1
-	// Values less than TraceLevel are handled as numbers.
+	// Values less than TraceLevel are handled as numbers, see TraceLevelN.
 )
 
+// TraceLevelN returns the n-th trace level, n being at least 1: TraceLevelN(1)
+// is TraceLevel, TraceLevelN(2) the finer level below it, and so on. Finer
+// trace levels are meant for dumps, like protocol level logging, which must
+// be separable from normal trace logging:
+//
+//	log.WithLevel(zerolog.TraceLevelN(3)).Bytes("frame", frame).Msg("recv")
+//
+// They are marshaled as numbers, like "-3", or as names, like "trace3", if
+// TraceLevelNames is true. ParseLevel parses both forms.
+func TraceLevelN(n int) Level {
+	if n < 1 {
+		n = 1
+	}
+	if n > 128 {
+		n = 128
+	}
+	return TraceLevel - Level(n-1)
+}
+
 func (l Level) String() string {
 	switch l {
 	case TraceLevel:
@@ -170,6 +190,12 @@ func (l Level) String() string {
 	case NoLevel:
 		return ""
 	}
+	if name, ok := customLevels[l]; ok {
+		return name
+	}
+	if l < TraceLevel && TraceLevelNames {
+		return LevelTraceValue + strconv.Itoa(int(TraceLevel-l)+1)
+	}
 	return strconv.Itoa(int(l))
 }
 
@@ -196,6 +222,15 @@ func ParseLevel(levelStr string) (Level, error) {
 	case strings.EqualFold(levelStr, LevelFieldMarshalFunc(NoLevel)):
 		return NoLevel, nil
 	}
+	if l, ok := parseCustomLevel(levelStr); ok {
+		return l, nil
+	}
+	if t := LevelFieldMarshalFunc(TraceLevel); len(levelStr) > len(t) && strings.EqualFold(levelStr[:len(t)], t) {
+		// Finer trace levels, like "trace2".
+		if n, err := strconv.Atoi(levelStr[len(t):]); err == nil && n >= 1 && n <= 128 {
+			return TraceLevelN(n), nil
+		}
+	}
 	i, err := strconv.Atoi(levelStr)
 	if err != nil {
 		return NoLevel, fmt.Errorf("unknown Level String: '%s', defaulting to NoLevel", levelStr)
@@ -227,13 +262,30 @@ func (l Level) MarshalText() ([]byte, error) {
 // serialization to the Writer. If your Writer is not thread safe,
 // you may consider a sync wrapper.
 type Logger struct {
-	w       LevelWriter
-	sampler Sampler
-	context []byte
-	hooks   []Hook
-	level   Level
-	stack   bool
-	ctx     context.Context
+	w        LevelWriter
+	sampler  Sampler
+	context  []byte
+	hooks    []Hook
+	tr       []Transformer
+	level    Level
+	regLevel *int32 // level set by SetRegisteredLevel, atomic, see Register
+	stack    bool
+	ctx      context.Context
+	ctxLevel Level
+	ctxLvSet bool // ctx carries ctxLevel, see setCtx
+	floatFmt *FloatFormat
+	settings *Settings
+	bytesFmt *BytesFormat
+	dead     *deadLetter
+	ack      *AckPolicy
+	dedup    DeDupMode
+	dedupSet bool // dedup overrides AutoDeDup
+	sealed   []sealedField
+	lazy     []lazyField
+	strict   bool
+	sink     ErrorSink
+	comp     *LogComponent
+	groups   int
 }
 
 // New creates a root logger with given output writer. If the output writer implements
@@ -261,13 +313,10 @@ func Nop() Logger {
 
 // Output duplicates the current logger and sets w as its output.
 func (l Logger) Output(w io.Writer) Logger {
-	l2 := New(w)
-	l2.level = l.level
-	l2.sampler = l.sampler
-	l2.stack = l.stack
-	if len(l.hooks) > 0 {
-		l2.hooks = append(l2.hooks, l.hooks...)
-	}
+	l2 := l
+	l2.w = New(w).w
+	l2.hooks = append([]Hook(nil), l.hooks...)
+	l2.tr = append([]Transformer(nil), l.tr...)
 	if l.context != nil {
 		l2.context = make([]byte, len(l.context), cap(l.context))
 		copy(l2.context, l.context)
@@ -289,6 +338,20 @@ func (l Logger) With() Context {
 	return Context{l}
 }
 
+// Namespace creates a child logger nesting the fields added next, to its
+// context and to its events, under the field name, preventing key collisions
+// between the components sharing a logger:
+//
+//	dbLog := reqLog.Namespace("db")
+//	dbLog.Info().Str("query", q).Dur("took", d).Msg("query done")
+//	// {"level":"info","request_id":"42","db":{"query":"SELECT 1","took":3},"message":"query done"}
+//
+// The fields of the parent logger and the fields added by hooks, like the
+// timestamp, are not nested. It is a shorthand for With().Group(name).Logger().
+func (l Logger) Namespace(name string) Logger {
+	return l.With().Group(name).Logger()
+}
+
 // UpdateContext updates the internal logger's context.
 //
 // Caution: This method is not concurrency safe.
@@ -305,25 +368,71 @@ func (l *Logger) UpdateContext(update func(c Context) Context) {
 	}
 	c := update(Context{*l})
 	l.context = c.l.context
+	l.groups = c.l.groups
 }
 
 // Level creates a child logger with the minimum accepted level set to level.
 func (l Logger) Level(lvl Level) Logger {
 	l.level = lvl
+	l.regLevel = nil
 	return l
 }
 
 // GetLevel returns the current Level of l.
 func (l Logger) GetLevel() Level {
+	if l.regLevel != nil {
+		return Level(atomic.LoadInt32(l.regLevel))
+	}
 	return l.level
 }
 
+// FloatFormat creates a child logger formatting its float fields using ff
+// instead of the global FloatingPointPrecision. Only fields added after this
+// call are affected, including those added by Fields and the LogArrayMarshaler
+// and LogObjectMarshaler values. The sub-events created by Dict and the
+// arrays created by Arr are encoded before being added to the event: their
+// floats keep the global formatting.
+func (l Logger) FloatFormat(ff FloatFormat) Logger {
+	if l.floatFmt != nil {
+		// Keep the non-finite encoding of Strict.
+		ff.nullNonFinite = l.floatFmt.nullNonFinite
+	}
+	l.floatFmt = &ff
+	return l
+}
+
+// BytesFormat creates a child logger formatting its Bytes and Hex fields
+// using bf. Only fields added after this call are affected.
+func (l Logger) BytesFormat(bf BytesFormat) Logger {
+	l.bytesFmt = &bf
+	return l
+}
+
 // Sample returns a logger with the s sampler.
 func (l Logger) Sample(s Sampler) Logger {
 	l.sampler = s
 	return l
 }
 
+// DeadLetter returns a logger sending the events dropped by its sampler to w
+// instead of discarding them, so sampling policies can be audited. If s is not
+// nil, only the dropped events sampled by s are sent to w.
+//
+// Note that dropped events sent to the dead letter writer are enabled: field
+// methods and Func are evaluated for them like for any other event.
+func (l Logger) DeadLetter(w io.Writer, s Sampler) Logger {
+	if w == nil {
+		l.dead = nil
+		return l
+	}
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	l.dead = &deadLetter{w: lw, sampler: s}
+	return l
+}
+
 // Hook returns a logger with the h Hook.
 func (l Logger) Hook(hooks ...Hook) Logger {
 	if len(hooks) == 0 {
@@ -335,6 +444,51 @@ func (l Logger) Hook(hooks ...Hook) Logger {
 	return l
 }
 
+// Transform returns a logger with the t Transformers appended to its
+// transformer chain. Transformers are run in order on every encoded event,
+// after hooks and before the event is written.
+func (l Logger) Transform(t ...Transformer) Logger {
+	if len(t) == 0 {
+		return l
+	}
+	newTr := make([]Transformer, len(l.tr), len(l.tr)+len(t))
+	copy(newTr, l.tr)
+	l.tr = append(newTr, t...)
+	return l
+}
+
+// AckPolicy returns a logger using p to acknowledge the events created with
+// Critical instead of DefaultAckPolicy.
+func (l Logger) AckPolicy(p AckPolicy) Logger {
+	l.ack = &p
+	return l
+}
+
+// Critical starts a new message with info level for an event that must not be
+// lost, like an event doubling as a business record. Critical events are not
+// subject to the logger's level and sampler, only to the Disabled level.
+//
+// Msg only returns once the writer acknowledged the event, according to the
+// logger's AckPolicy. If the writer implements AckWriter, its WriteAck method
+// is used, otherwise the event is considered acknowledged once written. Events
+// that could not be acknowledged are reported to the logger's ErrorSink, or to
+// ErrorHandler.
+//
+// You must call Msg on the returned event in order to send the event.
+func (l *Logger) Critical() *Event {
+	if l.w == nil || l.GetLevel() == Disabled || GlobalLevel() == Disabled {
+		return nil
+	}
+	e := l.initEvent(l.w, InfoLevel, nil)
+	if l.ack != nil {
+		e.ack = l.ack
+	} else {
+		p := DefaultAckPolicy
+		e.ack = &p
+	}
+	return e
+}
+
 // Trace starts a new message with trace level.
 //
 // You must call Msg on the returned event in order to send the event.
@@ -480,39 +634,118 @@ func (l Logger) Write(p []byte) (n int, err error) {
 }
 
 func (l *Logger) newEvent(level Level, done func(string)) *Event {
-	enabled := l.should(level)
-	if !enabled {
+	w := l.w
+	// enabled, with the static level check inlined: this is the whole cost
+	// of the disabled levels.
+	if dyn := l.dynamicLevel(); dyn && !l.dynamicEnabled(level) || !dyn && !l.levelEnabled(level) {
 		if done != nil {
 			done("")
 		}
 		return nil
 	}
-	e := newEvent(l.w, level)
+	if !l.sample(level) {
+		if !l.dead.sample(level) {
+			if done != nil {
+				done("")
+			}
+			return nil
+		}
+		w = l.dead.w
+	}
+	return l.initEvent(w, level, done)
+}
+
+// initEvent creates an event populated with the logger's context.
+func (l *Logger) initEvent(w LevelWriter, level Level, done func(string)) *Event {
+	e := getEvent(w, level)
 	e.done = done
 	e.ch = l.hooks
+	e.tr = l.tr
 	e.ctx = l.ctx
-	if level != NoLevel && LevelFieldName != "" {
-		e.Str(LevelFieldName, LevelFieldMarshalFunc(level))
+	e.floatFmt = l.floatFmt
+	e.settings = l.settings
+	e.bytesFmt = l.bytesFmt
+	e.dedup = l.dedup
+	if !l.dedupSet {
+		e.dedup = AutoDeDup
+	}
+	e.sealed = l.sealed
+	e.strict = l.strict
+	e.sink = l.sink
+	e.groups = l.groups
+	if name := l.settings.levelFieldName(); level != NoLevel && name != "" {
+		e.Str(name, l.settings.levelFieldValue(level))
 	}
 	if len(l.context) > 1 {
 		e.buf = enc.AppendObjectData(e.buf, l.context)
 	}
+	if len(l.lazy) > 0 {
+		e.appendLazy(l.lazy)
+	}
 	if l.stack {
 		e.Stack()
 	}
 	return e
 }
 
-// should returns true if the log event should be logged.
-func (l *Logger) should(lvl Level) bool {
+// enabled returns true if lvl passes the logger and global levels.
+func (l *Logger) enabled(lvl Level) bool {
+	if l.dynamicLevel() {
+		return l.dynamicEnabled(lvl)
+	}
+	return l.levelEnabled(lvl)
+}
+
+// dynamicLevel returns true if the level of l is set by SetRegisteredLevel,
+// its component or its context.
+func (l *Logger) dynamicLevel() bool {
+	return l.regLevel != nil || l.comp != nil || l.ctxLvSet
+}
+
+// levelEnabled is enabled for the loggers which level is only set by Level.
+func (l *Logger) levelEnabled(lvl Level) bool {
+	r := lvl.rank()
+	return l.w != nil && r >= l.level.rank() && r >= GlobalLevel().rank()
+}
+
+// dynamicEnabled is enabled for the loggers which level is set by
+// SetRegisteredLevel, their component or their context.
+func (l *Logger) dynamicEnabled(lvl Level) bool {
 	if l.w == nil {
 		return false
 	}
-	if lvl < l.level || lvl < GlobalLevel() {
-		return false
+	level := l.GetLevel()
+	if l.comp != nil {
+		if compLvl, ok := l.comp.Level(); ok {
+			level = compLvl
+		}
+	}
+	if l.ctxLvSet {
+		level = l.ctxLevel
 	}
+	r := lvl.rank()
+	return r >= level.rank() && r >= GlobalLevel().rank()
+}
+
+// sample returns true if the log event is part of the logger's sample.
+func (l *Logger) sample(lvl Level) bool {
 	if l.sampler != nil && !samplingDisabled() {
 		return l.sampler.Sample(lvl)
 	}
 	return true
 }
+
+// deadLetter receives the events dropped by a logger's sampler.
+type deadLetter struct {
+	w       LevelWriter
+	sampler Sampler
+}
+
+// sample returns true if a dropped event should be sent to the dead letter
+// writer.
+func (d *deadLetter) sample(lvl Level) bool {
+	if d == nil {
+		return false
+	}
+	return d.sampler == nil || d.sampler.Sample(lvl)
+}
@@ -53,6 +53,13 @@ func Trace() *zerolog.Event {
 	return Logger.Trace()
 }
 
+// Mark emits a minimal trace level event marking the checkpoint name, with
+// the monotonic time and the goroutine id. Building with the zerolog_nomark
+// tag compiles marks out.
+func Mark(name string) {
+	Logger.Mark(name)
+}
+
 // Debug starts a new message with debug level.
 //
 // You must call Msg on the returned event in order to send the event.
@@ -97,6 +104,14 @@ func Panic() *zerolog.Event {
 	return Logger.Panic()
 }
 
+// Critical starts a new message with info level for an event that must not be
+// lost. Msg only returns once the writer acknowledged the event.
+//
+// You must call Msg on the returned event in order to send the event.
+func Critical() *zerolog.Event {
+	return Logger.Critical()
+}
+
 // WithLevel starts a new message with level.
 //
 // You must call Msg on the returned event in order to send the event.
@@ -0,0 +1,54 @@
+package log
+
+import (
+	"sync"
+
+	"github.com/treavorj/zerolog"
+)
+
+// TB is the subset of testing.TB used by SetLoggerForTest.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+var testLogger struct {
+	sync.Mutex
+	owner TB
+	depth int
+}
+
+// SetLoggerForTest replaces the global Logger with l for the duration of the
+// test t, restoring the previous one on cleanup, so tests touching the
+// global logger don't leak state to each other.
+//
+// As the global logger is shared, t must not run in parallel with other
+// tests: the test fails if another test already replaced the logger and did
+// not complete yet. With Go 1.17 and later, t.Parallel also panics once
+// called, or if called before.
+func SetLoggerForTest(t TB, l zerolog.Logger) {
+	t.Helper()
+	if s, ok := t.(interface{ Setenv(key, value string) }); ok {
+		// Setenv forbids the parallel execution of t.
+		s.Setenv("ZEROLOG_TEST_LOGGER", "1")
+	}
+	testLogger.Lock()
+	defer testLogger.Unlock()
+	if testLogger.owner != nil && testLogger.owner != t {
+		t.Fatalf("log: global logger already replaced by another running test")
+		return
+	}
+	previous := Logger
+	testLogger.owner = t
+	testLogger.depth++
+	Logger = l
+	t.Cleanup(func() {
+		testLogger.Lock()
+		defer testLogger.Unlock()
+		Logger = previous
+		if testLogger.depth--; testLogger.depth == 0 {
+			testLogger.owner = nil
+		}
+	})
+}
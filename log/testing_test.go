@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+type fakeTB struct {
+	failed   string
+	cleanups []func()
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Fatalf(format string, args ...interface{}) {
+	t.failed = fmt.Sprintf(format, args...)
+}
+
+func (t *fakeTB) Cleanup(f func()) {
+	t.cleanups = append(t.cleanups, f)
+}
+
+func (t *fakeTB) cleanup() {
+	for i := len(t.cleanups) - 1; i >= 0; i-- {
+		t.cleanups[i]()
+	}
+}
+
+func TestSetLoggerForTest(t *testing.T) {
+	previous := Logger
+	out := &bytes.Buffer{}
+	t.Run("swap", func(t *testing.T) {
+		SetLoggerForTest(t, zerolog.New(out))
+		Print("hello")
+		if got, want := cbor.DecodeIfBinaryToString(out.Bytes()), `{"level":"debug","message":"hello"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+	if Logger.GetLevel() != previous.GetLevel() || fmt.Sprint(Logger) != fmt.Sprint(previous) {
+		t.Error("global logger not restored")
+	}
+
+	t1, t2 := &fakeTB{}, &fakeTB{}
+	SetLoggerForTest(t1, zerolog.Nop())
+	SetLoggerForTest(t1, zerolog.Nop())
+	SetLoggerForTest(t2, zerolog.Nop())
+	if t1.failed != "" || t2.failed == "" {
+		t.Errorf("concurrent use not detected: %q, %q", t1.failed, t2.failed)
+	}
+	t1.cleanup()
+	if fmt.Sprint(Logger) != fmt.Sprint(previous) {
+		t.Error("global logger not restored")
+	}
+	SetLoggerForTest(t2, zerolog.Nop())
+	t2.cleanup()
+	if t2.cleanups == nil || testLogger.owner != nil {
+		t.Error("logger not released")
+	}
+}
@@ -304,6 +304,19 @@ func ExampleEvent_EmbedObject() {
 	// Output: {"foo":"bar","price":"$64.49","message":"hello world"}
 }
 
+func ExampleEvent_EmbedRawJSON() {
+	log := zerolog.New(os.Stdout)
+
+	meta := []byte(`{"region":"eu-west-1","zone":"b"}`)
+
+	log.Log().
+		Str("foo", "bar").
+		EmbedRawJSON(meta).
+		Msg("hello world")
+
+	// Output: {"foo":"bar","region":"eu-west-1","zone":"b","message":"hello world"}
+}
+
 func ExampleEvent_Interface() {
 	log := zerolog.New(os.Stdout)
 
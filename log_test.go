@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"reflect"
 	"runtime"
@@ -491,6 +492,63 @@ func TestFieldsDisabled(t *testing.T) {
 	}
 }
 
+func TestGroup(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Info().Str("method", "GET").
+		Group("req").Str("path", "/").Group("headers").Str("accept", "*/*").EndGroup().Int("size", 42).EndGroup().
+		Int("status", 200).
+		EndGroup().
+		Msg("served")
+	log.Info().Group("req").Str("path", "/").Msgf("%d", 1)
+	log.Info().Dict("dict", Dict().Group("sub").Int("n", 1)).Msg("")
+
+	ctx := log.With().Str("service", "api").Group("req").Str("id", "42").Logger()
+	ctx.Info().Int("status", 200).Msg("served")
+	closed := ctx.With().Str("user", "bob").EndGroup().Str("version", "1").Logger()
+	closed.Info().Msg("")
+	reset := ctx.With().Reset().Logger()
+	reset.Info().Int("n", 1).Msg("")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","method":"GET","req":{"path":"/","headers":{"accept":"*/*"},"size":42},"status":200,"message":"served"}` + "\n" +
+		`{"level":"info","req":{"path":"/"},"message":"1"}` + "\n" +
+		`{"level":"info","dict":{"sub":{"n":1}}}` + "\n" +
+		`{"level":"info","service":"api","req":{"id":"42","status":200},"message":"served"}` + "\n" +
+		`{"level":"info","service":"api","req":{"id":"42","user":"bob"},"version":"1"}` + "\n" +
+		`{"level":"info","n":1}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestNamespace(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	out := &bytes.Buffer{}
+	reqLog := New(out).With().Str("request_id", "42").Timestamp().Logger()
+	dbLog := reqLog.Namespace("db").With().Str("host", "primary").Logger()
+	dbLog.Info().Str("query", "SELECT 1").Msg("query done")
+	cacheLog := dbLog.Namespace("cache")
+	cacheLog.UpdateContext(func(c Context) Context {
+		return c.Bool("hit", true)
+	})
+	cacheLog.Info().Msg("")
+	reqLog.Info().Str("query", "none").Msg("")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","request_id":"42","db":{"host":"primary","query":"SELECT 1"},"time":"2001-02-03T04:05:06Z","message":"query done"}` + "\n" +
+		`{"level":"info","request_id":"42","db":{"host":"primary","cache":{"hit":true}},"time":"2001-02-03T04:05:06Z"}` + "\n" +
+		`{"level":"info","request_id":"42","query":"none","time":"2001-02-03T04:05:06Z"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestMsgf(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out)
@@ -500,6 +558,18 @@ func TestMsgf(t *testing.T) {
 	}
 }
 
+func TestNormalizeMessageNewlines(t *testing.T) {
+	defer func() { NormalizeMessageNewlines = false }()
+	NormalizeMessageNewlines = true
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().Msg("a\r\nb\rc\n\n")
+	log.Log().Msgf("%s\r\n", "d")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"message":"a\nb\nc"}`+"\n"+`{"message":"d"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestWithAndFieldsCombined(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out).With().Str("f1", "val").Str("f2", "val").Logger()
@@ -714,6 +784,29 @@ func TestEventTimestamp(t *testing.T) {
 	}
 }
 
+func TestEventAt(t *testing.T) {
+	TimestampFunc = func() time.Time {
+		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
+	}
+	defer func() {
+		TimestampFunc = time.Now
+	}()
+	at := time.Date(1999, time.December, 31, 23, 59, 59, 0, time.UTC)
+	out := &bytes.Buffer{}
+	log := New(out).With().Timestamp().Logger()
+	log.Log().At(at).Msg("context")
+	log.Log().At(at).Timestamp().Msg("explicit")
+	log = New(out)
+	log.Log().At(at).Msg("no timestamp")
+
+	want := `{"time":"1999-12-31T23:59:59Z","message":"context"}` + "\n" +
+		`{"time":"1999-12-31T23:59:59Z","message":"explicit"}` + "\n" +
+		`{"time":"1999-12-31T23:59:59Z","message":"no timestamp"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestOutputWithoutTimestamp(t *testing.T) {
 	ignoredOut := &bytes.Buffer{}
 	out := &bytes.Buffer{}
@@ -725,6 +818,58 @@ func TestOutputWithoutTimestamp(t *testing.T) {
 	}
 }
 
+type discardSink struct{}
+
+func (discardSink) HandleError(*InternalError) {}
+
+func TestOutputCopiesAllFields(t *testing.T) {
+	regLevel := int32(WarnLevel)
+	l := Logger{
+		w:        LevelWriterAdapter{io.Discard},
+		sampler:  &BasicSampler{N: 2},
+		context:  []byte("{"),
+		hooks:    []Hook{LevelHook{}},
+		tr:       []Transformer{ECS{}},
+		level:    InfoLevel,
+		regLevel: &regLevel,
+		stack:    true,
+		ctx:      context.Background(),
+		ctxLevel: ErrorLevel,
+		ctxLvSet: true,
+		floatFmt: &FloatFormat{},
+		settings: &Settings{},
+		bytesFmt: &BytesFormat{},
+		dead:     &deadLetter{},
+		ack:      &AckPolicy{},
+		dedup:    DeDupModeDeep,
+		dedupSet: true,
+		sealed:   []sealedField{{}},
+		lazy:     []lazyField{{key: "k"}},
+		strict:   true,
+		sink:     discardSink{},
+		comp:     &LogComponent{},
+		groups:   1,
+	}
+	v := reflect.ValueOf(l)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Field(i).IsZero() {
+			t.Fatalf("field %s is not set by the test", v.Type().Field(i).Name)
+		}
+	}
+
+	l2 := l.Output(&bytes.Buffer{})
+	if l2.w == l.w {
+		t.Error("writer not replaced")
+	}
+	if &l2.hooks[0] == &l.hooks[0] || &l2.tr[0] == &l.tr[0] || &l2.context[0] == &l.context[0] {
+		t.Error("hooks, transformers or context not copied")
+	}
+	l2.w = l.w
+	if !reflect.DeepEqual(l, l2) {
+		t.Errorf("fields not carried over:\ngot:  %+v\nwant: %+v", l2, l)
+	}
+}
+
 func TestOutputWithTimestamp(t *testing.T) {
 	TimestampFunc = func() time.Time {
 		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
@@ -834,6 +979,20 @@ func TestCallerMarshalFunc(t *testing.T) {
 	}
 }
 
+func TestCallerFuncFieldName(t *testing.T) {
+	CallerFuncFieldName = "func"
+	defer func() { CallerFuncFieldName = "" }()
+	out := &bytes.Buffer{}
+	log := New(out)
+
+	_, file, line, _ := runtime.Caller(0)
+	caller := fmt.Sprintf("%s:%d", file, line+2)
+	log.Log().Caller().Msg("msg")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"caller":"`+caller+`","func":"zerolog.TestCallerFuncFieldName","message":"msg"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestLevelFieldMarshalFunc(t *testing.T) {
 	origLevelFieldMarshalFunc := LevelFieldMarshalFunc
 	LevelFieldMarshalFunc = func(l Level) string {
@@ -959,6 +1118,24 @@ func TestLevel_MarshalText(t *testing.T) {
 	}
 }
 
+func TestTraceLevelN(t *testing.T) {
+	if TraceLevelN(1) != TraceLevel || TraceLevelN(3) != Level(-3) {
+		t.Errorf("TraceLevelN(1), TraceLevelN(3) = %d, %d, want -1, -3", TraceLevelN(1), TraceLevelN(3))
+	}
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(TraceLevelN(2))
+	out := &bytes.Buffer{}
+	log := New(out).Level(TraceLevelN(2))
+	log.WithLevel(TraceLevelN(2)).Msg("")
+	log.WithLevel(TraceLevelN(3)).Msg("")
+	TraceLevelNames = true
+	defer func() { TraceLevelNames = false }()
+	log.WithLevel(TraceLevelN(2)).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"-2"}`+"\n"+`{"level":"trace2"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestParseLevel(t *testing.T) {
 	type args struct {
 		levelStr string
@@ -981,6 +1158,9 @@ func TestParseLevel(t *testing.T) {
 		{"-1", args{"-1"}, TraceLevel, false},
 		{"-2", args{"-2"}, Level(-2), false},
 		{"-3", args{"-3"}, Level(-3), false},
+		{"trace2", args{"trace2"}, TraceLevelN(2), false},
+		{"TRACE3", args{"TRACE3"}, Level(-3), false},
+		{"trace0", args{"trace0"}, NoLevel, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1042,3 +1222,83 @@ func TestHTMLNoEscaping(t *testing.T) {
 		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
 	}
 }
+
+func TestLargeIntAsString(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().
+		Uint64Str("id", 42).
+		Int64Str("neg", -42).
+		Uint64("big", 1<<60).
+		Int64("small", 1<<53).
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"id":"42","neg":"-42","big":1152921504606846976,"small":9007199254740992}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	LargeIntAsString = true
+	defer func() {
+		LargeIntAsString = false
+	}()
+	out.Reset()
+	log.Log().
+		Uint64("big", 1<<60).
+		Int64("neg", -(1 << 60)).
+		Int64("small", 1<<53).
+		Array("arr", Arr().Uint64(1<<60).Int(1)).
+		Fields(map[string]interface{}{"f": uint64(1 << 60)}).
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"big":"1152921504606846976","neg":"-1152921504606846976","small":9007199254740992,"arr":["1152921504606846976",1],"f":"1152921504606846976"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	out.Reset()
+	ctxLog := log.With().Ints64("ids", []int64{1, 1 << 60}).Uints64("uids", []uint64{1 << 60}).Logger()
+	ctxLog.Log().
+		Ints("ints", []int{1, -(1 << 60)}).
+		Ints64("ints64", []int64{1 << 60, 2}).
+		Uints("uints", []uint{1 << 60}).
+		Uints64("uints64", []uint64{}).
+		Fields([]interface{}{"fi", []int{1 << 60}, "fu", []uint64{3, 1 << 60}}).
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"ids":[1,"1152921504606846976"],"uids":["1152921504606846976"],`+
+		`"ints":[1,"-1152921504606846976"],"ints64":["1152921504606846976",2],"uints":["1152921504606846976"],"uints64":[],`+
+		`"fi":["1152921504606846976"],"fu":[3,"1152921504606846976"]}`+"\n"; got != want {
+		t.Errorf("invalid log output with slices:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDurBucket(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().DurBucket("latency", 10*time.Millisecond, time.Millisecond).Msg("")
+	log.Log().DurBucket("latency", 500*time.Microsecond, time.Millisecond).Msg("")
+	log.Log().DurBucket("latency", 16*time.Millisecond, 500*time.Microsecond).Msg("")
+	log.Log().DurBucket("latency", time.Second, 0).Msg("")
+	log.Log().DurBucket("latency", -time.Millisecond, time.Millisecond).Msg("")
+	secs := log.Settings(Settings{DurationFieldUnit: time.Second})
+	secs.Log().DurBucket("latency", 3*time.Second, time.Second).Msg("")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"latency":10,"latency_bucket":"8-16ms"}` + "\n" +
+		`{"latency":0.5,"latency_bucket":"0-1ms"}` + "\n" +
+		`{"latency":16,"latency_bucket":"16-32ms"}` + "\n" +
+		`{"latency":1000}` + "\n" +
+		`{"latency":-1}` + "\n" +
+		`{"latency":3,"latency_bucket":"2-4s"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestStrsFunc(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	items := []struct{ name string }{{"a"}, {"b\"c"}, {"d"}}
+	log.Log().StrsFunc("items", len(items), func(i int) string { return items[i].name }).Msg("")
+	log.Log().StrsFunc("empty", 0, nil).Msg("")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"items":["a","b\"c","d"]}` + "\n" +
+		`{"empty":[]}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,49 @@
+//go:build !zerolog_nomark
+// +build !zerolog_nomark
+
+package zerolog
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// markEpoch is the reference of the monotonic clock used by Mark.
+var markEpoch = time.Now()
+
+// Mark emits a minimal trace level event with name as message, the
+// monotonic nanoseconds elapsed since the program started as the
+// MarkMonotonicFieldName field and the current goroutine id as the
+// MarkGoroutineFieldName field. The level field, the logger context and hooks
+// are not added.
+//
+// Marks are meant to be sprinkled temporarily to follow the execution flow
+// while debugging. Building with the zerolog_nomark tag compiles them out.
+func (l *Logger) Mark(name string) {
+	if !l.enabled(TraceLevel) {
+		return
+	}
+	e := newEvent(l.w, TraceLevel)
+	e.tr = l.tr
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, l.settings.messageFieldName()), name)
+	e.buf = enc.AppendInt64(enc.AppendKey(e.buf, MarkMonotonicFieldName), int64(time.Since(markEpoch)))
+	e.buf = enc.AppendUint64(enc.AppendKey(e.buf, MarkGoroutineFieldName), goroutineID())
+	if err := e.write(); err != nil {
+		reportError(l.sink, l.settings, WriteFailure, TraceLevel, err)
+	}
+}
+
+// goroutineID returns the id of the calling goroutine, parsed from the
+// header of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
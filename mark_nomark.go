@@ -0,0 +1,7 @@
+//go:build zerolog_nomark
+// +build zerolog_nomark
+
+package zerolog
+
+// Mark does nothing as marks are compiled out by the zerolog_nomark build tag.
+func (l *Logger) Mark(name string) {}
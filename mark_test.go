@@ -0,0 +1,47 @@
+//go:build !zerolog_nomark
+// +build !zerolog_nomark
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestMark(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("foo", "bar").Logger()
+	log.Mark("before")
+	log.Mark("after")
+
+	d := json.NewDecoder(bytes.NewReader(decodeIfBinaryToBytes(out.Bytes())))
+	var prev float64
+	for _, name := range []string{"before", "after"} {
+		var evt map[string]interface{}
+		if err := d.Decode(&evt); err != nil {
+			t.Fatal(err)
+		}
+		if evt[LevelFieldName] != nil || evt["foo"] != nil {
+			t.Errorf("unexpected fields in %v", evt)
+		}
+		if evt[MessageFieldName] != name {
+			t.Errorf("message = %v, want %v", evt[MessageFieldName], name)
+		}
+		mono, _ := evt[MarkMonotonicFieldName].(float64)
+		if mono <= prev {
+			t.Errorf("%s = %v, want > %v", MarkMonotonicFieldName, mono, prev)
+		}
+		prev = mono
+		if gid, _ := evt[MarkGoroutineFieldName].(float64); gid == 0 {
+			t.Errorf("missing %s in %v", MarkGoroutineFieldName, evt)
+		}
+	}
+
+	out.Reset()
+	log = log.Level(DebugLevel)
+	log.Mark("filtered")
+	if out.Len() != 0 {
+		t.Errorf("mark not filtered: %s", out.String())
+	}
+}
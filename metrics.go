@@ -0,0 +1,152 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MetricsBackend receives the metric updates derived from events by a
+// MetricsExtractor. It is implemented on top of the application's metrics
+// library, like Prometheus or OpenTelemetry. Methods must be thread safe.
+type MetricsBackend interface {
+	// AddCounter adds delta to the counter name with the given labels.
+	AddCounter(name string, labels map[string]string, delta float64)
+
+	// SetGauge sets the gauge name with the given labels to value.
+	SetGauge(name string, labels map[string]string, value float64)
+}
+
+// MetricKind defines how a MetricRule updates its metric.
+type MetricKind uint8
+
+const (
+	// MetricCount increments a counter for each matching event.
+	MetricCount MetricKind = iota
+
+	// MetricSum adds the value of a numeric field to a counter.
+	MetricSum
+
+	// MetricGauge sets a gauge to the value of a numeric field.
+	MetricGauge
+)
+
+// MetricRule maps the events it matches to an update of the metric Name.
+type MetricRule struct {
+	// Name is the name of the metric.
+	Name string
+
+	// Kind is the kind of update.
+	Kind MetricKind
+
+	// Levels restricts the rule to events of these levels. All levels match
+	// if empty.
+	Levels []Level
+
+	// Fields restricts the rule to events with these field values, compared
+	// as strings, like {"message": "login failed"}.
+	Fields map[string]string
+
+	// Match, if not nil, restricts the rule to the events for which it
+	// returns true.
+	Match func(level Level, fields map[string]interface{}) bool
+
+	// Field is the numeric field providing the value of MetricSum and
+	// MetricGauge rules. Events without it are ignored.
+	Field string
+
+	// Labels are the fields copied into the labels of the metric. Missing
+	// fields have empty label values.
+	Labels []string
+}
+
+// MetricsExtractor is a Transformer turning existing log statements into
+// metrics, without changing the call sites. Events are left untouched:
+//
+//	log = log.Transform(&zerolog.MetricsExtractor{
+//	    Backend: backend,
+//	    Rules: []zerolog.MetricRule{
+//	        {Name: "login_failures_total", Fields: map[string]string{"message": "login failed"}},
+//	        {Name: "errors_total", Levels: []zerolog.Level{zerolog.ErrorLevel}, Labels: []string{"component"}},
+//	        {Name: "bytes_sent_total", Kind: zerolog.MetricSum, Field: "size"},
+//	        {Name: "queue_depth", Kind: zerolog.MetricGauge, Field: "depth"},
+//	    },
+//	})
+//
+// Events are only decoded when a rule matches their level.
+type MetricsExtractor struct {
+	Backend MetricsBackend
+	Rules   []MetricRule
+}
+
+// Transform implements the Transformer interface.
+func (m *MetricsExtractor) Transform(level Level, p []byte) ([]byte, bool) {
+	if m.Backend == nil {
+		return p, true
+	}
+	var fields map[string]interface{}
+	for i := range m.Rules {
+		r := &m.Rules[i]
+		if !r.matchLevel(level) {
+			continue
+		}
+		if fields == nil {
+			var err error
+			if fields, err = DecodeEvent(p); err != nil {
+				return p, true
+			}
+		}
+		if !r.matchFields(level, fields) {
+			continue
+		}
+		var value float64
+		if r.Kind != MetricCount {
+			n, ok := fields[r.Field].(json.Number)
+			if !ok {
+				continue
+			}
+			var err error
+			if value, err = n.Float64(); err != nil {
+				continue
+			}
+		}
+		labels := make(map[string]string, len(r.Labels))
+		for _, key := range r.Labels {
+			if v, ok := fields[key]; ok {
+				labels[key] = fmt.Sprint(v)
+			} else {
+				labels[key] = ""
+			}
+		}
+		switch r.Kind {
+		case MetricCount:
+			m.Backend.AddCounter(r.Name, labels, 1)
+		case MetricSum:
+			m.Backend.AddCounter(r.Name, labels, value)
+		case MetricGauge:
+			m.Backend.SetGauge(r.Name, labels, value)
+		}
+	}
+	return p, true
+}
+
+func (r *MetricRule) matchLevel(level Level) bool {
+	if len(r.Levels) == 0 {
+		return true
+	}
+	for _, l := range r.Levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MetricRule) matchFields(level Level, fields map[string]interface{}) bool {
+	for key, want := range r.Fields {
+		v, ok := fields[key]
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	return r.Match == nil || r.Match(level, fields)
+}
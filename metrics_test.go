@@ -0,0 +1,75 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type testMetrics struct {
+	mu      sync.Mutex
+	metrics map[string]float64
+}
+
+func (m *testMetrics) key(name string, labels map[string]string) string {
+	var l []string
+	for k, v := range labels {
+		l = append(l, k+"="+v)
+	}
+	sort.Strings(l)
+	return name + "{" + strings.Join(l, ",") + "}"
+}
+
+func (m *testMetrics) AddCounter(name string, labels map[string]string, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics[m.key(name, labels)] += delta
+}
+
+func (m *testMetrics) SetGauge(name string, labels map[string]string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics[m.key(name, labels)] = value
+}
+
+func TestMetricsExtractor(t *testing.T) {
+	backend := &testMetrics{metrics: map[string]float64{}}
+	out := &bytes.Buffer{}
+	log := New(out).Transform(&MetricsExtractor{
+		Backend: backend,
+		Rules: []MetricRule{
+			{Name: "login_failures", Fields: map[string]string{"message": "login failed"}},
+			{Name: "errors", Levels: []Level{ErrorLevel}, Labels: []string{"component"}},
+			{Name: "bytes", Kind: MetricSum, Field: "size"},
+			{Name: "depth", Kind: MetricGauge, Field: "depth"},
+			{Name: "slow", Match: func(level Level, fields map[string]interface{}) bool {
+				return fmt.Sprint(fields["slow"]) == "true"
+			}},
+		},
+	})
+	log.Warn().Str("user", "bob").Msg("login failed")
+	log.Error().Str("component", "db").Msg("")
+	log.Error().Msg("")
+	log.Info().Int("size", 100).Int("depth", 3).Msg("")
+	log.Info().Int("size", 50).Int("depth", 1).Bool("slow", true).Msg("")
+	log.Info().Str("size", "big").Msg("")
+
+	want := map[string]float64{
+		"login_failures{}":     1,
+		"errors{component=db}": 1,
+		"errors{component=}":   1,
+		"bytes{}":              150,
+		"depth{}":              1,
+		"slow{}":               1,
+	}
+	if !reflect.DeepEqual(backend.metrics, want) {
+		t.Errorf("invalid metrics:\ngot:  %v\nwant: %v", backend.metrics, want)
+	}
+	if n := strings.Count(decodeIfBinaryToString(out.Bytes()), "\n"); n != 6 {
+		t.Errorf("got %d events written, want 6", n)
+	}
+}
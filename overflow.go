@@ -0,0 +1,109 @@
+package zerolog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// FieldOverflow is a Transformer protecting the output from oversized field
+// values, like an accidental megabyte payload. Each top level field which
+// encoded value exceeds MaxSize bytes is replaced by a summary object:
+//
+//	{"truncated":true,"size":1048576,"hash":"9f86d0..."}
+//
+// where hash is the hex encoded SHA-256 of the encoded value. If Attachments
+// is set, the full value is written to it as an attachment event holding the
+// hash, field, size and value fields, so it can be looked up from the
+// summary:
+//
+//	log := zerolog.New(os.Stdout).Transform(&zerolog.FieldOverflow{
+//	    MaxSize:     64 << 10,
+//	    Attachments: attachmentsFile,
+//	})
+//
+// Only the JSON encoding is supported, binary events are left untouched.
+type FieldOverflow struct {
+	// MaxSize is the maximum size in bytes of an encoded field value.
+	MaxSize int
+
+	// Attachments, if not nil, receives the full values of the truncated
+	// fields. If it implements LevelWriter, the level of the event is passed.
+	Attachments io.Writer
+
+	// Sink, if not nil, receives a Truncation error for each truncated
+	// field, and a WriteFailure error for each attachment that cannot be
+	// written. Attachment failures are otherwise reported to ErrorHandler.
+	Sink ErrorSink
+}
+
+// Transform implements the Transformer interface.
+func (f *FieldOverflow) Transform(level Level, p []byte) ([]byte, bool) {
+	if f.MaxSize <= 0 || len(p) <= f.MaxSize || p[0] != '{' {
+		return p, true
+	}
+	var out []byte
+	last := 0 // end of the part of p already copied to out
+	for i := 1; i < len(p) && p[i] == '"'; {
+		keyEnd := skipString(p, i)
+		start := keyEnd + 1 // after the colon
+		end := skipValue(p, start)
+		if end-start > f.MaxSize {
+			if out == nil {
+				out = make([]byte, 0, len(p))
+			}
+			value := p[start:end]
+			sum := sha256.Sum256(value)
+			hash := hex.EncodeToString(sum[:])
+			out = append(out, p[last:start]...)
+			out = append(out, `{"truncated":true,"size":`...)
+			out = strconv.AppendInt(out, int64(len(value)), 10)
+			out = append(out, `,"hash":"`...)
+			out = append(out, hash...)
+			out = append(out, `"}`...)
+			last = end
+			if f.Sink != nil {
+				f.Sink.HandleError(&InternalError{
+					Kind:  Truncation,
+					Level: level,
+					Err:   fmt.Errorf("field %s truncated from %d bytes", p[i:keyEnd], len(value)),
+				})
+			}
+			if f.Attachments != nil {
+				f.attach(level, p[i:keyEnd], hash, value)
+			}
+		}
+		if i = end; i < len(p) && p[i] == ',' {
+			i++
+		}
+	}
+	if out == nil {
+		return p, true
+	}
+	return append(out, p[last:]...), true
+}
+
+// attach writes value to f.Attachments.
+func (f *FieldOverflow) attach(level Level, key []byte, hash string, value []byte) {
+	b := make([]byte, 0, len(value)+len(key)+128)
+	b = append(b, `{"hash":"`...)
+	b = append(b, hash...)
+	b = append(b, `","field":`...)
+	b = append(b, key...)
+	b = append(b, `,"size":`...)
+	b = strconv.AppendInt(b, int64(len(value)), 10)
+	b = append(b, `,"value":`...)
+	b = append(b, value...)
+	b = append(b, "}\n"...)
+	var err error
+	if lw, ok := f.Attachments.(LevelWriter); ok {
+		_, err = lw.WriteLevel(level, b)
+	} else {
+		_, err = f.Attachments.Write(b)
+	}
+	if err != nil {
+		reportError(f.Sink, nil, WriteFailure, level, err)
+	}
+}
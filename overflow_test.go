@@ -0,0 +1,50 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFieldOverflow(t *testing.T) {
+	out := &bytes.Buffer{}
+	att := &bytes.Buffer{}
+	log := New(out).Transform(&FieldOverflow{MaxSize: 16, Attachments: att})
+
+	big := strings.Repeat("x", 20)
+	sum := sha256.Sum256([]byte(`"` + big + `"`))
+	hash := hex.EncodeToString(sum[:])
+
+	log.Info().Str("payload", big).Int("n", 1).Msg("")
+	log.Info().Str("small", "ok").Msg("")
+	log.Info().Int("n", 1).Str("payload", big).Msg("")
+
+	summary := `{"truncated":true,"size":22,"hash":"` + hash + `"}`
+	want := `{"level":"info","payload":` + summary + `,"n":1}` + "\n" +
+		`{"level":"info","small":"ok"}` + "\n" +
+		`{"level":"info","n":1,"payload":` + summary + `}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	attachment := `{"hash":"` + hash + `","field":"payload","size":22,"value":"` + big + `"}` + "\n"
+	if got, want := att.String(), attachment+attachment; got != want {
+		t.Errorf("invalid attachments:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestFieldOverflowAttachmentFailure(t *testing.T) {
+	var errs []*InternalError
+	sink := ErrorSinkFunc(func(err *InternalError) { errs = append(errs, err) })
+	want := errors.New("attachment error")
+	log := New(&bytes.Buffer{}).Transform(&FieldOverflow{MaxSize: 16, Attachments: errWriter{want}, Sink: sink})
+	log.Warn().Str("payload", strings.Repeat("x", 20)).Msg("")
+	if len(errs) != 2 || errs[0].Kind != Truncation || errs[1].Kind != WriteFailure || errs[1].Level != WarnLevel || errs[1].Err != want {
+		t.Errorf("errs = %v, want a truncation and a warn write failure", errs)
+	}
+}
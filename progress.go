@@ -0,0 +1,100 @@
+package zerolog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Progress emits the progress events of a long-running operation. All its
+// events share the ProgressIDFieldName operation id and carry an increasing
+// ProgressSeqFieldName sequence number, on top of the fields of the logger
+// they were created from, which are encoded only once:
+//
+//	p := log.With().Str("job", "import").Logger().Progress(zerolog.InfoLevel, "")
+//	for i, row := range rows {
+//	    ...
+//	    if i%1000 == 0 {
+//	        p.Tick().Int("rows", i).Msg("importing")
+//	    }
+//	}
+//	p.Done().Int("rows", len(rows)).Msg("imported")
+type Progress struct {
+	l     Logger
+	level Level
+	id    string
+	seq   uint64
+}
+
+// Progress returns a Progress emitting events at level with the operation
+// id id. If id is empty, a random id is generated.
+func (l Logger) Progress(level Level, id string) *Progress {
+	if id == "" {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err == nil {
+			id = hex.EncodeToString(b[:])
+		}
+	}
+	return &Progress{
+		l:     l.With().Str(ProgressIDFieldName, id).Logger(),
+		level: level,
+		id:    id,
+	}
+}
+
+// ID returns the operation id.
+func (p *Progress) ID() string {
+	return p.id
+}
+
+// Tick starts a new progress event with the next sequence number. The
+// sequence number is incremented even if the event is disabled.
+//
+// You must call Msg on the returned event in order to send the event.
+func (p *Progress) Tick() *Event {
+	seq := atomic.AddUint64(&p.seq, 1)
+	return p.l.WithLevel(p.level).Uint64(ProgressSeqFieldName, seq)
+}
+
+// Done starts the final progress event, with the next sequence number and
+// the ProgressDoneFieldName field set to true.
+//
+// You must call Msg on the returned event in order to send the event.
+func (p *Progress) Done() *Event {
+	return p.Tick().Bool(ProgressDoneFieldName, true)
+}
+
+// Heartbeat sends a Tick event with message msg every interval until the
+// returned stop function is called. If fn is not nil, it is called to add
+// the fields of each event.
+func (p *Progress) Heartbeat(interval time.Duration, msg string, fn func(e *Event)) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				e := p.Tick()
+				if fn != nil {
+					fn(e)
+				}
+				e.Msg(msg)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package zerolog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("job", "import").Logger()
+	p := log.Progress(InfoLevel, "op1")
+	p.Tick().Int("rows", 10).Msg("importing")
+	p.Tick().Int("rows", 20).Msg("importing")
+	p.Done().Int("rows", 25).Msg("imported")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := strings.Join([]string{
+		`{"level":"info","job":"import","operation_id":"op1","seq":1,"rows":10,"message":"importing"}`,
+		`{"level":"info","job":"import","operation_id":"op1","seq":2,"rows":20,"message":"importing"}`,
+		`{"level":"info","job":"import","operation_id":"op1","seq":3,"done":true,"rows":25,"message":"imported"}`,
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+
+	if id := log.Progress(InfoLevel, "").ID(); len(id) != 16 {
+		t.Errorf("generated id = %q", id)
+	}
+}
+
+func TestProgressHeartbeat(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := New(out).Progress(InfoLevel, "op")
+	ticks := make(chan struct{}, 10)
+	stop := p.Heartbeat(time.Millisecond, "alive", func(e *Event) {
+		ticks <- struct{}{}
+	})
+	<-ticks
+	<-ticks
+	stop()
+	stop()
+	if got := strings.Count(decodeIfBinaryToString(out.Bytes()), `"message":"alive"`); got < 2 {
+		t.Errorf("got %d heartbeats, want at least 2", got)
+	}
+}
@@ -0,0 +1,208 @@
+package zerolog
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitFieldName is the field name used for the number of events
+// suppressed by a RateLimitWriter.
+var RateLimitFieldName = "suppressed"
+
+// rateLimitInterval is the interval at which a RateLimitWriter reports the
+// suppressed events.
+const rateLimitInterval = time.Second
+
+// rateBucket is the token bucket of the events sharing a key.
+type rateBucket struct {
+	level      Level
+	values     []interface{}
+	tokens     float64
+	last       time.Time
+	suppressed int
+}
+
+// RateLimitWriter is a LevelWriter suppressing floods of identical events.
+// Events are grouped by level and by the values of the key fields, the
+// message by default, and each group is limited by a token bucket. The
+// number of suppressed events of a group is reported every second by an
+// event holding the key fields and a suppressed field:
+//
+//	w := zerolog.RateLimit(os.Stdout, 10, 100)
+//	defer w.Close()
+//	log := zerolog.New(w)
+//	// {"level":"error","suppressed":1234,"time":"...","message":"connection refused"}
+type RateLimitWriter struct {
+	w     LevelWriter
+	l     Logger
+	limit float64
+	burst float64
+	keys  []string
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// RateLimit returns a RateLimitWriter writing to w up to limit events per
+// second of each group of events, with bursts of up to burst events. The
+// groups are made by the values of the keys fields, or of the message field
+// if none is given.
+func RateLimit(w io.Writer, limit float64, burst int, keys ...string) *RateLimitWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	if len(keys) == 0 {
+		keys = []string{MessageFieldName}
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	rw := &RateLimitWriter{
+		w:       lw,
+		l:       New(lw).With().Timestamp().Logger(),
+		limit:   limit,
+		burst:   float64(burst),
+		keys:    keys,
+		buckets: map[string]*rateBucket{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go rw.run()
+	return rw
+}
+
+// Write implements the io.Writer interface. The level is read from the
+// event.
+func (w *RateLimitWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RateLimitWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	fields, err := DecodeEvent(p)
+	if err != nil {
+		// Let the events which can't be keyed through.
+		return w.w.WriteLevel(level, p)
+	}
+	if level == NoLevel {
+		s, _ := fields[LevelFieldName].(string)
+		level, _ = ParseLevel(s)
+	}
+	values := make([]interface{}, len(w.keys))
+	strs := make([]string, len(w.keys)+1)
+	strs[0] = level.String()
+	for i, key := range w.keys {
+		values[i] = fields[key]
+		strs[i+1] = fmt.Sprint(values[i])
+	}
+	key := strings.Join(strs, "\x00")
+
+	now := TimestampFunc()
+	w.mu.Lock()
+	b := w.buckets[key]
+	if b == nil {
+		b = &rateBucket{level: level, values: values, tokens: w.burst, last: now}
+		w.buckets[key] = b
+	}
+	b.refill(now, w.limit, w.burst)
+	if b.tokens < 1 {
+		b.suppressed++
+		w.mu.Unlock()
+		return len(p), nil
+	}
+	b.tokens--
+	w.mu.Unlock()
+	return w.w.WriteLevel(level, p)
+}
+
+// refill adds the tokens earned since the last refill to b.
+func (b *rateBucket) refill(now time.Time, limit, burst float64) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * limit
+		if b.tokens > burst {
+			b.tokens = burst
+		}
+		b.last = now
+	}
+}
+
+// Flush reports the events suppressed since the last report and flushes the
+// wrapped writer.
+func (w *RateLimitWriter) Flush() error {
+	w.report()
+	return flush(w.w)
+}
+
+// Close stops the background go-routine, reports the suppressed events and
+// closes the wrapped writer if it implements io.Closer.
+func (w *RateLimitWriter) Close() error {
+	close(w.stop)
+	<-w.done
+	w.report()
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (w *RateLimitWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(rateLimitInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.report()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// report writes an event for each group having suppressed events, and
+// forgets the idle groups which bucket is full.
+func (w *RateLimitWriter) report() {
+	type pending struct {
+		key string
+		b   rateBucket
+	}
+	var reports []pending
+	now := TimestampFunc()
+	w.mu.Lock()
+	for key, b := range w.buckets {
+		if b.suppressed > 0 {
+			reports = append(reports, pending{key, *b})
+			b.suppressed = 0
+			continue
+		}
+		if b.refill(now, w.limit, w.burst); b.tokens >= w.burst {
+			delete(w.buckets, key)
+		}
+	}
+	w.mu.Unlock()
+
+	sort.Slice(reports, func(i, j int) bool { return reports[i].key < reports[j].key })
+	for _, r := range reports {
+		e := w.l.WithLevel(r.b.level)
+		msg := ""
+		for i, key := range w.keys {
+			v := r.b.values[i]
+			if key == MessageFieldName {
+				msg, _ = v.(string)
+				continue
+			}
+			if v != nil {
+				e.Interface(key, v)
+			}
+		}
+		e.Int(RateLimitFieldName, r.b.suppressed).Msg(msg)
+	}
+}
@@ -0,0 +1,59 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	TimestampFunc = func() time.Time { return now }
+
+	out := &bytes.Buffer{}
+	w := RateLimit(out, 1, 2)
+	log := New(w)
+	for i := 0; i < 5; i++ {
+		log.Error().Int("i", i).Msg("connection refused")
+	}
+	log.Info().Msg("other")
+	now = now.Add(time.Second)
+	log.Error().Int("i", 5).Msg("connection refused")
+	log.Error().Int("i", 6).Msg("connection refused")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush() // nothing suppressed
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"error","i":0,"message":"connection refused"}` + "\n" +
+		`{"level":"error","i":1,"message":"connection refused"}` + "\n" +
+		`{"level":"info","message":"other"}` + "\n" +
+		`{"level":"error","i":5,"message":"connection refused"}` + "\n" +
+		`{"level":"error","suppressed":4,"time":"2024-01-02T03:04:06Z","message":"connection refused"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	w.Close()
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	TimestampFunc = func() time.Time { return now }
+
+	out := &bytes.Buffer{}
+	w := RateLimit(out, 1, 1, "user")
+	log := New(w)
+	log.Warn().Str("user", "a").Msg("denied")
+	log.Warn().Str("user", "a").Msg("denied again")
+	log.Warn().Str("user", "b").Msg("denied")
+	w.Close()
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"warn","user":"a","message":"denied"}` + "\n" +
+		`{"level":"warn","user":"b","message":"denied"}` + "\n" +
+		`{"level":"warn","user":"a","suppressed":1,"time":"2024-01-02T03:04:05Z"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
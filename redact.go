@@ -0,0 +1,128 @@
+package zerolog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	jsonenc "github.com/treavorj/zerolog/internal/json"
+)
+
+// Redactor replaces the values of sensitive fields. See Logger.Redact.
+type Redactor interface {
+	// Redact returns the JSON encoded value replacing value, the JSON encoded
+	// value of the field key.
+	Redact(key string, value []byte) []byte
+}
+
+// RedactorFunc is an adaptor to allow the use of an ordinary function as a
+// Redactor.
+type RedactorFunc func(key string, value []byte) []byte
+
+// Redact implements the Redactor interface.
+func (f RedactorFunc) Redact(key string, value []byte) []byte {
+	return f(key, value)
+}
+
+// RedactedValue is the value used by MaskRedactor.
+var RedactedValue = "[REDACTED]"
+
+var (
+	// MaskRedactor replaces values with RedactedValue.
+	MaskRedactor Redactor = RedactorFunc(func(key string, value []byte) []byte {
+		return appendJSONString(RedactedValue)
+	})
+
+	// SHA256Redactor replaces values with the hex encoded SHA-256 hash of
+	// the value, prefixed with "sha256:", so equal values can still be
+	// correlated. Strings are hashed unquoted, other values as encoded.
+	SHA256Redactor Redactor = RedactorFunc(func(key string, value []byte) []byte {
+		b := value
+		var s string
+		if len(value) > 0 && value[0] == '"' && json.Unmarshal(value, &s) == nil {
+			b = []byte(s)
+		}
+		sum := sha256.Sum256(b)
+		return appendJSONString("sha256:" + hex.EncodeToString(sum[:]))
+	})
+)
+
+// PartialMaskRedactor returns a Redactor masking string values with '*'
+// except their last keep characters, like "************4242" for a card
+// number. Strings not longer than keep and non-string values are fully
+// masked with RedactedValue.
+func PartialMaskRedactor(keep int) Redactor {
+	return RedactorFunc(func(key string, value []byte) []byte {
+		var s string
+		if len(value) == 0 || value[0] != '"' || json.Unmarshal(value, &s) != nil {
+			return MaskRedactor.Redact(key, value)
+		}
+		n := utf8.RuneCountInString(s)
+		if n <= keep {
+			return MaskRedactor.Redact(key, value)
+		}
+		i := 0
+		for j := 0; j < n-keep; j++ {
+			_, size := utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		return appendJSONString(strings.Repeat("*", n-keep) + s[i:])
+	})
+}
+
+// appendJSONString returns s encoded as a JSON string, whatever the encoding
+// of the build, as redactors work on JSON values.
+func appendJSONString(s string) []byte {
+	return jsonenc.Encoder{}.AppendString(nil, s)
+}
+
+// redaction is the Transformer installed by Logger.Redact.
+type redaction struct {
+	r        Redactor
+	patterns []string
+}
+
+// Redact returns a logger replacing the values of the fields which key
+// matches one of patterns using r. Patterns use the path.Match syntax and
+// are matched case-insensitively, like "password" or "*_token". Fields are
+// matched at any depth: context fields, event fields, and fields of nested
+// dicts and objects, including objects in arrays.
+//
+//	log := zerolog.New(os.Stdout).
+//	    Redact(zerolog.MaskRedactor, "password", "*_token").
+//	    Redact(zerolog.PartialMaskRedactor(4), "card_number")
+//
+// Redaction is applied to the encoded event, before transformers installed
+// later and before writing. Keys are matched unescaped. Binary events are
+// decoded and encoded back, the redacted values being passed to r as JSON.
+func (l Logger) Redact(r Redactor, patterns ...string) Logger {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+	}
+	return l.Transform(&redaction{r: r, patterns: lower})
+}
+
+// Transform implements the Transformer interface.
+func (t *redaction) Transform(level Level, p []byte) ([]byte, bool) {
+	return rewriteFields(p, t), true
+}
+
+// field implements the fieldRewriter interface.
+func (t *redaction) field(key []byte) fieldAction {
+	k := strings.ToLower(string(key))
+	for _, p := range t.patterns {
+		if ok, _ := path.Match(p, k); ok {
+			return replaceField
+		}
+	}
+	return keepField
+}
+
+// replace implements the fieldRewriter interface.
+func (t *redaction) replace(key string, value []byte) []byte {
+	return t.r.Redact(key, value)
+}
@@ -0,0 +1,60 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).
+		Redact(MaskRedactor, "password", "*_TOKEN").
+		Redact(PartialMaskRedactor(4), "card").
+		Redact(SHA256Redactor, "email").
+		With().Str("api_token", "secret").Logger()
+
+	log.Log().
+		Str("user", "bob").
+		Str("password", "hunter2").
+		Str("card", "4242424242424242").
+		Str("email", "bob@example.com").
+		Dict("nested", Dict().Str("password", "x").Int("n", 1)).
+		Interface("list", []map[string]interface{}{{"refresh_token": 42}, {"ok": true}}).
+		RawJSON("raw", []byte(`{ "password" : "x" , "keep" : [1, 2] }`)).
+		Msg("login")
+
+	want := `{"api_token":"[REDACTED]","user":"bob","password":"[REDACTED]","card":"************4242",` +
+		`"email":"sha256:5ff860bf1190596c7188ab851db691f0f3169c453936e9e1eba2f9a47f7a0018",` +
+		`"nested":{"password":"[REDACTED]","n":1},"list":[{"refresh_token":"[REDACTED]"},{"ok":true}],` +
+		`"raw":{"password":"[REDACTED]","keep":[1,2]},"message":"login"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestPartialMaskRedactor(t *testing.T) {
+	r := PartialMaskRedactor(2)
+	for _, tt := range []struct{ in, want string }{
+		{`"héllo"`, `"***lo"`},
+		{`"ab"`, `"[REDACTED]"`},
+		{`12345`, `"[REDACTED]"`},
+	} {
+		if got := string(r.Redact("k", []byte(tt.in))); got != tt.want {
+			t.Errorf("Redact(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactEscapedKey(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Redact(MaskRedactor, "password")
+	log.Log().RawJSON("raw", []byte(`{"password":"x","n":1}`)).Msg("")
+
+	want := `{"raw":{"password":"[REDACTED]","n":1}}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+package zerolog
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+var registry = struct {
+	sync.RWMutex
+	loggers map[string]*Logger
+	owned   map[string]bool // loggers set by Replace, never modified in place
+}{loggers: map[string]*Logger{}, owned: map[string]bool{}}
+
+// Register makes l observable and adjustable under name through Loggers and
+// SetRegisteredLevel. Registering a name twice replaces the previous logger.
+//
+// l must be registered before being used concurrently. Its level is then
+// changed atomically by SetRegisteredLevel, which is safe while logging with
+// l. The loggers derived from l with With follow the level changes too,
+// until their level is set with Level: setting the level of l itself with
+// Level detaches it from the registry, it must be registered again.
+func Register(name string, l *Logger) {
+	registry.Lock()
+	defer registry.Unlock()
+	lvl := int32(l.GetLevel())
+	l.regLevel = &lvl
+	registry.loggers[name] = l
+	delete(registry.owned, name)
+}
+
+// Replace registers a copy of l under name, swapping the logger previously
+// registered, if any, in a single step. Unlike Register, the logger is owned
+// by the registry: reconfiguring it with Replace or SetRegisteredLevel
+// swaps in a new logger instead of modifying the current one, so it does not
+// race with the events being logged by the users of Get:
+//
+//	zerolog.Replace("payments", zerolog.New(os.Stdout).With().Str("svc", "payments").Logger())
+//	...
+//	zerolog.Get("payments").Info().Msg("charged")
+func Replace(name string, l Logger) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.loggers[name] = &l
+	registry.owned[name] = true
+}
+
+// Get returns the logger registered under name, or a disabled logger if
+// there is none. Loggers set by Replace must not be modified through the
+// returned pointer.
+func Get(name string) *Logger {
+	registry.RLock()
+	defer registry.RUnlock()
+	if l := registry.loggers[name]; l != nil {
+		return l
+	}
+	return disabledLogger
+}
+
+// Unregister removes the logger registered under name.
+func Unregister(name string) {
+	registry.Lock()
+	defer registry.Unlock()
+	delete(registry.loggers, name)
+	delete(registry.owned, name)
+}
+
+// Registered returns the logger registered under name, or nil.
+func Registered(name string) *Logger {
+	registry.RLock()
+	defer registry.RUnlock()
+	return registry.loggers[name]
+}
+
+// LoggerInfo describes a registered logger.
+type LoggerInfo struct {
+	Name    string     `json:"name"`
+	Level   string     `json:"level"`
+	Sampler string     `json:"sampler,omitempty"`
+	Writer  WriterInfo `json:"writer"`
+}
+
+// WriterInfo describes a writer and the writers it wraps.
+type WriterInfo struct {
+	Type    string       `json:"type"`
+	Writers []WriterInfo `json:"writers,omitempty"`
+}
+
+// Loggers returns the description of the registered loggers, sorted by name.
+func Loggers() []LoggerInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+	infos := make([]LoggerInfo, 0, len(registry.loggers))
+	for name, l := range registry.loggers {
+		info := LoggerInfo{
+			Name:   name,
+			Level:  l.GetLevel().String(),
+			Writer: describeWriter(l.w),
+		}
+		if l.sampler != nil {
+			info.Sampler = fmt.Sprintf("%T", l.sampler)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// SetRegisteredLevel sets the level of the logger registered under name. It
+// is safe to call while the logger is used: the level of the loggers set by
+// Register is changed atomically, the loggers set by Replace are swapped for
+// a copy with the new level.
+func SetRegisteredLevel(name string, level Level) error {
+	registry.Lock()
+	defer registry.Unlock()
+	l, ok := registry.loggers[name]
+	if !ok {
+		return fmt.Errorf("no logger registered as %q", name)
+	}
+	if registry.owned[name] {
+		l2 := l.Level(level)
+		registry.loggers[name] = &l2
+		return nil
+	}
+	if l.regLevel == nil {
+		return fmt.Errorf("level of logger %q set after its registration, register it again", name)
+	}
+	atomic.StoreInt32(l.regLevel, int32(level))
+	return nil
+}
+
+// describeWriter returns the description of w, unwrapping the writers known
+// to wrap other writers.
+func describeWriter(w io.Writer) WriterInfo {
+	var wrapped []io.Writer
+	switch t := w.(type) {
+	case nil:
+		return WriterInfo{Type: "nil"}
+	case LevelWriterAdapter:
+		// The adapter is an implementation detail of New.
+		return describeWriter(t.Writer)
+	case *syncWriter:
+		wrapped = append(wrapped, t.lw)
+	case multiLevelWriter:
+		for _, w := range t.writers {
+			wrapped = append(wrapped, w)
+		}
+	case *LevelWriterRouter:
+		for _, w := range t.writers() {
+			wrapped = append(wrapped, w)
+		}
+	case *FilteredLevelWriter:
+		wrapped = append(wrapped, t.Writer)
+	case *TriggerLevelWriter:
+		wrapped = append(wrapped, t.Writer)
+	case *TailSamplingWriter:
+		wrapped = append(wrapped, t.Writer)
+	case ConsoleWriter:
+		wrapped = append(wrapped, t.Out)
+	case *ConsoleWriter:
+		wrapped = append(wrapped, t.Out)
+	case *CSVWriter:
+		wrapped = append(wrapped, t.Out)
+	case *SyncAckWriter:
+		wrapped = append(wrapped, t.Writer)
+	}
+	info := WriterInfo{Type: fmt.Sprintf("%T", w)}
+	for _, w := range wrapped {
+		info.Writers = append(info.Writers, describeWriter(w))
+	}
+	return info
+}
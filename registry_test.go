@@ -0,0 +1,114 @@
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	out := &bytes.Buffer{}
+	db := New(MultiLevelWriter(out, SyncWriter(out))).Level(InfoLevel).Sample(&BasicSampler{N: 2})
+	http := New(out).Level(WarnLevel)
+	Register("db", &db)
+	Register("http", &http)
+	defer Unregister("db")
+	defer Unregister("http")
+
+	want := []LoggerInfo{
+		{
+			Name:    "db",
+			Level:   "info",
+			Sampler: "*zerolog.BasicSampler",
+			Writer: WriterInfo{
+				Type: "zerolog.multiLevelWriter",
+				Writers: []WriterInfo{
+					{Type: "*bytes.Buffer"},
+					{Type: "*zerolog.syncWriter", Writers: []WriterInfo{{Type: "*bytes.Buffer"}}},
+				},
+			},
+		},
+		{
+			Name:   "http",
+			Level:  "warn",
+			Writer: WriterInfo{Type: "*bytes.Buffer"},
+		},
+	}
+	if got := Loggers(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Loggers() =\n%#v\nwant\n%#v", got, want)
+	}
+
+	if err := SetRegisteredLevel("db", DebugLevel); err != nil {
+		t.Fatal(err)
+	}
+	if db.GetLevel() != DebugLevel {
+		t.Errorf("level = %v, want debug", db.GetLevel())
+	}
+	if Registered("db") != &db {
+		t.Error("Registered did not return the registered logger")
+	}
+	if err := SetRegisteredLevel("missing", DebugLevel); err == nil {
+		t.Error("expected an error for an unknown logger")
+	}
+
+	sub := db.With().Str("sub", "x").Logger()
+	SetRegisteredLevel("db", WarnLevel)
+	if sub.GetLevel() != WarnLevel {
+		t.Errorf("derived logger level = %v, want warn", sub.GetLevel())
+	}
+	db = db.Level(InfoLevel)
+	if err := SetRegisteredLevel("db", DebugLevel); err == nil {
+		t.Error("expected an error for a logger which level was set after its registration")
+	}
+}
+
+func TestRegistryReplace(t *testing.T) {
+	defer Unregister("payments")
+	if Get("payments") != disabledLogger {
+		t.Fatal("unregistered logger not disabled")
+	}
+	out := &bytes.Buffer{}
+	Replace("payments", New(out).With().Str("svc", "v1").Logger())
+	old := Get("payments")
+	Replace("payments", New(out).With().Str("svc", "v2").Logger())
+	old.Info().Msg("in flight")
+	Get("payments").Info().Msg("new")
+	if err := SetRegisteredLevel("payments", WarnLevel); err != nil {
+		t.Fatal(err)
+	}
+	if Get("payments").GetLevel() != WarnLevel || old.GetLevel() != TraceLevel {
+		t.Errorf("levels = %v, %v", Get("payments").GetLevel(), old.GetLevel())
+	}
+	Get("payments").Info().Msg("hidden")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","svc":"v1","message":"in flight"}` + "\n" +
+		`{"level":"info","svc":"v2","message":"new"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	l := New(out)
+	Register("payments", &l)
+	SetRegisteredLevel("payments", ErrorLevel)
+	if l.GetLevel() != ErrorLevel {
+		t.Errorf("registered logger level = %v, want error", l.GetLevel())
+	}
+}
+
+func TestRegistryReplaceConcurrent(t *testing.T) {
+	defer Unregister("concurrent")
+	Replace("concurrent", New(&bytes.Buffer{}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			Replace("concurrent", New(SyncWriter(&bytes.Buffer{})))
+			SetRegisteredLevel("concurrent", Level(i%3))
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		Get("concurrent").Error().Msg("x")
+	}
+	<-done
+}
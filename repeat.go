@@ -0,0 +1,170 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// RepeatCountFieldName is the field name used for the number of repetitions
+// collapsed by a RepeatWriter.
+var RepeatCountFieldName = "repeat_count"
+
+// RepeatWriter is a LevelWriter collapsing consecutive identical events, like
+// the "last message repeated N times" of syslog. The first event of a run is
+// written as is, and the repetitions which follow are replaced by the last of
+// them with a repeat_count field holding their number, added last:
+//
+//	w := zerolog.NewRepeatWriter(os.Stdout, 10*time.Second)
+//	defer w.Close()
+//	log := zerolog.New(w)
+//	// {"level":"warn","message":"disk almost full"}
+//	// {"level":"warn","message":"disk almost full","repeat_count":41}
+//
+// Events are identical when they have the same level, message and fields,
+// the timestamp aside, or only the same level, message and compared fields if
+// some are given. The repetitions are written once a different event is
+// written, or at the latest window after the first of them.
+type RepeatWriter struct {
+	w      LevelWriter
+	window time.Duration
+	fields []string
+
+	mu     sync.Mutex
+	key    string
+	last   []byte // last repetition of the current run
+	level  Level
+	count  int
+	timer  *time.Timer
+	closed bool
+}
+
+// NewRepeatWriter returns a RepeatWriter writing to w, collapsing the runs of
+// identical events of up to window. If fields are given, only the level, the
+// message and these fields are compared.
+func NewRepeatWriter(w io.Writer, window time.Duration, fields ...string) *RepeatWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	return &RepeatWriter{w: lw, window: window, fields: fields}
+}
+
+// Write implements the io.Writer interface.
+func (w *RepeatWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RepeatWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	key, ok := w.repeatKey(level, p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if ok && key == w.key && !w.closed {
+		w.last = append(w.last[:0], p...)
+		w.level = level
+		w.count++
+		if w.count == 1 && w.window > 0 {
+			w.timer = time.AfterFunc(w.window, w.expire)
+		}
+		return len(p), nil
+	}
+	if err = w.flushRun(); err != nil {
+		return 0, err
+	}
+	w.key = key
+	return w.w.WriteLevel(level, p)
+}
+
+// repeatKey returns the string identifying the events identical to p, and
+// false if p can't be decoded.
+func (w *RepeatWriter) repeatKey(level Level, p []byte) (string, bool) {
+	fields, err := DecodeEvent(p)
+	if err != nil {
+		return "", false
+	}
+	if len(w.fields) > 0 {
+		cmp := make(map[string]interface{}, len(w.fields)+2)
+		for _, key := range append([]string{LevelFieldName, MessageFieldName}, w.fields...) {
+			if v, ok := fields[key]; ok {
+				cmp[key] = v
+			}
+		}
+		fields = cmp
+	} else {
+		delete(fields, TimestampFieldName)
+	}
+	// Maps are marshaled with sorted keys.
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return "", false
+	}
+	return level.String() + "\x00" + string(b), true
+}
+
+// flushRun writes the last repetition of the current run, if any, with the
+// number of repetitions.
+func (w *RepeatWriter) flushRun() error {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	if w.count == 0 {
+		return nil
+	}
+	p := appendRepeatCount(w.last, w.count)
+	w.key, w.count = "", 0
+	_, err := w.w.WriteLevel(w.level, p)
+	return err
+}
+
+// expire ends the current run once its window is over.
+func (w *RepeatWriter) expire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.flushRun(); err != nil {
+		reportError(nil, nil, WriteFailure, w.level, err)
+	}
+}
+
+// appendRepeatCount returns a copy of the event p with the repeat count field
+// added last.
+func appendRepeatCount(p []byte, count int) []byte {
+	end := enc.AppendLineBreak(enc.AppendEndMarker(nil))
+	if !bytes.HasSuffix(p, end) {
+		return p
+	}
+	dst := append([]byte(nil), p[:len(p)-len(end)]...)
+	dst = enc.AppendInt(enc.AppendKey(dst, RepeatCountFieldName), count)
+	return append(dst, end...)
+}
+
+// Flush writes the repetitions of the current run and flushes the wrapped
+// writer.
+func (w *RepeatWriter) Flush() error {
+	w.mu.Lock()
+	err := w.flushRun()
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return flush(w.w)
+}
+
+// Close writes the repetitions of the current run and closes the wrapped
+// writer if it implements io.Closer.
+func (w *RepeatWriter) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	err := w.flushRun()
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
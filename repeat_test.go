@@ -0,0 +1,76 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRepeatWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewRepeatWriter(out, 0)
+	log := New(w)
+	log.Warn().Msg("disk almost full")
+	log.Warn().Int("pct", 91).Msg("disk almost full")
+	log.Warn().Int("pct", 91).Msg("disk almost full")
+	log.Warn().Int("pct", 91).Msg("disk almost full")
+	log.Error().Int("pct", 91).Msg("disk almost full")
+	log.Info().Msg("done")
+	log.Info().Msg("done")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"warn","message":"disk almost full"}` + "\n" +
+		`{"level":"warn","pct":91,"message":"disk almost full"}` + "\n" +
+		`{"level":"warn","pct":91,"message":"disk almost full","repeat_count":2}` + "\n" +
+		`{"level":"error","pct":91,"message":"disk almost full"}` + "\n" +
+		`{"level":"info","message":"done"}` + "\n" +
+		`{"level":"info","message":"done","repeat_count":1}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRepeatWriterFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewRepeatWriter(out, 0, "path")
+	log := New(w).With().Timestamp().Logger()
+	log.Info().Str("path", "/a").Int("n", 1).Msg("hit")
+	log.Info().Str("path", "/a").Int("n", 2).Msg("hit")
+	log.Info().Str("path", "/b").Int("n", 3).Msg("hit")
+	w.Flush()
+	fields := func(i int) map[string]interface{} {
+		lines := bytes.SplitAfter([]byte(decodeIfBinaryToString(out.Bytes())), []byte("\n"))
+		m, err := DecodeEvent(lines[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	if m := fields(1); m["n"] != json.Number("2") || m["repeat_count"] == nil {
+		t.Errorf("invalid repetition: %v", m)
+	}
+	if m := fields(2); m["path"] != "/b" {
+		t.Errorf("invalid event: %v", m)
+	}
+}
+
+func TestRepeatWriterWindow(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewRepeatWriter(SyncWriter(out), 10*time.Millisecond)
+	log := New(w)
+	log.Info().Msg("tick")
+	log.Info().Msg("tick")
+	time.Sleep(50 * time.Millisecond)
+	log.Info().Msg("tick")
+	w.Close()
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","message":"tick"}` + "\n" +
+		`{"level":"info","message":"tick","repeat_count":1}` + "\n" +
+		`{"level":"info","message":"tick"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
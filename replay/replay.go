@@ -0,0 +1,142 @@
+// Package replay provides helpers to replay recorded zerolog output.
+//
+// Recorded NDJSON (or CBOR when produced with the binary_log build tag) logs
+// are read event by event and sent again through a Logger or an io.Writer,
+// either as fast as possible or paced according to the recorded timestamps.
+// This is mostly useful to load-test the rest of a logging pipeline and
+// downstream collectors with realistic traffic.
+//
+//	f, _ := os.Open("recorded.log")
+//	r := replay.Replayer{Speed: 2}
+//	n, err := r.ToWriter(ctx, f, conn)
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+// Replayer reads recorded events and replays them.
+type Replayer struct {
+	// Speed scales the delay between events, computed from their recorded
+	// timestamps. 1 replays at the original speed, 2 twice as fast, etc. If
+	// Speed is zero or negative, events are replayed as fast as possible.
+	Speed float64
+
+	// PreserveTimestamps keeps the recorded timestamp on replayed events.
+	// When false, the recorded timestamp is dropped and the replay time is
+	// used instead.
+	PreserveTimestamps bool
+}
+
+// ToWriter replays the events read from src to w. If w implements
+// zerolog.LevelWriter, the recorded level of each event is passed to its
+// WriteLevel method.
+//
+// It returns the number of events replayed.
+func (r Replayer) ToWriter(ctx context.Context, src io.Reader, w io.Writer) (int, error) {
+	l := zerolog.New(w).With().Timestamp().Logger()
+	return r.ToLogger(ctx, src, l)
+}
+
+// ToLogger replays the events read from src through l. The recorded level and
+// message of each event are used to create the new event, the remaining fields
+// are added using Event.Fields.
+//
+// When PreserveTimestamps is true, the recorded timestamp of each event is set
+// using Event.At, replacing the timestamp added by l if any. Otherwise, l is
+// expected to add its own timestamp (see Context.Timestamp).
+//
+// It returns the number of events replayed.
+func (r Replayer) ToLogger(ctx context.Context, src io.Reader, l zerolog.Logger) (int, error) {
+	br := bufio.NewReader(src)
+	if b, err := br.Peek(1); err == nil && b[0] > 0x7F {
+		// Binary (CBOR) recording, decode it to NDJSON on the fly.
+		pr, pw := io.Pipe()
+		go func() {
+			pw.CloseWithError(cbor.Cbor2JsonManyObjects(br, pw))
+		}()
+		defer pr.Close()
+		br = bufio.NewReader(pr)
+	}
+
+	var (
+		n         int
+		first     time.Time
+		startedAt time.Time
+	)
+	scanner := bufio.NewScanner(br)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		p := bytes.TrimSpace(scanner.Bytes())
+		if len(p) == 0 {
+			continue
+		}
+		var evt map[string]interface{}
+		d := json.NewDecoder(bytes.NewReader(p))
+		d.UseNumber()
+		if err := d.Decode(&evt); err != nil {
+			return n, fmt.Errorf("replay: cannot decode event at line %d: %v", line, err)
+		}
+
+		ts, hasTS := parseTime(evt[zerolog.TimestampFieldName])
+		if hasTS && r.Speed > 0 {
+			if first.IsZero() {
+				first, startedAt = ts, time.Now()
+			} else if err := sleepUntil(ctx, startedAt.Add(time.Duration(float64(ts.Sub(first))/r.Speed))); err != nil {
+				return n, err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return n, err
+		}
+
+		level := zerolog.NoLevel
+		if s, ok := evt[zerolog.LevelFieldName].(string); ok {
+			if lvl, err := zerolog.ParseLevel(s); err == nil {
+				level = lvl
+			}
+		}
+		msg, _ := evt[zerolog.MessageFieldName].(string)
+		delete(evt, zerolog.LevelFieldName)
+		delete(evt, zerolog.MessageFieldName)
+		delete(evt, zerolog.TimestampFieldName)
+
+		e := l.WithLevel(level)
+		if r.PreserveTimestamps && hasTS {
+			e = e.At(ts).Timestamp()
+		}
+		e.Fields(evt).Msg(msg)
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// parseTime parses a recorded timestamp formatted using
+// zerolog.TimeFieldFormat.
+func parseTime(v interface{}) (time.Time, bool) {
+	t, err := zerolog.ParseTimeValue(v, zerolog.TimeFieldFormat, time.UTC)
+	return t, err == nil
+}
+
+func sleepUntil(ctx context.Context, t time.Time) error {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
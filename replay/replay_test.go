@@ -0,0 +1,81 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+func TestToWriterPreserveTimestamps(t *testing.T) {
+	in := `{"level":"info","time":"2020-01-01T00:00:00Z","foo":"bar","message":"hello"}` + "\n" +
+		"\n" +
+		`{"time":"2020-01-01T00:00:01Z","n":42}` + "\n"
+	out := &bytes.Buffer{}
+	n, err := Replayer{PreserveTimestamps: true}.ToWriter(context.Background(), strings.NewReader(in), out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("replayed %d events, want 2", n)
+	}
+	want := `{"level":"info","time":"2020-01-01T00:00:00Z","foo":"bar","message":"hello"}` + "\n" +
+		`{"time":"2020-01-01T00:00:01Z","n":42}` + "\n"
+	if got := cbor.DecodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestToLoggerDropsTimestamps(t *testing.T) {
+	in := `{"level":"warn","time":"2020-01-01T00:00:00Z","message":"hello"}` + "\n"
+	out := &bytes.Buffer{}
+	l := zerolog.New(out).With().Str("replayed", "yes").Logger()
+	if _, err := (Replayer{}).ToLogger(context.Background(), strings.NewReader(in), l); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"warn","replayed":"yes","message":"hello"}` + "\n"
+	if got := cbor.DecodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestToWriterSpeed(t *testing.T) {
+	in := `{"time":"2020-01-01T00:00:00Z"}` + "\n" +
+		`{"time":"2020-01-01T00:00:01Z"}` + "\n"
+	start := time.Now()
+	n, err := Replayer{Speed: 10}.ToWriter(context.Background(), strings.NewReader(in), &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("replayed %d events, want 2", n)
+	}
+	if d := time.Since(start); d < 100*time.Millisecond {
+		t.Errorf("replay took %v, want at least 100ms", d)
+	}
+}
+
+func TestToWriterCanceled(t *testing.T) {
+	in := `{"time":"2020-01-01T00:00:00Z"}` + "\n" +
+		`{"time":"2020-01-01T01:00:00Z"}` + "\n"
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	n, err := Replayer{Speed: 1}.ToWriter(ctx, strings.NewReader(in), &bytes.Buffer{})
+	if err != context.DeadlineExceeded {
+		t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if n != 1 {
+		t.Errorf("replayed %d events, want 1", n)
+	}
+}
+
+func TestToWriterInvalid(t *testing.T) {
+	_, err := Replayer{}.ToWriter(context.Background(), strings.NewReader("{}\nnot json\n"), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("got error %v, want a decode error at line 2", err)
+	}
+}
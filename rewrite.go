@@ -0,0 +1,136 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// fieldAction is the rewriting of a field selected by a fieldRewriter.
+type fieldAction uint8
+
+const (
+	// keepField keeps the field, the fields nested in its value being
+	// rewritten.
+	keepField fieldAction = iota
+
+	// dropField removes the field.
+	dropField
+
+	// replaceField replaces the value of the field with the one returned by
+	// fieldRewriter.replace.
+	replaceField
+)
+
+// fieldRewriter rewrites the fields of the encoded events at any depth, like
+// the stripping of the field categories and the redaction of Logger.Redact.
+type fieldRewriter interface {
+	// field returns the rewriting of the field which unescaped key is key.
+	field(key []byte) fieldAction
+
+	// replace returns the JSON encoded value replacing value, the JSON
+	// encoded value of the field key.
+	replace(key string, value []byte) []byte
+}
+
+// rewriteFields returns the event p with its fields rewritten by rw. Binary
+// events are decoded and encoded back by rewriteBinaryFields.
+func rewriteFields(p []byte, rw fieldRewriter) []byte {
+	if len(p) == 0 {
+		return p
+	}
+	if p[0] != '{' {
+		return rewriteBinaryFields(p, rw)
+	}
+	n := len(p)
+	for n > 0 && (p[n-1] == '\n' || p[n-1] == ' ') {
+		n--
+	}
+	out := appendRewritten(make([]byte, 0, len(p)), p[:n], rw)
+	return append(out, p[n:]...)
+}
+
+// appendRewritten appends the JSON value v to dst, with the fields of the
+// objects it holds rewritten by rw.
+func appendRewritten(dst, v []byte, rw fieldRewriter) []byte {
+	if len(v) == 0 || v[0] != '{' && v[0] != '[' {
+		return append(dst, v...)
+	}
+	isObj := v[0] == '{'
+	dst = append(dst, v[0])
+	n := 0 // number of elements written
+	i := skipSpace(v, 1)
+	for i < len(v) && v[i] != '}' && v[i] != ']' {
+		start := len(dst)
+		if n > 0 {
+			dst = append(dst, ',')
+		}
+		action := keepField
+		var key []byte
+		if isObj {
+			if v[i] != '"' {
+				// Malformed, copy the rest as is.
+				return append(dst[:start], v[i:]...)
+			}
+			end := skipString(v, i)
+			key = unescapeKey(v[i:end])
+			action = rw.field(key)
+			dst = append(dst, v[i:end]...)
+			dst = append(dst, ':')
+			i = skipSpace(v, end)
+			if i < len(v) && v[i] == ':' {
+				i = skipSpace(v, i+1)
+			}
+		}
+		next := skipValue(v, i)
+		val := trimSpace(v[i:next])
+		switch action {
+		case dropField:
+			dst = dst[:start]
+		case replaceField:
+			dst = append(dst, rw.replace(string(key), val)...)
+			n++
+		default:
+			dst = appendRewritten(dst, val, rw)
+			n++
+		}
+		i = next
+		if i < len(v) && v[i] == ',' {
+			i = skipSpace(v, i+1)
+		}
+	}
+	return append(dst, v[i:]...)
+}
+
+// unescapeKey returns the JSON encoded key s unquoted and unescaped.
+func unescapeKey(s []byte) []byte {
+	if len(s) < 2 {
+		return nil
+	}
+	k := s[1 : len(s)-1]
+	if bytes.IndexByte(k, '\\') < 0 {
+		return k
+	}
+	var u string
+	if json.Unmarshal(s, &u) != nil {
+		return k
+	}
+	return []byte(u)
+}
+
+func skipSpace(b []byte, i int) int {
+	for i < len(b) && (b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func trimSpace(b []byte) []byte {
+	i, j := 0, len(b)
+	for i < j && (b[i] == ' ' || b[i] == '\t' || b[i] == '\n' || b[i] == '\r') {
+		i++
+	}
+	for j > i && (b[j-1] == ' ' || b[j-1] == '\t' || b[j-1] == '\n' || b[j-1] == '\r') {
+		j--
+	}
+	return b[i:j]
+}
@@ -0,0 +1,223 @@
+//go:build binary_log
+// +build binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+// CBOR major types and markers, see RFC 8949.
+const (
+	cborBytes = 2
+	cborText  = 3
+	cborArray = 4
+	cborMap   = 5
+	cborTag   = 6
+	cborOther = 7
+
+	cborIndefinite   = 31
+	cborBreak        = 0xff
+	cborEmbeddedJSON = 262
+)
+
+// rewriteBinaryFields returns the binary event p with its fields rewritten by
+// rw. The values replaced by rw are decoded to JSON and encoded back, and the
+// fields of the embedded JSON values are rewritten as JSON. Malformed events
+// are returned untouched.
+func rewriteBinaryFields(p []byte, rw fieldRewriter) []byte {
+	if p[0]>>5 != cborMap {
+		return p
+	}
+	dst, i, ok := appendRewrittenCBOR(make([]byte, 0, len(p)), p, 0, rw)
+	if !ok {
+		return p
+	}
+	return append(dst, p[i:]...)
+}
+
+// appendRewrittenCBOR appends the data item at p[i] to dst, with the fields
+// of the maps it holds rewritten by rw. It returns the index following the
+// item, and false if p is malformed. Rewritten maps are encoded with an
+// indefinite length, as the events.
+func appendRewrittenCBOR(dst, p []byte, i int, rw fieldRewriter) ([]byte, int, bool) {
+	start := i
+	major, arg, indefinite, i, ok := cborHead(p, i)
+	if !ok {
+		return dst, 0, false
+	}
+	switch {
+	case major == cborMap:
+		dst = append(dst, cborMap<<5|cborIndefinite)
+		for n := uint64(0); ; n++ {
+			if indefinite {
+				if i >= len(p) {
+					return dst, 0, false
+				}
+				if p[i] == cborBreak {
+					i++
+					break
+				}
+			} else if n == arg {
+				break
+			}
+			kmajor, _, kindefinite, kbody, ok := cborHead(p, i)
+			if !ok {
+				return dst, 0, false
+			}
+			kend, ok := skipCBOR(p, i)
+			if !ok {
+				return dst, 0, false
+			}
+			vend, ok := skipCBOR(p, kend)
+			if !ok {
+				return dst, 0, false
+			}
+			action := keepField
+			var key []byte
+			if kmajor == cborText && !kindefinite {
+				key = p[kbody:kend]
+				action = rw.field(key)
+			}
+			switch action {
+			case dropField:
+			case replaceField:
+				dst = append(dst, p[i:kend]...)
+				dst = appendJSONAsCBOR(dst, rw.replace(string(key), cborToJSON(p[kend:vend])))
+			default:
+				dst = append(dst, p[i:kend]...)
+				if dst, _, ok = appendRewrittenCBOR(dst, p, kend, rw); !ok {
+					return dst, 0, false
+				}
+			}
+			i = vend
+		}
+		return append(dst, cborBreak), i, true
+	case major == cborArray:
+		dst = append(dst, p[start:i]...)
+		for n := uint64(0); ; n++ {
+			if indefinite {
+				if i >= len(p) {
+					return dst, 0, false
+				}
+				if p[i] == cborBreak {
+					return append(dst, cborBreak), i + 1, true
+				}
+			} else if n == arg {
+				return dst, i, true
+			}
+			if dst, i, ok = appendRewrittenCBOR(dst, p, i, rw); !ok {
+				return dst, 0, false
+			}
+		}
+	case major == cborTag && arg == cborEmbeddedJSON:
+		bmajor, size, bindefinite, body, ok := cborHead(p, i)
+		if ok && bmajor == cborBytes && !bindefinite && size <= uint64(len(p)-body) {
+			end := body + int(size)
+			j := appendRewritten(nil, trimSpace(p[body:end]), rw)
+			return cbor.AppendEmbeddedJSON(dst, j), end, true
+		}
+	case major == cborTag:
+		dst = append(dst, p[start:i]...)
+		return appendRewrittenCBOR(dst, p, i, rw)
+	}
+	end, ok := skipCBOR(p, start)
+	if !ok {
+		return dst, 0, false
+	}
+	return append(dst, p[start:end]...), end, true
+}
+
+// cborHead decodes the head of the data item at p[i]: its major type, its
+// argument, whether its length is indefinite, and the index following the
+// head. It returns false if p is malformed.
+func cborHead(p []byte, i int) (major byte, arg uint64, indefinite bool, next int, ok bool) {
+	if i >= len(p) {
+		return
+	}
+	major, info := p[i]>>5, p[i]&0x1f
+	i++
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info <= 27:
+		n := 1 << (info - 24)
+		if n > len(p)-i {
+			return
+		}
+		for _, b := range p[i : i+n] {
+			arg = arg<<8 | uint64(b)
+		}
+		i += n
+	case info == cborIndefinite && major >= cborBytes && major <= cborMap:
+		indefinite = true
+	default:
+		return
+	}
+	return major, arg, indefinite, i, true
+}
+
+// skipCBOR returns the index following the data item at p[i], and false if p
+// is malformed.
+func skipCBOR(p []byte, i int) (int, bool) {
+	major, arg, indefinite, i, ok := cborHead(p, i)
+	if !ok {
+		return 0, false
+	}
+	switch major {
+	case cborBytes, cborText, cborArray, cborMap:
+		if indefinite {
+			for {
+				if i >= len(p) {
+					return 0, false
+				}
+				if p[i] == cborBreak {
+					return i + 1, true
+				}
+				if i, ok = skipCBOR(p, i); !ok {
+					return 0, false
+				}
+			}
+		}
+		if arg > uint64(len(p)-i) {
+			return 0, false
+		}
+		if major == cborBytes || major == cborText {
+			return i + int(arg), true
+		}
+		if major == cborMap {
+			arg *= 2
+		}
+		for n := uint64(0); n < arg; n++ {
+			if i, ok = skipCBOR(p, i); !ok {
+				return 0, false
+			}
+		}
+		return i, true
+	case cborTag:
+		return skipCBOR(p, i)
+	}
+	return i, true
+}
+
+// cborToJSON returns the data item c decoded to JSON.
+func cborToJSON(c []byte) []byte {
+	var b bytes.Buffer
+	if cbor.Cbor2JsonManyObjects(bytes.NewReader(c), &b) != nil {
+		return []byte("null")
+	}
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
+
+// appendJSONAsCBOR appends the JSON value j to dst, as a CBOR string if j is
+// a string and as embedded JSON otherwise.
+func appendJSONAsCBOR(dst, j []byte) []byte {
+	var s string
+	if len(j) > 0 && j[0] == '"' && json.Unmarshal(j, &s) == nil {
+		return enc.AppendString(dst, s)
+	}
+	return cbor.AppendEmbeddedJSON(dst, j)
+}
@@ -0,0 +1,47 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStripFieldCategoriesEncodings(t *testing.T) {
+	defer func() {
+		fieldCategories.keys = map[string]string{}
+		StripFieldCategories()
+	}()
+	RegisterFieldCategory(CategoryPayload, "body")
+	StripFieldCategories(CategoryPayload)
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Info().
+		Str("body", "secret").
+		Dict("req", Dict().Str("body", "secret").Int("size", 6)).
+		Interface("obj", map[string]interface{}{"body": "secret", "id": 1}).
+		Msg("stripped")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","req":{"size":6},"obj":{"id":1},"message":"stripped"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRedactEncodings(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Redact(MaskRedactor, "password")
+	log.Log().
+		Str("password", "hunter2").
+		Dict("nested", Dict().Str("password", "x").Int("n", 1)).
+		Interface("obj", map[string]interface{}{"password": "x"}).
+		RawJSON("raw", []byte(`{"password":"x"}`)).
+		Msg("login")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"password":"[REDACTED]","nested":{"password":"[REDACTED]","n":1},` +
+		`"obj":{"password":"[REDACTED]"},"raw":{"password":"[REDACTED]"},"message":"login"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,332 @@
+// Package rollingwriter provides a zerolog output writing to a file rotated
+// on size, with retention policies on the rotated backups.
+//
+//	w, err := rollingwriter.New("/var/log/app/app.log", rollingwriter.Options{
+//	    MaxSize:    100 << 20,
+//	    MaxAge:     7 * 24 * time.Hour,
+//	    MaxBackups: 10,
+//	    Compress:   true,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Rotated files are renamed after the time of the rotation, like
+// app-2006-01-02T15-04-05.000000000.log, and compressed with gzip if
+// requested. Retention policies and compression are applied in the
+// background after each rotation.
+package rollingwriter
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+// backupTimeFormat is the time format of the backup names. It sorts
+// chronologically.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+const compressSuffix = ".gz"
+
+// Options configures a Writer.
+type Options struct {
+	// MaxSize is the size in bytes after which the file is rotated. If zero,
+	// the file is only rotated by Rotate.
+	MaxSize int64
+
+	// MaxAge, if not zero, removes the backups older than MaxAge, based on
+	// the time in their name.
+	MaxAge time.Duration
+
+	// MaxBackups, if not zero, keeps at most MaxBackups backups, removing the
+	// oldest ones.
+	MaxBackups int
+
+	// Compress compresses the backups with gzip.
+	Compress bool
+
+	// LocalTime uses the local time in the backup names instead of UTC.
+	LocalTime bool
+
+	// Sink, if not nil, receives a WriteFailure error for each failed
+	// application of the retention policies or compression in the
+	// background. They are otherwise reported to ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+// Writer is an io.Writer writing to a file rotated according to its Options.
+// It is safe for concurrent use.
+type Writer struct {
+	filename string
+	opts     Options
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+
+	millMu sync.Mutex
+	wg     sync.WaitGroup
+}
+
+// New opens, or creates, filename for appending and returns a Writer
+// writing to it. The directory of filename is created if needed.
+func New(filename string, opts Options) (*Writer, error) {
+	w := &Writer{
+		filename: filename,
+		opts:     opts,
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// open opens the file for appending. w.mu must be held.
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0o755); err != nil {
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	f, err := os.OpenFile(w.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+// Write implements io.Writer. The file is rotated first if p would make it
+// exceed MaxSize.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("rollingwriter: writer closed")
+	}
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Rotate closes the current file, renames it as a backup and opens a new
+// file. Retention policies and compression are then applied in the
+// background.
+func (w *Writer) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("rollingwriter: writer closed")
+	}
+	return w.rotate()
+}
+
+// Reopen closes the current file and opens filename again, without renaming
+// it. It lets external tools like logrotate move the file, typically
+// followed by a SIGHUP handled with zerolog.HandleSignals.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return errors.New("rollingwriter: writer closed")
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	return w.open()
+}
+
+// rotate expects w.mu to be held. If the file can't be renamed or the new
+// file opened, the original file is reopened so that the following writes
+// are not lost.
+func (w *Writer) rotate() error {
+	if err := w.file.Close(); err != nil {
+		w.restore("")
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	now := time.Now()
+	if !w.opts.LocalTime {
+		now = now.UTC()
+	}
+	backup := w.backupName(now)
+	if err := os.Rename(w.filename, backup); err != nil {
+		w.restore("")
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	if err := w.open(); err != nil {
+		w.restore(backup)
+		return err
+	}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		if err := w.mill(); err != nil {
+			zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel, err)
+		}
+	}()
+	return nil
+}
+
+// restore reopens the original file after a failed rotation, renaming
+// backup back first if not empty. w.mu must be held.
+func (w *Writer) restore(backup string) {
+	if backup != "" {
+		os.Rename(backup, w.filename)
+	}
+	w.open()
+}
+
+// prefixAndExt returns the name of the file without and with its extension.
+func (w *Writer) prefixAndExt() (prefix, ext string) {
+	base := filepath.Base(w.filename)
+	ext = filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+func (w *Writer) backupName(t time.Time) string {
+	prefix, ext := w.prefixAndExt()
+	return filepath.Join(filepath.Dir(w.filename), prefix+t.Format(backupTimeFormat)+ext)
+}
+
+type backup struct {
+	name string
+	t    time.Time
+}
+
+// backups returns the backups of the file, most recent first.
+func (w *Writer) backups() ([]backup, error) {
+	dir := filepath.Dir(w.filename)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	prefix, ext := w.prefixAndExt()
+	loc := time.UTC
+	if w.opts.LocalTime {
+		loc = time.Local
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name[len(prefix):], compressSuffix), ext)
+		t, err := time.ParseInLocation(backupTimeFormat, ts, loc)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{name: filepath.Join(dir, name), t: t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].t.After(backups[j].t)
+	})
+	return backups, nil
+}
+
+// mill applies the retention policies and compresses the backups.
+func (w *Writer) mill() error {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+	backups, err := w.backups()
+	if err != nil {
+		return fmt.Errorf("rollingwriter: %v", err)
+	}
+	var errs []string
+	cutoff := time.Now().Add(-w.opts.MaxAge)
+	for i, b := range backups {
+		if w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups || w.opts.MaxAge > 0 && b.t.Before(cutoff) {
+			if err := os.Remove(b.name); err != nil {
+				errs = append(errs, err.Error())
+			}
+			continue
+		}
+		if w.opts.Compress && !strings.HasSuffix(b.name, compressSuffix) {
+			if err := compress(b.name); err != nil {
+				errs = append(errs, err.Error())
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("rollingwriter: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// compress gzips name to name.gz and removes name.
+func compress(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(name + compressSuffix)
+		}
+	}()
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
+
+// Sync commits the current file to stable storage.
+func (w *Writer) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the current file and waits for the background retention and
+// compression to complete.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("rollingwriter: writer already closed")
+	}
+	w.closed = true
+	err := w.file.Close()
+	w.mu.Unlock()
+	w.wg.Wait()
+	return err
+}
@@ -0,0 +1,181 @@
+package rollingwriter
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestWriterRotatesOnSize(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(filepath.Join(dir, "app.log"), Options{MaxSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"12345\n", "67890\n", "abcde\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	names := files(t, dir)
+	if len(names) != 3 || names[2] != "app.log" {
+		t.Fatalf("files = %v", names)
+	}
+	for i, want := range []string{"12345\n", "67890\n", "abcde\n"} {
+		b, _ := os.ReadFile(filepath.Join(dir, names[i]))
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", names[i], b, want)
+		}
+	}
+	if !strings.HasPrefix(names[0], "app-") || !strings.HasSuffix(names[0], ".log") {
+		t.Errorf("invalid backup name %s", names[0])
+	}
+	if _, err := w.Write([]byte("x")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+}
+
+func TestWriterRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app-"+time.Now().UTC().Add(-48*time.Hour).Format(backupTimeFormat)+".log")
+	if err := os.WriteFile(old, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(filepath.Join(dir, "app.log"), Options{MaxAge: 24 * time.Hour, MaxBackups: 2, Compress: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	names := files(t, dir)
+	if len(names) != 3 || names[2] != "app.log" {
+		t.Fatalf("files = %v", names)
+	}
+	for i, want := range []string{"b\n", "c\n"} {
+		if !strings.HasSuffix(names[i], ".log.gz") {
+			t.Fatalf("backup %s not compressed", names[i])
+		}
+		f, _ := os.Open(filepath.Join(dir, names[i]))
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(gz)
+		f.Close()
+		if string(b) != want {
+			t.Errorf("%s = %q, want %q", names[i], b, want)
+		}
+	}
+}
+
+func TestWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	w, err := New(name, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Write([]byte("before\n"))
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+	for file, want := range map[string]string{name + ".1": "before\n", name: "after\n"} {
+		if b, _ := os.ReadFile(file); string(b) != want {
+			t.Errorf("%s = %q, want %q", file, b, want)
+		}
+	}
+}
+
+func TestWriterRotateFailure(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	w, err := New(name, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	// The file is removed behind the writer, so that it can't be renamed.
+	if err := os.Remove(name); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded without a file")
+	}
+	if _, err := w.Write([]byte("after\n")); err != nil {
+		t.Fatalf("write after a failed rotation: %v", err)
+	}
+	if b, _ := os.ReadFile(name); string(b) != "after\n" {
+		t.Errorf("%s = %q, want %q", name, b, "after\n")
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	dir := t.TempDir()
+	var errs []*zerolog.InternalError
+	w, err := New(filepath.Join(dir, "app.log"), Options{
+		Compress: true,
+		Sink: zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+			errs = append(errs, err)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A directory in the way of the compressed backup makes the compression
+	// fail.
+	old := filepath.Join(dir, "app-2000-01-01T00-00-00.000000000.log")
+	if err := os.WriteFile(old, []byte("old\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(old+compressSuffix, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("line\n"))
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 || errs[0].Kind != zerolog.WriteFailure {
+		t.Errorf("errs = %v, want a single WriteFailure", errs)
+	}
+}
@@ -0,0 +1,119 @@
+package zerolog
+
+import "io"
+
+type levelRoute struct {
+	min, max Level
+	w        LevelWriter
+}
+
+// LevelWriterRouter is a LevelWriter routing events to different writers
+// depending on their level, like errors to stderr and an alerting service,
+// and info to stdout:
+//
+//	w := zerolog.NewLevelWriterRouter().
+//	    Route(zerolog.ErrorLevel, zerolog.PanicLevel, os.Stderr, alerts).
+//	    Route(zerolog.DebugLevel, zerolog.WarnLevel, os.Stdout)
+//	log := zerolog.New(w)
+//
+// An event is written to the writers of every route its level matches, or
+// to the default writers if none matches. Events without level are routed
+// as NoLevel.
+type LevelWriterRouter struct {
+	routes   []levelRoute
+	fallback LevelWriter
+}
+
+// NewLevelWriterRouter creates a router without routes.
+func NewLevelWriterRouter() *LevelWriterRouter {
+	return &LevelWriterRouter{}
+}
+
+// Route routes the events with a level between min and max inclusive to
+// writers, and returns r.
+func (r *LevelWriterRouter) Route(min, max Level, writers ...io.Writer) *LevelWriterRouter {
+	r.routes = append(r.routes, levelRoute{min: min, max: max, w: routeWriter(writers)})
+	return r
+}
+
+// Default routes the events not matching any route to writers, and returns
+// r. Such events are dropped if no default writer is set.
+func (r *LevelWriterRouter) Default(writers ...io.Writer) *LevelWriterRouter {
+	r.fallback = routeWriter(writers)
+	return r
+}
+
+// routeWriter returns a LevelWriter writing to writers.
+func routeWriter(writers []io.Writer) LevelWriter {
+	if len(writers) == 1 {
+		if lw, ok := writers[0].(LevelWriter); ok {
+			return lw
+		}
+		return LevelWriterAdapter{writers[0]}
+	}
+	return MultiLevelWriter(writers...)
+}
+
+// Write implements io.Writer, routing p as NoLevel.
+func (r *LevelWriterRouter) Write(p []byte) (n int, err error) {
+	return r.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements LevelWriter. All the matching writers are written
+// and the first error is returned.
+func (r *LevelWriterRouter) WriteLevel(l Level, p []byte) (n int, err error) {
+	routed := false
+	for _, route := range r.routes {
+		if !l.AtLeast(route.min) || !route.max.AtLeast(l) {
+			continue
+		}
+		routed = true
+		if _, _err := route.w.WriteLevel(l, p); _err != nil && err == nil {
+			err = _err
+		}
+	}
+	if !routed && r.fallback != nil {
+		_, err = r.fallback.WriteLevel(l, p)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writers returns the writers of all the routes, followed by the default
+// writers.
+func (r *LevelWriterRouter) writers() []LevelWriter {
+	ws := make([]LevelWriter, 0, len(r.routes)+1)
+	for _, route := range r.routes {
+		ws = append(ws, route.w)
+	}
+	if r.fallback != nil {
+		ws = append(ws, r.fallback)
+	}
+	return ws
+}
+
+// Close calls Close on all the writers implementing io.Closer and returns
+// the first error.
+func (r *LevelWriterRouter) Close() (err error) {
+	for _, w := range r.writers() {
+		if closer, ok := w.(io.Closer); ok {
+			if _err := closer.Close(); err == nil {
+				err = _err
+			}
+		}
+	}
+	return err
+}
+
+// Flush calls Flush on all the writers having a Flush method and returns the
+// first error.
+func (r *LevelWriterRouter) Flush() (err error) {
+	for _, w := range r.writers() {
+		if _err := flush(w); err == nil {
+			err = _err
+		}
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestLevelWriterRouter(t *testing.T) {
+	var stderr, alerts, stdout, other bytes.Buffer
+	r := NewLevelWriterRouter().
+		Route(ErrorLevel, PanicLevel, &stderr, &alerts).
+		Route(DebugLevel, InfoLevel, &stdout).
+		Route(InfoLevel, InfoLevel, &alerts).
+		Default(&other)
+	log := New(r)
+	log.Error().Msg("e")
+	log.Info().Msg("i")
+	log.Debug().Msg("d")
+	log.Warn().Msg("w")
+	log.Log().Msg("n")
+
+	for _, tt := range []struct {
+		name string
+		buf  *bytes.Buffer
+		want string
+	}{
+		{"stderr", &stderr, `{"level":"error","message":"e"}` + "\n"},
+		{"alerts", &alerts, `{"level":"error","message":"e"}` + "\n" + `{"level":"info","message":"i"}` + "\n"},
+		{"stdout", &stdout, `{"level":"info","message":"i"}` + "\n" + `{"level":"debug","message":"d"}` + "\n"},
+		{"other", &other, `{"level":"warn","message":"w"}` + "\n" + `{"message":"n"}` + "\n"},
+	} {
+		if got := decodeIfBinaryToString(tt.buf.Bytes()); got != tt.want {
+			t.Errorf("%s: invalid log output:\ngot:  %v\nwant: %v", tt.name, got, tt.want)
+		}
+	}
+
+	want := WriterInfo{
+		Type: "*zerolog.LevelWriterRouter",
+		Writers: []WriterInfo{
+			{Type: "zerolog.multiLevelWriter", Writers: []WriterInfo{{Type: "*bytes.Buffer"}, {Type: "*bytes.Buffer"}}},
+			{Type: "*bytes.Buffer"},
+			{Type: "*bytes.Buffer"},
+			{Type: "*bytes.Buffer"},
+		},
+	}
+	if got := describeWriter(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("describeWriter() = %#v, want %#v", got, want)
+	}
+}
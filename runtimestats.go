@@ -0,0 +1,81 @@
+package zerolog
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RuntimeStatsCacheDuration is how long the memory statistics gathered by
+// Event.RuntimeStats are reused, as reading them briefly stops the world.
+var RuntimeStatsCacheDuration = time.Second
+
+type runtimeStats struct {
+	heapInuse     uint64
+	gcPauseP99    time.Duration
+	gcCPUFraction float64
+}
+
+var runtimeStatsCache struct {
+	sync.Mutex
+	stats runtimeStats
+	read  time.Time
+}
+
+// readRuntimeStats returns the memory statistics, read at most once per
+// RuntimeStatsCacheDuration.
+func readRuntimeStats() runtimeStats {
+	c := &runtimeStatsCache
+	c.Lock()
+	defer c.Unlock()
+	now := time.Now()
+	if !c.read.IsZero() && now.Sub(c.read) < RuntimeStatsCacheDuration {
+		return c.stats
+	}
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	c.stats = runtimeStats{
+		heapInuse:     m.HeapInuse,
+		gcPauseP99:    pauseP99(&m),
+		gcCPUFraction: m.GCCPUFraction,
+	}
+	c.read = now
+	return c.stats
+}
+
+// pauseP99 returns the 99th percentile of the recent GC pauses.
+func pauseP99(m *runtime.MemStats) time.Duration {
+	n := int(m.NumGC)
+	if n > len(m.PauseNs) {
+		n = len(m.PauseNs)
+	}
+	if n == 0 {
+		return 0
+	}
+	pauses := make([]uint64, n)
+	copy(pauses, m.PauseNs[:n])
+	sort.Slice(pauses, func(i, j int) bool { return pauses[i] < pauses[j] })
+	return time.Duration(pauses[(n*99-1)/100])
+}
+
+// RuntimeStats adds the RuntimeStatsFieldName field holding a compact
+// snapshot of the Go runtime health: heap in use in bytes ("heap_inuse"), the
+// 99th percentile of the recent GC pauses ("gc_pause_p99", formatted like
+// Dur), the number of goroutines ("goroutines") and the fraction of CPU time
+// used by the GC ("gc_cpu_fraction"). It is meant for periodic health-beat
+// events.
+//
+// Memory statistics are cached for RuntimeStatsCacheDuration.
+func (e *Event) RuntimeStats() *Event {
+	if e == nil {
+		return e
+	}
+	e.guardUse("RuntimeStats")
+	s := readRuntimeStats()
+	return e.Dict(RuntimeStatsFieldName, Dict().
+		Uint64("heap_inuse", s.heapInuse).
+		Dur("gc_pause_p99", s.gcPauseP99).
+		Int("goroutines", runtime.NumGoroutine()).
+		Float64("gc_cpu_fraction", s.gcCPUFraction))
+}
@@ -0,0 +1,53 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestEventRuntimeStats(t *testing.T) {
+	runtime.GC()
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().RuntimeStats().Msg("")
+
+	var evt map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(decodeIfBinaryToString(out.Bytes())), &evt); err != nil {
+		t.Fatal(err)
+	}
+	stats := evt[RuntimeStatsFieldName]
+	for _, key := range []string{"heap_inuse", "gc_pause_p99", "goroutines", "gc_cpu_fraction"} {
+		if _, ok := stats[key]; !ok {
+			t.Errorf("missing %s in %v", key, stats)
+		}
+	}
+	if n, _ := stats["goroutines"].(float64); n < 1 {
+		t.Errorf("goroutines = %v", stats["goroutines"])
+	}
+	if n, _ := stats["heap_inuse"].(float64); n <= 0 {
+		t.Errorf("heap_inuse = %v", stats["heap_inuse"])
+	}
+
+	// Cached statistics are reused.
+	first := runtimeStatsCache.read
+	log.Log().RuntimeStats().Msg("")
+	if runtimeStatsCache.read != first {
+		t.Error("statistics not cached")
+	}
+}
+
+func TestPauseP99(t *testing.T) {
+	m := &runtime.MemStats{NumGC: 100}
+	for i := 0; i < 100; i++ {
+		m.PauseNs[i] = uint64(i+1) * uint64(time.Millisecond)
+	}
+	if got, want := pauseP99(m), 99*time.Millisecond; got != want {
+		t.Errorf("pauseP99() = %v, want %v", got, want)
+	}
+	if got := pauseP99(&runtime.MemStats{}); got != 0 {
+		t.Errorf("pauseP99() without GC = %v, want 0", got)
+	}
+}
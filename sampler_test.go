@@ -3,6 +3,8 @@
 package zerolog
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 	"time"
 )
@@ -103,3 +105,32 @@ func BenchmarkSamplers(b *testing.B) {
 		})
 	}
 }
+
+func TestDeadLetter(t *testing.T) {
+	out := &bytes.Buffer{}
+	dead := &bytes.Buffer{}
+	log := New(out).Sample(&BasicSampler{N: 2}).DeadLetter(dead, nil)
+	for i := 0; i < 4; i++ {
+		log.Info().Int("i", i).Msg("")
+	}
+	if got, want := out.String(), `{"level":"info","i":0}`+"\n"+`{"level":"info","i":2}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := dead.String(), `{"level":"info","i":1}`+"\n"+`{"level":"info","i":3}`+"\n"; got != want {
+		t.Errorf("invalid dead letter output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	dead.Reset()
+	log = New(out).Level(InfoLevel).Sample(&BasicSampler{N: 0}).DeadLetter(dead, &BasicSampler{N: 3})
+	for i := 0; i < 6; i++ {
+		log.Info().Int("i", i).Msg("")
+		log.Debug().Int("i", i).Msg("")
+	}
+	if got := out.String(); got != "" {
+		t.Errorf("invalid log output: %v", got)
+	}
+	if got, want := strings.Count(dead.String(), "\n"), 2; got != want {
+		t.Errorf("got %d dead letter events, want %d", got, want)
+	}
+}
@@ -0,0 +1,125 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// sealedField is a context field whose value can't be changed.
+type sealedField struct {
+	key   []byte // quoted JSON key
+	value []byte // raw JSON value
+}
+
+// Seal marks the context fields named keys as sealed: their value can no
+// longer be overridden or removed by fields added later to the context or to
+// the events, by Reset, or by DeDup. It is meant for security relevant
+// fields, like an authenticated subject, that downstream code must not be
+// able to spoof.
+//
+// Keys not present in the context, and keys already sealed, are left
+// untouched. Attempts to override a sealed field are dropped from the
+// events and reported as Drop errors to the logger's ErrorSink, or to
+// ErrorHandler. Only the JSON encoding is supported.
+func (c Context) Seal(keys ...string) Context {
+	if len(c.l.context) == 0 || c.l.context[0] != '{' {
+		return c
+	}
+	sealed := c.l.sealed[:len(c.l.sealed):len(c.l.sealed)]
+	for _, key := range keys {
+		qkey := enc.AppendString(nil, key)
+		if findSealed(sealed, qkey) != nil {
+			continue
+		}
+		if value := lastValue(c.l.context, qkey); value != nil {
+			sealed = append(sealed, sealedField{key: qkey, value: value})
+		}
+	}
+	c.l.sealed = sealed
+	return c
+}
+
+func findSealed(sealed []sealedField, key []byte) *sealedField {
+	for i := range sealed {
+		if bytes.Equal(sealed[i].key, key) {
+			return &sealed[i]
+		}
+	}
+	return nil
+}
+
+// lastValue returns a copy of the value of the last top level field named
+// key in the unterminated JSON object buf, or nil.
+func lastValue(buf, key []byte) []byte {
+	var value []byte
+	for i := 1; i < len(buf) && buf[i] == '"'; {
+		end := skipString(buf, i)
+		next := skipValue(buf, end+1)
+		if bytes.Equal(buf[i:end], key) {
+			value = append([]byte(nil), buf[end+1:next]...)
+		}
+		i = next
+		if i < len(buf) && buf[i] == ',' {
+			i++
+		}
+	}
+	return value
+}
+
+// enforceSealed drops from the event the fields overriding sealed fields and
+// restores the sealed fields that were removed.
+func (e *Event) enforceSealed() {
+	if len(e.buf) == 0 || e.buf[0] != '{' {
+		return
+	}
+	var (
+		seenStack [8]bool
+		seen      = seenStack[:0]
+	)
+	for range e.sealed {
+		seen = append(seen, false)
+	}
+	// Fields are moved in place: out never passes the field being read.
+	out := e.buf[:1]
+	for i := 1; i < len(e.buf) && e.buf[i] == '"'; {
+		end := skipString(e.buf, i)
+		next := skipValue(e.buf, end+1)
+		keep := true
+		for j := range e.sealed {
+			s := &e.sealed[j]
+			if !bytes.Equal(e.buf[i:end], s.key) {
+				continue
+			}
+			if seen[j] || !bytes.Equal(e.buf[end+1:next], s.value) {
+				keep = false
+				e.sealedOverride(s.key)
+			} else {
+				seen[j] = true
+			}
+		}
+		if keep {
+			if len(out) > 1 {
+				out = append(out, ',')
+			}
+			out = append(out, e.buf[i:next]...)
+		}
+		i = next
+		if i < len(e.buf) && e.buf[i] == ',' {
+			i++
+		}
+	}
+	for j, s := range e.sealed {
+		if !seen[j] {
+			if len(out) > 1 {
+				out = append(out, ',')
+			}
+			out = append(append(append(out, s.key...), ':'), s.value...)
+		}
+	}
+	e.buf = out
+}
+
+// sealedOverride reports an attempt to override the sealed field key.
+func (e *Event) sealedOverride(key []byte) {
+	reportError(e.sink, e.settings, Drop, e.level, fmt.Errorf("sealed field %s cannot be overridden", key))
+}
@@ -0,0 +1,71 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestContextSeal(t *testing.T) {
+	var errs []string
+	defer func(h func(error)) { ErrorHandler = h }(ErrorHandler)
+	ErrorHandler = func(err error) { errs = append(errs, err.Error()) }
+
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("auth_subject", "alice").Str("role", "user").Seal("auth_subject", "missing").Logger()
+
+	tests := []struct {
+		name string
+		log  func()
+		want string
+		errs int
+	}{
+		{"untouched", func() { log.Log().Msg("") }, `{"auth_subject":"alice","role":"user"}` + "\n", 0},
+		{"event field", func() { log.Log().Str("auth_subject", "mallory").Str("role", "admin").Msg("") }, `{"auth_subject":"alice","role":"user","role":"admin"}` + "\n", 1},
+		{"context field", func() {
+			l := log.With().Str("auth_subject", "mallory").Logger()
+			l.Log().Msg("")
+		}, `{"auth_subject":"alice","role":"user"}` + "\n", 1},
+		{"reset", func() {
+			l := log.With().Reset().Logger()
+			l.Log().Msg("")
+		}, `{"auth_subject":"alice"}` + "\n", 0},
+		{"context dedup", func() {
+			l := log.With().Str("auth_subject", "mallory").DeDup().Logger()
+			l.Log().Msg("")
+		}, "", 1},
+		{"auto dedup", func() {
+			l := log.AutoDeDup(DeDupModeShallow)
+			l.Log().Str("auth_subject", "mallory").Msg("")
+		}, `{"auth_subject":"alice","role":"user"}` + "\n", 1},
+		{"output", func() {
+			l := log.Output(out)
+			l.Log().Str("auth_subject", "mallory").Msg("")
+		}, `{"auth_subject":"alice","role":"user"}` + "\n", 1},
+		{"reseal", func() {
+			l := log.With().Seal("auth_subject").Logger()
+			l.Log().Str("auth_subject", "mallory").Msg("")
+		}, `{"auth_subject":"alice","role":"user"}` + "\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			errs = nil
+			tt.log()
+			if got := out.String(); tt.want != "" && got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+			if tt.want == "" {
+				// Field order isn't deterministic after DeDup.
+				if got := out.String(); !bytes.Contains(out.Bytes(), []byte(`"auth_subject":"alice"`)) || bytes.Contains(out.Bytes(), []byte("mallory")) {
+					t.Errorf("invalid log output: %v", got)
+				}
+			}
+			if len(errs) != tt.errs {
+				t.Errorf("got %d errors %v, want %d", len(errs), errs, tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,374 @@
+// Package sentrywriter provides a zerolog writer forwarding events to Sentry,
+// speaking its HTTP envelope API directly so zerolog does not depend on the
+// Sentry SDK.
+//
+//	w, err := sentrywriter.NewWriter(sentrywriter.Options{
+//	    DSN:         "https://public@o0.ingest.sentry.io/42",
+//	    Environment: "production",
+//	    Tags:        []string{"component"},
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(zerolog.MultiLevelWriter(os.Stdout, w))
+//
+// Events at or above MinLevel are converted to Sentry events: the message
+// field becomes the message, the error field an exception which stack trace
+// is read from the stack field (as marshaled by pkgerrors), the fields listed
+// in Tags become tags and the other fields extra data. Events are grouped by
+// a fingerprint made of the message and the values of FingerprintKeys.
+//
+// Events are buffered in memory and sent by a background go-routine.
+package sentrywriter
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+// ErrBufferFull is returned by Write when the event buffer is full.
+var ErrBufferFull = errors.New("sentrywriter: buffer full, event dropped")
+
+// Options configures a Writer.
+type Options struct {
+	// DSN is the Sentry DSN of the project, like
+	// "https://public@o0.ingest.sentry.io/42".
+	DSN string
+
+	// MinLevel is the minimum level of the forwarded events. Defaults to
+	// zerolog.WarnLevel. As the zero value is replaced, set it to
+	// zerolog.TraceLevel to forward debug events.
+	MinLevel zerolog.Level
+
+	// Tags are the keys of the fields sent as tags, which Sentry indexes.
+	// Other fields are sent as extra data.
+	Tags []string
+
+	// FingerprintKeys are the keys of the fields which values are added to
+	// the message to build the fingerprint grouping events into issues.
+	FingerprintKeys []string
+
+	// Environment, Release and ServerName are set on all the events.
+	Environment string
+	Release     string
+	ServerName  string
+
+	// BufferSize is the number of events buffered while waiting to be sent.
+	// Events written when the buffer is full are dropped. Defaults to 256.
+	BufferSize int
+
+	// Client is the HTTP client used to send events. Defaults to a client
+	// with a 10s timeout.
+	Client *http.Client
+
+	// Sink, if not nil, receives a WriteFailure error for each event that
+	// cannot be sent. They are otherwise reported to ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+// Writer is a zerolog.LevelWriter forwarding events to Sentry.
+type Writer struct {
+	opts     Options
+	endpoint string
+	auth     string
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan []byte
+	flush  chan chan struct{}
+	done   chan struct{}
+}
+
+// NewWriter returns a Writer sending events to the project of opts.DSN.
+func NewWriter(opts Options) (*Writer, error) {
+	u, err := url.Parse(opts.DSN)
+	if err != nil || u.User == nil || u.Host == "" {
+		return nil, fmt.Errorf("sentrywriter: invalid DSN %q", opts.DSN)
+	}
+	i := strings.LastIndexByte(u.Path, '/')
+	project := u.Path[i+1:]
+	if project == "" {
+		return nil, fmt.Errorf("sentrywriter: invalid DSN %q: missing project id", opts.DSN)
+	}
+	if opts.MinLevel == zerolog.DebugLevel {
+		opts.MinLevel = zerolog.WarnLevel
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 256
+	}
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	w := &Writer{
+		opts:     opts,
+		endpoint: u.Scheme + "://" + u.Host + u.Path[:i] + "/api/" + project + "/envelope/",
+		auth:     "Sentry sentry_version=7, sentry_client=zerolog-sentrywriter/1.0, sentry_key=" + u.User.Username(),
+		queue:    make(chan []byte, opts.BufferSize),
+		flush:    make(chan chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write implements io.Writer. The level is read from the event.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. Events below MinLevel are
+// ignored, the others are converted and buffered until sent.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	evt, err := zerolog.DecodeEvent(p)
+	if err != nil {
+		return 0, fmt.Errorf("sentrywriter: %v", err)
+	}
+	if level == zerolog.NoLevel {
+		s, _ := evt[zerolog.LevelFieldName].(string)
+		if level, err = zerolog.ParseLevel(s); err != nil || level == zerolog.NoLevel {
+			return len(p), nil
+		}
+	}
+	if !level.AtLeast(w.opts.MinLevel) || level == zerolog.Disabled {
+		return len(p), nil
+	}
+	envelope, err := w.envelope(level, evt)
+	if err != nil {
+		return 0, fmt.Errorf("sentrywriter: %v", err)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errors.New("sentrywriter: writer closed")
+	}
+	select {
+	case w.queue <- envelope:
+		return len(p), nil
+	default:
+		return 0, ErrBufferFull
+	}
+}
+
+// Flush sends the buffered events and returns once they are sent or dropped.
+func (w *Writer) Flush() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return errors.New("sentrywriter: writer closed")
+	}
+	c := make(chan struct{})
+	w.flush <- c
+	<-c
+	return nil
+}
+
+// Close sends the buffered events and stops the background go-routine.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("sentrywriter: writer already closed")
+	}
+	w.closed = true
+	close(w.queue)
+	w.mu.Unlock()
+	<-w.done
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	for {
+		select {
+		case envelope, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			w.send(envelope)
+		case c := <-w.flush:
+			for n := len(w.queue); n > 0; n-- {
+				w.send(<-w.queue)
+			}
+			close(c)
+		}
+	}
+}
+
+// send posts envelope to Sentry, reporting failures to opts.Sink or
+// zerolog.ErrorHandler.
+func (w *Writer) send(envelope []byte) {
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(envelope))
+	if err == nil {
+		req.Header.Set("Content-Type", "application/x-sentry-envelope")
+		req.Header.Set("X-Sentry-Auth", w.auth)
+		var resp *http.Response
+		if resp, err = w.opts.Client.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode/100 != 2 {
+				err = fmt.Errorf("unexpected status %s", resp.Status)
+			}
+		}
+	}
+	if err != nil {
+		zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel,
+			fmt.Errorf("sentrywriter: cannot send event: %v", err))
+	}
+}
+
+// sentryLevel returns the Sentry level of level.
+func sentryLevel(level zerolog.Level) string {
+	switch {
+	case !level.AtLeast(zerolog.InfoLevel):
+		return "debug"
+	case !level.AtLeast(zerolog.WarnLevel):
+		return "info"
+	case !level.AtLeast(zerolog.ErrorLevel):
+		return "warning"
+	case !level.AtLeast(zerolog.FatalLevel):
+		return "error"
+	}
+	return "fatal"
+}
+
+// envelope converts evt to a Sentry envelope holding a single event.
+func (w *Writer) envelope(level zerolog.Level, evt map[string]interface{}) ([]byte, error) {
+	var id [16]byte
+	rand.Read(id[:])
+	eventID := hex.EncodeToString(id[:])
+
+	message, _ := evt[zerolog.MessageFieldName].(string)
+	out := map[string]interface{}{
+		"event_id":  eventID,
+		"timestamp": float64(time.Now().UnixNano()) / 1e9,
+		"level":     sentryLevel(level),
+		"platform":  "go",
+		"logger":    "zerolog",
+	}
+	if ts, ok := evt[zerolog.TimestampFieldName].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ts); err == nil {
+			out["timestamp"] = float64(t.UnixNano()) / 1e9
+		}
+	}
+	if message != "" {
+		out["message"] = map[string]interface{}{"formatted": message}
+	}
+	if w.opts.Environment != "" {
+		out["environment"] = w.opts.Environment
+	}
+	if w.opts.Release != "" {
+		out["release"] = w.opts.Release
+	}
+	if w.opts.ServerName != "" {
+		out["server_name"] = w.opts.ServerName
+	}
+	if errMsg, ok := evt[zerolog.ErrorFieldName]; ok {
+		exc := map[string]interface{}{
+			"type":  "error",
+			"value": fmt.Sprint(errMsg),
+		}
+		if kind, ok := evt[zerolog.ErrorKindFieldName].(string); ok {
+			exc["type"] = kind
+		}
+		if frames := stackFrames(evt[zerolog.ErrorStackFieldName]); frames != nil {
+			exc["stacktrace"] = map[string]interface{}{"frames": frames}
+		}
+		out["exception"] = map[string]interface{}{"values": []interface{}{exc}}
+	}
+	fingerprint := []string{message}
+	if message == "" {
+		fingerprint = []string{"{{ default }}"}
+	}
+	for _, key := range w.opts.FingerprintKeys {
+		if v, ok := evt[key]; ok {
+			fingerprint = append(fingerprint, fmt.Sprint(v))
+		}
+	}
+	out["fingerprint"] = fingerprint
+
+	tags := map[string]string{}
+	extra := map[string]interface{}{}
+	for key, v := range evt {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.ErrorFieldName, zerolog.ErrorStackFieldName:
+			continue
+		}
+		if isTag(key, w.opts.Tags) {
+			tags[key] = fmt.Sprint(v)
+		} else {
+			extra[key] = v
+		}
+	}
+	if len(tags) > 0 {
+		out["tags"] = tags
+	}
+	if len(extra) > 0 {
+		out["extra"] = extra
+	}
+
+	payload, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 0, len(payload)+128)
+	b = append(b, `{"event_id":"`...)
+	b = append(b, eventID...)
+	b = append(b, "\"}\n"...)
+	b = append(b, `{"type":"event","length":`...)
+	b = append(b, fmt.Sprint(len(payload))...)
+	b = append(b, "}\n"...)
+	b = append(b, payload...)
+	return append(b, '\n'), nil
+}
+
+func isTag(key string, tags []string) bool {
+	for _, t := range tags {
+		if t == key {
+			return true
+		}
+	}
+	return false
+}
+
+// stackFrames converts a stack marshaled by pkgerrors, innermost frame first,
+// to Sentry frames, innermost frame last.
+func stackFrames(stack interface{}) []interface{} {
+	entries, ok := stack.([]interface{})
+	if !ok || len(entries) == 0 {
+		return nil
+	}
+	frames := make([]interface{}, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry, ok := entries[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		frame := map[string]interface{}{}
+		if f, ok := entry["func"]; ok {
+			frame["function"] = fmt.Sprint(f)
+		}
+		if f, ok := entry["source"]; ok {
+			frame["filename"] = fmt.Sprint(f)
+		}
+		if l, ok := entry["line"]; ok {
+			var line int
+			if _, err := fmt.Sscan(fmt.Sprint(l), &line); err == nil {
+				frame["lineno"] = line
+			}
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
@@ -0,0 +1,147 @@
+//go:build !binary_log
+// +build !binary_log
+
+package sentrywriter
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/treavorj/zerolog"
+)
+
+func TestWriter(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	var auth, path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		auth, path = r.Header.Get("X-Sentry-Auth"), r.URL.Path
+		s := bufio.NewScanner(r.Body)
+		var lines []string
+		for s.Scan() {
+			lines = append(lines, s.Text())
+		}
+		if len(lines) != 3 {
+			t.Errorf("invalid envelope: %q", lines)
+			return
+		}
+		var evt map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[2]), &evt); err != nil {
+			t.Error(err)
+		}
+		events = append(events, evt)
+		io.WriteString(w, `{}`)
+	}))
+	defer srv.Close()
+
+	w, err := NewWriter(Options{
+		DSN:             strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42",
+		Tags:            []string{"component"},
+		FingerprintKeys: []string{"component"},
+		Environment:     "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+	log.Info().Msg("ignored")
+	log.Error().
+		Str("component", "db").
+		Int("attempt", 3).
+		Str("error", "timeout").
+		Interface("stack", []map[string]string{
+			{"func": "query", "line": "12", "source": "db.go"},
+			{"func": "main", "line": "3", "source": "main.go"},
+		}).
+		Msg("query failed")
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if path != "/api/42/envelope/" || !strings.Contains(auth, "sentry_key=public") {
+		t.Errorf("invalid request: path %q, auth %q", path, auth)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	evt := events[0]
+	delete(evt, "event_id")
+	delete(evt, "timestamp")
+	want := map[string]interface{}{
+		"level":       "error",
+		"platform":    "go",
+		"logger":      "zerolog",
+		"environment": "test",
+		"message":     map[string]interface{}{"formatted": "query failed"},
+		"fingerprint": []interface{}{"query failed", "db"},
+		"tags":        map[string]interface{}{"component": "db"},
+		"extra":       map[string]interface{}{"attempt": float64(3)},
+		"exception": map[string]interface{}{"values": []interface{}{map[string]interface{}{
+			"type":  "error",
+			"value": "timeout",
+			"stacktrace": map[string]interface{}{"frames": []interface{}{
+				map[string]interface{}{"function": "main", "filename": "main.go", "lineno": float64(3)},
+				map[string]interface{}{"function": "query", "filename": "db.go", "lineno": float64(12)},
+			}},
+		}}},
+	}
+	if !reflect.DeepEqual(evt, want) {
+		t.Errorf("invalid event:\ngot:  %v\nwant: %v", evt, want)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	var errs []*zerolog.InternalError
+	w, err := NewWriter(Options{
+		DSN: strings.Replace(srv.URL, "http://", "http://public@", 1) + "/42",
+		Sink: zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+			errs = append(errs, err)
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+	log.Error().Msg("lost")
+	w.Close()
+	if len(errs) != 1 || errs[0].Kind != zerolog.WriteFailure || !strings.Contains(errs[0].Error(), "503") {
+		t.Errorf("errs = %v, want a single 503 WriteFailure", errs)
+	}
+}
+
+func TestSentryLevelCustomLevels(t *testing.T) {
+	notice, err := zerolog.ParseLevel("notice")
+	if err != nil {
+		if notice, err = zerolog.RegisterLevel("notice", zerolog.InfoLevel, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := sentryLevel(notice); got != "info" {
+		t.Errorf("sentryLevel(notice) = %q, want info", got)
+	}
+}
+
+func TestNewWriterInvalidDSN(t *testing.T) {
+	for _, dsn := range []string{"", "https://o0.ingest.sentry.io/42", "https://key@o0.ingest.sentry.io/"} {
+		if _, err := NewWriter(Options{DSN: dsn}); err == nil {
+			t.Errorf("NewWriter(%q) did not fail", dsn)
+		}
+	}
+}
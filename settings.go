@@ -0,0 +1,190 @@
+package zerolog
+
+import "time"
+
+// Settings holds the settings otherwise read from the globals, so libraries
+// and applications sharing a process can log with different field names or
+// formats. Set them per logger with Logger.Settings, starting from
+// GlobalSettings:
+//
+//	s := zerolog.GlobalSettings()
+//	s.MessageFieldName = "msg"
+//	s.TimeFieldFormat = time.RFC3339Nano
+//	log := zerolog.New(os.Stdout).Settings(s)
+//
+// Loggers without settings keep following the globals, including changes
+// made after their creation. Nil functions and a zero DurationFieldUnit
+// fall back to the globals.
+//
+// Settings apply to the fields added through the logger, its context and its
+// events. Array and Dict values, the values of Fields, and the writers and
+// transformers decoding events, like ConsoleWriter, still follow the globals.
+type Settings struct {
+	TimestampFieldName    string
+	LevelFieldName        string
+	LevelFieldMarshalFunc func(l Level) string
+	MessageFieldName      string
+	ErrorFieldName        string
+	ErrorStackFieldName   string
+	ErrorStackMarshaler   func(err error) interface{}
+	ErrorMarshalFunc      func(err error) interface{}
+	CallerFieldName       string
+	CallerSkipFrameCount  int
+	CallerMarshalFunc     func(pc uintptr, file string, line int) string
+	TimeFieldFormat       string
+	TimestampFunc         func() time.Time
+	DurationFieldUnit     time.Duration
+	DurationFieldInteger  bool
+	ErrorHandler          func(err error)
+}
+
+// GlobalSettings returns the current values of the globals held by Settings.
+func GlobalSettings() Settings {
+	return Settings{
+		TimestampFieldName:    TimestampFieldName,
+		LevelFieldName:        LevelFieldName,
+		LevelFieldMarshalFunc: LevelFieldMarshalFunc,
+		MessageFieldName:      MessageFieldName,
+		ErrorFieldName:        ErrorFieldName,
+		ErrorStackFieldName:   ErrorStackFieldName,
+		ErrorStackMarshaler:   ErrorStackMarshaler,
+		ErrorMarshalFunc:      ErrorMarshalFunc,
+		CallerFieldName:       CallerFieldName,
+		CallerSkipFrameCount:  CallerSkipFrameCount,
+		CallerMarshalFunc:     CallerMarshalFunc,
+		TimeFieldFormat:       TimeFieldFormat,
+		TimestampFunc:         TimestampFunc,
+		DurationFieldUnit:     DurationFieldUnit,
+		DurationFieldInteger:  DurationFieldInteger,
+		ErrorHandler:          ErrorHandler,
+	}
+}
+
+// Settings creates a child logger using s instead of the globals. Only
+// fields added after this call are affected.
+func (l Logger) Settings(s Settings) Logger {
+	l.settings = &s
+	return l
+}
+
+// GetSettings returns the settings of l, the globals if none were set.
+func (l Logger) GetSettings() Settings {
+	if l.settings == nil {
+		return GlobalSettings()
+	}
+	return *l.settings
+}
+
+// The accessors below return the setting of s, or the global if s is nil.
+
+func (s *Settings) timestampFieldName() string {
+	if s == nil {
+		return TimestampFieldName
+	}
+	return s.TimestampFieldName
+}
+
+func (s *Settings) levelFieldName() string {
+	if s == nil {
+		return LevelFieldName
+	}
+	return s.LevelFieldName
+}
+
+func (s *Settings) levelFieldValue(l Level) string {
+	if s == nil || s.LevelFieldMarshalFunc == nil {
+		return LevelFieldMarshalFunc(l)
+	}
+	return s.LevelFieldMarshalFunc(l)
+}
+
+func (s *Settings) messageFieldName() string {
+	if s == nil {
+		return MessageFieldName
+	}
+	return s.MessageFieldName
+}
+
+func (s *Settings) errorFieldName() string {
+	if s == nil {
+		return ErrorFieldName
+	}
+	return s.ErrorFieldName
+}
+
+func (s *Settings) errorStackFieldName() string {
+	if s == nil {
+		return ErrorStackFieldName
+	}
+	return s.ErrorStackFieldName
+}
+
+func (s *Settings) errorStackMarshaler() func(err error) interface{} {
+	if s == nil || s.ErrorStackMarshaler == nil {
+		return ErrorStackMarshaler
+	}
+	return s.ErrorStackMarshaler
+}
+
+func (s *Settings) errorMarshalFunc() func(err error) interface{} {
+	if s == nil || s.ErrorMarshalFunc == nil {
+		return ErrorMarshalFunc
+	}
+	return s.ErrorMarshalFunc
+}
+
+func (s *Settings) callerFieldName() string {
+	if s == nil {
+		return CallerFieldName
+	}
+	return s.CallerFieldName
+}
+
+func (s *Settings) callerSkipFrameCount() int {
+	if s == nil {
+		return CallerSkipFrameCount
+	}
+	return s.CallerSkipFrameCount
+}
+
+func (s *Settings) callerMarshalFunc() func(pc uintptr, file string, line int) string {
+	if s == nil || s.CallerMarshalFunc == nil {
+		return CallerMarshalFunc
+	}
+	return s.CallerMarshalFunc
+}
+
+func (s *Settings) timeFieldFormat() string {
+	if s == nil {
+		return TimeFieldFormat
+	}
+	return s.TimeFieldFormat
+}
+
+func (s *Settings) now() time.Time {
+	if s == nil || s.TimestampFunc == nil {
+		return TimestampFunc()
+	}
+	return s.TimestampFunc()
+}
+
+func (s *Settings) durationFieldUnit() time.Duration {
+	if s == nil || s.DurationFieldUnit == 0 {
+		return DurationFieldUnit
+	}
+	return s.DurationFieldUnit
+}
+
+func (s *Settings) durationFieldInteger() bool {
+	if s == nil {
+		return DurationFieldInteger
+	}
+	return s.DurationFieldInteger
+}
+
+func (s *Settings) errorHandler() func(err error) {
+	if s == nil || s.ErrorHandler == nil {
+		return ErrorHandler
+	}
+	return s.ErrorHandler
+}
@@ -0,0 +1,61 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSettings(t *testing.T) {
+	s := GlobalSettings()
+	s.TimestampFieldName = "ts"
+	s.LevelFieldName = "severity"
+	s.LevelFieldMarshalFunc = func(l Level) string { return "L" + l.String() }
+	s.MessageFieldName = "msg"
+	s.ErrorFieldName = "err"
+	s.CallerFieldName = "src"
+	s.CallerMarshalFunc = func(pc uintptr, file string, line int) string { return "here" }
+	s.TimeFieldFormat = time.RFC3339
+	s.TimestampFunc = func() time.Time { return time.Unix(1700000000, 0).UTC() }
+	s.DurationFieldUnit = time.Second
+	s.DurationFieldInteger = true
+
+	out := &bytes.Buffer{}
+	log := New(out).Settings(s).With().Timestamp().Dur("timeout", 90*time.Second).Logger()
+	log.Info().Caller().Err(errors.New("boom")).Dur("d", 2*time.Second).Msg("hello")
+	log.Info().Msgf("%d", 1)
+	plain := New(out)
+	plain.Info().Err(errors.New("boom")).Msg("hello")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"severity":"Linfo","timeout":90,"src":"here","err":"boom","d":2,"ts":"2023-11-14T22:13:20Z","msg":"hello"}` + "\n" +
+		`{"severity":"Linfo","timeout":90,"ts":"2023-11-14T22:13:20Z","msg":"1"}` + "\n" +
+		`{"level":"info","error":"boom","message":"hello"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := log.GetSettings().MessageFieldName; got != "msg" {
+		t.Errorf("GetSettings().MessageFieldName = %q, want msg", got)
+	}
+	if got := plain.GetSettings().MessageFieldName; got != MessageFieldName {
+		t.Errorf("GetSettings().MessageFieldName = %q, want %q", got, MessageFieldName)
+	}
+}
+
+func TestSettingsErrorHandler(t *testing.T) {
+	var got error
+	s := GlobalSettings()
+	s.ErrorHandler = func(err error) { got = err }
+	log := New(settingsFailingWriter{}).Settings(s)
+	log.Info().Msg("")
+	if got == nil || got.Error() != "disk full" {
+		t.Errorf("ErrorHandler got %v, want disk full", got)
+	}
+}
+
+type settingsFailingWriter struct{}
+
+func (settingsFailingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
@@ -0,0 +1,250 @@
+package zerolog
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	_ "unsafe" // for go:linkname
+)
+
+// errShardedClosed is returned by the ShardedWriter methods once closed.
+var errShardedClosed = errors.New("zerolog: sharded writer closed")
+
+// ShardedOptions configures a ShardedWriter.
+type ShardedOptions struct {
+	// Shards is the number of buffers. Defaults to runtime.GOMAXPROCS(0).
+	Shards int
+
+	// BufferSize is the size in bytes of a buffer above which the buffers are
+	// written to the wrapped writer by the go-routine filling it. Defaults to
+	// 64KiB.
+	BufferSize int
+
+	// FlushInterval is the interval at which the buffers are written by the
+	// background go-routine. Defaults to 100ms.
+	FlushInterval time.Duration
+
+	// StrictOrder, if true, uses a single buffer so events are written in the
+	// order they were logged, at the cost of the contention the shards avoid.
+	StrictOrder bool
+
+	// Sink, if not nil, receives the failures of the writes made by the
+	// background go-routine, otherwise reported to ErrorHandler.
+	Sink ErrorSink
+}
+
+//go:linkname procPin runtime.procPin
+func procPin() int
+
+//go:linkname procUnpin runtime.procUnpin
+func procUnpin()
+
+//go:linkname nanotime runtime.nanotime
+func nanotime() int64
+
+// shardRecordHeader is the size of the header of the events in the shard
+// buffers: the time of the event and its length.
+const shardRecordHeader = 12
+
+// shard is a buffer of a ShardedWriter, padded to its own cache lines.
+type shard struct {
+	mu  sync.Mutex
+	buf []byte // events, each preceded by its time and length
+	_   [64]byte
+}
+
+// ShardedWriter is a writer spreading the events over several buffers, each
+// with its own lock, written to the wrapped writer by a background go-routine.
+// It removes the contention of a single lock, like the one of SyncWriter,
+// when many cores log concurrently. See NewShardedWriter.
+//
+// The shard of an event is the one of the P, the logical processor of the Go
+// scheduler, running the logging go-routine, so the concurrent callers don't
+// share any state. The events are buffered with their monotonic time, and
+// the shards are merged by time when written: the events of a go-routine are
+// written in order, even when it moved to another P in between, and the
+// events of different go-routines in the order of their time, as precise as
+// the clock of the platform. Set StrictOrder when the output must follow the
+// order of the Write calls exactly.
+type ShardedWriter struct {
+	w      io.Writer
+	opts   ShardedOptions
+	shards []shard
+	closed int32 // atomic
+
+	outMu sync.Mutex // serializes the writes to w, guards batch, pos and out
+	batch [][]byte   // buffers swapped with the ones of the shards
+	pos   []int      // merge position in each batch buffer
+	out   []byte
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewShardedWriter returns a ShardedWriter buffering the events written to
+// w until Close is called:
+//
+//	w := zerolog.NewShardedWriter(os.Stdout, zerolog.ShardedOptions{})
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// The wrapped writer is used under a lock and does not need to be thread
+// safe. As events are written in batches, it receives several events per
+// Write and their level is not forwarded.
+func NewShardedWriter(w io.Writer, opts ShardedOptions) *ShardedWriter {
+	if opts.Shards <= 0 {
+		opts.Shards = runtime.GOMAXPROCS(0)
+	}
+	if opts.StrictOrder {
+		opts.Shards = 1
+	}
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 64 * 1024
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 100 * time.Millisecond
+	}
+	sw := &ShardedWriter{
+		w:      w,
+		opts:   opts,
+		shards: make([]shard, opts.Shards),
+		batch:  make([][]byte, opts.Shards),
+		pos:    make([]int, opts.Shards),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go sw.run()
+	return sw
+}
+
+// Write implements the io.Writer interface. p is copied into a buffer.
+func (w *ShardedWriter) Write(p []byte) (n int, err error) {
+	s := &w.shards[w.shardIndex()]
+	s.mu.Lock()
+	if atomic.LoadInt32(&w.closed) != 0 {
+		s.mu.Unlock()
+		return 0, errShardedClosed
+	}
+	// The time is read under the lock so each shard is ordered by time.
+	var hdr [shardRecordHeader]byte
+	binary.LittleEndian.PutUint64(hdr[:8], uint64(nanotime()))
+	binary.LittleEndian.PutUint32(hdr[8:], uint32(len(p)))
+	s.buf = append(append(s.buf, hdr[:]...), p...)
+	full := len(s.buf) >= w.opts.BufferSize
+	s.mu.Unlock()
+	if full {
+		if err = w.writeAll(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// shardIndex returns the index of the shard of the P running the caller.
+func (w *ShardedWriter) shardIndex() int {
+	if len(w.shards) == 1 {
+		return 0
+	}
+	p := procPin()
+	procUnpin()
+	return p % len(w.shards)
+}
+
+// writeAll writes the events of all the shards to the wrapped writer, merged
+// by time.
+func (w *ShardedWriter) writeAll() error {
+	w.outMu.Lock()
+	defer w.outMu.Unlock()
+	// Take the events of all the shards at once: an event of a go-routine
+	// can't be left for the next batch while a later one is written.
+	for i := range w.shards {
+		w.shards[i].mu.Lock()
+	}
+	empty := true
+	for i := range w.shards {
+		s := &w.shards[i]
+		w.batch[i], s.buf = s.buf, w.batch[i][:0]
+		empty = empty && len(w.batch[i]) == 0
+	}
+	for i := range w.shards {
+		w.shards[i].mu.Unlock()
+	}
+	if empty {
+		return nil
+	}
+	w.out = w.out[:0]
+	for i := range w.pos {
+		w.pos[i] = 0
+	}
+	for {
+		next := -1
+		var nextTime uint64
+		for i, b := range w.batch {
+			if w.pos[i] == len(b) {
+				continue
+			}
+			if t := binary.LittleEndian.Uint64(b[w.pos[i]:]); next == -1 || t < nextTime {
+				next, nextTime = i, t
+			}
+		}
+		if next == -1 {
+			break
+		}
+		b, start := w.batch[next], w.pos[next]+shardRecordHeader
+		end := start + int(binary.LittleEndian.Uint32(b[start-4:]))
+		w.out = append(w.out, b[start:end]...)
+		w.pos[next] = end
+	}
+	_, err := w.w.Write(w.out)
+	return err
+}
+
+// Flush writes the buffered events and flushes the wrapped writer.
+func (w *ShardedWriter) Flush() error {
+	if atomic.LoadInt32(&w.closed) != 0 {
+		return errShardedClosed
+	}
+	if err := w.writeAll(); err != nil {
+		return err
+	}
+	w.outMu.Lock()
+	defer w.outMu.Unlock()
+	return flush(w.w)
+}
+
+// Close stops the background go-routine, writes the buffered events and
+// closes the wrapped writer if it implements io.Closer.
+func (w *ShardedWriter) Close() error {
+	if !atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+		return errShardedClosed
+	}
+	close(w.stop)
+	<-w.done
+	err := w.writeAll()
+	if closer, ok := w.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+func (w *ShardedWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := w.writeAll(); err != nil {
+				reportError(w.opts.Sink, nil, WriteFailure, NoLevel, err)
+			}
+		case <-w.stop:
+			return
+		}
+	}
+}
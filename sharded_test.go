@@ -0,0 +1,152 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShardedWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewShardedWriter(out, ShardedOptions{Shards: 4, FlushInterval: time.Hour})
+	log := New(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info().Int("g", i).Int("n", j).Send()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if out.Len() != 0 {
+		t.Errorf("events written before Flush: %d bytes", out.Len())
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("{}\n")); err != errShardedClosed {
+		t.Errorf("Write after Close error = %v, want %v", err, errShardedClosed)
+	}
+	if n := strings.Count(decodeIfBinaryToString(out.Bytes()), "\n"); n != 800 {
+		t.Errorf("got %d events, want 800", n)
+	}
+}
+
+func TestShardedWriterStrictOrder(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewShardedWriter(out, ShardedOptions{StrictOrder: true, BufferSize: 64})
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var seq []string
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				mu.Lock()
+				line := string(rune('a'+i)) + strings.Repeat("x", j%10) + "\n"
+				seq = append(seq, line)
+				w.Write([]byte(line))
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	w.Close()
+	if got, want := out.String(), strings.Join(seq, ""); got != want {
+		t.Errorf("events reordered:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestShardedWriterGoroutineOrder(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	out := &bytes.Buffer{}
+	w := NewShardedWriter(out, ShardedOptions{Shards: 4, BufferSize: 256, FlushInterval: time.Millisecond})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				w.Write([]byte(fmt.Sprintf("%d %d\n", i, j)))
+				if j%10 == 0 {
+					// Give the go-routine a chance to move to another P.
+					runtime.Gosched()
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	next := make([]int, 8)
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var i, j int
+		if _, err := fmt.Sscanf(line, "%d %d", &i, &j); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		if j != next[i] {
+			t.Fatalf("go-routine %d: got event %d, want %d", i, j, next[i])
+		}
+		next[i]++
+	}
+	for i, n := range next {
+		if n != 200 {
+			t.Errorf("go-routine %d: got %d events, want 200", i, n)
+		}
+	}
+}
+
+func TestShardedWriterInterval(t *testing.T) {
+	out := &bytes.Buffer{}
+	sw := SyncWriter(out)
+	w := NewShardedWriter(sw, ShardedOptions{FlushInterval: 10 * time.Millisecond})
+	defer w.Close()
+	w.Write([]byte("a\n"))
+	w.Write([]byte("b\n"))
+	time.Sleep(50 * time.Millisecond)
+	sw.(*syncWriter).mu.Lock()
+	lines := strings.Fields(out.String())
+	sw.(*syncWriter).mu.Unlock()
+	sort.Strings(lines)
+	if strings.Join(lines, ",") != "a,b" {
+		t.Errorf("events not written by the background go-routine: %q", lines)
+	}
+}
+
+func BenchmarkShardedWriter(b *testing.B) {
+	w := NewShardedWriter(ioutil.Discard, ShardedOptions{})
+	defer w.Close()
+	logger := New(w)
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
+
+func BenchmarkSyncWriter(b *testing.B) {
+	logger := New(SyncWriter(ioutil.Discard))
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			logger.Info().Msg(fakeMessage)
+		}
+	})
+}
@@ -0,0 +1,116 @@
+//go:build !windows
+// +build !windows
+
+package zerolog
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Reopener is implemented by the file outputs able to reopen their file once
+// it has been moved, like rollingwriter.Writer.
+type Reopener interface {
+	Reopen() error
+}
+
+// SignalOptions configures HandleSignals.
+type SignalOptions struct {
+	// Logger is the name of the logger, registered with Register or Replace,
+	// which level is changed with SetRegisteredLevel. The global level is
+	// changed if empty.
+	Logger string
+
+	// Level is the level set by BumpSignal. Defaults to DebugLevel, the zero
+	// value.
+	Level Level
+
+	// BumpSignal sets the level to Level. Defaults to SIGUSR1.
+	BumpSignal os.Signal
+
+	// RestoreSignal restores the level in effect when HandleSignals was
+	// called. Defaults to SIGUSR2.
+	RestoreSignal os.Signal
+
+	// ReopenSignal reopens the Reopen outputs. Defaults to SIGHUP.
+	ReopenSignal os.Signal
+
+	// Reopen are the outputs reopened on ReopenSignal, typically sent by
+	// logrotate once the files have been moved.
+	Reopen []Reopener
+}
+
+// HandleSignals installs signal handlers changing the logging level and
+// reopening file outputs at runtime, until the returned function is called:
+//
+//	w, _ := rollingwriter.New("/var/log/app.log", rollingwriter.Options{})
+//	log := zerolog.New(w).Level(zerolog.InfoLevel)
+//	zerolog.Register("app", &log)
+//	stop := zerolog.HandleSignals(zerolog.SignalOptions{Logger: "app", Reopen: []zerolog.Reopener{w}})
+//	defer stop()
+//	// kill -USR1 <pid>: debug level
+//	// kill -USR2 <pid>: back to info level
+//	// kill -HUP <pid>: reopen /var/log/app.log
+//
+// Both the level of a registered logger and the global level are changed
+// atomically, so it is safe while logging. Reopen and level failures are
+// reported to ErrorHandler.
+func HandleSignals(opts SignalOptions) (stop func()) {
+	if opts.BumpSignal == nil {
+		opts.BumpSignal = syscall.SIGUSR1
+	}
+	if opts.RestoreSignal == nil {
+		opts.RestoreSignal = syscall.SIGUSR2
+	}
+	if opts.ReopenSignal == nil {
+		opts.ReopenSignal = syscall.SIGHUP
+	}
+	initial := GlobalLevel()
+	if opts.Logger != "" {
+		initial = Get(opts.Logger).GetLevel()
+	}
+	setLevel := func(level Level) {
+		if opts.Logger == "" {
+			SetGlobalLevel(level)
+		} else if err := SetRegisteredLevel(opts.Logger, level); err != nil {
+			reportError(nil, nil, 0, level, err)
+		}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, opts.BumpSignal, opts.RestoreSignal, opts.ReopenSignal)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case sig := <-sigs:
+				switch sig {
+				case opts.BumpSignal:
+					setLevel(opts.Level)
+				case opts.RestoreSignal:
+					setLevel(initial)
+				case opts.ReopenSignal:
+					for _, r := range opts.Reopen {
+						if err := r.Reopen(); err != nil {
+							reportError(nil, nil, 0, NoLevel, err)
+						}
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(done)
+			<-stopped
+		})
+	}
+}
@@ -0,0 +1,108 @@
+//go:build !windows
+// +build !windows
+
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type testReopener chan struct{}
+
+func (r testReopener) Reopen() error {
+	r <- struct{}{}
+	return nil
+}
+
+// waitFor polls cond for up to a second.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timeout waiting for %s", what)
+}
+
+func TestHandleSignalsGlobalLevel(t *testing.T) {
+	defer SetGlobalLevel(GlobalLevel())
+	SetGlobalLevel(WarnLevel)
+	reopened := make(testReopener, 1)
+	stop := HandleSignals(SignalOptions{Level: TraceLevel, Reopen: []Reopener{reopened}})
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	waitFor(t, "bump", func() bool { return GlobalLevel() == TraceLevel })
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR2)
+	waitFor(t, "restore", func() bool { return GlobalLevel() == WarnLevel })
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	select {
+	case <-reopened:
+	case <-time.After(time.Second):
+		t.Fatal("output not reopened")
+	}
+}
+
+func TestHandleSignalsReopenError(t *testing.T) {
+	defer func(h func(error)) { ErrorHandler = h }(ErrorHandler)
+	errs := make(chan error, 1)
+	ErrorHandler = func(err error) { errs <- err }
+	failing := reopenerFunc(func() error { return errors.New("no such file") })
+	stop := HandleSignals(SignalOptions{Reopen: []Reopener{failing}})
+	defer stop()
+
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	select {
+	case err := <-errs:
+		if err.Error() != "no such file" {
+			t.Errorf("error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("reopen error not reported")
+	}
+}
+
+type reopenerFunc func() error
+
+func (f reopenerFunc) Reopen() error { return f() }
+
+func TestHandleSignalsLogger(t *testing.T) {
+	log := New(&bytes.Buffer{}).Level(InfoLevel)
+	Register("signal", &log)
+	defer Unregister("signal")
+	reopened := make(testReopener, 1)
+	stop := HandleSignals(SignalOptions{Logger: "signal", Reopen: []Reopener{reopened}})
+	syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	timeout := time.After(time.Second)
+	// The level is changed while logging.
+	for log.GetLevel() != DebugLevel {
+		select {
+		case <-timeout:
+			t.Fatalf("level = %v, want debug", log.GetLevel())
+		default:
+			log.Info().Msg("")
+		}
+	}
+	syscall.Kill(syscall.Getpid(), syscall.SIGHUP)
+	select {
+	case <-reopened:
+	case <-timeout:
+		t.Fatal("output not reopened")
+	}
+	stop()
+	stop() // idempotent
+}
+
+func TestConsoleExpansionToggleOnSignal(t *testing.T) {
+	expand := &ConsoleExpansion{}
+	stop := expand.ToggleOnSignal(syscall.SIGWINCH)
+	defer stop()
+	syscall.Kill(syscall.Getpid(), syscall.SIGWINCH)
+	waitFor(t, "toggle", expand.Expanded)
+}
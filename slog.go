@@ -0,0 +1,359 @@
+//go:build go1.21
+// +build go1.21
+
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// slogFrame is a group opened with WithGroup, with the attributes added to
+// it by WithAttrs.
+type slogFrame struct {
+	group string
+	attrs []slog.Attr
+}
+
+type slogHandler struct {
+	l Logger
+	// frames holds the groups opened by WithGroup. Attributes added before
+	// any group are encoded in the logger context instead.
+	frames []slogFrame
+}
+
+// NewSlogHandler returns a slog.Handler emitting the records through l, so
+// libraries written against log/slog share the zerolog pipeline. Attributes
+// are added as typed fields, groups as dicts, and slog levels are mapped to
+// the closest zerolog level at or below them. The time of the records is
+// used as the event timestamp, see Event.At.
+//
+// Attributes added with WithAttrs outside of any group are encoded once in
+// the logger context.
+func NewSlogHandler(l Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// levelFromSlog maps a slog level to the closest zerolog level at or below
+// it.
+func levelFromSlog(l slog.Level) Level {
+	switch {
+	case l >= slog.LevelError:
+		return ErrorLevel
+	case l >= slog.LevelWarn:
+		return WarnLevel
+	case l >= slog.LevelInfo:
+		return InfoLevel
+	case l >= slog.LevelDebug:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+// Enabled implements slog.Handler.
+func (h *slogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return h.l.enabled(levelFromSlog(l))
+}
+
+// Handle implements slog.Handler.
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	e := h.l.WithLevel(levelFromSlog(r.Level))
+	if e == nil {
+		return nil
+	}
+	if ctx != nil {
+		e.Ctx(ctx)
+	}
+	if !r.Time.IsZero() {
+		e.At(r.Time)
+	}
+	// The record attributes go in the innermost group, and the groups are
+	// closed from the innermost one.
+	dicts := make([]*Event, len(h.frames)+1)
+	dicts[0] = e
+	for i, f := range h.frames {
+		dicts[i+1] = Dict()
+		appendSlogAttrs(dicts[i+1], f.attrs)
+	}
+	inner := dicts[len(dicts)-1]
+	r.Attrs(func(a slog.Attr) bool {
+		appendSlogAttr(inner, a)
+		return true
+	})
+	for i := len(h.frames) - 1; i >= 0; i-- {
+		if len(dicts[i+1].buf) > 1 {
+			dicts[i].Dict(h.frames[i].group, dicts[i+1])
+		} else {
+			// Empty groups are omitted.
+			putEvent(dicts[i+1])
+		}
+	}
+	e.Msg(r.Message)
+	return nil
+}
+
+// WithAttrs implements slog.Handler.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := &slogHandler{l: h.l, frames: h.frames}
+	if len(h.frames) == 0 {
+		dict := Dict()
+		appendSlogAttrs(dict, attrs)
+		h2.l = h.l.With().Logger()
+		if len(dict.buf) > 1 {
+			h2.l.context = enc.AppendObjectData(h2.l.context, dict.buf)
+		}
+		putEvent(dict)
+		return h2
+	}
+	h2.frames = append([]slogFrame(nil), h.frames...)
+	last := &h2.frames[len(h2.frames)-1]
+	last.attrs = append(last.attrs[:len(last.attrs):len(last.attrs)], attrs...)
+	return h2
+}
+
+// WithGroup implements slog.Handler.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	frames := make([]slogFrame, len(h.frames), len(h.frames)+1)
+	copy(frames, h.frames)
+	return &slogHandler{l: h.l, frames: append(frames, slogFrame{group: name})}
+}
+
+func appendSlogAttrs(e *Event, attrs []slog.Attr) {
+	for _, a := range attrs {
+		appendSlogAttr(e, a)
+	}
+}
+
+// appendSlogAttr adds a as a typed field of e.
+func appendSlogAttr(e *Event, a slog.Attr) {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return
+		}
+		if a.Key == "" {
+			// Groups without key are inlined.
+			appendSlogAttrs(e, attrs)
+			return
+		}
+		dict := Dict()
+		appendSlogAttrs(dict, attrs)
+		e.Dict(a.Key, dict)
+		return
+	}
+	if a.Key == "" {
+		return
+	}
+	switch v.Kind() {
+	case slog.KindString:
+		e.Str(a.Key, v.String())
+	case slog.KindInt64:
+		e.Int64(a.Key, v.Int64())
+	case slog.KindUint64:
+		e.Uint64(a.Key, v.Uint64())
+	case slog.KindFloat64:
+		e.Float64(a.Key, v.Float64())
+	case slog.KindBool:
+		e.Bool(a.Key, v.Bool())
+	case slog.KindDuration:
+		e.Dur(a.Key, v.Duration())
+	case slog.KindTime:
+		e.Time(a.Key, v.Time())
+	default:
+		if err, ok := v.Any().(error); ok {
+			e.AnErr(a.Key, err)
+		} else {
+			e.Interface(a.Key, v.Any())
+		}
+	}
+}
+
+// levelToSlog maps a zerolog level to a slog level.
+func levelToSlog(l Level) slog.Level {
+	switch l {
+	case TraceLevel:
+		return slog.LevelDebug - 4
+	case DebugLevel:
+		return slog.LevelDebug
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	case FatalLevel:
+		return slog.LevelError + 4
+	case PanicLevel:
+		return slog.LevelError + 8
+	default:
+		if l < TraceLevel {
+			return slog.LevelDebug - 4 + slog.Level(l-TraceLevel)
+		}
+		return slog.LevelInfo
+	}
+}
+
+// slogWriter is a LevelWriter passing the events to a slog.Handler.
+type slogWriter struct {
+	h slog.Handler
+}
+
+// FromSlogHandler returns a Logger routing its events to h, so applications
+// using the zerolog API can feed an existing slog handler chain. The level of
+// the logger is set to the lowest level enabled by h.
+//
+// Each event is decoded once into a slog.Record: the message, level and
+// timestamp fields become the record message, level and time, and the other
+// fields its attributes, in order, objects becoming groups. Numbers are
+// passed as int64 when integral and float64 otherwise.
+func FromSlogHandler(h slog.Handler) Logger {
+	l := New(slogWriter{h})
+	l.level = Disabled
+	for lvl := PanicLevel; lvl >= TraceLevel; lvl-- {
+		if h.Enabled(context.Background(), levelToSlog(lvl)) {
+			l.level = lvl
+		}
+	}
+	return l
+}
+
+// Write implements io.Writer.
+func (w slogWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements LevelWriter.
+func (w slogWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	ctx := context.Background()
+	sl := levelToSlog(level)
+	if level != NoLevel && !w.h.Enabled(ctx, sl) {
+		return len(p), nil
+	}
+	attrs, err := decodeSlogAttrs(decodeIfBinaryToBytes(p))
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode event: %s", err)
+	}
+	var (
+		msg string
+		t   time.Time
+	)
+	kept := attrs[:0]
+	for _, a := range attrs {
+		switch {
+		case a.Key == MessageFieldName && a.Value.Kind() == slog.KindString:
+			msg = a.Value.String()
+		case a.Key == LevelFieldName && a.Value.Kind() == slog.KindString:
+			if level == NoLevel {
+				if lvl, err := ParseLevel(a.Value.String()); err == nil {
+					sl = levelToSlog(lvl)
+				}
+			}
+		case a.Key == TimestampFieldName:
+			if ts, ok := parseSlogTime(a.Value); ok {
+				t = ts
+			} else {
+				kept = append(kept, a)
+			}
+		default:
+			kept = append(kept, a)
+		}
+	}
+	if level == NoLevel && !w.h.Enabled(ctx, sl) {
+		return len(p), nil
+	}
+	r := slog.NewRecord(t, sl, msg, 0)
+	r.AddAttrs(kept...)
+	if err := w.h.Handle(ctx, r); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// parseSlogTime parses a timestamp encoded with TimeFieldFormat.
+func parseSlogTime(v slog.Value) (time.Time, bool) {
+	switch v.Kind() {
+	case slog.KindString:
+		t, err := time.Parse(TimeFieldFormat, v.String())
+		return t, err == nil
+	case slog.KindInt64:
+		i := v.Int64()
+		switch TimeFieldFormat {
+		case TimeFormatUnix:
+			return time.Unix(i, 0), true
+		case TimeFormatUnixMs:
+			return time.Unix(0, i*int64(time.Millisecond)), true
+		case TimeFormatUnixMicro:
+			return time.Unix(0, i*int64(time.Microsecond)), true
+		case TimeFormatUnixNano:
+			return time.Unix(0, i), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// decodeSlogAttrs decodes the JSON object p into attributes, preserving the
+// order of its fields.
+func decodeSlogAttrs(p []byte) ([]slog.Attr, error) {
+	d := json.NewDecoder(bytes.NewReader(p))
+	d.UseNumber()
+	if t, err := d.Token(); err != nil {
+		return nil, err
+	} else if t != json.Delim('{') {
+		return nil, errors.New("not an object")
+	}
+	var attrs []slog.Attr
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, _ := t.(string)
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return nil, err
+		}
+		if len(raw) > 0 && raw[0] == '{' {
+			group, err := decodeSlogAttrs(raw)
+			if err != nil {
+				return nil, err
+			}
+			attrs = append(attrs, slog.Attr{Key: key, Value: slog.GroupValue(group...)})
+			continue
+		}
+		vd := json.NewDecoder(bytes.NewReader(raw))
+		vd.UseNumber()
+		var v interface{}
+		if err := vd.Decode(&v); err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case string:
+			attrs = append(attrs, slog.String(key, v))
+		case bool:
+			attrs = append(attrs, slog.Bool(key, v))
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				attrs = append(attrs, slog.Int64(key, i))
+			} else if f, err := v.Float64(); err == nil {
+				attrs = append(attrs, slog.Float64(key, f))
+			} else {
+				attrs = append(attrs, slog.String(key, v.String()))
+			}
+		default:
+			attrs = append(attrs, slog.Any(key, v))
+		}
+	}
+	return attrs, nil
+}
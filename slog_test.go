@@ -0,0 +1,116 @@
+//go:build go1.21 && !binary_log
+// +build go1.21,!binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	out := &bytes.Buffer{}
+	h := NewSlogHandler(New(out).Level(DebugLevel))
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		h    slog.Handler
+		rec  func() slog.Record
+		want string
+	}{
+		{"typed attrs", h, func() slog.Record {
+			r := slog.NewRecord(time.Time{}, slog.LevelInfo, "hello", 0)
+			r.AddAttrs(
+				slog.String("s", "v"),
+				slog.Int("i", -1),
+				slog.Uint64("u", 2),
+				slog.Float64("f", 1.5),
+				slog.Bool("b", true),
+				slog.Duration("d", time.Second),
+				slog.Any("err", errors.New("boom")),
+				slog.Any("any", []int{1, 2}),
+				slog.Group("g", slog.String("a", "b")),
+				slog.Group("", slog.String("inline", "x")),
+				slog.Group("empty"),
+			)
+			return r
+		}, `{"level":"info","s":"v","i":-1,"u":2,"f":1.5,"b":true,"d":1000,"err":"boom","any":[1,2],"g":{"a":"b"},"inline":"x","message":"hello"}` + "\n"},
+		{"levels", h, func() slog.Record {
+			return slog.NewRecord(time.Time{}, slog.LevelWarn+1, "", 0)
+		}, `{"level":"warn"}` + "\n"},
+		{"groups", h.WithAttrs([]slog.Attr{slog.String("app", "x")}).WithGroup("req").WithAttrs([]slog.Attr{slog.String("id", "1")}).WithGroup("user"), func() slog.Record {
+			r := slog.NewRecord(time.Time{}, slog.LevelError, "failed", 0)
+			r.AddAttrs(slog.String("name", "bob"))
+			return r
+		}, `{"level":"error","app":"x","req":{"id":"1","user":{"name":"bob"}},"message":"failed"}` + "\n"},
+		{"empty group", h.WithGroup("req"), func() slog.Record {
+			return slog.NewRecord(time.Time{}, slog.LevelDebug, "", 0)
+		}, `{"level":"debug"}` + "\n"},
+		{"time", h, func() slog.Record {
+			return slog.NewRecord(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), slog.LevelInfo, "", 0)
+		}, `{"level":"info","time":"2024-01-02T03:04:05Z"}` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			if err := tt.h.Handle(ctx, tt.rec()); err != nil {
+				t.Fatal(err)
+			}
+			if got := out.String(); got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+
+	if h.Enabled(ctx, slog.LevelDebug-1) {
+		t.Error("trace records enabled on a debug logger")
+	}
+	if !h.Enabled(ctx, slog.LevelDebug) {
+		t.Error("debug records disabled on a debug logger")
+	}
+}
+
+type recordingHandler struct {
+	level   slog.Level
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(_ context.Context, l slog.Level) bool { return l >= h.level }
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.records = append(h.records, r)
+	return nil
+}
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+func (h *recordingHandler) WithGroup(string) slog.Handler      { return h }
+
+func TestFromSlogHandler(t *testing.T) {
+	h := &recordingHandler{level: slog.LevelInfo}
+	log := FromSlogHandler(h).With().Timestamp().Str("app", "x").Logger()
+	if got := log.GetLevel(); got != InfoLevel {
+		t.Errorf("level = %v, want info", got)
+	}
+	log.Debug().Msg("dropped")
+	log.Warn().Int("n", 1).Float64("f", 1.5).Bool("b", true).Dict("d", Dict().Str("k", "v")).Msg("hello")
+
+	if len(h.records) != 1 {
+		t.Fatalf("got %d records, want 1", len(h.records))
+	}
+	r := h.records[0]
+	if r.Level != slog.LevelWarn || r.Message != "hello" || r.Time.IsZero() {
+		t.Errorf("record = %v %q %v", r.Level, r.Message, r.Time)
+	}
+	var got []string
+	r.Attrs(func(a slog.Attr) bool {
+		got = append(got, a.String())
+		return true
+	})
+	if want := "[app=x n=1 f=1.5 b=true d=[k=v]]"; fmt.Sprint(got) != want {
+		t.Errorf("attrs = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,433 @@
+// Package spool provides a zerolog writer persisting events to an on-disk
+// spool before shipping them to a downstream zerolog.AckWriter, so events not
+// yet acknowledged survive restarts.
+//
+//	w, err := spool.New("/var/spool/app-logs", shipper, spool.Options{})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Events are appended to segment files. A background go-routine ships them in
+// order, and persists a cursor past each acknowledged event. Once all the
+// events of a segment are acknowledged, the segment is removed. On restart,
+// shipping resumes from the cursor: an event is only shipped twice if the
+// process stops between its acknowledgment and the cursor update. The event
+// being written when the process crashed, if only partially written, is
+// dropped.
+//
+// Any writer can be used downstream by wrapping it in a
+// zerolog.SyncAckWriter.
+package spool
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+const (
+	segmentExt = ".spool"
+	cursorFile = "cursor"
+	headerSize = 5 // payload length and level
+)
+
+// Options configures a Writer.
+type Options struct {
+	// SegmentSize is the size in bytes after which a new segment file is
+	// started. Defaults to 16MiB.
+	SegmentSize int64
+
+	// AckTimeout is the maximum duration of a single shipping attempt.
+	// Defaults to 5s.
+	AckTimeout time.Duration
+
+	// RetryBackoff is the delay between two shipping attempts of an event.
+	// Defaults to 1s.
+	RetryBackoff time.Duration
+
+	// Sync commits each event to stable storage before Write returns.
+	Sync bool
+
+	// Sink, if not nil, receives a WriteFailure error for each failed
+	// shipping attempt and cursor update. They are otherwise reported to
+	// ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+// cursor is the position following the last acknowledged event.
+type cursor struct {
+	Segment uint64 `json:"segment"`
+	Offset  int64  `json:"offset"`
+}
+
+// Writer is a zerolog.LevelWriter spooling events on disk and shipping them
+// to a zerolog.AckWriter.
+type Writer struct {
+	dir  string
+	dest zerolog.AckWriter
+	opts Options
+
+	mu      sync.Mutex
+	seg     *os.File
+	segID   uint64
+	segSize int64
+	closed  bool
+
+	cmu sync.Mutex
+	cur cursor
+
+	// The segment being shipped, only used by the run go-routine.
+	rseg   *os.File
+	rsegID uint64
+
+	notify chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// New opens the spool in dir, creating it if needed, and starts shipping the
+// events it holds, and the ones written to the returned Writer, to dest.
+func New(dir string, dest zerolog.AckWriter, opts Options) (*Writer, error) {
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = 16 << 20
+	}
+	if opts.AckTimeout <= 0 {
+		opts.AckTimeout = 5 * time.Second
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = time.Second
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("spool: %v", err)
+	}
+	w := &Writer{
+		dir:    dir,
+		dest:   dest,
+		opts:   opts,
+		notify: make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	segments, err := w.segments()
+	if err != nil {
+		return nil, err
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, cursorFile)); err == nil {
+		if err := json.Unmarshal(b, &w.cur); err != nil {
+			return nil, fmt.Errorf("spool: invalid cursor: %v", err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("spool: %v", err)
+	}
+	if len(segments) > 0 && w.cur.Segment < segments[0] {
+		w.cur = cursor{Segment: segments[0]}
+	}
+	w.segID = w.cur.Segment
+	if len(segments) > 0 && segments[len(segments)-1] > w.segID {
+		w.segID = segments[len(segments)-1]
+	}
+	if w.segID == 0 {
+		w.segID = 1
+		w.cur.Segment = 1
+	}
+	if err := w.repairSegment(); err != nil {
+		return nil, err
+	}
+	if err := w.openSegment(); err != nil {
+		return nil, err
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *Writer) segmentName(id uint64) string {
+	return filepath.Join(w.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+// segments returns the ids of the segment files, in order.
+func (w *Writer) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return nil, fmt.Errorf("spool: %v", err)
+	}
+	var ids []uint64
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		if id, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// repairSegment truncates the segment w.segID to the end of its last
+// complete record, dropping the partial record left by a crash during a
+// write, so the records appended next are read back.
+func (w *Writer) repairSegment() error {
+	f, err := os.OpenFile(w.segmentName(w.segID), os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("spool: %v", err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("spool: %v", err)
+	}
+	r := bufio.NewReader(f)
+	var end int64
+	var hdr [headerSize]byte
+	for {
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			break
+		}
+		n := int64(binary.BigEndian.Uint32(hdr[:4]))
+		if end+headerSize+n > info.Size() {
+			break
+		}
+		if _, err := r.Discard(int(n)); err != nil {
+			break
+		}
+		end += headerSize + n
+	}
+	if end < info.Size() {
+		if err := f.Truncate(end); err != nil {
+			return fmt.Errorf("spool: %v", err)
+		}
+	}
+	return nil
+}
+
+// openSegment opens the segment w.segID for appending. w.mu must be held.
+func (w *Writer) openSegment() error {
+	f, err := os.OpenFile(w.segmentName(w.segID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("spool: %v", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("spool: %v", err)
+	}
+	w.seg = f
+	w.segSize = info.Size()
+	return nil
+}
+
+// Write implements io.Writer. The event is spooled without level.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. It returns once the event is
+// spooled, shipping happens in the background.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	rec := make([]byte, headerSize+len(p))
+	binary.BigEndian.PutUint32(rec, uint32(len(p)))
+	rec[4] = byte(int8(level))
+	copy(rec[headerSize:], p)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("spool: writer closed")
+	}
+	if w.segSize > 0 && w.segSize+int64(len(rec)) > w.opts.SegmentSize {
+		if err := w.seg.Close(); err != nil {
+			return 0, fmt.Errorf("spool: %v", err)
+		}
+		w.segID++
+		if err := w.openSegment(); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := w.seg.Write(rec); err != nil {
+		return 0, fmt.Errorf("spool: %v", err)
+	}
+	w.segSize += int64(len(rec))
+	if w.opts.Sync {
+		if err := w.seg.Sync(); err != nil {
+			return 0, fmt.Errorf("spool: %v", err)
+		}
+	}
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+	return len(p), nil
+}
+
+// writeSegment returns the id of the segment being written.
+func (w *Writer) writeSegment() uint64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.segID
+}
+
+// saveCursor persists c atomically.
+func (w *Writer) saveCursor(c cursor) error {
+	b, _ := json.Marshal(c)
+	tmp := filepath.Join(w.dir, cursorFile+".tmp")
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(w.dir, cursorFile)); err != nil {
+		return err
+	}
+	w.cmu.Lock()
+	w.cur = c
+	w.cmu.Unlock()
+	return nil
+}
+
+// next reads the event at c. It returns io.EOF if no complete event follows
+// c in its segment.
+func (w *Writer) next(c cursor) (level zerolog.Level, p []byte, err error) {
+	if w.rseg == nil || w.rsegID != c.Segment {
+		w.closeReader()
+		f, err := os.Open(w.segmentName(c.Segment))
+		if err != nil {
+			return 0, nil, err
+		}
+		w.rseg, w.rsegID = f, c.Segment
+	}
+	f := w.rseg
+	var hdr [headerSize]byte
+	if _, err := f.ReadAt(hdr[:], c.Offset); err != nil {
+		return 0, nil, io.EOF
+	}
+	p = make([]byte, binary.BigEndian.Uint32(hdr[:4]))
+	if _, err := f.ReadAt(p, c.Offset+headerSize); err != nil {
+		return 0, nil, io.EOF
+	}
+	return zerolog.Level(int8(hdr[4])), p, nil
+}
+
+// closeReader closes the segment being shipped, if any.
+func (w *Writer) closeReader() {
+	if w.rseg != nil {
+		w.rseg.Close()
+		w.rseg = nil
+	}
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	defer w.closeReader()
+	w.cmu.Lock()
+	c := w.cur
+	w.cmu.Unlock()
+	for {
+		level, p, err := w.next(c)
+		if err != nil {
+			if c.Segment < w.writeSegment() {
+				// The segment is complete and fully acknowledged.
+				old := c.Segment
+				c = cursor{Segment: old + 1}
+				if err := w.saveCursor(c); err != nil {
+					zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel, err)
+				}
+				w.closeReader()
+				os.Remove(w.segmentName(old))
+				continue
+			}
+			select {
+			case <-w.stop:
+				return
+			case <-w.notify:
+			}
+			continue
+		}
+		if !w.ship(level, p) {
+			return
+		}
+		c.Offset += headerSize + int64(len(p))
+		if err := w.saveCursor(c); err != nil {
+			zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel, err)
+		}
+	}
+}
+
+// ship sends p until acknowledged. It returns false if the writer is closed
+// in the meantime.
+func (w *Writer) ship(level zerolog.Level, p []byte) bool {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), w.opts.AckTimeout)
+		err := w.dest.WriteAck(ctx, level, p)
+		cancel()
+		if err == nil {
+			return true
+		}
+		zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, level,
+			fmt.Errorf("spool: event not acknowledged: %v", err))
+		select {
+		case <-w.stop:
+			return false
+		case <-time.After(w.opts.RetryBackoff):
+		}
+	}
+}
+
+// Pending reports whether spooled events are not acknowledged yet.
+func (w *Writer) Pending() bool {
+	w.mu.Lock()
+	segID, segSize := w.segID, w.segSize
+	w.mu.Unlock()
+	w.cmu.Lock()
+	defer w.cmu.Unlock()
+	return w.cur.Segment < segID || w.cur.Offset < segSize
+}
+
+// Drain waits until all the spooled events are acknowledged, or until ctx is
+// done.
+func (w *Writer) Drain(ctx context.Context) error {
+	t := time.NewTicker(10 * time.Millisecond)
+	defer t.Stop()
+	for w.Pending() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+	return nil
+}
+
+// Close stops shipping and closes the spool. The events not acknowledged yet
+// are shipped when the spool is opened again.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return errors.New("spool: writer already closed")
+	}
+	w.closed = true
+	err := w.seg.Close()
+	w.mu.Unlock()
+	close(w.stop)
+	<-w.done
+	if err != nil {
+		return fmt.Errorf("spool: %v", err)
+	}
+	return nil
+}
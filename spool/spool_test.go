@@ -0,0 +1,160 @@
+package spool
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+type recorder struct {
+	mu     sync.Mutex
+	events []string
+	fail   bool
+}
+
+func (r *recorder) WriteAck(ctx context.Context, level zerolog.Level, p []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.fail {
+		return errors.New("unavailable")
+	}
+	r.events = append(r.events, level.String()+" "+cbor.DecodeIfBinaryToString(p))
+	return nil
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func drain(t *testing.T, w *Writer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWriterShips(t *testing.T) {
+	dir := t.TempDir()
+	r := &recorder{}
+	w, err := New(dir, r, Options{SegmentSize: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	l := zerolog.New(w)
+	l.Info().Msg("a")
+	l.Warn().Msg("b")
+	l.Error().Msg("c")
+	drain(t, w)
+
+	want := []string{
+		"info {\"level\":\"info\",\"message\":\"a\"}\n",
+		"warn {\"level\":\"warn\",\"message\":\"b\"}\n",
+		"error {\"level\":\"error\",\"message\":\"c\"}\n",
+	}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("shipped %q, want %q", got, want)
+	}
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	if len(segments) != 1 {
+		t.Errorf("acknowledged segments not removed: %v", segments)
+	}
+}
+
+func TestWriterResumesAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	r := &recorder{}
+	var failures int32
+	w, err := New(dir, r, Options{
+		RetryBackoff: time.Millisecond,
+		Sink: zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+			if err.Kind == zerolog.WriteFailure {
+				atomic.AddInt32(&failures, 1)
+			}
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("one\n"))
+	drain(t, w)
+
+	r.mu.Lock()
+	r.fail = true
+	r.mu.Unlock()
+	w.Write([]byte("two\n"))
+	w.Write([]byte("three\n"))
+	for deadline := time.Now().Add(5 * time.Second); atomic.LoadInt32(&failures) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("shipping failure not reported to the sink")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, cursorFile)); err != nil {
+		t.Fatalf("cursor not persisted: %v", err)
+	}
+
+	r2 := &recorder{}
+	w, err = New(dir, r2, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	drain(t, w)
+	want := []string{" two\n", " three\n"}
+	if got := r2.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("resent %q, want %q", got, want)
+	}
+}
+
+func TestWriterDropsTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	defer func(h func(error)) { zerolog.ErrorHandler = h }(zerolog.ErrorHandler)
+	zerolog.ErrorHandler = func(error) {}
+	w, err := New(dir, &recorder{fail: true}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("one\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash in the middle of a write.
+	segments, _ := filepath.Glob(filepath.Join(dir, "*"+segmentExt))
+	f, err := os.OpenFile(segments[len(segments)-1], os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte{0, 0, 0, 100, 1, 't', 'o'})
+	f.Close()
+
+	r := &recorder{}
+	w, err = New(dir, r, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.Write([]byte("two\n"))
+	drain(t, w)
+	want := []string{" one\n", " two\n"}
+	if got := r.get(); !reflect.DeepEqual(got, want) {
+		t.Errorf("shipped %q, want %q", got, want)
+	}
+}
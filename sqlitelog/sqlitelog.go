@@ -0,0 +1,348 @@
+// Package sqlitelog provides a zerolog writer storing events in a local
+// SQLite database, giving small self-hosted applications searchable logs
+// without external infrastructure.
+//
+// The package does not depend on a specific SQLite driver: open the database
+// with the driver of your choice and pass the *sql.DB to NewWriter.
+//
+//	db, _ := sql.Open("sqlite3", "app-logs.db")
+//	w, err := sqlitelog.NewWriter(db, sqlitelog.Options{MaxAge: 7 * 24 * time.Hour})
+//	if err != nil {
+//	    return err
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// Events are stored as JSON along with their time, level and message, and can
+// be searched using Writer.Query.
+package sqlitelog
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/treavorj/zerolog"
+	"github.com/treavorj/zerolog/internal/cbor"
+)
+
+// Options configures a Writer.
+type Options struct {
+	// Table is the name of the table storing the events. Defaults to "logs".
+	Table string
+
+	// BatchSize is the number of events inserted per transaction. Defaults to
+	// 100.
+	BatchSize int
+
+	// FlushInterval is the maximum time an event is buffered before being
+	// inserted. Retention policies are also applied at this interval. Defaults
+	// to 1s.
+	FlushInterval time.Duration
+
+	// MaxAge, if not zero, deletes the events older than MaxAge. The events
+	// having a retention hint, added by zerolog.Event.Retention, are instead
+	// deleted once their retention has elapsed.
+	MaxAge time.Duration
+
+	// MaxRows, if not zero, deletes the oldest events to keep at most MaxRows
+	// events in the table, regardless of their retention hints.
+	MaxRows int64
+
+	// Sink, if not nil, receives a WriteFailure error for each batch that
+	// cannot be inserted and each failed application of the retention
+	// policies. They are otherwise reported to ErrorHandler.
+	Sink zerolog.ErrorSink
+}
+
+type record struct {
+	time    int64
+	level   zerolog.Level
+	message string
+	event   string
+	expires interface{} // nil or int64
+}
+
+// Writer is a zerolog.LevelWriter inserting events in a SQLite database in
+// batched transactions.
+type Writer struct {
+	db   *sql.DB
+	opts Options
+
+	mu      sync.Mutex
+	pending []record
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWriter creates the events table in db if needed, enables the WAL journal
+// mode and returns a Writer inserting events in it. A background go-routine
+// flushes buffered events and applies retention policies until Close is
+// called.
+func NewWriter(db *sql.DB, opts Options) (*Writer, error) {
+	if opts.Table == "" {
+		opts.Table = "logs"
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	stmts := []string{
+		"PRAGMA journal_mode=WAL",
+		"CREATE TABLE IF NOT EXISTS " + opts.Table + " (" +
+			"id INTEGER PRIMARY KEY AUTOINCREMENT, " +
+			"time INTEGER NOT NULL, " +
+			"level INTEGER NOT NULL, " +
+			"message TEXT NOT NULL, " +
+			"event TEXT NOT NULL, " +
+			"expires INTEGER)",
+		"CREATE INDEX IF NOT EXISTS " + opts.Table + "_time ON " + opts.Table + " (time)",
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, fmt.Errorf("sqlitelog: %v", err)
+		}
+	}
+	w := &Writer{
+		db:   db,
+		opts: opts,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+// Write implements io.Writer. The level of the event is read from the
+// zerolog.LevelFieldName field.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements zerolog.LevelWriter. The event is buffered and
+// inserted with the next batch.
+func (w *Writer) WriteLevel(level zerolog.Level, p []byte) (n int, err error) {
+	var evt map[string]interface{}
+	js := bytes.TrimSpace(cbor.DecodeIfBinaryToBytes(p))
+	d := json.NewDecoder(bytes.NewReader(js))
+	d.UseNumber()
+	if err := d.Decode(&evt); err != nil {
+		return 0, fmt.Errorf("sqlitelog: cannot decode event: %v", err)
+	}
+	if level == zerolog.NoLevel {
+		if s, ok := evt[zerolog.LevelFieldName].(string); ok {
+			if lvl, err := zerolog.ParseLevel(s); err == nil {
+				level = lvl
+			}
+		}
+	}
+	msg, _ := evt[zerolog.MessageFieldName].(string)
+	now := time.Now().UnixNano()
+	var expires interface{}
+	if n, ok := evt[zerolog.RetentionFieldName].(json.Number); ok {
+		if secs, err := n.Int64(); err == nil {
+			expires = now + secs*int64(time.Second)
+		}
+	}
+
+	w.mu.Lock()
+	w.pending = append(w.pending, record{
+		time:    now,
+		level:   level,
+		message: msg,
+		event:   string(js),
+		expires: expires,
+	})
+	full := len(w.pending) >= w.opts.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		if err := w.Flush(); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush inserts the buffered events in a single transaction.
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	pending := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	tx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("sqlitelog: %v", err)
+	}
+	stmt, err := tx.Prepare("INSERT INTO " + w.opts.Table + " (time, level, message, event, expires) VALUES (?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("sqlitelog: %v", err)
+	}
+	defer stmt.Close()
+	for _, r := range pending {
+		if _, err := stmt.Exec(r.time, int64(r.level), r.message, r.event, r.expires); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("sqlitelog: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("sqlitelog: %v", err)
+	}
+	return nil
+}
+
+// applyRetention deletes the events exceeding their retention hint, MaxAge
+// or MaxRows.
+func (w *Writer) applyRetention() error {
+	now := time.Now()
+	if _, err := w.db.Exec("DELETE FROM "+w.opts.Table+" WHERE expires < ?", now.UnixNano()); err != nil {
+		return fmt.Errorf("sqlitelog: %v", err)
+	}
+	if w.opts.MaxAge > 0 {
+		cutoff := now.Add(-w.opts.MaxAge).UnixNano()
+		if _, err := w.db.Exec("DELETE FROM "+w.opts.Table+" WHERE time < ? AND expires IS NULL", cutoff); err != nil {
+			return fmt.Errorf("sqlitelog: %v", err)
+		}
+	}
+	if w.opts.MaxRows > 0 {
+		if _, err := w.db.Exec("DELETE FROM "+w.opts.Table+" WHERE id <= (SELECT MAX(id) FROM "+w.opts.Table+") - ?", w.opts.MaxRows); err != nil {
+			return fmt.Errorf("sqlitelog: %v", err)
+		}
+	}
+	return nil
+}
+
+func (w *Writer) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.Flush(); err != nil {
+				zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel, err)
+			}
+			if err := w.applyRetention(); err != nil {
+				zerolog.ReportError(w.opts.Sink, zerolog.WriteFailure, zerolog.NoLevel, err)
+			}
+		}
+	}
+}
+
+// Close stops the background go-routine and flushes the buffered events. The
+// database is not closed.
+func (w *Writer) Close() error {
+	select {
+	case <-w.stop:
+		return errors.New("sqlitelog: writer already closed")
+	default:
+	}
+	close(w.stop)
+	<-w.done
+	return w.Flush()
+}
+
+// Query selects stored events. Zero fields are not used as filters.
+type Query struct {
+	// Since and Until restrict the events to the given time range.
+	Since, Until time.Time
+
+	// Levels restricts the events to the given levels.
+	Levels []zerolog.Level
+
+	// Contains restricts the events to the ones with a message containing
+	// this string.
+	Contains string
+
+	// Limit is the maximum number of events returned.
+	Limit int
+}
+
+// Record is a stored event.
+type Record struct {
+	ID      int64
+	Time    time.Time
+	Level   zerolog.Level
+	Message string
+	Event   json.RawMessage
+}
+
+func (q Query) build(table string) (string, []interface{}) {
+	var (
+		where []string
+		args  []interface{}
+	)
+	if !q.Since.IsZero() {
+		where = append(where, "time >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Until.IsZero() {
+		where = append(where, "time < ?")
+		args = append(args, q.Until.UnixNano())
+	}
+	if len(q.Levels) > 0 {
+		where = append(where, "level IN (?"+strings.Repeat(", ?", len(q.Levels)-1)+")")
+		for _, l := range q.Levels {
+			args = append(args, int64(l))
+		}
+	}
+	if q.Contains != "" {
+		where = append(where, `message LIKE ? ESCAPE '\'`)
+		r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+		args = append(args, "%"+r.Replace(q.Contains)+"%")
+	}
+	s := "SELECT id, time, level, message, event FROM " + table
+	if len(where) > 0 {
+		s += " WHERE " + strings.Join(where, " AND ")
+	}
+	s += " ORDER BY id DESC"
+	if q.Limit > 0 {
+		s += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+	return s, args
+}
+
+// Query returns the stored events matching q, most recent first. Buffered
+// events not yet flushed are not returned.
+func (w *Writer) Query(ctx context.Context, q Query) ([]Record, error) {
+	s, args := q.build(w.opts.Table)
+	rows, err := w.db.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, fmt.Errorf("sqlitelog: %v", err)
+	}
+	defer rows.Close()
+	var records []Record
+	for rows.Next() {
+		var (
+			r     Record
+			ts    int64
+			level int64
+			event string
+		)
+		if err := rows.Scan(&r.ID, &ts, &level, &r.Message, &event); err != nil {
+			return nil, fmt.Errorf("sqlitelog: %v", err)
+		}
+		r.Time = time.Unix(0, ts)
+		r.Level = zerolog.Level(level)
+		r.Event = json.RawMessage(event)
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
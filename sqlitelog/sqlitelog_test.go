@@ -0,0 +1,194 @@
+package sqlitelog
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/treavorj/zerolog"
+)
+
+// recordingDriver is a fake database/sql driver recording executed
+// statements.
+type recordingDriver struct {
+	mu    sync.Mutex
+	execs []string
+	args  [][]driver.Value
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+func (d *recordingDriver) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.execs...)
+}
+
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(q string) (driver.Stmt, error) { return &recordingStmt{c.d, q}, nil }
+func (c *recordingConn) Close() error                          { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.d.record("BEGIN", nil)
+	return c, nil
+}
+func (c *recordingConn) Commit() error {
+	c.d.record("COMMIT", nil)
+	return nil
+}
+func (c *recordingConn) Rollback() error {
+	c.d.record("ROLLBACK", nil)
+	return nil
+}
+
+func (d *recordingDriver) record(q string, args []driver.Value) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.execs = append(d.execs, q)
+	d.args = append(d.args, args)
+}
+
+type recordingStmt struct {
+	d *recordingDriver
+	q string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record(s.q, args)
+	return driver.RowsAffected(1), nil
+}
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+var testDriver = &recordingDriver{}
+
+func init() {
+	sql.Register("sqlitelog-recording", testDriver)
+	sql.Register("sqlitelog-sink", &recordingDriver{})
+}
+
+func TestWriter(t *testing.T) {
+	db, err := sql.Open("sqlitelog-recording", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	w, err := NewWriter(db, Options{BatchSize: 2, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := zerolog.New(w)
+	log.Info().Str("foo", "bar").Msg("first")
+	log.Warn().Msg("second")
+	log.Error().Retention(time.Hour).Msg("third")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	stmts := testDriver.statements()
+	if len(stmts) != 10 {
+		t.Fatalf("got %d statements, want 10: %q", len(stmts), stmts)
+	}
+	if stmts[0] != "PRAGMA journal_mode=WAL" || !strings.HasPrefix(stmts[1], "CREATE TABLE IF NOT EXISTS logs") {
+		t.Errorf("unexpected schema statements: %q", stmts[:3])
+	}
+	// First batch of 2 events, then the remaining one flushed on Close.
+	kinds := make([]string, 0, 7)
+	for _, s := range stmts[3:] {
+		kinds = append(kinds, strings.Fields(s)[0])
+	}
+	if want := []string{"BEGIN", "INSERT", "INSERT", "COMMIT", "BEGIN", "INSERT", "COMMIT"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("got statements %v, want %v", kinds, want)
+	}
+	args := testDriver.args[4]
+	if args[1] != int64(zerolog.InfoLevel) || args[2] != "first" || args[3] != `{"level":"info","foo":"bar","message":"first"}` || args[4] != nil {
+		t.Errorf("unexpected insert args: %v", args)
+	}
+	args = testDriver.args[8]
+	if expires, ok := args[4].(int64); !ok || expires-args[0].(int64) != int64(time.Hour) {
+		t.Errorf("unexpected expires for an event with a retention hint: %v", args)
+	}
+}
+
+func TestApplyRetention(t *testing.T) {
+	db, err := sql.Open("sqlitelog-recording", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &Writer{db: db, opts: Options{Table: "logs", MaxAge: time.Hour, MaxRows: 10}}
+	testDriver.mu.Lock()
+	testDriver.execs, testDriver.args = nil, nil
+	testDriver.mu.Unlock()
+	if err := w.applyRetention(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"DELETE FROM logs WHERE expires < ?",
+		"DELETE FROM logs WHERE time < ? AND expires IS NULL",
+		"DELETE FROM logs WHERE id <= (SELECT MAX(id) FROM logs) - ?",
+	}
+	if got := testDriver.statements(); !reflect.DeepEqual(got, want) {
+		t.Errorf("got statements %q, want %q", got, want)
+	}
+}
+
+func TestWriterSink(t *testing.T) {
+	db, err := sql.Open("sqlitelog-sink", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := make(chan *zerolog.InternalError, 10)
+	w, err := NewWriter(db, Options{
+		FlushInterval: time.Millisecond,
+		Sink: zerolog.ErrorSinkFunc(func(err *zerolog.InternalError) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	db.Close()
+	select {
+	case err := <-errs:
+		if err.Kind != zerolog.WriteFailure {
+			t.Errorf("kind = %v, want %v", err.Kind, zerolog.WriteFailure)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("retention failure not reported to the sink")
+	}
+}
+
+func TestQueryBuild(t *testing.T) {
+	since := time.Unix(10, 0)
+	q := Query{
+		Since:    since,
+		Levels:   []zerolog.Level{zerolog.WarnLevel, zerolog.ErrorLevel},
+		Contains: "50%",
+		Limit:    10,
+	}
+	s, args := q.build("logs")
+	want := `SELECT id, time, level, message, event FROM logs WHERE time >= ? AND level IN (?, ?) AND message LIKE ? ESCAPE '\' ORDER BY id DESC LIMIT ?`
+	if s != want {
+		t.Errorf("got query %s, want %s", s, want)
+	}
+	wantArgs := []interface{}{since.UnixNano(), int64(zerolog.WarnLevel), int64(zerolog.ErrorLevel), `%50\%%`, 10}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("got args %v, want %v", args, wantArgs)
+	}
+
+	if s, args := (Query{}).build("t"); s != "SELECT id, time, level, message, event FROM t ORDER BY id DESC" || len(args) != 0 {
+		t.Errorf("unexpected empty query: %s %v", s, args)
+	}
+}
@@ -0,0 +1,52 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// NonFiniteMode selects how a strict logger encodes NaN and infinite floats.
+type NonFiniteMode uint8
+
+const (
+	// NonFiniteString encodes NaN and infinities as the "NaN", "+Inf" and
+	// "-Inf" strings. This is also the behavior of non-strict loggers.
+	NonFiniteString NonFiniteMode = iota
+	// NonFiniteNull encodes NaN and infinities as null.
+	NonFiniteNull
+)
+
+var errInvalidEvent = errors.New("invalid JSON event dropped")
+
+// Strict returns a logger guaranteeing its output is valid JSON: events are
+// validated before being written and the invalid ones, like events with a
+// malformed RawJSON field, are dropped and reported as Drop errors to the
+// logger's ErrorSink, or to ErrorHandler. Float
+// fields with NaN or infinite values are encoded according to nonFinite.
+//
+// Validation has a cost proportional to the size of the events. Only the
+// JSON encoding is validated.
+func (l Logger) Strict(nonFinite NonFiniteMode) Logger {
+	l.strict = true
+	if nonFinite == NonFiniteNull {
+		ff := FloatFormat{useGlobal: true}
+		if l.floatFmt != nil {
+			ff = *l.floatFmt
+		}
+		ff.nullNonFinite = true
+		l.floatFmt = &ff
+	} else if l.floatFmt != nil && l.floatFmt.nullNonFinite {
+		ff := *l.floatFmt
+		ff.nullNonFinite = false
+		l.floatFmt = &ff
+	}
+	return l
+}
+
+// validEvent returns false if p is a JSON event which is not valid.
+func validEvent(p []byte) bool {
+	if len(p) == 0 || p[0] != '{' {
+		return true
+	}
+	return json.Valid(p)
+}
@@ -0,0 +1,43 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestStrict(t *testing.T) {
+	var errs []error
+	defer func(h func(error)) { ErrorHandler = h }(ErrorHandler)
+	ErrorHandler = func(err error) { errs = append(errs, err) }
+
+	out := &bytes.Buffer{}
+	nan, inf := math.NaN(), math.Inf(1)
+
+	log := New(out).Strict(NonFiniteString)
+	log.Log().Float64("f", nan).Floats32("a", []float32{1, float32(inf)}).Msg("")
+	log.Log().RawJSON("raw", []byte(`{"a":`)).Msg("dropped")
+	if got, want := out.String(), `{"f":"NaN","a":[1,"+Inf"]}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if len(errs) != 1 || errs[0] != errInvalidEvent {
+		t.Errorf("errors = %v, want [%v]", errs, errInvalidEvent)
+	}
+
+	out.Reset()
+	log = New(out).Strict(NonFiniteNull)
+	log.Log().Float64("f", nan).Float32("g", float32(-inf)).Floats64("a", []float64{1.5, inf}).Float64("h", 0.1).Msg("")
+	precise := log.FloatFormat(FloatFormat{Precision: 1})
+	precise.Log().Float64("f", inf).Float64("h", 0.25).Msg("")
+	lax := log.Strict(NonFiniteString)
+	lax.Log().Float64("f", nan).Msg("")
+	want := `{"f":null,"g":null,"a":[1.5,null],"h":0.1}` + "\n" +
+		`{"f":null,"h":0.2}` + "\n" +
+		`{"f":"NaN"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
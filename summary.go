@@ -0,0 +1,247 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SummaryRule selects events to replace by periodic summaries in a
+// SummaryWriter.
+type SummaryRule struct {
+	// Name identifies the summaries of the rule, written as their summary
+	// field.
+	Name string
+
+	// Levels restricts the rule to events of these levels. All levels match
+	// if empty.
+	Levels []Level
+
+	// Fields restricts the rule to events with these field values, compared
+	// as strings, like {"path": "/healthz"}.
+	Fields map[string]string
+
+	// Match, if not nil, restricts the rule to the events for which it
+	// returns true.
+	Match func(level Level, fields map[string]interface{}) bool
+
+	// Field, if not empty, is the numeric field which minimum, maximum and
+	// average are added to the summaries, as Field+"_min", Field+"_max" and
+	// Field+"_avg".
+	Field string
+
+	// GroupBy are the fields which values split the summaries: a summary is
+	// written for each combination of values, which are copied into it.
+	GroupBy []string
+}
+
+// summary aggregates the events matched by a rule in the current window.
+type summary struct {
+	rule   *SummaryRule
+	groups []string
+	level  Level
+	msg    string
+	count  int
+	n      int // number of values of the numeric field
+	min    float64
+	max    float64
+	sum    float64
+}
+
+// SummaryWriter is a LevelWriter replacing the events matched by its rules
+// with summaries written every interval, turning the per-request noise of
+// high traffic endpoints into aggregates:
+//
+//	w := zerolog.NewSummaryWriter(os.Stdout, 10*time.Second, zerolog.SummaryRule{
+//	    Name:    "requests",
+//	    Fields:  map[string]string{"message": "request handled"},
+//	    Field:   "latency",
+//	    GroupBy: []string{"path"},
+//	})
+//	defer w.Close()
+//	log := zerolog.New(w)
+//	// {"level":"info","summary":"requests","path":"/api","count":1200,"latency_min":1,"latency_max":87,"latency_avg":4.2,"window":10000,"time":"...","message":"request handled"}
+//
+// A summary holds the number of matched events, the statistics of the rule
+// field, the values of the GroupBy fields and the window duration. Its level
+// is the highest and its message the first of the summarized events. Events
+// matched by no rule are written as is.
+type SummaryWriter struct {
+	w        LevelWriter
+	l        Logger
+	interval time.Duration
+	rules    []SummaryRule
+	matchers []MetricRule // rules sharing the matching logic of MetricRule
+
+	mu        sync.Mutex
+	summaries map[string]*summary
+	start     time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewSummaryWriter returns a SummaryWriter writing to w, summarizing the
+// events matched by rules every interval until Close is called.
+func NewSummaryWriter(w io.Writer, interval time.Duration, rules ...SummaryRule) *SummaryWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	matchers := make([]MetricRule, len(rules))
+	for i, r := range rules {
+		matchers[i] = MetricRule{Levels: r.Levels, Fields: r.Fields, Match: r.Match}
+	}
+	sw := &SummaryWriter{
+		w:         lw,
+		l:         New(lw).With().Timestamp().Logger(),
+		interval:  interval,
+		rules:     rules,
+		matchers:  matchers,
+		summaries: map[string]*summary{},
+		start:     TimestampFunc(),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go sw.run()
+	return sw
+}
+
+// Write implements the io.Writer interface. The level is read from the
+// event.
+func (w *SummaryWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *SummaryWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	var fields map[string]interface{}
+	for i := range w.rules {
+		m := &w.matchers[i]
+		if !m.matchLevel(level) && level != NoLevel {
+			continue
+		}
+		if fields == nil {
+			if fields, err = DecodeEvent(p); err != nil {
+				break
+			}
+			if level == NoLevel {
+				s, _ := fields[LevelFieldName].(string)
+				level, _ = ParseLevel(s)
+			}
+		}
+		if m.matchLevel(level) && m.matchFields(level, fields) {
+			w.add(&w.rules[i], level, fields)
+			return len(p), nil
+		}
+	}
+	return w.w.WriteLevel(level, p)
+}
+
+// add aggregates the event fields into the summary of r.
+func (w *SummaryWriter) add(r *SummaryRule, level Level, fields map[string]interface{}) {
+	groups := make([]string, len(r.GroupBy))
+	for i, key := range r.GroupBy {
+		if v, ok := fields[key]; ok {
+			groups[i] = fmt.Sprint(v)
+		}
+	}
+	key := r.Name + "\x00" + strings.Join(groups, "\x00")
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	s := w.summaries[key]
+	if s == nil {
+		msg, _ := fields[MessageFieldName].(string)
+		s = &summary{rule: r, groups: groups, level: level, msg: msg}
+		w.summaries[key] = s
+	}
+	s.count++
+	if level != NoLevel && (s.level == NoLevel || !s.level.AtLeast(level)) {
+		s.level = level
+	}
+	if r.Field == "" {
+		return
+	}
+	if n, ok := fields[r.Field].(json.Number); ok {
+		if v, err := n.Float64(); err == nil {
+			if s.n == 0 || v < s.min {
+				s.min = v
+			}
+			if s.n == 0 || v > s.max {
+				s.max = v
+			}
+			s.n++
+			s.sum += v
+		}
+	}
+}
+
+// Flush writes the summaries of the current window, starting a new one, and
+// flushes the wrapped writer.
+func (w *SummaryWriter) Flush() error {
+	w.emit()
+	return flush(w.w)
+}
+
+// Close stops the background go-routine, writes the summaries of the current
+// window and closes the wrapped writer if it implements io.Closer.
+func (w *SummaryWriter) Close() error {
+	close(w.stop)
+	<-w.done
+	w.emit()
+	if closer, ok := w.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (w *SummaryWriter) run() {
+	defer close(w.done)
+	if w.interval <= 0 {
+		<-w.stop
+		return
+	}
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.emit()
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// emit writes the summaries of the current window and starts a new one.
+func (w *SummaryWriter) emit() {
+	w.mu.Lock()
+	summaries := w.summaries
+	window := TimestampFunc().Sub(w.start)
+	w.summaries = map[string]*summary{}
+	w.start = TimestampFunc()
+	w.mu.Unlock()
+
+	keys := make([]string, 0, len(summaries))
+	for key := range summaries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		s := summaries[key]
+		e := w.l.WithLevel(s.level).Str("summary", s.rule.Name)
+		for i, g := range s.rule.GroupBy {
+			e.Str(g, s.groups[i])
+		}
+		e.Int("count", s.count)
+		if s.n > 0 {
+			f := s.rule.Field
+			e.Float64(f+"_min", s.min).Float64(f+"_max", s.max).Float64(f+"_avg", s.sum/float64(s.n))
+		}
+		e.Dur("window", window).Msg(s.msg)
+	}
+}
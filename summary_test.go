@@ -0,0 +1,57 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestSummaryWriter(t *testing.T) {
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	TimestampFunc = func() time.Time { return now }
+
+	out := &bytes.Buffer{}
+	w := NewSummaryWriter(out, 0, SummaryRule{
+		Name:    "requests",
+		Fields:  map[string]string{"message": "request handled"},
+		Field:   "latency",
+		GroupBy: []string{"path"},
+	}, SummaryRule{
+		Name:   "retries",
+		Levels: []Level{WarnLevel},
+	})
+	log := New(w)
+	log.Info().Str("path", "/a").Int("latency", 3).Msg("request handled")
+	log.Info().Str("path", "/b").Int("latency", 10).Msg("request handled")
+	log.Error().Str("path", "/a").Int("latency", 9).Msg("request handled")
+	log.Info().Str("path", "/a").Msg("request handled")
+	log.Warn().Msg("retrying")
+	log.Info().Msg("other")
+	now = now.Add(10 * time.Second)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	w.Flush() // empty window
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","message":"other"}` + "\n" +
+		`{"level":"error","summary":"requests","path":"/a","count":3,"latency_min":3,"latency_max":9,"latency_avg":6,"window":10000,"time":"2024-01-02T03:04:15Z","message":"request handled"}` + "\n" +
+		`{"level":"info","summary":"requests","path":"/b","count":1,"latency_min":10,"latency_max":10,"latency_avg":10,"window":10000,"time":"2024-01-02T03:04:15Z","message":"request handled"}` + "\n" +
+		`{"level":"warn","summary":"retries","count":1,"window":10000,"time":"2024-01-02T03:04:15Z","message":"retrying"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	w.Close()
+}
+
+func TestSummaryWriterInterval(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewSummaryWriter(SyncWriter(out), 10*time.Millisecond, SummaryRule{Name: "all"})
+	log := New(w)
+	log.Info().Msg("tick")
+	time.Sleep(50 * time.Millisecond)
+	w.Close()
+	if !bytes.Contains(out.Bytes(), []byte("all")) {
+		t.Errorf("no summary written: %q", out.Bytes())
+	}
+}
@@ -0,0 +1,317 @@
+package zerolog
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Syslog5424Formatter formats events as RFC 5424 syslog messages. The level
+// sets the severity, the message field becomes the MSG part and the other
+// fields are mapped to the parameters of a single STRUCTURED-DATA element:
+//
+//	<11>1 2024-01-02T03:04:05.000000Z host api 42 - [zerolog@32473 user="bob" attempt="3"] login failed
+//
+// Nested objects and arrays are written as JSON parameter values.
+type Syslog5424Formatter struct {
+	// Facility is the syslog facility code, from 0 (kern) to 23 (local7).
+	// Defaults to 1 (user).
+	Facility int
+
+	// Hostname, AppName and ProcID fill the header. They default to the
+	// host name, the program name and the process id.
+	Hostname string
+	AppName  string
+	ProcID   string
+
+	// MsgIDField, if not empty, is the field used as the MSGID of the
+	// header instead of a parameter.
+	MsgIDField string
+
+	// SDID is the id of the structured data element. Defaults to
+	// "zerolog@32473", 32473 being the example enterprise number of RFC 5424.
+	SDID string
+}
+
+// syslogSeverity returns the RFC 5424 severity of level, following
+// SyslogLevelWriter.
+func syslogSeverity(level Level) int {
+	switch level {
+	case TraceLevel, DebugLevel:
+		return 7
+	case WarnLevel:
+		return 4
+	case ErrorLevel:
+		return 3
+	case FatalLevel:
+		return 0
+	case PanicLevel:
+		return 2
+	}
+	if level < TraceLevel {
+		return 7
+	}
+	return 6
+}
+
+// Format appends the RFC 5424 message of the event p of the given level to
+// dst. If level is NoLevel, it is read from the event.
+func (f Syslog5424Formatter) Format(dst []byte, level Level, p []byte) ([]byte, error) {
+	d := json.NewDecoder(bytes.NewReader(decodeIfBinaryToBytes(p)))
+	d.UseNumber()
+	if tok, err := d.Token(); err != nil || tok != json.Delim('{') {
+		return dst, fmt.Errorf("cannot decode event: %v", err)
+	}
+	ts := time.Now()
+	var msg, msgID string
+	var sd []byte
+	for d.More() {
+		tok, err := d.Token()
+		if err != nil {
+			return dst, fmt.Errorf("cannot decode event: %s", err)
+		}
+		key := tok.(string)
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			return dst, fmt.Errorf("cannot decode event: %s", err)
+		}
+		value := string(raw)
+		if raw[0] == '"' {
+			json.Unmarshal(raw, &value)
+		}
+		switch key {
+		case MessageFieldName:
+			msg = value
+			continue
+		case LevelFieldName:
+			if level == NoLevel {
+				if l, err := ParseLevel(value); err == nil {
+					level = l
+				}
+			}
+			continue
+		case TimestampFieldName:
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				ts = t
+				continue
+			}
+		}
+		if key == f.MsgIDField {
+			msgID = value
+			continue
+		}
+		sd = append(sd, ' ')
+		sd = appendSDName(sd, key)
+		sd = append(sd, '=', '"')
+		sd = appendSDValue(sd, value)
+		sd = append(sd, '"')
+	}
+
+	facility := f.Facility
+	if facility <= 0 || facility > 23 {
+		facility = 1
+	}
+	dst = append(dst, '<')
+	dst = strconv.AppendInt(dst, int64(facility*8+syslogSeverity(level)), 10)
+	dst = append(dst, ">1 "...)
+	dst = ts.AppendFormat(dst, "2006-01-02T15:04:05.000000Z07:00")
+	hostname, appName, procID := f.Hostname, f.AppName, f.ProcID
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+	if procID == "" {
+		procID = strconv.Itoa(os.Getpid())
+	}
+	for _, h := range []struct {
+		s   string
+		max int
+	}{{hostname, 255}, {appName, 48}, {procID, 128}, {msgID, 32}} {
+		dst = append(dst, ' ')
+		dst = appendHeaderField(dst, h.s, h.max)
+	}
+	if len(sd) == 0 {
+		dst = append(dst, " -"...)
+	} else {
+		sdID := f.SDID
+		if sdID == "" {
+			sdID = "zerolog@32473"
+		}
+		dst = append(dst, " ["...)
+		dst = appendSDName(dst, sdID)
+		dst = append(dst, sd...)
+		dst = append(dst, ']')
+	}
+	if msg != "" {
+		dst = append(dst, ' ')
+		dst = append(dst, msg...)
+	}
+	return dst, nil
+}
+
+// appendHeaderField appends s, restricted to printable US-ASCII and max
+// characters, or the NILVALUE if empty.
+func appendHeaderField(dst []byte, s string, max int) []byte {
+	n := 0
+	for i := 0; i < len(s) && n < max; i++ {
+		if c := s[i]; c > 32 && c < 127 {
+			dst = append(dst, c)
+			n++
+		}
+	}
+	if n == 0 {
+		dst = append(dst, '-')
+	}
+	return dst
+}
+
+// appendSDName appends s as an SD-NAME, replacing the forbidden characters
+// by underscores and truncating it to 32 characters.
+func appendSDName(dst []byte, s string) []byte {
+	if s == "" {
+		return append(dst, '_')
+	}
+	for i := 0; i < len(s) && i < 32; i++ {
+		c := s[i]
+		if c <= 32 || c >= 127 || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendSDValue appends s as a PARAM-VALUE, escaping '"', '\' and ']'.
+func appendSDValue(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\', ']':
+			dst = append(dst, '\\', c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// Syslog5424Writer is a LevelWriter sending events as RFC 5424 messages to a
+// local or remote syslog daemon. See NewSyslog5424Writer.
+type Syslog5424Writer struct {
+	Syslog5424Formatter
+
+	network   string
+	address   string
+	tlsConfig *tls.Config
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslog5424Writer returns a writer sending the events formatted by f to
+// the syslog daemon at address. Network is "udp", "tcp", "unix" or
+// "unixgram". If tlsConfig is not nil, tcp connections use TLS, as per
+// RFC 5425. If network is empty, the local daemon is used through its
+// /dev/log, /var/run/syslog or /var/run/log socket.
+//
+// Tcp connections use the octet counting framing of RFC 6587. The
+// connection is reestablished once on write errors.
+func NewSyslog5424Writer(network, address string, tlsConfig *tls.Config, f Syslog5424Formatter) (*Syslog5424Writer, error) {
+	w := &Syslog5424Writer{
+		Syslog5424Formatter: f,
+		network:             network,
+		address:             address,
+		tlsConfig:           tlsConfig,
+	}
+	if err := w.connect(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// connect opens the connection. w.mu must be held, or w not shared yet.
+func (w *Syslog5424Writer) connect() (err error) {
+	if w.conn != nil {
+		w.conn.Close()
+		w.conn = nil
+	}
+	if w.network == "" {
+		for _, network := range []string{"unixgram", "unix"} {
+			for _, path := range []string{"/dev/log", "/var/run/syslog", "/var/run/log"} {
+				if w.conn, err = net.Dial(network, path); err == nil {
+					return nil
+				}
+			}
+		}
+		return errors.New("syslog: cannot connect to the local syslog daemon")
+	}
+	if w.tlsConfig != nil && w.network == "tcp" {
+		w.conn, err = tls.Dial(w.network, w.address, w.tlsConfig)
+	} else {
+		w.conn, err = net.Dial(w.network, w.address)
+	}
+	return err
+}
+
+// frame returns msg framed for the connection: datagrams are sent as is,
+// tcp messages use octet counting and unix stream messages are terminated by
+// a line break.
+func (w *Syslog5424Writer) frame(msg []byte) []byte {
+	switch w.conn.RemoteAddr().Network() {
+	case "udp", "unixgram":
+		return msg
+	case "unix":
+		return append(msg, '\n')
+	}
+	out := append(strconv.AppendInt(make([]byte, 0, len(msg)+8), int64(len(msg)), 10), ' ')
+	return append(out, msg...)
+}
+
+// Write implements io.Writer. The level is read from the event.
+func (w *Syslog5424Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements LevelWriter.
+func (w *Syslog5424Writer) WriteLevel(level Level, p []byte) (n int, err error) {
+	msg, err := w.Format(nil, level, p)
+	if err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if w.conn == nil {
+			if err = w.connect(); err != nil {
+				continue
+			}
+		}
+		if _, err = w.conn.Write(w.frame(msg)); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	return 0, err
+}
+
+// Close closes the connection.
+func (w *Syslog5424Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
@@ -0,0 +1,89 @@
+package zerolog
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestSyslog5424Formatter(t *testing.T) {
+	f := Syslog5424Formatter{Facility: 16, Hostname: "host", AppName: "api", ProcID: "42", MsgIDField: "op"}
+	var got []string
+	log := New(LevelWriterFunc(func(level Level, p []byte) (int, error) {
+		b, err := f.Format(nil, level, p)
+		got = append(got, string(b))
+		return len(p), err
+	}))
+	log.Error().Str("time", "2024-01-02T03:04:05Z").Str("op", "login").Str("user", `b"o]b\`).
+		Int("attempt", 3).Interface("tags", []string{"a"}).Str("bad key=", "x").Msg("login failed")
+	log.Log().Str("time", "2024-01-02T03:04:05Z").Send()
+
+	want := []string{
+		`<131>1 2024-01-02T03:04:05.000000Z host api 42 login [zerolog@32473 user="b\"o\]b\\" attempt="3" tags="[\"a\"\]" bad_key_="x"] login failed`,
+		`<134>1 2024-01-02T03:04:05.000000Z host api 42 - -`,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("invalid message:\ngot:  %s\nwant: %s", got[i], want[i])
+		}
+	}
+}
+
+func TestSyslog5424Writer(t *testing.T) {
+	f := Syslog5424Formatter{Hostname: "host", AppName: "api", ProcID: "42"}
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	w, err := NewSyslog5424Writer("udp", pc.LocalAddr().String(), nil, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := New(w)
+	log.Warn().Msg("over udp")
+	w.Close()
+	buf := make([]byte, 1024)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(buf[:n]); !strings.HasPrefix(got, "<12>1 ") || !strings.HasSuffix(got, " host api 42 - - over udp") {
+		t.Errorf("invalid udp message: %q", got)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		size, _ := r.ReadString(' ')
+		rest := make([]byte, 1024)
+		n, _ := r.Read(rest)
+		received <- size + string(rest[:n])
+	}()
+	w, err = NewSyslog5424Writer("tcp", ln.Addr().String(), nil, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log = New(w)
+	log.Info().Msg("over tcp")
+	got := <-received
+	if i := strings.IndexByte(got, ' '); i < 0 || got[:i] != "58" || !strings.HasSuffix(got, " host api 42 - - over tcp") {
+		t.Errorf("invalid tcp message: %q", got)
+	}
+}
@@ -0,0 +1,199 @@
+package zerolog
+
+import (
+	"bytes"
+	"container/list"
+	"io"
+	"sync"
+)
+
+// TailSamplingWriter buffers the log lines at the ConditionalLevel or below
+// per correlation id, and writes them out ahead of the first trigger level
+// (or higher) line sharing the same id. Errors thus always arrive with their
+// preceding detailed context, even though low levels are normally
+// suppressed.
+//
+// The correlation id is read from the Key top level field of the lines.
+// Conditional lines without id, and conditional lines of ids never
+// triggered, are never written out. Lines with a level higher than
+// ConditionalLevel are always written out.
+type TailSamplingWriter struct {
+	// Destination writer. If LevelWriter is provided (usually), its WriteLevel
+	// is used instead of Write.
+	io.Writer
+
+	// Key is the name of the field holding the correlation id, like the one
+	// set by hlog.RequestIDHandler.
+	Key string
+
+	// ConditionalLevel is the level (and below) at which lines are buffered
+	// until a trigger level (or higher) line with the same id is emitted.
+	// Usually this is set to DebugLevel.
+	ConditionalLevel Level
+
+	// TriggerLevel is the lowest level that triggers the sending of the
+	// conditional level lines of an id. Usually this is set to ErrorLevel.
+	TriggerLevel Level
+
+	// MaxIDs is the maximum number of ids buffered. The least recently used
+	// id is evicted when it is reached. Defaults to 1024.
+	MaxIDs int
+
+	// MaxLines is the maximum number of lines buffered per id. The oldest
+	// lines are dropped when it is reached. Defaults to 100.
+	MaxLines int
+
+	mu    sync.Mutex
+	lru   *list.List
+	byID  map[string]*list.Element
+	lines int
+}
+
+type tailBuffer struct {
+	id     string
+	levels []Level
+	lines  [][]byte
+}
+
+// NewTailSamplingWriter creates a TailSamplingWriter buffering the lines at
+// DebugLevel and below until an ErrorLevel or higher line with the same key
+// field value is written to w.
+func NewTailSamplingWriter(w io.Writer, key string) *TailSamplingWriter {
+	return &TailSamplingWriter{
+		Writer:           w,
+		Key:              key,
+		ConditionalLevel: DebugLevel,
+		TriggerLevel:     ErrorLevel,
+	}
+}
+
+// Write implements io.Writer. Lines written without level are passed
+// through.
+func (w *TailSamplingWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements LevelWriter.
+func (w *TailSamplingWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	if l == NoLevel || !w.ConditionalLevel.AtLeast(l) && !l.AtLeast(w.TriggerLevel) {
+		return w.write(l, p)
+	}
+	id, ok := topLevelValue(decodeIfBinaryToBytes(p), w.Key)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.byID == nil {
+		w.byID = map[string]*list.Element{}
+		w.lru = list.New()
+	}
+
+	if w.ConditionalLevel.AtLeast(l) {
+		if ok {
+			w.buffer(id, l, p)
+		}
+		return len(p), nil
+	}
+
+	if elem, found := w.byID[id]; ok && found {
+		b := w.remove(elem)
+		for i, line := range b.lines {
+			if _, err := w.write(b.levels[i], line); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return w.write(l, p)
+}
+
+// buffer adds a copy of the line p to the buffer of id. w.mu must be held.
+func (w *TailSamplingWriter) buffer(id string, l Level, p []byte) {
+	elem, found := w.byID[id]
+	if found {
+		w.lru.MoveToFront(elem)
+	} else {
+		maxIDs := w.MaxIDs
+		if maxIDs <= 0 {
+			maxIDs = 1024
+		}
+		for w.lru.Len() >= maxIDs {
+			w.remove(w.lru.Back())
+		}
+		elem = w.lru.PushFront(&tailBuffer{id: id})
+		w.byID[id] = elem
+	}
+	b := elem.Value.(*tailBuffer)
+	maxLines := w.MaxLines
+	if maxLines <= 0 {
+		maxLines = 100
+	}
+	if len(b.lines) >= maxLines {
+		copy(b.levels, b.levels[1:])
+		copy(b.lines, b.lines[1:])
+		b.levels = b.levels[:len(b.levels)-1]
+		b.lines = b.lines[:len(b.lines)-1]
+		w.lines--
+	}
+	b.levels = append(b.levels, l)
+	b.lines = append(b.lines, append([]byte(nil), p...))
+	w.lines++
+}
+
+// remove drops the buffer of elem and returns it. w.mu must be held.
+func (w *TailSamplingWriter) remove(elem *list.Element) *tailBuffer {
+	b := w.lru.Remove(elem).(*tailBuffer)
+	delete(w.byID, b.id)
+	w.lines -= len(b.lines)
+	return b
+}
+
+func (w *TailSamplingWriter) write(l Level, p []byte) (n int, err error) {
+	if lw, ok := w.Writer.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.Writer.Write(p)
+}
+
+// Buffered returns the number of ids and of lines currently buffered.
+func (w *TailSamplingWriter) Buffered() (ids, lines int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.lru == nil {
+		return 0, 0
+	}
+	return w.lru.Len(), w.lines
+}
+
+// Close drops the buffered lines and closes the destination writer if it is
+// an io.Closer.
+func (w *TailSamplingWriter) Close() error {
+	w.mu.Lock()
+	w.lru = nil
+	w.byID = nil
+	w.lines = 0
+	w.mu.Unlock()
+	if closer, ok := w.Writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// topLevelValue returns the raw JSON value of the top level field key of the
+// JSON object p.
+func topLevelValue(p []byte, key string) (string, bool) {
+	p = bytes.TrimSpace(p)
+	if len(p) == 0 || p[0] != '{' {
+		return "", false
+	}
+	for i := 1; i < len(p) && p[i] == '"'; {
+		end := skipString(p, i)
+		next := skipValue(p, end+1)
+		if end-i-2 == len(key) && string(p[i+1:end-1]) == key {
+			return string(p[end+1 : next]), true
+		}
+		i = next
+		if i < len(p) && p[i] == ',' {
+			i++
+		}
+	}
+	return "", false
+}
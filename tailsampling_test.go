@@ -0,0 +1,68 @@
+package zerolog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTailSamplingWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewTailSamplingWriter(out, "req_id")
+	w.MaxLines = 2
+	log := New(w)
+
+	a := log.With().Str("req_id", "a").Logger()
+	b := log.With().Str("req_id", "b").Logger()
+	a.Debug().Msg("a1")
+	b.Debug().Msg("b1")
+	a.Trace().Msg("a2")
+	a.Debug().Msg("a3")
+	log.Debug().Msg("no id")
+	b.Info().Msg("b2")
+	if ids, lines := w.Buffered(); ids != 2 || lines != 3 {
+		t.Errorf("Buffered() = %d, %d, want 2, 3", ids, lines)
+	}
+	a.Error().Msg("a4")
+	log.Error().Msg("error without id")
+
+	got := decodeIfBinaryToString(out.Bytes())
+	want := strings.Join([]string{
+		`{"level":"info","req_id":"b","message":"b2"}`,
+		`{"level":"trace","req_id":"a","message":"a2"}`,
+		`{"level":"debug","req_id":"a","message":"a3"}`,
+		`{"level":"error","req_id":"a","message":"a4"}`,
+		`{"level":"error","message":"error without id"}`,
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+	if ids, lines := w.Buffered(); ids != 1 || lines != 1 {
+		t.Errorf("Buffered() = %d, %d, want 1, 1", ids, lines)
+	}
+}
+
+func TestTailSamplingWriterMaxIDs(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := NewTailSamplingWriter(out, "id")
+	w.MaxIDs = 2
+	log := New(w)
+	for _, id := range []string{"a", "b", "a", "c"} {
+		log.Debug().Str("id", id).Msg("")
+	}
+	for _, id := range []string{"a", "b", "c"} {
+		log.Error().Str("id", id).Msg("")
+	}
+	got := decodeIfBinaryToString(out.Bytes())
+	want := strings.Join([]string{
+		`{"level":"debug","id":"a"}`,
+		`{"level":"debug","id":"a"}`,
+		`{"level":"error","id":"a"}`,
+		`{"level":"error","id":"b"}`,
+		`{"level":"debug","id":"c"}`,
+		`{"level":"error","id":"c"}`,
+	}, "\n") + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ParseTimeValue parses v, the value of a time field decoded from an event,
+// formatted with format like TimeFieldFormat. Numbers, like json.Number when
+// decoded with json.Decoder.UseNumber, are read in the unit of the unix
+// format, seconds if format is a layout. Strings are parsed with the layout,
+// in loc if they don't hold a time zone, time.UTC if loc is nil:
+//
+//	t, err := zerolog.ParseTimeValue(evt["time"], zerolog.TimeFormatUnixMs, nil)
+//	human := zerolog.FormatTimeValue(t.In(paris), time.RFC3339) // "2024-05-01T14:03:12+02:00"
+func ParseTimeValue(v interface{}, format string, loc *time.Location) (time.Time, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	var i int64
+	switch v := v.(type) {
+	case string:
+		if isUnixTimeFormat(format) {
+			return time.Time{}, fmt.Errorf("time %q is not a number", v)
+		}
+		return time.ParseInLocation(format, v, loc)
+	case json.Number:
+		n, err := strconv.ParseInt(string(v), 10, 64)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(string(v), 64)
+			if ferr != nil {
+				return time.Time{}, err
+			}
+			n = int64(f)
+		}
+		i = n
+	case float64:
+		i = int64(v)
+	case int64:
+		i = v
+	case int:
+		i = int64(v)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported time value %v of type %T", v, v)
+	}
+	switch format {
+	case TimeFormatUnixMs:
+		return time.Unix(0, i*int64(time.Millisecond)).In(loc), nil
+	case TimeFormatUnixMicro:
+		return time.Unix(0, i*int64(time.Microsecond)).In(loc), nil
+	case TimeFormatUnixNano:
+		return time.Unix(0, i).In(loc), nil
+	}
+	return time.Unix(i, 0).In(loc), nil
+}
+
+// FormatTimeValue returns t formatted with format, like the time fields
+// formatted with TimeFieldFormat: an int64 for the unix formats, a string
+// otherwise. With ParseTimeValue, it converts the time fields of recorded
+// events between the compact unix formats and human readable layouts.
+func FormatTimeValue(t time.Time, format string) interface{} {
+	switch format {
+	case TimeFormatUnix:
+		return t.Unix()
+	case TimeFormatUnixMs:
+		return t.UnixNano() / int64(time.Millisecond)
+	case TimeFormatUnixMicro:
+		return t.UnixNano() / int64(time.Microsecond)
+	case TimeFormatUnixNano:
+		return t.UnixNano()
+	}
+	return t.Format(format)
+}
+
+func isUnixTimeFormat(format string) bool {
+	switch format {
+	case TimeFormatUnix, TimeFormatUnixMs, TimeFormatUnixMicro, TimeFormatUnixNano:
+		return true
+	}
+	return false
+}
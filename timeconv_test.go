@@ -0,0 +1,56 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTimeValue(t *testing.T) {
+	want := time.Date(2023, time.November, 14, 22, 13, 20, 123000000, time.UTC)
+	tests := []struct {
+		v      interface{}
+		format string
+		want   time.Time
+	}{
+		{json.Number("1700000000"), TimeFormatUnix, want.Truncate(time.Second)},
+		{json.Number("1700000000123"), TimeFormatUnixMs, want},
+		{float64(1700000000123000), TimeFormatUnixMicro, want},
+		{int64(1700000000123000000), TimeFormatUnixNano, want},
+		{"2023-11-14T22:13:20.123Z", time.RFC3339Nano, want},
+		{"2023-11-14 23:13:20.123", "2006-01-02 15:04:05.999", want},
+	}
+	cet := time.FixedZone("CET", 3600)
+	for _, tt := range tests {
+		got, err := ParseTimeValue(tt.v, tt.format, cet)
+		if err != nil {
+			t.Errorf("ParseTimeValue(%v, %q): %v", tt.v, tt.format, err)
+		} else if !got.Equal(tt.want) {
+			t.Errorf("ParseTimeValue(%v, %q) = %v, want %v", tt.v, tt.format, got, tt.want)
+		}
+	}
+	for _, v := range []interface{}{"1700000000", json.Number("x"), true} {
+		if _, err := ParseTimeValue(v, TimeFormatUnixMs, nil); err == nil {
+			t.Errorf("ParseTimeValue(%v) did not fail", v)
+		}
+	}
+}
+
+func TestFormatTimeValue(t *testing.T) {
+	ts := time.Date(2023, time.November, 14, 22, 13, 20, 123456789, time.UTC)
+	tests := []struct {
+		format string
+		want   interface{}
+	}{
+		{TimeFormatUnix, int64(1700000000)},
+		{TimeFormatUnixMs, int64(1700000000123)},
+		{TimeFormatUnixMicro, int64(1700000000123456)},
+		{TimeFormatUnixNano, int64(1700000000123456789)},
+		{time.RFC3339, "2023-11-14T22:13:20Z"},
+	}
+	for _, tt := range tests {
+		if got := FormatTimeValue(ts, tt.format); got != tt.want {
+			t.Errorf("FormatTimeValue(%q) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
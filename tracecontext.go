@@ -0,0 +1,54 @@
+package zerolog
+
+// TraceContext identifies the span a context.Context carries, as returned by
+// TraceContextExtractor.
+//
+// To keep zerolog free of tracing dependencies, the trace context is
+// extracted by a function set by the application. With OpenTelemetry:
+//
+//	zerolog.TraceContextExtractor = func(ctx context.Context) (zerolog.TraceContext, bool) {
+//	    sc := trace.SpanContextFromContext(ctx)
+//	    return zerolog.TraceContext{
+//	        TraceID: sc.TraceID().String(),
+//	        SpanID:  sc.SpanID().String(),
+//	        Flags:   byte(sc.TraceFlags()),
+//	    }, sc.IsValid()
+//	}
+//
+// Then events created from a logger attached to the context of a span carry
+// the TraceIDFieldName, SpanIDFieldName and TraceFlagsFieldName fields, with
+// no other change to the logging code:
+//
+//	ctx, span := tracer.Start(ctx, "handle")
+//	ctx = logger.WithContext(ctx)
+//	zerolog.Ctx(ctx).Info().Msg("hello")
+//	// Output: {"level":"info","trace_id":"4bf9...","span_id":"00f0...","trace_flags":"01","message":"hello"}
+//
+// The context of an event can be replaced, to log the current span instead of
+// the one of the logger, with Event.Ctx.
+type TraceContext struct {
+	// TraceID is the trace id, as a hex string.
+	TraceID string
+
+	// SpanID is the span id, as a hex string.
+	SpanID string
+
+	// Flags are the W3C trace flags, like the sampled flag.
+	Flags byte
+}
+
+// appendTraceContext adds the trace context of e.ctx, if any.
+func (e *Event) appendTraceContext() {
+	tc, ok := TraceContextExtractor(e.ctx)
+	if !ok {
+		return
+	}
+	if tc.TraceID != "" {
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, TraceIDFieldName), tc.TraceID)
+	}
+	if tc.SpanID != "" {
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, SpanIDFieldName), tc.SpanID)
+	}
+	const hex = "0123456789abcdef"
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, TraceFlagsFieldName), string([]byte{hex[tc.Flags>>4], hex[tc.Flags&0x0f]}))
+}
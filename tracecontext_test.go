@@ -0,0 +1,40 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+type spanKey struct{}
+
+func TestTraceContextExtractor(t *testing.T) {
+	defer func() { TraceContextExtractor = nil }()
+	TraceContextExtractor = func(ctx context.Context) (TraceContext, bool) {
+		tc, ok := ctx.Value(spanKey{}).(TraceContext)
+		return tc, ok
+	}
+	ctx := context.WithValue(context.Background(), spanKey{}, TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+		Flags:   1,
+	})
+	child := context.WithValue(ctx, spanKey{}, TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "53995c3f42cd8ad8",
+	})
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	ctx = log.WithContext(ctx)
+	Ctx(ctx).Info().Msg("attached")
+	Ctx(ctx).Info().Ctx(child).Msgf("%s", "child")
+	log.Info().Msg("none")
+	got := decodeIfBinaryToString(out.Bytes())
+	want := `{"level":"info","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","trace_flags":"01","message":"attached"}` + "\n" +
+		`{"level":"info","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"53995c3f42cd8ad8","trace_flags":"00","message":"child"}` + "\n" +
+		`{"level":"info","message":"none"}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
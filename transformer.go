@@ -0,0 +1,33 @@
+package zerolog
+
+// Transformer defines an interface to transform encoded events before they
+// are written to the logger's output. Transformers are the place to plug
+// redaction, renaming, truncation or routing annotations without wrapping the
+// writer.
+type Transformer interface {
+	// Transform receives the fully encoded event p, including the trailing
+	// line break, and returns the event to write. p may be modified in place
+	// or a new slice may be returned. If false is returned, the event is
+	// dropped and the remaining transformers are not called.
+	Transform(level Level, p []byte) ([]byte, bool)
+}
+
+// TransformerFunc is an adaptor to allow the use of an ordinary function as a
+// Transformer.
+type TransformerFunc func(level Level, p []byte) ([]byte, bool)
+
+// Transform implements the Transformer interface.
+func (f TransformerFunc) Transform(level Level, p []byte) ([]byte, bool) {
+	return f(level, p)
+}
+
+// transform runs p through the transformers chain.
+func transform(chain []Transformer, level Level, p []byte) ([]byte, bool) {
+	for _, t := range chain {
+		var ok bool
+		if p, ok = t.Transform(level, p); !ok {
+			return nil, false
+		}
+	}
+	return p, true
+}
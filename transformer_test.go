@@ -0,0 +1,37 @@
+//go:build !binary_log
+// +build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTransform(t *testing.T) {
+	upper := TransformerFunc(func(level Level, p []byte) ([]byte, bool) {
+		return bytes.ToUpper(p), true
+	})
+	dropDebug := TransformerFunc(func(level Level, p []byte) ([]byte, bool) {
+		return p, level != DebugLevel
+	})
+	redact := TransformerFunc(func(level Level, p []byte) ([]byte, bool) {
+		return bytes.Replace(p, []byte("SECRET"), []byte("***"), -1), true
+	})
+
+	out := &bytes.Buffer{}
+	log := New(out).Transform(upper, dropDebug)
+	log = log.Transform(redact)
+	log.Info().Str("foo", "secret").Msg("hello")
+	log.Debug().Msg("dropped")
+	if got, want := out.String(), `{"LEVEL":"INFO","FOO":"***","MESSAGE":"HELLO"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	log = log.Output(out)
+	log.Warn().Msg("output")
+	if got, want := out.String(), `{"LEVEL":"WARN","MESSAGE":"OUTPUT"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
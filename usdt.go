@@ -0,0 +1,12 @@
+//go:build !zerolog_usdt || !linux || !cgo || !(amd64 || arm64)
+// +build !zerolog_usdt !linux !cgo !amd64,!arm64
+
+package zerolog
+
+// usdtProbes is true when building with the zerolog_usdt tag on linux/amd64
+// or linux/arm64 with cgo: a zerolog:event USDT probe fires on each event.
+const usdtProbes = false
+
+func eventProbeEnabled() bool { return false }
+
+func fireEventProbe(level Level, msg string) {}
@@ -0,0 +1,79 @@
+//go:build zerolog_usdt && linux && cgo && (amd64 || arm64)
+// +build zerolog_usdt,linux,cgo
+// +build amd64 arm64
+
+package zerolog
+
+/*
+#include <stddef.h>
+
+// zerolog_event_semaphore is incremented by the tracers attached to the
+// zerolog:event probe, so the probe is only fired while observed.
+unsigned short zerolog_event_semaphore __attribute__((unused)) __attribute__((section(".probes")));
+
+// zerolog_event_probe is the zerolog:event USDT probe site: a nop described
+// by a stapsdt ELF note, laid out as <sys/sdt.h> does, so tracers like
+// bpftrace can find it without requiring the systemtap headers to build.
+static void zerolog_event_probe(int level, const char *msg, size_t len) {
+	__asm__ __volatile__(
+		"990: nop\n"
+		".pushsection .note.stapsdt,\"?\",\"note\"\n"
+		".balign 4\n"
+		".4byte 992f-991f,994f-993f,3\n"
+		"991: .asciz \"stapsdt\"\n"
+		"992: .balign 4\n"
+		"993: .8byte 990b\n"
+		".8byte _.stapsdt.base\n"
+		".8byte zerolog_event_semaphore\n"
+		".asciz \"zerolog\"\n"
+		".asciz \"event\"\n"
+		".asciz \"-4@%0 8@%1 8@%2\"\n"
+		"994: .balign 4\n"
+		".popsection\n"
+		".ifndef _.stapsdt.base\n"
+		".pushsection .stapsdt.base,\"aG\",\"progbits\",.stapsdt.base,comdat\n"
+		".weak _.stapsdt.base\n"
+		".hidden _.stapsdt.base\n"
+		"_.stapsdt.base: .space 1\n"
+		".size _.stapsdt.base,1\n"
+		".popsection\n"
+		".endif\n"
+		:: "r"(level), "r"(msg), "r"(len));
+}
+*/
+import "C"
+
+import "unsafe"
+
+// usdtProbes is true when building with the zerolog_usdt tag on linux/amd64
+// or linux/arm64 with cgo: a zerolog:event USDT probe fires on each event.
+const usdtProbes = true
+
+// eventProbeEnabled returns true if a tracer is attached to the
+// zerolog:event probe.
+func eventProbeEnabled() bool {
+	return C.zerolog_event_semaphore != 0
+}
+
+// fireEventProbe fires the zerolog:event probe with the level of the event
+// and its message, if a tracer is attached.
+func fireEventProbe(level Level, msg string) {
+	if eventProbeEnabled() {
+		eventProbe(level, msg)
+	}
+}
+
+// eventProbe fires the probe. It is replaced by the tests.
+var eventProbe = func(level Level, msg string) {
+	var p *C.char
+	if msg != "" {
+		p = (*C.char)(*(*unsafe.Pointer)(unsafe.Pointer(&msg)))
+	}
+	C.zerolog_event_probe(C.int(level), p, C.size_t(len(msg)))
+}
+
+// setEventSemaphore sets the semaphore of the zerolog:event probe, like an
+// attached tracer, for the tests.
+func setEventSemaphore(n uint16) {
+	C.zerolog_event_semaphore = C.ushort(n)
+}
@@ -0,0 +1,60 @@
+//go:build zerolog_usdt && linux && cgo && (amd64 || arm64)
+// +build zerolog_usdt,linux,cgo
+// +build amd64 arm64
+
+package zerolog
+
+import (
+	"bytes"
+	"debug/elf"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEventProbeNote(t *testing.T) {
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := elf.Open(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sec := f.Section(".note.stapsdt")
+	if sec == nil {
+		t.Fatal("no .note.stapsdt section")
+	}
+	b, err := sec.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(b, []byte("zerolog\x00event\x00")) {
+		t.Errorf("zerolog:event probe not found in %q", b)
+	}
+
+	// Firing the probe without tracer is a no-op.
+	log := New(io.Discard)
+	log.Info().Msg("hello")
+}
+
+func TestEventProbeFires(t *testing.T) {
+	var got []string
+	defer func(p func(Level, string)) { eventProbe = p }(eventProbe)
+	eventProbe = func(level Level, msg string) {
+		got = append(got, level.String()+" "+msg)
+	}
+	setEventSemaphore(1)
+	defer setEventSemaphore(0)
+
+	log := New(io.Discard)
+	log.Info().Msg("hello")
+	log.Warn().Msgf("hello %s", "world")
+	log.Error().Send()
+	want := []string{"info hello", "warn hello world", "error "}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("probe fired with %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@ package zerolog
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"path"
 	"runtime"
@@ -36,6 +37,47 @@ func (lw LevelWriterAdapter) Close() error {
 	return nil
 }
 
+// Flush calls the underlying writer's Flush method if any. Otherwise does
+// nothing.
+func (lw LevelWriterAdapter) Flush() error {
+	return flush(lw.Writer)
+}
+
+// WriterFunc is an adaptor to allow the use of an ordinary function as an
+// io.Writer.
+type WriterFunc func(p []byte) (n int, err error)
+
+// Write implements the io.Writer interface.
+func (f WriterFunc) Write(p []byte) (n int, err error) {
+	return f(p)
+}
+
+// LevelWriterFunc is an adaptor to allow the use of an ordinary function as a
+// LevelWriter. Write calls the function with NoLevel.
+type LevelWriterFunc func(level Level, p []byte) (n int, err error)
+
+// Write implements the io.Writer interface.
+func (f LevelWriterFunc) Write(p []byte) (n int, err error) {
+	return f(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (f LevelWriterFunc) WriteLevel(level Level, p []byte) (n int, err error) {
+	return f(level, p)
+}
+
+// flush calls the Flush method of w, if any. Both the Flush() error form,
+// like bufio.Writer, and the Flush() form, like http.Flusher, are supported.
+func flush(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
+
 type syncWriter struct {
 	mu sync.Mutex
 	lw LevelWriter
@@ -75,56 +117,141 @@ func (s *syncWriter) Close() error {
 	return nil
 }
 
+func (s *syncWriter) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return flush(s.lw)
+}
+
 type multiLevelWriter struct {
-	writers []LevelWriter
+	writers  []LevelWriter
+	failFast bool
+	sink     ErrorSink
 }
 
 func (t multiLevelWriter) Write(p []byte) (n int, err error) {
-	for _, w := range t.writers {
-		if _n, _err := w.Write(p); err == nil {
+	return t.write(NoLevel, p, func(w LevelWriter) (int, error) {
+		return w.Write(p)
+	})
+}
+
+func (t multiLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return t.write(l, p, func(w LevelWriter) (int, error) {
+		return w.WriteLevel(l, p)
+	})
+}
+
+// write calls write with each writer, isolating their failures.
+func (t multiLevelWriter) write(l Level, p []byte, write func(w LevelWriter) (int, error)) (n int, err error) {
+	var errs []error
+	for i, w := range t.writers {
+		_n, _err := write(w)
+		if _err == nil && _n != len(p) {
+			_err = io.ErrShortWrite
+		}
+		if len(errs) == 0 {
 			n = _n
-			if _err != nil {
-				err = _err
-			} else if _n != len(p) {
-				err = io.ErrShortWrite
-			}
 		}
+		if _err == nil {
+			continue
+		}
+		if t.sink != nil {
+			reportError(t.sink, nil, WriteFailure, l, fmt.Errorf("writer %d (%T): %w", i, w, _err))
+		}
+		errs = append(errs, _err)
 	}
-	return n, err
+	switch {
+	case len(errs) == 0:
+		return n, nil
+	case t.failFast:
+		return n, errs[0]
+	case t.sink != nil && len(errs) < len(t.writers):
+		// Reported to the sink, and the event was written somewhere.
+		return len(p), nil
+	case len(errs) == 1:
+		return n, errs[0]
+	}
+	return n, MultiWriteError(errs)
 }
 
-func (t multiLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+// Calls close on all the underlying writers that are io.Closers. All the
+// writers are closed and the first error is returned, unless the writer fails
+// fast: the remainder of the closers are then not closed.
+func (t multiLevelWriter) Close() (err error) {
 	for _, w := range t.writers {
-		if _n, _err := w.WriteLevel(l, p); err == nil {
-			n = _n
-			if _err != nil {
-				err = _err
-			} else if _n != len(p) {
-				err = io.ErrShortWrite
+		if closer, ok := w.(io.Closer); ok {
+			if _err := closer.Close(); _err != nil {
+				if t.failFast {
+					return _err
+				}
+				if err == nil {
+					err = _err
+				}
 			}
 		}
 	}
-	return n, err
+	return err
 }
 
-// Calls close on all the underlying writers that are io.Closers. If any of the
-// Close methods return an error, the remainder of the closers are not closed
-// and the error is returned.
-func (t multiLevelWriter) Close() error {
+// Flush calls Flush on all the underlying writers having a Flush method. All
+// the writers are flushed and the first error is returned.
+func (t multiLevelWriter) Flush() (err error) {
 	for _, w := range t.writers {
-		if closer, ok := w.(io.Closer); ok {
-			if err := closer.Close(); err != nil {
-				return err
-			}
+		if _err := flush(w); err == nil {
+			err = _err
 		}
 	}
-	return nil
+	return err
+}
+
+// MultiWriteError is returned by the writers of MultiLevelWriter when several
+// of its writers failed.
+type MultiWriteError []error
+
+// Error implements the error interface.
+func (e MultiWriteError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the failing writers, so errors.Is and
+// errors.As match any of them.
+func (e MultiWriteError) Unwrap() []error {
+	return e
+}
+
+// MultiWriterOptions configures NewMultiLevelWriter.
+type MultiWriterOptions struct {
+	// FailFast returns the error of the first failing writer as is, rather
+	// than the errors of all the failing writers, like MultiLevelWriter did
+	// before isolating them. The event is still written to all the writers,
+	// and Close stops at the first failing writer.
+	FailFast bool
+
+	// Sink, if not nil, receives the failure of each writer as WriteFailure.
+	// The write then only fails if all the writers failed.
+	Sink ErrorSink
 }
 
 // MultiLevelWriter creates a writer that duplicates its writes to all the
 // provided writers, similar to the Unix tee(1) command. If some writers
 // implement LevelWriter, their WriteLevel method will be used instead of Write.
+//
+// A failing writer doesn't prevent the event from being written to the
+// others. The errors of the failing writers are returned, combined in a
+// MultiWriteError if several failed.
 func MultiLevelWriter(writers ...io.Writer) LevelWriter {
+	return NewMultiLevelWriter(MultiWriterOptions{}, writers...)
+}
+
+// NewMultiLevelWriter is like MultiLevelWriter, with the handling of the
+// failures of the writers configured by opts:
+//
+//	w := zerolog.NewMultiLevelWriter(zerolog.MultiWriterOptions{Sink: sink}, file, conn)
+func NewMultiLevelWriter(opts MultiWriterOptions, writers ...io.Writer) LevelWriter {
 	lwriters := make([]LevelWriter, 0, len(writers))
 	for _, w := range writers {
 		if lw, ok := w.(LevelWriter); ok {
@@ -133,7 +260,7 @@ func MultiLevelWriter(writers ...io.Writer) LevelWriter {
 			lwriters = append(lwriters, LevelWriterAdapter{w})
 		}
 	}
-	return multiLevelWriter{lwriters}
+	return multiLevelWriter{writers: lwriters, failFast: opts.FailFast, sink: opts.Sink}
 }
 
 // TestingLog is the logging interface of testing.TB.
@@ -207,12 +334,43 @@ func (w *FilteredLevelWriter) Write(p []byte) (int, error) {
 // WriteLevel calls WriteLevel of the underlying Writer only if the level is equal
 // or above the Level.
 func (w *FilteredLevelWriter) WriteLevel(level Level, p []byte) (int, error) {
-	if level >= w.Level {
+	if level.AtLeast(w.Level) {
 		return w.Writer.WriteLevel(level, p)
 	}
 	return len(p), nil
 }
 
+// Close calls the underlying writer's Close method if it is an io.Closer.
+// Otherwise does nothing.
+func (w *FilteredLevelWriter) Close() error {
+	if closer, ok := w.Writer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Flush calls the underlying writer's Flush method if any. Otherwise does
+// nothing.
+func (w *FilteredLevelWriter) Flush() error {
+	return flush(w.Writer)
+}
+
+// LevelThreshold returns a FilteredLevelWriter writing only logs at level or
+// above to w, like the network sink of a MultiLevelWriter also writing all
+// the logs to a file:
+//
+//	log := zerolog.New(zerolog.MultiLevelWriter(
+//	    file,
+//	    zerolog.LevelThreshold(conn, zerolog.WarnLevel),
+//	)).Level(zerolog.DebugLevel)
+func LevelThreshold(w io.Writer, level Level) *FilteredLevelWriter {
+	lw, ok := w.(LevelWriter)
+	if !ok {
+		lw = LevelWriterAdapter{w}
+	}
+	return &FilteredLevelWriter{Writer: lw, Level: level}
+}
+
 var triggerWriterPool = &sync.Pool{
 	New: func() interface{} {
 		return bytes.NewBuffer(make([]byte, 0, 1024))
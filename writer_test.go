@@ -114,6 +114,50 @@ func TestResilientMultiWriter(t *testing.T) {
 	}
 }
 
+func TestMultiLevelWriterErrors(t *testing.T) {
+	errPipe := errors.New("broken pipe")
+	errDisk := errors.New("disk full")
+
+	var a, b bytes.Buffer
+	_, err := MultiLevelWriter(errWriter{errPipe}, &a, errWriter{errDisk}, &b).WriteLevel(InfoLevel, []byte("x"))
+	if want := "broken pipe; disk full"; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+	if a.String() != "x" || b.String() != "x" {
+		t.Errorf("healthy writers got %q and %q, want x", a.String(), b.String())
+	}
+
+	var reported []*InternalError
+	sink := ErrorSinkFunc(func(err *InternalError) {
+		reported = append(reported, err)
+	})
+	a.Reset()
+	n, err := NewMultiLevelWriter(MultiWriterOptions{Sink: sink}, errWriter{errPipe}, &a).WriteLevel(WarnLevel, []byte("x"))
+	if n != 1 || err != nil {
+		t.Errorf("got (%d, %v), want (1, nil)", n, err)
+	}
+	if len(reported) != 1 || reported[0].Kind != WriteFailure || reported[0].Level != WarnLevel || !errors.Is(reported[0].Err, errPipe) {
+		t.Errorf("invalid reported errors: %v", reported)
+	}
+	if a.String() != "x" {
+		t.Errorf("healthy writer got %q, want x", a.String())
+	}
+	if _, err = NewMultiLevelWriter(MultiWriterOptions{Sink: sink}, errWriter{errPipe}).Write([]byte("x")); err != errPipe {
+		t.Errorf("got error %v, want %v when all the writers fail", err, errPipe)
+	}
+
+	a.Reset()
+	_, err = NewMultiLevelWriter(MultiWriterOptions{FailFast: true}, errWriter{errPipe}, &a, errWriter{errDisk}).Write([]byte("x"))
+	if err != errPipe || a.String() != "x" {
+		t.Errorf("got error %v and %q written, want %v and x written", err, a.String(), errPipe)
+	}
+
+	_, err = MultiLevelWriter(errWriter{errPipe}, errWriter{io.ErrShortWrite}).Write([]byte("x"))
+	if !errors.Is(err, errPipe) || !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("errors.Is doesn't match the errors of %v", err)
+	}
+}
+
 type testingLog struct {
 	testing.TB
 	buf bytes.Buffer
@@ -196,6 +240,22 @@ func TestFilteredLevelWriter(t *testing.T) {
 	}
 }
 
+func TestLevelThreshold(t *testing.T) {
+	var all, warn bytes.Buffer
+	log := New(MultiLevelWriter(&all, LevelThreshold(&warn, WarnLevel)))
+	log.Info().Msg("info")
+	log.Warn().Msg("warn")
+	log.Error().Msg("error")
+	if got, want := all.String(), `{"level":"info","message":"info"}`+"\n"+
+		`{"level":"warn","message":"warn"}`+"\n"+`{"level":"error","message":"error"}`+"\n"; got != want {
+		t.Errorf("invalid unfiltered output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := warn.String(), `{"level":"warn","message":"warn"}`+"\n"+
+		`{"level":"error","message":"error"}`+"\n"; got != want {
+		t.Errorf("invalid filtered output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 type testWrite struct {
 	Level
 	Line []byte
@@ -250,3 +310,57 @@ func TestTriggerLevelWriter(t *testing.T) {
 		})
 	}
 }
+
+type closeFlushWriter struct {
+	bytes.Buffer
+	closed, flushed int
+}
+
+func (w *closeFlushWriter) Close() error {
+	w.closed++
+	return nil
+}
+
+func (w *closeFlushWriter) Flush() error {
+	w.flushed++
+	return nil
+}
+
+func TestWriterFuncs(t *testing.T) {
+	var got []string
+	w := MultiLevelWriter(
+		WriterFunc(func(p []byte) (int, error) {
+			got = append(got, "writer")
+			return len(p), nil
+		}),
+		LevelWriterFunc(func(l Level, p []byte) (int, error) {
+			got = append(got, l.String())
+			return len(p), nil
+		}),
+	)
+	log := New(w)
+	log.Warn().Msg("")
+	if want := []string{"writer", "warn"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCloseFlushPropagation(t *testing.T) {
+	console := &closeFlushWriter{}
+	filtered := &closeFlushWriter{}
+	w := SyncWriter(MultiLevelWriter(
+		ConsoleWriter{Out: console, NoColor: true},
+		&FilteredLevelWriter{Writer: LevelWriterAdapter{filtered}, Level: InfoLevel},
+	))
+	if err := w.(interface{ Flush() error }).Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.(io.Closer).Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, cfw := range map[string]*closeFlushWriter{"console": console, "filtered": filtered} {
+		if cfw.flushed != 1 || cfw.closed != 1 {
+			t.Errorf("%s: flushed %d times, closed %d times, want 1 and 1", name, cfw.flushed, cfw.closed)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package zerolog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// YAMLWriter parses the JSON input and writes each event to Out as a YAML
+// document. It is meant for configuration dumps and diagnostic events, which
+// nested structures are far easier to read as YAML:
+//
+//	dump := log.Output(zerolog.NewYAMLWriter(os.Stderr))
+//	dump.Info().Interface("config", cfg).Msg("loaded")
+//	// Output:
+//	// ---
+//	// level: info
+//	// config:
+//	//   listen: ":8080"
+//	//   backends:
+//	//     - host: db1
+//	//       weight: 2
+//	// message: loaded
+//
+// Fields are written in the order of the event. Strings are only written as
+// plain scalars when they can't be read back as another type, multiline
+// strings are written as literal blocks.
+type YAMLWriter struct {
+	// Out is the output destination.
+	Out io.Writer
+
+	// Indent is the number of spaces of each indentation level. It defaults
+	// to 2.
+	Indent int
+
+	mu sync.Mutex
+}
+
+// NewYAMLWriter creates a YAMLWriter writing to out.
+func NewYAMLWriter(out io.Writer, options ...func(w *YAMLWriter)) *YAMLWriter {
+	w := &YAMLWriter{Out: out}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// yamlNode is a decoded JSON value keeping the order of the object keys.
+type yamlNode struct {
+	scalar string // scalar value, for values which are not containers
+	str    bool   // scalar is a string
+	object bool
+	array  bool
+	keys   []string
+	values []*yamlNode
+}
+
+// decodeYAMLNode decodes the next JSON value of d.
+func decodeYAMLNode(d *json.Decoder) (*yamlNode, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &yamlNode{}
+	switch tok := tok.(type) {
+	case json.Delim:
+		n.object = tok == '{'
+		n.array = !n.object
+		for d.More() {
+			if n.object {
+				key, err := d.Token()
+				if err != nil {
+					return nil, err
+				}
+				n.keys = append(n.keys, key.(string))
+			}
+			v, err := decodeYAMLNode(d)
+			if err != nil {
+				return nil, err
+			}
+			n.values = append(n.values, v)
+		}
+		if _, err := d.Token(); err != nil {
+			return nil, err
+		}
+	case string:
+		n.scalar, n.str = tok, true
+	case json.Number:
+		n.scalar = tok.String()
+	case bool:
+		n.scalar = fmt.Sprint(tok)
+	case nil:
+		n.scalar = "null"
+	}
+	return n, nil
+}
+
+// Write transforms the JSON input into a YAML document and writes it to
+// w.Out.
+func (w *YAMLWriter) Write(p []byte) (n int, err error) {
+	d := json.NewDecoder(bytes.NewReader(decodeIfBinaryToBytes(p)))
+	d.UseNumber()
+	root, err := decodeYAMLNode(d)
+	if err != nil {
+		return n, fmt.Errorf("cannot decode event: %s", err)
+	}
+
+	buf := consoleBufPool.Get().(*bytes.Buffer)
+	defer func() {
+		buf.Reset()
+		consoleBufPool.Put(buf)
+	}()
+	y := yamlEncoder{buf: buf, indent: w.Indent}
+	if y.indent <= 0 {
+		y.indent = 2
+	}
+	buf.WriteString("---\n")
+	if root.object && len(root.keys) > 0 {
+		y.object(root, 0, false)
+	} else {
+		y.value(root, 0)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err = buf.WriteTo(w.Out); err != nil {
+		return n, err
+	}
+	return len(p), nil
+}
+
+// Close calls the underlying writer's Close method if it is an io.Closer.
+// Otherwise does nothing.
+func (w *YAMLWriter) Close() error {
+	if closer, ok := w.Out.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// Flush calls the underlying writer's Flush method if any. Otherwise does
+// nothing.
+func (w *YAMLWriter) Flush() error {
+	return flush(w.Out)
+}
+
+type yamlEncoder struct {
+	buf    *bytes.Buffer
+	indent int
+}
+
+func (y yamlEncoder) pad(depth int) {
+	for i := 0; i < depth; i++ {
+		y.buf.WriteByte(' ')
+	}
+}
+
+// object writes the fields of the non empty object n at depth. If inline,
+// the first key follows an array item dash.
+func (y yamlEncoder) object(n *yamlNode, depth int, inline bool) {
+	for i, key := range n.keys {
+		if i > 0 || !inline {
+			y.pad(depth)
+		}
+		y.buf.WriteString(yamlString(key))
+		y.buf.WriteByte(':')
+		y.value(n.values[i], depth)
+	}
+}
+
+// array writes the items of the non empty array n at depth.
+func (y yamlEncoder) array(n *yamlNode, depth int) {
+	for _, v := range n.values {
+		y.pad(depth)
+		y.buf.WriteByte('-')
+		if v.object && len(v.keys) > 0 {
+			y.buf.WriteByte(' ')
+			y.object(v, depth+y.indent, true)
+		} else {
+			y.value(v, depth)
+		}
+	}
+}
+
+// value writes n, following a key or an array item dash at depth.
+func (y yamlEncoder) value(n *yamlNode, depth int) {
+	switch {
+	case n.object && len(n.keys) == 0:
+		y.buf.WriteString(" {}\n")
+	case n.array && len(n.values) == 0:
+		y.buf.WriteString(" []\n")
+	case n.object:
+		y.buf.WriteByte('\n')
+		y.object(n, depth+y.indent, false)
+	case n.array:
+		y.buf.WriteByte('\n')
+		y.array(n, depth+y.indent)
+	case !n.str:
+		y.buf.WriteByte(' ')
+		y.buf.WriteString(n.scalar)
+		y.buf.WriteByte('\n')
+	case yamlLiteral(n.scalar):
+		y.literal(n.scalar, depth+y.indent)
+	default:
+		y.buf.WriteByte(' ')
+		y.buf.WriteString(yamlString(n.scalar))
+		y.buf.WriteByte('\n')
+	}
+}
+
+// literal writes the multiline string s as a literal block which lines are
+// indented by depth.
+func (y yamlEncoder) literal(s string, depth int) {
+	body := strings.TrimRight(s, "\n")
+	trailing := len(s) - len(body)
+	switch trailing {
+	case 0:
+		y.buf.WriteString(" |-\n")
+	case 1:
+		y.buf.WriteString(" |\n")
+	default:
+		y.buf.WriteString(" |+\n")
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if line != "" {
+			y.pad(depth)
+			y.buf.WriteString(line)
+		}
+		y.buf.WriteByte('\n')
+	}
+	for i := 1; i < trailing; i++ {
+		y.buf.WriteByte('\n')
+	}
+}
+
+// yamlLiteral returns true if s is a multiline string which can be written
+// as a literal block.
+func yamlLiteral(s string) bool {
+	if !strings.Contains(s, "\n") || strings.TrimLeft(s, "\n")[0] == ' ' {
+		return false
+	}
+	for _, r := range s {
+		if r != '\n' && r != '\t' && !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// yamlString returns s as a plain scalar if it can't be read as anything else
+// than this string, or as a double-quoted scalar otherwise.
+func yamlString(s string) string {
+	if yamlPlain(s) {
+		return s
+	}
+	// JSON strings are valid YAML double-quoted scalars.
+	return string(appendJSONString(s))
+}
+
+func yamlPlain(s string) bool {
+	if s == "" || s[len(s)-1] == ' ' || s[len(s)-1] == ':' ||
+		strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@` .+0123456789") ||
+		strings.Contains(s, ": ") || strings.Contains(s, " #") {
+		return false
+	}
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestYAMLWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(NewYAMLWriter(out))
+	log.Info().
+		Interface("config", map[string]interface{}{
+			"listen":   ":8080",
+			"backends": []interface{}{map[string]interface{}{"host": "db1", "weight": 2}, "db2"},
+			"empty":    []int{},
+		}).
+		Strs("words", []string{"true", "0.5", "plain text", "a: b", ""}).
+		Str("script", "line 1\n  line 2\n").
+		Bool("ok", true).
+		Interface("nothing", nil).
+		Msg("loaded")
+	log.Log().Str("true", "x\x01y").Send()
+
+	want := `---
+level: info
+config:
+  backends:
+    - host: db1
+      weight: 2
+    - db2
+  empty: []
+  listen: ":8080"
+words:
+  - "true"
+  - "0.5"
+  - plain text
+  - "a: b"
+  - ""
+script: |
+  line 1
+    line 2
+ok: true
+nothing: null
+message: loaded
+---
+"true": "x\u0001y"
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid YAML output:\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}